@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamiraudit"
+)
+
+// When the audit-log setting (or SHAMIR_AUDIT_LOG) is set, the split, recover, verify, serve and service commands
+// append their audit events to that file as JSON lines (see the shamiraudit package), continuing the chain of the
+// events already logged. The events are signed when the audit-key setting (or SHAMIR_AUDIT_KEY) names a file
+// holding the hex or base64 encoding of an Ed25519 seed. The actor of the local commands is the user running them.
+//
+// The audit command checks that the events of a log follow each other, and that they are signed with the public
+// key of --key if set.
+
+func runAudit(args []string) error {
+	flags := newFlagSet("audit", "log file")
+	keyFile := flags.String("key", "", "file holding the Ed25519 public key the events must be signed with")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	var key ed25519.PublicKey
+	if *keyFile != "" {
+		var err error
+		if key, err = readPublicKey(*keyFile); err != nil {
+			return err
+		}
+	}
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	records, err := shamiraudit.ParseJSON(file)
+	if err != nil {
+		return err
+	}
+	report := auditReport{Events: len(records), Signed: key != nil}
+	if err := shamiraudit.Verify(records, key); err != nil {
+		report.Error = err.Error()
+	}
+	if jsonOutput {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+		if report.Error != "" {
+			return errReported
+		}
+		return nil
+	}
+	if report.Error != "" {
+		return errors.New(report.Error)
+	}
+	if key != nil {
+		fmt.Fprintf(os.Stderr, "OK: %d events, signed with the key\n", len(records))
+	} else {
+		fmt.Fprintf(os.Stderr, "OK: %d events\n", len(records))
+	}
+	return nil
+}
+
+// openAudit returns the audit logger of the commands, nil if audit-log is not set, along with a function closing
+// the log file.
+func openAudit() (*shamiraudit.Logger, func(), error) {
+	if defaults.AuditLog == "" {
+		return nil, func() {}, nil
+	}
+	options := []shamiraudit.Option{shamiraudit.WithSource("shamir")}
+	if defaults.AuditKey != "" {
+		key, err := readSigningKey(defaults.AuditKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, shamiraudit.WithSigningKey(key))
+	}
+	file, err := os.OpenFile(defaults.AuditLog, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, err := shamiraudit.ParseJSON(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("%s: %w", defaults.AuditLog, err)
+	}
+	if len(records) > 0 {
+		options = append(options, shamiraudit.WithPrevious(records[len(records)-1]))
+	}
+	logger := shamiraudit.New(slog.NewJSONHandler(file, nil), options...)
+	return logger, func() { file.Close() }, nil
+}
+
+// audit logs an event of a local command, whose actor is the user running it, along with the error of the
+// operation if not nil.
+func audit(logger *shamiraudit.Logger, event shamiraudit.Event, err error) error {
+	if logger == nil {
+		return nil
+	}
+	if current, err := user.Current(); err == nil {
+		event.Actor = current.Username
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	if err := logger.Log(context.Background(), event); err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	return nil
+}
+
+// readSigningKey reads an Ed25519 private key from a file holding the hex or base64 encoding of its seed.
+func readSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(data)
+	text := strings.TrimSpace(string(data))
+	seed, err := hex.DecodeString(text)
+	if err != nil {
+		seed, err = base64.StdEncoding.DecodeString(text)
+	}
+	defer clear(seed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("the audit key must be the hex or base64 encoding of an Ed25519 seed")
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
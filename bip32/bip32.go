@@ -0,0 +1,195 @@
+package bip32
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package splits the master seed of a BIP-32 hierarchical deterministic wallet
+// (https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki) along with derivation metadata, so that
+// recovery tooling can check that the recovered seed is the seed of the expected wallet.
+//
+// The metadata is stored in the label of every share (see shamir.Share), and is authenticated when the shares
+// are signed by the dealer. It holds the fingerprint of the master key and, for every account path, the first
+// 8 bytes of the identifier of the account key, which identifies its extended public key:
+//
+// 	bip32 3442193e m/84'/0'/0'=e889b6af7bea3a39 m/44'/0'/0'=...
+//
+// The label being limited to 255 bytes, a few account paths can be recorded.
+
+const (
+	labelPrefix        = "bip32"
+	accountIDLength    = 8
+	minSeedLength      = 16
+	maxSeedLength      = 64
+	fingerprintHexSize = 8
+)
+
+// ErrDerivationMismatch is returned when a recovered seed does not derive the keys recorded in the metadata of
+// its shares.
+var ErrDerivationMismatch = errors.New("bip32: the recovered seed does not match the derivation metadata")
+
+// Metadata is the derivation metadata recorded in the shares of a seed.
+type Metadata struct {
+	// Fingerprint is the fingerprint of the master key.
+	Fingerprint [4]byte
+	// Accounts maps the account paths to the first 8 bytes of the identifier of their key.
+	Accounts map[string][]byte
+	// paths holds the account paths in their recorded order.
+	paths []string
+}
+
+// Paths returns the account paths of the metadata, in the order they were recorded.
+func (m *Metadata) Paths() []string {
+	return append([]string{}, m.paths...)
+}
+
+// Split splits a BIP-32 master seed into n shares, such that threshold shares are required to recover it, and
+// records the fingerprint of the master key and the keys of the account paths, e.g. m/84'/0'/0', in the label of
+// the shares. If key is not nil, the shares are signed with the private key of the dealer.
+func Split(seed []byte, paths []string, n, threshold uint8, key ed25519.PrivateKey) ([]shamir.Share, error) {
+	metadata, err := NewMetadata(seed, paths)
+	if err != nil {
+		return nil, err
+	}
+	label := metadata.String()
+	if len(label) > 255 {
+		return nil, errors.New("bip32: too many account paths to record in the shares")
+	}
+
+	shares, err := shamir.Split(seed, n, threshold)
+	if err != nil {
+		return nil, err
+	}
+	for i := range shares {
+		shares[i].Label = label
+		if key != nil {
+			if shares[i], err = shamir.Sign(shares[i], key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return shares, nil
+}
+
+// Recover recovers a seed from its shares, and checks that it derives the keys recorded in their metadata. If key
+// is not nil, the shares must be signed by the dealer.
+func Recover(shares []shamir.Share, key ed25519.PublicKey) ([]byte, *Metadata, error) {
+	if len(shares) == 0 {
+		return nil, nil, errors.New("bip32: no share provided")
+	}
+	for _, share := range shares {
+		if share.Label != shares[0].Label {
+			return nil, nil, errors.New("bip32: the shares hold different derivation metadata")
+		}
+		if key != nil {
+			if err := shamir.Verify(share, key); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	expected, err := ParseMetadata(shares[0].Label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seed, err := shamir.Recover(shares)
+	if err != nil {
+		return nil, nil, err
+	}
+	actual, err := NewMetadata(seed, expected.paths)
+	if err != nil {
+		return nil, nil, err
+	}
+	if actual.Fingerprint != expected.Fingerprint {
+		return nil, nil, ErrDerivationMismatch
+	}
+	for _, path := range expected.paths {
+		if !bytes.Equal(actual.Accounts[path], expected.Accounts[path]) {
+			return nil, nil, ErrDerivationMismatch
+		}
+	}
+	return seed, expected, nil
+}
+
+// NewMetadata derives the metadata of a seed for the provided account paths.
+func NewMetadata(seed []byte, paths []string) (*Metadata, error) {
+	if len(seed) < minSeedLength || len(seed) > maxSeedLength {
+		return nil, errors.New("bip32: the seed must be 16 to 64 bytes long")
+	}
+	master, err := masterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	m := &Metadata{Fingerprint: master.fingerprint(), Accounts: make(map[string][]byte, len(paths))}
+	for _, path := range paths {
+		if _, ok := m.Accounts[path]; ok {
+			return nil, fmt.Errorf("bip32: duplicate account path %q", path)
+		}
+		account, err := derive(master, path)
+		if err != nil {
+			return nil, err
+		}
+		m.Accounts[path] = account.identifier()[:accountIDLength]
+		m.paths = append(m.paths, path)
+	}
+	return m, nil
+}
+
+// ParseMetadata parses the metadata recorded in the label of a share.
+func ParseMetadata(label string) (*Metadata, error) {
+	fields := strings.Fields(label)
+	if len(fields) < 2 || fields[0] != labelPrefix || len(fields[1]) != fingerprintHexSize {
+		return nil, errors.New("bip32: the share does not hold derivation metadata")
+	}
+	m := &Metadata{Accounts: make(map[string][]byte, len(fields)-2)}
+	if _, err := hex.Decode(m.Fingerprint[:], []byte(fields[1])); err != nil {
+		return nil, errors.New("bip32: invalid master key fingerprint")
+	}
+	for _, field := range fields[2:] {
+		path, encoded, ok := strings.Cut(field, "=")
+		id, err := hex.DecodeString(encoded)
+		if !ok || err != nil || len(id) != accountIDLength {
+			return nil, fmt.Errorf("bip32: invalid account metadata %q", field)
+		}
+		if _, err := parsePath(path); err != nil {
+			return nil, err
+		}
+		if _, ok := m.Accounts[path]; ok {
+			return nil, fmt.Errorf("bip32: duplicate account path %q", path)
+		}
+		m.Accounts[path] = id
+		m.paths = append(m.paths, path)
+	}
+	return m, nil
+}
+
+// String returns the encoding of the metadata in the label of a share.
+func (m *Metadata) String() string {
+	var b strings.Builder
+	b.WriteString(labelPrefix + " " + hex.EncodeToString(m.Fingerprint[:]))
+	for _, path := range m.paths {
+		fmt.Fprintf(&b, " %s=%x", path, m.Accounts[path])
+	}
+	return b.String()
+}
+
+// ExtendedPublicKey returns the extended public key (xpub) of the key derived from a seed along a path, e.g. in
+// order to compare it with the xpub of an account known to a wallet.
+func ExtendedPublicKey(seed []byte, path string) (string, error) {
+	master, err := masterKey(seed)
+	if err != nil {
+		return "", err
+	}
+	k, err := derive(master, path)
+	if err != nil {
+		return "", err
+	}
+	return k.xpub(), nil
+}
@@ -0,0 +1,164 @@
+package bip32
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Keys are derived as specified by BIP-32, using private derivation from the master key of the seed. Only the
+// derivation needed to check a recovered seed is implemented: the master key, the child keys along a path, and
+// the serialization of the extended public key (xpub) of the last key of the path.
+
+const (
+	hardened = 1 << 31
+	// xpubVersion is the version prefix of mainnet extended public keys.
+	xpubVersion = 0x0488b21e
+)
+
+// extendedKey is a private key and its chain code.
+type extendedKey struct {
+	key         secp256k1.ModNScalar
+	chainCode   [32]byte
+	depth       uint8
+	parent      [4]byte
+	childNumber uint32
+}
+
+// masterKey derives the master key of a seed.
+func masterKey(seed []byte) (*extendedKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	k := &extendedKey{}
+	if k.key.SetByteSlice(sum[:32]) || k.key.IsZero() {
+		return nil, errors.New("bip32: the seed derives an invalid master key")
+	}
+	copy(k.chainCode[:], sum[32:])
+	return k, nil
+}
+
+// child derives the child key of k with the provided index.
+func (k *extendedKey) child(index uint32) (*extendedKey, error) {
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	if index >= hardened {
+		var private [33]byte
+		k.key.PutBytesUnchecked(private[1:])
+		mac.Write(private[:])
+	} else {
+		mac.Write(k.publicKey())
+	}
+	mac.Write(binary.BigEndian.AppendUint32(nil, index))
+	sum := mac.Sum(nil)
+
+	child := &extendedKey{depth: k.depth + 1, parent: k.fingerprint(), childNumber: index}
+	if child.key.SetByteSlice(sum[:32]) {
+		return nil, fmt.Errorf("bip32: the child key %d is invalid", index)
+	}
+	child.key.Add(&k.key)
+	if child.key.IsZero() {
+		return nil, fmt.Errorf("bip32: the child key %d is invalid", index)
+	}
+	copy(child.chainCode[:], sum[32:])
+	return child, nil
+}
+
+// publicKey returns the compressed public key of k.
+func (k *extendedKey) publicKey() []byte {
+	return secp256k1.NewPrivateKey(&k.key).PubKey().SerializeCompressed()
+}
+
+// fingerprint returns the first 4 bytes of the identifier of k, i.e. of the HASH160 of its public key.
+func (k *extendedKey) fingerprint() [4]byte {
+	var fingerprint [4]byte
+	copy(fingerprint[:], k.identifier())
+	return fingerprint
+}
+
+// identifier returns the HASH160 of the public key of k.
+func (k *extendedKey) identifier() []byte {
+	sha := sha256.Sum256(k.publicKey())
+	h := ripemd160.New()
+	h.Write(sha[:])
+	return h.Sum(nil)
+}
+
+// xpub returns the Base58Check serialization of the extended public key of k.
+func (k *extendedKey) xpub() string {
+	data := binary.BigEndian.AppendUint32(make([]byte, 0, 82), xpubVersion)
+	data = append(data, k.depth)
+	data = append(data, k.parent[:]...)
+	data = binary.BigEndian.AppendUint32(data, k.childNumber)
+	data = append(data, k.chainCode[:]...)
+	data = append(data, k.publicKey()...)
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return base58(append(data, second[:4]...))
+}
+
+// derive derives the key of a path, e.g. m/84'/0'/0', from the master key.
+func derive(master *extendedKey, path string) (*extendedKey, error) {
+	indexes, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	k := master
+	for _, index := range indexes {
+		if k, err = k.child(index); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// parsePath parses a derivation path, in which hardened indexes are followed by ' or h.
+func parsePath(path string) ([]uint32, error) {
+	segments := strings.Split(path, "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf("bip32: invalid derivation path %q", path)
+	}
+	indexes := make([]uint32, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		var offset uint32
+		if trimmed := strings.TrimRight(segment, "'h"); len(trimmed) == len(segment)-1 {
+			segment, offset = trimmed, hardened
+		}
+		index, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil || index >= hardened {
+			return nil, fmt.Errorf("bip32: invalid derivation path %q", path)
+		}
+		indexes = append(indexes, uint32(index)+offset)
+	}
+	return indexes, nil
+}
+
+// base58 encodes data using the Bitcoin alphabet.
+func base58(data []byte) string {
+	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	x := new(big.Int).SetBytes(data)
+	radix, mod := big.NewInt(58), new(big.Int)
+	var encoded []byte
+	for x.Sign() > 0 {
+		x.DivMod(x, radix, mod)
+		encoded = append(encoded, alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, alphabet[0])
+	}
+	for i, j := 0, len(encoded)-1; i < j; i, j = i+1, j-1 {
+		encoded[i], encoded[j] = encoded[j], encoded[i]
+	}
+	return string(encoded)
+}
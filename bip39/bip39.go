@@ -0,0 +1,138 @@
+package bip39
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/wordlist"
+)
+
+// This package splits BIP-39 mnemonics (https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki)
+// using Shamir secret sharing.
+//
+// The words of a mnemonic encode some entropy (16 to 32 bytes) followed by a checksum.
+// The entropy, not the words, is split using the shamir package. Every share of the entropy is the same length
+// as the entropy itself, so that it can be encoded as a valid, checksummed mnemonic too.
+// The mnemonic of a share is not a usable wallet seed: it must be combined with other shares using Recover.
+
+// Share is the share of a BIP-39 mnemonic.
+// Index is the coordinate used to evaluate the polynomials for the participant and Threshold the number of shares
+// required to recover the secret: both must be kept along with the mnemonic, which cannot encode them.
+type Share struct {
+	Index     uint8
+	Threshold uint8
+	Mnemonic  string
+}
+
+// Split splits a BIP-39 mnemonic into n shares, such that threshold shares are required to recover it.
+func Split(mnemonic string, n, threshold uint8) ([]Share, error) {
+	entropy, err := MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := shamir.Split(entropy, n, threshold)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Share, len(shares))
+	for i, share := range shares {
+		words, err := EntropyToMnemonic(share.Payload)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = Share{Index: share.Index, Threshold: threshold, Mnemonic: words}
+	}
+	return result, nil
+}
+
+// Recover combines shares produced by Split and returns the original mnemonic.
+// An error is returned if fewer shares than their threshold are provided, since they would silently recover an
+// unrelated mnemonic.
+func Recover(shares []Share) (string, error) {
+	entropyShares := make([]shamir.Share, len(shares))
+	for i, share := range shares {
+		if share.Threshold == 0 || share.Threshold != shares[0].Threshold {
+			return "", errors.New("bip39: all shares must record the same, non-zero threshold")
+		}
+		entropy, err := MnemonicToEntropy(share.Mnemonic)
+		if err != nil {
+			return "", fmt.Errorf("share %d: %w", i+1, err)
+		}
+		entropyShares[i] = shamir.Share{Index: share.Index, Threshold: share.Threshold, Payload: entropy}
+	}
+	entropy, err := shamir.Recover(entropyShares)
+	if err != nil {
+		return "", err
+	}
+	return EntropyToMnemonic(entropy)
+}
+
+// EntropyToMnemonic encodes entropy as an English BIP-39 mnemonic.
+// The entropy must be between 16 and 32 bytes long, and a multiple of 4 bytes.
+func EntropyToMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", errors.New("bip39: the entropy must be between 16 and 32 bytes long, and a multiple of 4 bytes")
+	}
+
+	// the checksum is made of the first len(entropy)*8/32 bits of the SHA-256 hash of the entropy
+	checksumBits := len(entropy) / 4
+	hash := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), hash[0])
+
+	words := make([]string, (len(entropy)*8+checksumBits)/11)
+	for i := range words {
+		words[i] = wordlist.English[readBits(data, i*11, 11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes an English BIP-39 mnemonic into its entropy, verifying its checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, errors.New("bip39: a mnemonic must be made of 12, 15, 18, 21 or 24 words")
+	}
+
+	// total length in bits of the entropy and the checksum
+	totalBits := len(words) * 11
+	checksumBits := totalBits / 33
+	data := make([]byte, (totalBits+7)/8)
+	for i, word := range words {
+		index, ok := englishIndex[word]
+		if !ok {
+			return nil, fmt.Errorf("bip39: invalid word %q at position %d", word, i+1)
+		}
+		writeBits(data, i*11, 11, index)
+	}
+
+	entropy := data[:(totalBits-checksumBits)/8]
+	hash := sha256.Sum256(entropy)
+	if readBits(data, len(entropy)*8, checksumBits) != int(hash[0]>>(8-checksumBits)) {
+		return nil, errors.New("bip39: invalid mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+var englishIndex = wordlist.Index(wordlist.English)
+
+// readBits reads length bits from data, starting at bit offset (most significant bit first).
+func readBits(data []byte, offset, length int) int {
+	var value int
+	for i := offset; i < offset+length; i++ {
+		value = value<<1 | int(data[i/8]>>(7-i%8)&1)
+	}
+	return value
+}
+
+// writeBits writes the length least significant bits of value into data, starting at bit offset.
+func writeBits(data []byte, offset, length, value int) {
+	for i := 0; i < length; i++ {
+		if value>>(length-1-i)&1 == 1 {
+			data[(offset+i)/8] |= 1 << (7 - (offset+i)%8)
+		}
+	}
+}
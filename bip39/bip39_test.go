@@ -0,0 +1,127 @@
+package bip39
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// The English test vectors of BIP-39, from https://github.com/trezor/python-mnemonic/blob/master/vectors.json.
+var vectors = []struct {
+	entropy  string
+	mnemonic string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+	},
+	{
+		"80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+	},
+	{
+		"ffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+	},
+	{
+		"000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon " +
+			"abandon abandon abandon abandon abandon agent",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo when",
+	},
+	{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon " +
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+	},
+	{
+		"9e885d952ad362caeb4efe34a8e91bd2",
+		"ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+	},
+	{
+		"f585c11aec520db57dd353c69554b21a89b20fb0650966fa0a9d6f74fd989d8f",
+		"void come effort suffer camp survey warrior heavy shoot primary clutch crush open amazing screen " +
+			"patrol group space point ten exist slush involve unfold",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		mnemonic, err := EntropyToMnemonic(entropy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mnemonic != v.mnemonic {
+			t.Errorf("EntropyToMnemonic(%s) = %q, want %q", v.entropy, mnemonic, v.mnemonic)
+		}
+		decoded, err := MnemonicToEntropy(v.mnemonic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(decoded); got != v.entropy {
+			t.Errorf("MnemonicToEntropy(%q) = %s, want %s", v.mnemonic, got, v.entropy)
+		}
+	}
+	if _, err := MnemonicToEntropy("abandon abandon abandon abandon abandon abandon abandon abandon abandon " +
+		"abandon abandon abandon"); err == nil {
+		t.Error("MnemonicToEntropy() accepted an invalid checksum")
+	}
+}
+
+func TestSplitRecover(t *testing.T) {
+	mnemonic := "legal winner thank year wave sausage worth useful legal winner thank yellow"
+	shares, err := Split(mnemonic, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, share := range shares {
+		if share.Threshold != 3 {
+			t.Errorf("Split() share threshold = %d, want 3", share.Threshold)
+		}
+		if _, err := MnemonicToEntropy(share.Mnemonic); err != nil {
+			t.Errorf("Split() share %q is not a valid mnemonic: %v", share.Mnemonic, err)
+		}
+	}
+	for _, subset := range [][]Share{shares[:3], shares[2:], {shares[4], shares[0], shares[2]}} {
+		recovered, err := Recover(subset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recovered != mnemonic {
+			t.Errorf("Recover() = %q, want %q", recovered, mnemonic)
+		}
+	}
+}
+
+func TestRecoverTooFewShares(t *testing.T) {
+	shares, err := Split("letter advice cage absurd amount doctor acoustic avoid letter advice cage above", 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Recover(shares[:2]); err == nil {
+		t.Error("Recover() accepted fewer shares than the threshold")
+	}
+	unrecorded := []Share{shares[0], shares[1], shares[2]}
+	for i := range unrecorded {
+		unrecorded[i].Threshold = 0
+	}
+	if _, err := Recover(unrecorded); err == nil {
+		t.Error("Recover() accepted shares without a threshold")
+	}
+	mixed := []Share{shares[0], shares[1], shares[2]}
+	mixed[2].Threshold = 2
+	if _, err := Recover(mixed); err == nil {
+		t.Error("Recover() accepted shares with different thresholds")
+	}
+}
@@ -0,0 +1,498 @@
+package ceremony
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/hpke"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shamiraudit"
+)
+
+// A recovery ceremony lets custodians submit their shares from their own machines to a coordinator, which checks
+// every share as it is submitted, recovers the secret in memory once the threshold is reached, delivers it to a
+// sink (see Sink) and forgets it. The coordinator is served over HTTPS, and custodians are authenticated by their
+// TLS client certificates (see Config.Identify). The API is made of JSON documents, byte strings being base64
+// encoded:
+//
+// 	GET  /v1/ceremony   the status of the ceremony, see Status
+// 	POST /v1/shares     submit a share, see Submission; the response is the status of the ceremony
+// 	POST /v1/approvals  approve the recovery, as one of the approvers required by the release policies of the
+// 	                    shares (see shamir.Policy); the response is the status of the ceremony
+//
+// The coordinator enforces the release policies of the shares: a share is rejected before its not-before time,
+// and the secret is only recovered once all the approvers required by the shares submitted approved the recovery.
+// When Config.Roles is set, shares are accepted beyond the threshold until the shares of every role required are
+// submitted. A custodian could strip or edit the policy and the role of their share, so that the coordinator only
+// trusts them when the shares are signed by the dealer (see Config.DealerKey): without the key of the dealer,
+// shares carrying a policy or a role are refused, and Config.Roles cannot be set.
+//
+// Shares may be encrypted to the ephemeral X25519 key of the ceremony (Status.PublicKey) with HPKE (see Seal), so
+// that they are not exposed to the proxies terminating TLS on the way. The key is generated when the coordinator
+// is created, and is never stored. Errors are reported as {"error": "..."} along with a 4xx or 5xx status. Client
+// submits shares to a coordinator.
+
+// maxSubmissionSize is the maximum size of the body of a submission.
+const maxSubmissionSize = 1 << 20
+
+var (
+	// ErrCompleted is returned when a share is submitted once the ceremony is over.
+	ErrCompleted = errors.New("ceremony: the ceremony is over")
+	// ErrUnknownCustodian is returned when a share is submitted by a custodian who does not take part in the
+	// ceremony.
+	ErrUnknownCustodian = errors.New("ceremony: unknown custodian")
+	// ErrDuplicateSubmission is returned when a custodian submits a second share, or a share already submitted.
+	ErrDuplicateSubmission = errors.New("ceremony: the share was already submitted")
+	// ErrDuplicateApproval is returned when an approver approves the recovery twice.
+	ErrDuplicateApproval = errors.New("ceremony: the recovery was already approved")
+)
+
+// Config configures a recovery ceremony.
+type Config struct {
+	// Manifest is the manifest of the split, against which the shares and the recovered secret are checked.
+	// If nil, the shares must belong to the same split and the threshold is read from the shares.
+	Manifest *shamir.Manifest
+	// Custodians lists the identities of the custodians allowed to submit a share. If empty, any authenticated
+	// client may submit a share.
+	Custodians []string
+	// Identify returns the identity of the custodian submitting a request. It defaults to the common name of the
+	// verified TLS client certificate.
+	Identify func(r *http.Request) (string, error)
+	// Sink receives the recovered secret.
+	Sink Sink
+	// Audit logs the shares submitted, the approvals and the recovery, along with the custodians, if not nil. A
+	// share is only accepted once its verification is logged.
+	Audit *shamiraudit.Logger
+	// Roles is the minimum number of shares of every role required on top of the threshold, if not nil. It
+	// requires DealerKey.
+	Roles shamir.RoleQuorum
+	// DealerKey, if set, requires every share to be signed by the dealer (see shamir.Sign), which protects their
+	// release policies and roles.
+	DealerKey ed25519.PublicKey
+	// Now returns the current time, against which the not-before times of the shares are checked. It defaults to
+	// time.Now.
+	Now func() time.Time
+}
+
+// Submission is the body of a share submission. Exactly one of Share and Encrypted is set.
+type Submission struct {
+	// Share is the binary encoding of the share.
+	Share []byte `json:"share,omitempty"`
+	// Encrypted is the share encrypted to the key of the ceremony with Seal.
+	Encrypted []byte `json:"encrypted,omitempty"`
+}
+
+// Status is the status of a ceremony.
+type Status struct {
+	SplitID   *shamir.SplitID `json:"splitId,omitempty"`
+	Threshold uint8           `json:"threshold"`
+	// Received is the number of shares accepted so far.
+	Received int `json:"received"`
+	// Custodians lists the custodians who submitted a share.
+	Custodians []string `json:"custodians"`
+	// Approvals lists the approvers who approved the recovery.
+	Approvals []string `json:"approvals,omitempty"`
+	// Pending lists the approvers required by the shares submitted who did not approve the recovery yet.
+	Pending []string `json:"pending,omitempty"`
+	// MissingRoles lists the roles of which more shares are required.
+	MissingRoles []string `json:"missingRoles,omitempty"`
+	// PublicKey is the X25519 key the shares may be encrypted to.
+	PublicKey []byte `json:"publicKey"`
+	Completed bool   `json:"completed"`
+	// Error is set when the secret could not be recovered or delivered.
+	Error string `json:"error,omitempty"`
+}
+
+// Coordinator runs a recovery ceremony. It is safe for concurrent use.
+type Coordinator struct {
+	config     Config
+	key        hpke.PrivateKey
+	public     []byte
+	mu         sync.Mutex
+	shares     []shamir.Share
+	custodians []string
+	approvals  []string
+	completed  bool
+	err        error
+	done       chan struct{}
+}
+
+// New creates the coordinator of a ceremony, along with its ephemeral key.
+func New(config Config) (*Coordinator, error) {
+	if config.Sink == nil {
+		return nil, errors.New("ceremony: a sink is required")
+	}
+	if config.Roles != nil && config.DealerKey == nil {
+		return nil, errors.New("ceremony: the roles of the shares can only be trusted with the key of the dealer")
+	}
+	if config.Identify == nil {
+		config.Identify = ClientCertificateIdentity
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hpke.NewDHKEMPrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return &Coordinator{
+		config: config,
+		key:    key,
+		public: private.PublicKey().Bytes(),
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// ClientCertificateIdentity identifies custodians by the common name of their verified TLS client certificate.
+func ClientCertificateIdentity(r *http.Request) (string, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return "", errors.New("ceremony: a verified client certificate is required")
+	}
+	name := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	if name == "" {
+		return "", errors.New("ceremony: the client certificate has no common name")
+	}
+	return name, nil
+}
+
+// Done is closed once the ceremony is over, whether the secret was delivered or not (see Err).
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error which ended the ceremony, if any.
+func (c *Coordinator) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Status returns the status of the ceremony.
+func (c *Coordinator) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status()
+}
+
+func (c *Coordinator) status() Status {
+	s := Status{
+		Threshold:  c.threshold(),
+		Received:   len(c.shares),
+		Custodians: append([]string{}, c.custodians...),
+		Approvals:  slices.Clone(c.approvals),
+		PublicKey:  c.public,
+		Completed:  c.completed,
+	}
+	s.MissingRoles = c.config.Roles.Missing(c.shares)
+	for _, share := range c.shares {
+		for _, approver := range share.Policy.Approvers {
+			if !slices.Contains(c.approvals, approver) && !slices.Contains(s.Pending, approver) {
+				s.Pending = append(s.Pending, approver)
+			}
+		}
+	}
+	if c.config.Manifest != nil {
+		s.SplitID = &c.config.Manifest.SplitID
+	} else if len(c.shares) > 0 {
+		s.SplitID = &c.shares[0].SplitID
+	}
+	if c.err != nil {
+		s.Error = c.err.Error()
+	}
+	return s
+}
+
+// threshold returns the number of shares required, or 0 if it is not known yet.
+func (c *Coordinator) threshold() uint8 {
+	if c.config.Manifest != nil {
+		return c.config.Manifest.Threshold
+	}
+	if len(c.shares) > 0 {
+		return c.shares[0].Threshold
+	}
+	return 0
+}
+
+// Submit checks a share submitted by a custodian, and recovers and delivers the secret once the threshold is
+// reached.
+func (c *Coordinator) Submit(ctx context.Context, custodian string, submission Submission) (Status, error) {
+	share, err := c.open(submission)
+	if err != nil {
+		c.audit(ctx, shamiraudit.Event{Type: shamiraudit.ShareVerified, Actor: custodian}, err)
+		return Status{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	event := shamiraudit.Event{
+		Type:      shamiraudit.ShareVerified,
+		SplitID:   &share.SplitID,
+		Threshold: share.Threshold,
+		Shares:    []int{int(share.Index)},
+		Actor:     custodian,
+	}
+	if err := c.accept(custodian, share); err != nil {
+		c.audit(ctx, event, err)
+		return Status{}, err
+	}
+	if err := c.config.Audit.Log(ctx, event); err != nil {
+		return Status{}, err
+	}
+	c.shares = append(c.shares, share)
+	c.custodians = append(c.custodians, custodian)
+	c.complete(ctx)
+	return c.status(), c.err
+}
+
+// Approve records the approval of the recovery by an approver required by the release policies of the shares,
+// and recovers and delivers the secret if it was only waiting for this approval.
+func (c *Coordinator) Approve(ctx context.Context, approver string) (Status, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	event := shamiraudit.Event{Type: shamiraudit.RecoveryApproved, Actor: approver}
+	if c.config.Manifest != nil {
+		event.SplitID = &c.config.Manifest.SplitID
+	}
+	if c.completed {
+		c.audit(ctx, event, ErrCompleted)
+		return Status{}, ErrCompleted
+	}
+	if slices.Contains(c.approvals, approver) {
+		c.audit(ctx, event, ErrDuplicateApproval)
+		return Status{}, ErrDuplicateApproval
+	}
+	if err := c.config.Audit.Log(ctx, event); err != nil {
+		return Status{}, err
+	}
+	c.approvals = append(c.approvals, approver)
+	c.complete(ctx)
+	return c.status(), c.err
+}
+
+// complete recovers and delivers the secret once the threshold is reached, and the release policies of the shares
+// and the roles required are satisfied, which ends the ceremony.
+func (c *Coordinator) complete(ctx context.Context) {
+	threshold := c.threshold()
+	if threshold == 0 || len(c.shares) < int(threshold) {
+		return
+	}
+	if c.config.Roles.Check(c.shares) != nil || shamir.CheckPolicies(c.shares, c.config.Now(), c.approvals) != nil {
+		// wait for the shares of the missing roles and the missing approvals
+		return
+	}
+
+	event := shamiraudit.Event{
+		Type:      shamiraudit.RecoveryAttempted,
+		SplitID:   &c.shares[0].SplitID,
+		Threshold: threshold,
+		Shares:    shamiraudit.Indexes(c.shares),
+		Actor:     strings.Join(c.custodians, ","),
+	}
+	c.audit(ctx, event, nil)
+	c.err = c.recover(ctx)
+	event.Type = shamiraudit.RecoverySucceeded
+	if c.err != nil {
+		event.Type = shamiraudit.RecoveryFailed
+	}
+	c.audit(ctx, event, c.err)
+	c.completed = true
+	// the metadata of the shares is kept for the status of the ceremony
+	for i := range c.shares {
+		clear(c.shares[i].Payload)
+		c.shares[i].Payload = nil
+	}
+	close(c.done)
+}
+
+// accept checks that a custodian may submit a share, and that the share can be combined with the shares already
+// submitted.
+func (c *Coordinator) accept(custodian string, share shamir.Share) error {
+	if c.completed {
+		return ErrCompleted
+	}
+	if len(c.config.Custodians) > 0 && !slices.Contains(c.config.Custodians, custodian) {
+		return ErrUnknownCustodian
+	}
+	if slices.Contains(c.custodians, custodian) {
+		return ErrDuplicateSubmission
+	}
+	return c.check(share)
+}
+
+// audit logs an event, along with the error of the operation if not nil. Failures to log the events which do not
+// gate the ceremony are ignored, as the ceremony cannot be undone.
+func (c *Coordinator) audit(ctx context.Context, event shamiraudit.Event, err error) {
+	if err != nil {
+		event.Error = err.Error()
+	}
+	c.config.Audit.Log(ctx, event)
+}
+
+// open decodes the share of a submission, decrypting it if needed.
+func (c *Coordinator) open(submission Submission) (shamir.Share, error) {
+	var share shamir.Share
+	switch {
+	case submission.Share != nil && submission.Encrypted == nil:
+		if err := share.UnmarshalBinary(submission.Share); err != nil {
+			return shamir.Share{}, err
+		}
+		return share, nil
+	case submission.Encrypted != nil && submission.Share == nil:
+		encoded, err := hpke.Open(c.key, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), []byte(sealInfo),
+			submission.Encrypted)
+		if err != nil {
+			return shamir.Share{}, errors.New("ceremony: the share could not be decrypted")
+		}
+		defer clear(encoded)
+		if err := share.UnmarshalBinary(encoded); err != nil {
+			return shamir.Share{}, err
+		}
+		return share, nil
+	}
+	return shamir.Share{}, errors.New("ceremony: a submission holds either a share or an encrypted share")
+}
+
+// check checks that a share can be combined with the shares already submitted.
+func (c *Coordinator) check(share shamir.Share) error {
+	if c.config.DealerKey != nil {
+		if err := shamir.Verify(share, c.config.DealerKey); err != nil {
+			return err
+		}
+	} else if !share.Policy.IsZero() || share.Role != "" {
+		return errors.New("ceremony: the policy and the role of an unsigned share cannot be trusted, " +
+			"the key of the dealer is required")
+	}
+	if now := c.config.Now(); now.Before(share.Policy.NotBefore) {
+		return fmt.Errorf("%w: the share cannot be used before %s", shamir.ErrPolicy,
+			share.Policy.NotBefore.Format(time.RFC3339))
+	}
+	if c.config.Manifest != nil {
+		if err := c.config.Manifest.Validate([]shamir.Share{share}); err != nil {
+			return err
+		}
+	}
+	if len(c.shares) == 0 {
+		if c.config.Manifest == nil && share.Threshold == 0 {
+			return errors.New("ceremony: the threshold of the share is unknown, a manifest is required")
+		}
+		return nil
+	}
+	first := c.shares[0]
+	if share.SplitID != first.SplitID || share.Threshold != first.Threshold {
+		return errors.New("ceremony: the share belongs to another split")
+	}
+	if len(share.Payload) != len(first.Payload) || share.Polynomial != first.Polynomial ||
+		share.Padded != first.Padded {
+		return errors.New("ceremony: the share does not match the shares already submitted")
+	}
+	for _, other := range c.shares {
+		if other.Index == share.Index {
+			return ErrDuplicateSubmission
+		}
+	}
+	return nil
+}
+
+// recover recovers the secret from the shares submitted, and delivers it to the sink.
+func (c *Coordinator) recover(ctx context.Context) error {
+	var secret []byte
+	var err error
+	if c.config.Manifest != nil {
+		secret, err = c.config.Manifest.RecoverContext(ctx, c.shares)
+	} else {
+		secret, err = shamir.RecoverContext(ctx, c.shares)
+	}
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	if err := c.config.Sink.Deliver(ctx, secret); err != nil {
+		return fmt.Errorf("ceremony: the secret could not be delivered: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler of the ceremony API.
+func (c *Coordinator) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/ceremony", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := c.config.Identify(r); err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, c.Status())
+	})
+	mux.HandleFunc("POST /v1/shares", func(w http.ResponseWriter, r *http.Request) {
+		custodian, err := c.config.Identify(r)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+		var submission Submission
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSubmissionSize)).Decode(&submission); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		status, err := c.Submit(r.Context(), custodian, submission)
+		switch {
+		case err == nil:
+			writeJSON(w, http.StatusOK, status)
+		case errors.Is(err, ErrUnknownCustodian):
+			writeError(w, http.StatusForbidden, err)
+		case errors.Is(err, ErrCompleted), errors.Is(err, ErrDuplicateSubmission):
+			writeError(w, http.StatusConflict, err)
+		case status.Completed:
+			// the share was accepted, but the secret could not be recovered or delivered
+			writeJSON(w, http.StatusInternalServerError, status)
+		default:
+			writeError(w, http.StatusUnprocessableEntity, err)
+		}
+	})
+	mux.HandleFunc("POST /v1/approvals", func(w http.ResponseWriter, r *http.Request) {
+		approver, err := c.config.Identify(r)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+		status, err := c.Approve(r.Context(), approver)
+		switch {
+		case err == nil:
+			writeJSON(w, http.StatusOK, status)
+		case errors.Is(err, ErrCompleted), errors.Is(err, ErrDuplicateApproval):
+			writeError(w, http.StatusConflict, err)
+		case status.Completed:
+			writeJSON(w, http.StatusInternalServerError, status)
+		default:
+			writeError(w, http.StatusUnprocessableEntity, err)
+		}
+	})
+	return mux
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response.
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
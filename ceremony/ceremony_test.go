@@ -0,0 +1,74 @@
+package ceremony
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+func TestSubmitRestrictedShares(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("correct horse battery staple")
+	policy := shamir.Policy{NotBefore: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)}
+	shares, err := shamir.Split(bytes.Clone(secret), 3, 2, shamir.WithReleasePolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range shares {
+		if shares[i], err = shamir.Sign(shares[i], private); err != nil {
+			t.Fatal(err)
+		}
+	}
+	submission := func(share shamir.Share) Submission {
+		data, err := share.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Submission{Share: data}
+	}
+	var delivered []byte
+	config := Config{
+		Sink: SinkFunc(func(_ context.Context, secret []byte) error {
+			delivered = bytes.Clone(secret)
+			return nil
+		}),
+		Now: func() time.Time { return time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC) },
+	}
+
+	// without the key of the dealer, the policy of the shares cannot be trusted
+	coordinator, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := coordinator.Submit(context.Background(), "alice", submission(shares[0])); err == nil {
+		t.Error("Submit() accepted a share with a policy without the key of the dealer")
+	}
+	if _, err := New(Config{Sink: config.Sink, Roles: shamir.RoleQuorum{"legal": 1}}); err == nil {
+		t.Error("New() accepted roles without the key of the dealer")
+	}
+
+	config.DealerKey = public
+	if coordinator, err = New(config); err != nil {
+		t.Fatal(err)
+	}
+	stripped := shares[0]
+	stripped.Policy = shamir.Policy{}
+	if _, err := coordinator.Submit(context.Background(), "alice", submission(stripped)); err == nil {
+		t.Error("Submit() accepted a share whose policy was stripped")
+	}
+	for i, custodian := range []string{"alice", "bob"} {
+		if _, err := coordinator.Submit(context.Background(), custodian, submission(shares[i])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(delivered, secret) {
+		t.Errorf("delivered %q, want %q", delivered, secret)
+	}
+}
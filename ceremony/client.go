@@ -0,0 +1,136 @@
+package ceremony
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/hpke"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// Shares are sealed with HPKE (RFC 9180) in base mode, using DHKEM(X25519, HKDF-SHA256), HKDF-SHA256 and
+// ChaCha20-Poly1305. The encapsulated key is followed by the ciphertext of the binary encoding of the share. The
+// custodians are authenticated by the transport (see Config.Identify) rather than by HPKE: Client authenticates
+// them with the TLS client certificate of its HTTP client.
+
+// sealInfo is the HPKE info binding the sealed shares to their use.
+const sealInfo = "shamir-sss ceremony share"
+
+// Seal encrypts a share to the X25519 public key of a ceremony (Status.PublicKey).
+func Seal(publicKey []byte, share shamir.Share) ([]byte, error) {
+	public, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("ceremony: invalid public key: %w", err)
+	}
+	key, err := hpke.NewDHKEMPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	defer clear(encoded)
+	return hpke.Seal(key, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), []byte(sealInfo), encoded)
+}
+
+// Client submits shares to the coordinator of a ceremony.
+type Client struct {
+	// URL is the base URL of the coordinator, such as https://coordinator.example.com:8443.
+	URL string
+	// HTTPClient sends the requests. It should present the TLS client certificate of the custodian. It defaults
+	// to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Status returns the status of the ceremony.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodGet, "/v1/ceremony", nil)
+}
+
+// Submit encrypts a share to the key of the ceremony, and submits it. It returns the status of the ceremony once
+// the share is accepted.
+func (c *Client) Submit(ctx context.Context, share shamir.Share) (Status, error) {
+	status, err := c.Status(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	if status.Completed {
+		return Status{}, ErrCompleted
+	}
+	if status.SplitID != nil && *status.SplitID != share.SplitID {
+		return Status{}, fmt.Errorf("ceremony: the share belongs to split %s, the ceremony recovers split %s",
+			share.SplitID, *status.SplitID)
+	}
+	sealed, err := Seal(status.PublicKey, share)
+	if err != nil {
+		return Status{}, err
+	}
+	body, err := json.Marshal(Submission{Encrypted: sealed})
+	if err != nil {
+		return Status{}, err
+	}
+	return c.do(ctx, http.MethodPost, "/v1/shares", body)
+}
+
+// Approve approves the recovery, as one of the approvers required by the release policies of the shares.
+func (c *Client) Approve(ctx context.Context) (Status, error) {
+	return c.do(ctx, http.MethodPost, "/v1/approvals", nil)
+}
+
+// do sends a request to the coordinator, and decodes the status it responds with.
+func (c *Client) do(ctx context.Context, method, path string, body []byte) (Status, error) {
+	request, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return Status{}, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return Status{}, err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxSubmissionSize))
+	if err != nil {
+		return Status{}, err
+	}
+
+	// errors are reported as {"error": "..."}, which also decodes into a Status
+	var status Status
+	if err := json.Unmarshal(data, &status); err != nil {
+		return Status{}, fmt.Errorf("ceremony: invalid response from the coordinator (%s)", response.Status)
+	}
+	if response.StatusCode != http.StatusOK {
+		if status.Error == "" {
+			status.Error = response.Status
+		}
+		err := errors.New(status.Error)
+		switch response.StatusCode {
+		case http.StatusForbidden:
+			err = ErrUnknownCustodian
+		case http.StatusConflict:
+			if status.Error == ErrCompleted.Error() {
+				err = ErrCompleted
+			} else if status.Error == ErrDuplicateSubmission.Error() {
+				err = ErrDuplicateSubmission
+			} else if status.Error == ErrDuplicateApproval.Error() {
+				err = ErrDuplicateApproval
+			}
+		}
+		return status, err
+	}
+	return status, nil
+}
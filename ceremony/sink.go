@@ -0,0 +1,85 @@
+package ceremony
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Sink receives the secret recovered by a ceremony. The secret is cleared once Deliver returns, so that sinks
+// must not retain it.
+type Sink interface {
+	Deliver(ctx context.Context, secret []byte) error
+}
+
+// SinkFunc adapts a function to the Sink interface, e.g. to import the secret into a KMS.
+type SinkFunc func(ctx context.Context, secret []byte) error
+
+// Deliver implements the Sink interface.
+func (f SinkFunc) Deliver(ctx context.Context, secret []byte) error {
+	return f(ctx, secret)
+}
+
+// FileSink writes the secret to a new file, readable by its owner only.
+type FileSink struct {
+	Path string
+}
+
+// Deliver implements the Sink interface. It fails if the file already exists.
+func (s FileSink) Deliver(_ context.Context, secret []byte) error {
+	f, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(secret); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ExecSink runs a command, e.g. a hook importing the secret into a KMS, with the secret on its stdin.
+type ExecSink struct {
+	// Command is the name of the program and its arguments.
+	Command []string
+}
+
+// Deliver implements the Sink interface. The output of the command is reported if it fails.
+func (s ExecSink) Deliver(ctx context.Context, secret []byte) error {
+	if len(s.Command) == 0 {
+		return errors.New("ceremony: no command to run")
+	}
+	cmd := exec.CommandContext(ctx, s.Command[0], s.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(secret)
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", s.Command[0], err, strings.TrimSpace(output.String()))
+	}
+	return nil
+}
+
+// ParseSink parses the description of a sink: "file:PATH" or "exec:COMMAND [ARGUMENT...]", the arguments of the
+// command being separated by spaces.
+func ParseSink(s string) (Sink, error) {
+	kind, value, _ := strings.Cut(s, ":")
+	switch kind {
+	case "file":
+		if value != "" {
+			return FileSink{Path: value}, nil
+		}
+	case "exec":
+		if command := strings.Fields(value); len(command) > 0 {
+			return ExecSink{Command: command}, nil
+		}
+	}
+	return nil, fmt.Errorf("ceremony: invalid sink %q, expected file:PATH or exec:COMMAND", s)
+}
@@ -0,0 +1,64 @@
+package codex32
+
+// The codex32 checksum is a BCH code over GF(32). Unlike bech32, the human-readable part is not covered by
+// the checksum. The residue of the checksum is 65 bits wide, hence it is represented by its 5 high bits
+// and its 60 low bits.
+
+const lowMask = 1<<60 - 1
+
+// residue is a 65 bits value, split into its 5 high bits and its 60 low bits.
+type residue struct {
+	high, low uint64
+}
+
+var generators = [5]residue{
+	{0x19, 0xdc500ce73fde210},
+	{0x1b, 0xfae00def77fe529},
+	{0x1f, 0xbd920fffe7bee52},
+	{0x17, 0x39640bdeee3fdad},
+	{0x7, 0x729a039cfc75f5a},
+}
+
+// target is the residue of a valid codex32 string.
+var target = residue{0x10, 0xce0795c2fd1e62a}
+
+// polymod computes the residue of the values.
+func polymod(values []byte) residue {
+	r := residue{0, 0x23181b3}
+	for _, value := range values {
+		b := r.high
+		r = residue{r.low >> 55, (r.low<<5)&lowMask ^ uint64(value)}
+		for i, generator := range generators {
+			if b>>i&1 == 1 {
+				r.high ^= generator.high
+				r.low ^= generator.low
+			}
+		}
+	}
+	return r
+}
+
+// verifyChecksum verifies the checksum of the data part.
+func verifyChecksum(values []byte) bool {
+	return polymod(values) == target
+}
+
+// checksum computes the 13 checksum values of the data part.
+func checksum(values []byte) []byte {
+	padded := append(append([]byte{}, values...), make([]byte, checksumLength)...)
+	r := polymod(padded)
+	r.high ^= target.high
+	r.low ^= target.low
+
+	result := make([]byte, checksumLength)
+	for i := range result {
+		// the residue is 65 bits long, the first value holds its 5 high bits
+		shift := 5 * (checksumLength - 1 - i)
+		if shift >= 60 {
+			result[i] = byte(r.high)
+		} else {
+			result[i] = byte(r.low >> shift & 31)
+		}
+	}
+	return result
+}
@@ -0,0 +1,281 @@
+package codex32
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// This package implements the codex32 format described by BIP-93
+// (https://github.com/bitcoin/bips/blob/master/bip-0093.mediawiki), used to back up BIP-32 master seeds.
+//
+// A codex32 string is made of the human-readable part "ms", the separator "1" and a data part using the
+// bech32 character set:
+// 	- the threshold k, either "0" for an unshared secret or "2" to "9"
+// 	- a 4 characters identifier, shared by all the shares of a secret
+// 	- the share index: "s" is the secret itself, any other character is a share
+// 	- the payload, the secret or its share encoded in groups of 5 bits
+// 	- a 13 characters BCH checksum
+//
+// Unlike the shamir package, codex32 operates in GF(32): every character is a field element, and all the
+// characters of the data part are interpolated together. Since the checksum is linear, the strings derived
+// by interpolation are valid codex32 strings. This also means that shares computed by hand using the codex32
+// paper computers (volvelles) can be combined with this package, and the other way around.
+//
+// Only short codex32 strings are supported, of at most 93 characters, that is secrets of 16 to 44 bytes, which
+// covers the 128 to 256 bits seeds recommended by BIP-32; the 45 to 64 bytes seeds allowed by BIP-93 require long
+// codex32 strings. Encode sets the padding bits of the payload to zero, and Decode ignores them: BIP-93 allows
+// any value, as the padding bits of the shares are derived by interpolation like the other bits (see its test
+// vectors 1 and 4), so that several strings encode the same secret.
+
+const (
+	hrp            = "ms"
+	charset        = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	checksumLength = 13
+	headerLength   = 6
+	// maxDataLength is the maximum length of the data part before the checksum for short strings, which are at
+	// most 93 characters long
+	maxDataLength = 93 - len(hrp) - 1 - checksumLength
+	// minSecretLength is the minimum length of a secret in bytes, the 128 bits of the smallest BIP-32 seed
+	minSecretLength = 16
+	// secretIndex is the share index of the secret, i.e. the character "s"
+	secretIndex = 16
+)
+
+// shareIndices holds the characters used as share indices, in the order they are assigned by Split.
+const shareIndices = "acdefghjklmnpqrtuvwxyz023456789"
+
+// Share is a decoded codex32 string.
+type Share struct {
+	// Threshold is the number of shares required to recover the secret, or 0 for an unshared secret.
+	Threshold uint8
+	// Identifier is the 4 characters identifier of the secret.
+	Identifier string
+	// Index is the share index character, 's' for the secret itself.
+	Index byte
+	// Payload is the secret or share data.
+	Payload []byte
+}
+
+// Split splits a secret into n codex32 shares, such that threshold shares are required to recover it.
+// The identifier must be made of 4 bech32 characters and should be unique to the secret.
+// Following BIP-93, the first threshold-1 shares are picked at random and the remaining shares are
+// derived by interpolation along with the secret.
+func Split(secret []byte, identifier string, n, threshold uint8) ([]string, error) {
+	if threshold < 2 || threshold > 9 {
+		return nil, errors.New("codex32: the threshold must be between 2 and 9")
+	}
+	if n < threshold || int(n) > len(shareIndices) {
+		return nil, fmt.Errorf("codex32: the number of shares must be between the threshold and %d", len(shareIndices))
+	}
+
+	secretShare := Share{Threshold: threshold, Identifier: identifier, Index: 's', Payload: secret}
+	encoded, err := secretShare.encode()
+	if err != nil {
+		return nil, err
+	}
+
+	base := [][]byte{encoded}
+	for i := 0; i < int(threshold)-1; i++ {
+		payload := make([]byte, len(secret))
+		if _, err := rand.Read(payload); err != nil {
+			return nil, err
+		}
+		random := Share{Threshold: threshold, Identifier: identifier, Index: shareIndices[i], Payload: payload}
+		values, err := random.encode()
+		if err != nil {
+			return nil, err
+		}
+		base = append(base, values)
+	}
+
+	shares := make([]string, n)
+	for i := range shares {
+		if i < int(threshold)-1 {
+			shares[i] = toString(base[i+1])
+		} else {
+			shares[i] = toString(interpolate(base, byte(strings.IndexByte(charset, shareIndices[i]))))
+		}
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from codex32 shares. At least threshold shares must be provided,
+// or a single string holding the secret itself.
+func Combine(shares []string) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("codex32: no share provided")
+	}
+
+	decoded := make([]Share, len(shares))
+	values := make([][]byte, len(shares))
+	for i, s := range shares {
+		share, err := Decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("codex32: share %d: %w", i+1, err)
+		}
+		if share.Index == 's' {
+			return share.Payload, nil
+		}
+		if i > 0 {
+			first := decoded[0]
+			if share.Threshold != first.Threshold || share.Identifier != first.Identifier || len(share.Payload) != len(first.Payload) {
+				return nil, errors.New("codex32: the shares do not belong to the same secret")
+			}
+			for _, previous := range decoded[:i] {
+				if previous.Index == share.Index {
+					return nil, fmt.Errorf("codex32: duplicate share index %q", share.Index)
+				}
+			}
+		}
+		decoded[i] = share
+		values[i], _ = dataValues(strings.ToLower(s))
+	}
+
+	threshold := int(decoded[0].Threshold)
+	if threshold == 0 {
+		return nil, errors.New("codex32: an unshared secret must use the share index s")
+	}
+	if len(shares) < threshold {
+		return nil, fmt.Errorf("codex32: %d shares are required to recover the secret", threshold)
+	}
+	secret, err := Decode(toString(interpolate(values[:threshold], secretIndex)))
+	if err != nil {
+		return nil, err
+	}
+	return secret.Payload, nil
+}
+
+// Encode encodes the share as a lowercase codex32 string.
+func (s Share) Encode() (string, error) {
+	values, err := s.encode()
+	if err != nil {
+		return "", err
+	}
+	return toString(values), nil
+}
+
+// encode returns the values of the data part of the share, including the checksum.
+func (s Share) encode() ([]byte, error) {
+	if s.Threshold == 1 || s.Threshold > 9 {
+		return nil, errors.New("codex32: the threshold must be 0 or between 2 and 9")
+	}
+	if s.Threshold == 0 && s.Index != 's' {
+		return nil, errors.New("codex32: an unshared secret must use the share index s")
+	}
+	if len(s.Identifier) != 4 {
+		return nil, errors.New("codex32: the identifier must be 4 characters long")
+	}
+	if len(s.Payload) < minSecretLength {
+		return nil, fmt.Errorf("codex32: the secret must be at least %d bytes long", minSecretLength)
+	}
+	header := fmt.Sprintf("%d%s%c", s.Threshold, strings.ToLower(s.Identifier), s.Index)
+
+	values := make([]byte, 0, headerLength+(len(s.Payload)*8+4)/5+checksumLength)
+	for i := 0; i < len(header); i++ {
+		value := strings.IndexByte(charset, header[i])
+		if value < 0 {
+			return nil, fmt.Errorf("codex32: invalid character %q", header[i])
+		}
+		values = append(values, byte(value))
+	}
+	values = append(values, convertBits(s.Payload, 8, 5)...)
+	if len(values) > maxDataLength {
+		return nil, errors.New("codex32: the payload is too long for a short codex32 string")
+	}
+	return append(values, checksum(values)...), nil
+}
+
+// Decode parses a codex32 string and verifies its checksum.
+// Strings may be uppercase or lowercase, but not mixed case.
+func Decode(s string) (Share, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return Share{}, errors.New("codex32: mixed case string")
+	}
+	values, err := dataValues(strings.ToLower(s))
+	if err != nil {
+		return Share{}, err
+	}
+	if len(values) <= headerLength+checksumLength || len(values) > maxDataLength+checksumLength {
+		return Share{}, errors.New("codex32: invalid length")
+	}
+	if !verifyChecksum(values) {
+		return Share{}, errors.New("codex32: invalid checksum")
+	}
+
+	threshold := charset[values[0]]
+	if threshold != '0' && (threshold < '2' || threshold > '9') {
+		return Share{}, errors.New("codex32: invalid threshold")
+	}
+	share := Share{
+		Threshold:  threshold - '0',
+		Identifier: toChars(values[1:5]),
+		Index:      charset[values[5]],
+	}
+	if share.Threshold == 0 && share.Index != 's' {
+		return Share{}, errors.New("codex32: an unshared secret must use the share index s")
+	}
+
+	payload := values[headerLength : len(values)-checksumLength]
+	if len(payload)*5%8 > 4 {
+		return Share{}, errors.New("codex32: invalid payload padding")
+	}
+	share.Payload = convertBits(payload, 5, 8)
+	if len(share.Payload) < minSecretLength {
+		return Share{}, fmt.Errorf("codex32: the payload must be at least %d bytes long", minSecretLength)
+	}
+	return share, nil
+}
+
+// dataValues returns the values of the characters of the data part of a lowercase codex32 string.
+func dataValues(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, hrp+"1") {
+		return nil, errors.New("codex32: the string must start with ms1")
+	}
+	data := s[len(hrp)+1:]
+	values := make([]byte, len(data))
+	for i := 0; i < len(data); i++ {
+		value := strings.IndexByte(charset, data[i])
+		if value < 0 {
+			return nil, fmt.Errorf("codex32: invalid character %q at position %d", data[i], len(hrp)+2+i)
+		}
+		values[i] = byte(value)
+	}
+	return values, nil
+}
+
+// toString returns the codex32 string of the values of a data part.
+func toString(values []byte) string {
+	return hrp + "1" + toChars(values)
+}
+
+// toChars maps values to their bech32 characters.
+func toChars(values []byte) string {
+	var b strings.Builder
+	for _, value := range values {
+		b.WriteByte(charset[value])
+	}
+	return b.String()
+}
+
+// convertBits regroups the bits of data from groups of from bits to groups of to bits.
+// When encoding (from 8 to 5 bits), the last group is padded with zero bits. When decoding, the padding
+// bits are dropped.
+func convertBits(data []byte, from, to uint) []byte {
+	var accumulator, bits uint
+	var result []byte
+	for _, value := range data {
+		accumulator = accumulator<<from | uint(value)
+		bits += from
+		for bits >= to {
+			bits -= to
+			result = append(result, byte(accumulator>>bits&(1<<to-1)))
+		}
+		accumulator &= 1<<bits - 1
+	}
+	if to == 5 && bits > 0 {
+		result = append(result, byte(accumulator<<(to-bits)&(1<<to-1)))
+	}
+	return result
+}
@@ -0,0 +1,147 @@
+package codex32
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// The test vectors of BIP-93 using short codex32 strings.
+
+func TestDecodeVector1(t *testing.T) {
+	share, err := Decode("ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := hex.DecodeString("318c6318c6318c6318c6318c6318c631")
+	if share.Threshold != 0 || share.Identifier != "test" || share.Index != 's' || !bytes.Equal(share.Payload, want) {
+		t.Errorf("Decode() = %+v, want the secret %x", share, want)
+	}
+}
+
+func TestCombineVector2(t *testing.T) {
+	shares := []string{
+		"MS12NAMEA320ZYXWVUTSRQPNMLKJHGFEDCAXRPP870HKKQRM",
+		"MS12NAMECACDEFGHJKLMNPQRSTUVWXYZ023FTR2GDZMPY6PN",
+	}
+	secret, err := Combine(shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := hex.DecodeString("d1808e096b35b209ca12132b264662a5")
+	if !bytes.Equal(secret, want) {
+		t.Errorf("Combine() = %x, want %x", secret, want)
+	}
+	testDerive(t, shares, 's', "MS12NAMES6XQGUZTTXKEQNJSJZV4JV3NZ5K3KWGSPHUH6EVW")
+}
+
+func TestCombineVector3(t *testing.T) {
+	shares := []string{
+		"ms13casha320zyxwvutsrqpnmlkjhgfedca2a8d0zehn8a0t",
+		"ms13cashcacdefghjklmnpqrstuvwxyz023949xq35my48dr",
+		"ms13cashd0wsedstcdcts64cd7wvy4m90lm28w4ffupqs7rm",
+		"ms13casheekgpemxzshcrmqhaydlp6yhms3ws7320xyxsar9",
+		"ms13cashf8jh6sdrkpyrsp5ut94pj8ktehhw2hfvyrj48704",
+	}
+	want, _ := hex.DecodeString("ffeeddccbbaa99887766554433221100")
+	for _, subset := range [][]string{shares[:3], shares[2:], {shares[4], shares[1], shares[3]}} {
+		secret, err := Combine(subset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(secret, want) {
+			t.Errorf("Combine(%q) = %x, want %x", subset, secret, want)
+		}
+	}
+	testDerive(t, shares[:3], 's', "ms13cashsllhdmn9m42vcsamx24zrxgs3qqjzqud4m0d6nln")
+	testDerive(t, shares[:3], 'e', shares[3])
+	testDerive(t, shares[:3], 'f', shares[4])
+}
+
+func TestDecodeVector4(t *testing.T) {
+	share, err := Decode("ms10leetsllhdmn9m42vcsamx24zrxgs3qrl7ahwvhw4fnzrhve25gvezzyqqtum9pgv99ycma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := hex.DecodeString("ffeeddccbbaa99887766554433221100ffeeddccbbaa99887766554433221100")
+	if !bytes.Equal(share.Payload, want) {
+		t.Errorf("Decode() = %x, want %x", share.Payload, want)
+	}
+	encoded, err := Share{Identifier: "leet", Index: 's', Payload: want}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded != "ms10leetsllhdmn9m42vcsamx24zrxgs3qrl7ahwvhw4fnzrhve25gvezzyqqtum9pgv99ycma" {
+		t.Errorf("Encode() = %s", encoded)
+	}
+}
+
+// testDerive checks the share of index derived by interpolation of the shares.
+func testDerive(t *testing.T, shares []string, index byte, want string) {
+	t.Helper()
+	values := make([][]byte, len(shares))
+	for i, share := range shares {
+		values[i], _ = dataValues(strings.ToLower(share))
+	}
+	derived := toString(interpolate(values, byte(strings.IndexByte(charset, index))))
+	if derived != strings.ToLower(want) {
+		t.Errorf("derived share %c = %s, want %s", index, derived, strings.ToLower(want))
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid := "ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw"
+	for _, s := range []string{
+		valid[:len(valid)-1] + "q",                          // invalid checksum
+		"Ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw",  // mixed case
+		"ms10testsxxxxxxxxxxxxxxxxxxxxxxxxxxx4nzvca9cmczlw", // invalid length
+		"ms10tests",
+	} {
+		if _, err := Decode(s); err == nil {
+			t.Errorf("Decode(%q) accepted an invalid string", s)
+		}
+	}
+}
+
+func TestEncodeLength(t *testing.T) {
+	for _, length := range []int{15, 45, 46, 64} {
+		share := Share{Identifier: "leet", Index: 's', Payload: make([]byte, length)}
+		if _, err := share.Encode(); err == nil {
+			t.Errorf("Encode() accepted a %d bytes secret", length)
+		}
+	}
+	share := Share{Identifier: "leet", Index: 's', Payload: bytes.Repeat([]byte{0xff}, 44)}
+	encoded, err := share.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encoded) > 93 {
+		t.Errorf("Encode() = %d characters, want at most 93", len(encoded))
+	}
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Payload, share.Payload) {
+		t.Errorf("Decode() = %x, want %x", decoded.Payload, share.Payload)
+	}
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5a}, 32)
+	shares, err := Split(secret, "cash", 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := Combine([]string{shares[4], shares[0], shares[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Errorf("Combine() = %x, want %x", recovered, secret)
+	}
+	if _, err := Split(make([]byte, 45), "cash", 5, 3); err == nil {
+		t.Error("Split() accepted a 45 bytes secret")
+	}
+}
@@ -0,0 +1,51 @@
+package codex32
+
+// Arithmetic in GF(32), defined as GF(2)[x]/(x^5 + x^3 + 1) as in bech32.
+// Field elements are the values of the bech32 characters.
+
+// multiply computes a*b in GF(32).
+func multiply(a, b byte) byte {
+	var product uint
+	for i := 0; i < 5; i++ {
+		if b>>i&1 == 1 {
+			product ^= uint(a) << i
+		}
+	}
+	// reduce modulo x^5 + x^3 + 1
+	for i := 8; i >= 5; i-- {
+		if product>>i&1 == 1 {
+			product ^= 0x29 << (i - 5)
+		}
+	}
+	return byte(product)
+}
+
+// inverse computes 1/a in GF(32) as a^30, since a^31 = 1 for any non-zero element.
+func inverse(a byte) byte {
+	if a == 0 {
+		panic("division by 0")
+	}
+	result := byte(1)
+	for i := 0; i < 30; i++ {
+		result = multiply(result, a)
+	}
+	return result
+}
+
+// interpolate evaluates at x the polynomials going through the shares, for every character of the data part,
+// using Lagrange's algorithm. The share index of every share (its 6th value) is used as its coordinate.
+func interpolate(shares [][]byte, x byte) []byte {
+	result := make([]byte, len(shares[0]))
+	for i, si := range shares {
+		basis := byte(1)
+		for j, sj := range shares {
+			if j != i {
+				basis = multiply(basis, multiply(x^sj[5], inverse(si[5]^sj[5])))
+			}
+		}
+		for k := range result {
+			result[k] ^= multiply(basis, si[k])
+		}
+	}
+	return result
+}
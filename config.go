@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The defaults of the flags can be set for a team in a configuration file, by default config.yaml in the shamir
+// directory of the user configuration directory (e.g. ~/.config/shamir/config.yaml), or the file set by the
+// SHAMIR_CONFIG environment variable:
+//
+// 	shares: 5
+// 	threshold: 3
+// 	format: mnemonic
+// 	language: english
+// 	out-dir: shares/
+// 	name: share-{n}.txt
+// 	audit-log: /var/log/shamir/audit.log
+// 	audit-key: /etc/shamir/audit.key
+//
+// Every setting can be overridden by an environment variable (SHAMIR_SHARES, SHAMIR_THRESHOLD, SHAMIR_FORMAT,
+// SHAMIR_LANGUAGE, SHAMIR_OUT_DIR, SHAMIR_NAME, SHAMIR_AUDIT_LOG and SHAMIR_AUDIT_KEY), and flags override both.
+// The audit settings have no flags, so that they cannot be bypassed per command (see audit.go).
+
+// config holds the defaults of the flags.
+type config struct {
+	Shares    uint   `yaml:"shares"`
+	Threshold uint   `yaml:"threshold"`
+	Format    string `yaml:"format"`
+	Language  string `yaml:"language"`
+	OutDir    string `yaml:"out-dir"`
+	Name      string `yaml:"name"`
+	AuditLog  string `yaml:"audit-log"`
+	AuditKey  string `yaml:"audit-key"`
+}
+
+// defaults holds the defaults of the flags, once loaded by loadConfig.
+var defaults = config{
+	Shares:    5,
+	Threshold: 3,
+	Format:    formatHex,
+	Language:  "english",
+	Name:      defaultNameTemplate,
+}
+
+// loadConfig loads the configuration file, if any, then the environment variables into defaults.
+func loadConfig() error {
+	path := os.Getenv("SHAMIR_CONFIG")
+	explicit := path != ""
+	if !explicit {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return loadEnv()
+		}
+		path = filepath.Join(dir, "shamir", "config.yaml")
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && !explicit:
+	case err != nil:
+		return err
+	default:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&defaults); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return loadEnv()
+}
+
+// loadEnv loads the environment variables into defaults.
+func loadEnv() error {
+	for _, v := range []struct {
+		name  string
+		value *uint
+	}{{"SHAMIR_SHARES", &defaults.Shares}, {"SHAMIR_THRESHOLD", &defaults.Threshold}} {
+		if s, ok := os.LookupEnv(v.name); ok {
+			n, err := strconv.ParseUint(s, 10, 8)
+			if err != nil {
+				return fmt.Errorf("%s: %w", v.name, err)
+			}
+			*v.value = uint(n)
+		}
+	}
+	for _, v := range []struct {
+		name  string
+		value *string
+	}{
+		{"SHAMIR_FORMAT", &defaults.Format},
+		{"SHAMIR_LANGUAGE", &defaults.Language},
+		{"SHAMIR_OUT_DIR", &defaults.OutDir},
+		{"SHAMIR_NAME", &defaults.Name},
+		{"SHAMIR_AUDIT_LOG", &defaults.AuditLog},
+		{"SHAMIR_AUDIT_KEY", &defaults.AuditKey},
+	} {
+		if s, ok := os.LookupEnv(v.name); ok {
+			*v.value = s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shareqr"
+)
+
+// The convert command re-encodes a share for another storage medium, without splitting the secret again. Shares
+// longer than a single QR code are written as several images, suffixed with the number of the part.
+
+const (
+	formatAuto   = "auto"
+	formatSLIP39 = "slip39"
+	qrSize       = 512
+)
+
+func runConvert(args []string) error {
+	flags := newFlagSet("convert", "")
+	allFormats := strings.Join(append(formats[:len(formats):len(formats)], formatBinary, formatQR), ", ")
+	from := flags.String("from", formatAuto, "format of the input share: "+formatAuto+", "+allFormats)
+	to := flags.String("to", defaults.Format, "format of the output share: "+allFormats)
+	in := flags.String("in", "-", "file holding the share, - for stdin")
+	out := flags.String("out", "-", "file to write the share to, - for stdout")
+	language := flags.String("language", defaults.Language, "language of the wordlist of mnemonic shares")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return errUsage
+	}
+	if *from == formatSLIP39 || *to == formatSLIP39 {
+		return errors.New("SLIP-0039 shares use their own sharing scheme and cannot be converted, " +
+			"split the secret again with the slip39 package")
+	}
+
+	data, err := readInput(*in)
+	if err != nil {
+		return err
+	}
+	format := *from
+	if format == formatAuto {
+		format = detectFormat(data)
+	}
+	share, err := decodeShareAs(data, format, *language)
+	if err != nil {
+		return err
+	}
+
+	var outputs [][]byte
+	switch *to {
+	case formatBinary:
+		encoded, err := share.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		outputs = [][]byte{encoded}
+	case formatQR:
+		if outputs, err = shareqr.PNG(share, qrSize); err != nil {
+			return err
+		}
+		if len(outputs) > 1 && *out == "-" {
+			return fmt.Errorf("the share spans %d QR codes, use --out to write them to files", len(outputs))
+		}
+	default:
+		encoded, err := encodeShare(share, *to, *language)
+		if err != nil {
+			return err
+		}
+		outputs = [][]byte{[]byte(encoded + "\n")}
+	}
+
+	report := convertReport{Index: share.Index, SplitID: share.SplitID, From: format, To: *to}
+	if *out == "-" {
+		if !jsonOutput {
+			_, err := os.Stdout.Write(outputs[0])
+			return err
+		}
+		if *to == formatBinary || *to == formatQR {
+			report.Data = outputs[0]
+		} else {
+			report.Share = strings.TrimSuffix(string(outputs[0]), "\n")
+		}
+		return printJSON(report)
+	}
+	for i, output := range outputs {
+		path := *out
+		if len(outputs) > 1 {
+			extension := filepath.Ext(path)
+			path = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, extension), i+1, extension)
+		}
+		if err := os.WriteFile(path, output, 0o600); err != nil {
+			return err
+		}
+		report.Outputs = append(report.Outputs, path)
+	}
+	if jsonOutput {
+		return printJSON(report)
+	}
+	return nil
+}
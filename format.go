@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shareqr"
+)
+
+// Shares are written as text, one share per file or per line unless the format spans several lines (pem): the
+// binary encoding of the share is hex or base64 encoded, or the share is encoded as an armored block (see
+// shamir.EncodePEM), a JSON object, words (see shamir.EncodeMnemonic), a Bech32m string (see shamir.EncodeBech32m)
+// or a URI (see shamir.FormatURI). Shares can also be read from and written to files holding their raw binary
+// encoding (binary) or PNG images of QR codes (qr, see the shareqr package).
+//
+// When reading shares, the format is detected from their first bytes: a share holding whitespace is made of
+// words, a share made of hex digits is hex encoded, a share starting with the default human-readable part is
+// encoded with Bech32m, and any other share is base64 encoded.
+
+const (
+	formatHex      = "hex"
+	formatBase64   = "base64"
+	formatPEM      = "pem"
+	formatJSON     = "json"
+	formatMnemonic = "mnemonic"
+	formatBech32   = "bech32"
+	formatURI      = "uri"
+	formatBinary   = "binary"
+	formatQR       = "qr"
+)
+
+// formats lists the text formats of shares.
+var formats = []string{formatHex, formatBase64, formatPEM, formatJSON, formatMnemonic, formatBech32, formatURI}
+
+// encodeShare encodes a share in the provided text format, using the wordlist of language for mnemonics.
+func encodeShare(share shamir.Share, format, language string) (string, error) {
+	switch format {
+	case formatHex, formatBase64:
+		data, err := share.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		if format == formatHex {
+			return hex.EncodeToString(data), nil
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	case formatPEM:
+		data, err := shamir.EncodePEM(share)
+		return strings.TrimSuffix(string(data), "\n"), err
+	case formatJSON:
+		data, err := json.Marshal(share)
+		return string(data), err
+	case formatMnemonic:
+		return shamir.EncodeMnemonic(share, shamir.WithLanguage(language))
+	case formatBech32:
+		return shamir.EncodeBech32m(share, shamir.DefaultHRP)
+	case formatURI:
+		return shamir.FormatURI(share), nil
+	}
+	return "", fmt.Errorf("unknown share format %q, expected one of %s", format, strings.Join(formats, ", "))
+}
+
+// detectFormat returns the format of an encoded share.
+func detectFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("SHMR")):
+		return formatBinary
+	case bytes.HasPrefix(data, []byte("\x89PNG")), bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return formatQR
+	}
+	text := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(text, "-----BEGIN"):
+		return formatPEM
+	case strings.HasPrefix(text, "{"):
+		return formatJSON
+	case strings.HasPrefix(text, shamir.URIScheme+":"):
+		return formatURI
+	case strings.ContainsFunc(text, unicode.IsSpace):
+		return formatMnemonic
+	case strings.HasPrefix(strings.ToLower(text), shamir.DefaultHRP+"1"):
+		return formatBech32
+	}
+	if _, err := hex.DecodeString(text); err == nil {
+		return formatHex
+	}
+	return formatBase64
+}
+
+// decodeShare decodes a share whatever its format, using the wordlist of language for mnemonics.
+func decodeShare(data []byte, language string) (shamir.Share, error) {
+	return decodeShareAs(data, detectFormat(data), language)
+}
+
+// correctShare decodes a share like decodeShare, but fixes the mistyped words of mnemonics and the mistyped
+// characters of Bech32m strings (see shamir.CorrectMnemonic and shamir.CorrectBech32m). It returns a description
+// of the corrections, or an empty string if the share was not corrected.
+func correctShare(data []byte, language string) (shamir.Share, string, error) {
+	text := strings.TrimSpace(string(data))
+	var share shamir.Share
+	var positions []int
+	var err error
+	unit := "word"
+	switch detectFormat(data) {
+	case formatMnemonic:
+		share, positions, err = shamir.CorrectMnemonic(text, shamir.WithLanguage(language))
+	case formatBech32:
+		share, _, positions, err = shamir.CorrectBech32m(text)
+		unit = "character"
+	default:
+		share, err = decodeShare(data, language)
+	}
+	if err != nil || len(positions) == 0 {
+		return share, "", err
+	}
+	if len(positions) == 1 {
+		return share, fmt.Sprintf("corrected the %s at position %d", unit, positions[0]), nil
+	}
+	described := make([]string, len(positions))
+	for i, position := range positions {
+		described[i] = fmt.Sprint(position)
+	}
+	return share, fmt.Sprintf("corrected the %ss at positions %s", unit, strings.Join(described, ", ")), nil
+}
+
+// decodeBinary returns the binary encoding of a share in the binary, hex or base64 format.
+func decodeBinary(data []byte, format string) ([]byte, error) {
+	text := strings.TrimSpace(string(data))
+	var binary []byte
+	var err error
+	switch format {
+	case formatBinary:
+		return data, nil
+	case formatHex:
+		binary, err = hex.DecodeString(text)
+	default:
+		binary, err = base64.StdEncoding.DecodeString(text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s share: %w", format, err)
+	}
+	return binary, nil
+}
+
+// decodeSchemeShare returns the binary encoding of a share of another scheme than the default one, which
+// decodeShare does not handle (see shamir.Scheme), and its scheme. ok is false for the shares of the default
+// scheme and the shares which are not encoded in binary.
+func decodeSchemeShare(data []byte) (encoded []byte, scheme shamir.Scheme, ok bool) {
+	format := detectFormat(data)
+	if format != formatBinary && format != formatHex && format != formatBase64 {
+		return nil, nil, false
+	}
+	encoded, err := decodeBinary(data, format)
+	if err != nil {
+		return nil, nil, false
+	}
+	scheme, err = shamir.SchemeOf(encoded)
+	if err != nil || scheme.ID() == shamir.Version {
+		return nil, nil, false
+	}
+	return encoded, scheme, true
+}
+
+// decodeShareAs decodes a share in the provided format, using the wordlist of language for mnemonics.
+func decodeShareAs(data []byte, format, language string) (shamir.Share, error) {
+	text := strings.TrimSpace(string(data))
+	var share shamir.Share
+	switch format {
+	case formatBinary, formatHex, formatBase64:
+		binary, err := decodeBinary(data, format)
+		if err != nil {
+			return shamir.Share{}, err
+		}
+		// shares without the binary envelope are legacy shares
+		return shamir.ParseShare(binary)
+	case formatPEM:
+		share, _, err := shamir.DecodePEM(data)
+		return share, err
+	case formatJSON:
+		if err := json.Unmarshal(data, &share); err != nil {
+			return shamir.Share{}, err
+		}
+		return share, nil
+	case formatMnemonic:
+		return shamir.DecodeMnemonic(text, shamir.WithLanguage(language))
+	case formatBech32:
+		share, _, err := shamir.DecodeBech32m(text)
+		return share, err
+	case formatURI:
+		return shamir.ParseURI(text)
+	case formatQR:
+		contents, err := shareqr.ReadImage(bytes.NewReader(data))
+		if err != nil {
+			return shamir.Share{}, err
+		}
+		shares, err := shareqr.Assemble(contents)
+		if err != nil {
+			return shamir.Share{}, err
+		}
+		if len(shares) != 1 {
+			return shamir.Share{}, fmt.Errorf("the image holds %d shares, expected 1", len(shares))
+		}
+		return shares[0], nil
+	}
+	return shamir.Share{}, fmt.Errorf("unknown share format %q", format)
+}
@@ -0,0 +1,49 @@
+package galois
+
+import (
+	"errors"
+	"io"
+	"math/big"
+)
+
+// ErrDivisionByZero is the value of the panic raised when dividing by zero, or inverting zero.
+// Dividing by zero is a programming error: Shamir's scheme only divides by the difference of distinct
+// coordinates.
+var ErrDivisionByZero = errors.New("galois: division by zero")
+
+// Field is a finite field whose elements are of type E, e.g. uint8 for Field256, uint16 for Field65536 and
+// *big.Int for FieldPrime. Polynomial evaluation and interpolation can be written once against this interface
+// and reused for every field.
+type Field[E any] interface {
+	// Zero returns the additive identity.
+	Zero() E
+	// One returns the multiplicative identity.
+	One() E
+	// Add computes the addition a+b.
+	Add(a, b E) E
+	// Subtract computes the substraction a-b.
+	Subtract(a, b E) E
+	// Multiply computes the multiplication a*b.
+	Multiply(a, b E) E
+	// Divide computes the division a/b. It panics with ErrDivisionByZero if b is 0.
+	Divide(a, b E) E
+	// Inverse computes the multiplicative inverse of a. It panics with ErrDivisionByZero if a is 0.
+	Inverse(a E) E
+	// Equal returns true if a and b are the same element.
+	Equal(a, b E) bool
+	// ElementSize returns the length in bytes of the encoding of an element.
+	ElementSize() int
+	// PutElement encodes a into the first ElementSize bytes of dst, using the canonical encoding of the field
+	// (big-endian for the fields of this package).
+	PutElement(dst []byte, a E)
+	// Element decodes the element encoded in the first ElementSize bytes of src.
+	Element(src []byte) E
+	// Random returns a uniformly random element, reading randomness from r.
+	Random(r io.Reader) (E, error)
+}
+
+var (
+	_ Field[uint8]    = (*Field256)(nil)
+	_ Field[uint16]   = (*Field65536)(nil)
+	_ Field[*big.Int] = (*FieldPrime)(nil)
+)
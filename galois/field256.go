@@ -2,10 +2,19 @@ package galois
 
 import (
 	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/etiennebch/shamir-sss/random"
 )
 
 // Much of the operations implemented follow the logic described at https://www.samiam.org/galois.html
 // we use a generator of 229, as demonstrated in the example.
+//
+// Multiplication and division use the log and exp tables below rather than multiplying polynomials bit by bit:
+// if g is the generator, exp229[i] = g^i and log229[g^i] = i, so that a*b = exp229[(log229[a]+log229[b]) % 255].
+// The tables of other reduction polynomials are computed by NewField256WithPolynomial.
 
 var log229 = [256]uint8{
 	0x00, 0xff, 0xc8, 0x08, 0x91, 0x10, 0xd0, 0x36,
@@ -77,12 +86,130 @@ var exp229 = [256]uint8{
 	0xaa, 0xcd, 0x9a, 0xa0, 0x75, 0x54, 0x0e, 0x01,
 }
 
+// GF(2^8) can be built using any irreducible polynomial of degree 8 as the reduction polynomial. The resulting
+// fields are isomorphic, but the same bytes represent different elements, so that shares can only be combined
+// by implementations using the same polynomial: the AES polynomial 0x11B is used by default, and
+// NewField256WithPolynomial supports others, e.g. the Reed-Solomon polynomial 0x11D.
+
+const (
+	// PolynomialAES is the reduction polynomial x^8 + x^4 + x^3 + x + 1 used by AES.
+	PolynomialAES uint16 = 0x11b
+	// PolynomialReedSolomon is the reduction polynomial x^8 + x^4 + x^3 + x^2 + 1 commonly used by
+	// Reed-Solomon codes.
+	PolynomialReedSolomon uint16 = 0x11d
+)
+
+// tables256 holds the log and exp tables of GF(2^8) for a reduction polynomial.
+type tables256 struct {
+	polynomial uint16
+	generator  uint8
+	log, exp   [256]uint8
+}
+
+var aesTables = &tables256{polynomial: PolynomialAES, generator: 229, log: log229, exp: exp229}
+
 // Field256 represents the Galois finite field 2^8.
-type Field256 struct{}
+// The zero value uses the AES polynomial. A Field256 is immutable and safe for concurrent use.
+type Field256 struct {
+	tables       *tables256
+	constantTime bool
+}
+
+// Option configures a Field256.
+type Option func(*Field256)
+
+// WithConstantTime makes Multiply, Divide and Inverse run in constant time: the products are computed bit by bit
+// rather than looked up in the log and exp tables, whose access pattern depends on the operands and can leak
+// them through cache-timing side channels. This is about an order of magnitude slower.
+func WithConstantTime() Option {
+	return func(f *Field256) {
+		f.constantTime = true
+	}
+}
+
+// NewField256 returns a pointer to a new Field256 struct, using the AES polynomial.
+func NewField256(options ...Option) *Field256 {
+	f := &Field256{tables: aesTables}
+	for _, option := range options {
+		option(f)
+	}
+	return f
+}
+
+// NewField256WithPolynomial returns a pointer to a new Field256 struct using the provided reduction polynomial,
+// e.g. PolynomialReedSolomon. It returns an error if the polynomial is not an irreducible polynomial of degree 8.
+func NewField256WithPolynomial(polynomial uint16, options ...Option) (*Field256, error) {
+	if polynomial == PolynomialAES {
+		return NewField256(options...), nil
+	}
+	if polynomial&0xff00 != 0x100 {
+		return nil, errors.New("galois: the reduction polynomial must be of degree 8")
+	}
+	// the polynomial is irreducible if and only if the multiplicative group of the quotient ring is cyclic of
+	// order 255, so that looking for a generator also checks the polynomial
+	for generator := 2; generator < 256; generator++ {
+		if t, ok := powers(generator, polynomial); ok {
+			f := &Field256{tables: t}
+			for _, option := range options {
+				option(f)
+			}
+			return f, nil
+		}
+	}
+	return nil, errors.New("galois: the reduction polynomial is not irreducible")
+}
+
+// powers computes the log and exp tables of the generator modulo the polynomial. It returns false if the
+// multiplicative order of the generator is not 255.
+func powers(generator int, polynomial uint16) (*tables256, bool) {
+	t := &tables256{polynomial: polynomial, generator: uint8(generator)}
+	x := 1
+	for i := 0; i < 255; i++ {
+		if i > 0 && x == 1 {
+			return nil, false
+		}
+		t.exp[i] = uint8(x)
+		t.log[x] = uint8(i)
+		x = multiplyPolynomial(x, generator, polynomial)
+	}
+	if x != 1 {
+		return nil, false
+	}
+	t.exp[255] = 1
+	return t, true
+}
 
-// NewField256 returns a pointer to a new Field256 struct.
-func NewField256() *Field256 {
-	return &Field256{}
+// multiplyPolynomial multiplies a and b as polynomials over GF(2) modulo the polynomial, one bit at a time.
+func multiplyPolynomial(a, b int, polynomial uint16) int {
+	var product int
+	for ; b != 0; b >>= 1 {
+		if b&1 != 0 {
+			product ^= a
+		}
+		a <<= 1
+		if a&0x100 != 0 {
+			a ^= int(polynomial)
+		}
+	}
+	return product
+}
+
+// ConstantTime returns true if the field runs in constant time (see WithConstantTime).
+func (f *Field256) ConstantTime() bool {
+	return f.constantTime
+}
+
+// Polynomial returns the reduction polynomial of the field.
+func (f *Field256) Polynomial() uint16 {
+	return f.t().polynomial
+}
+
+// t returns the tables of the field.
+func (f *Field256) t() *tables256 {
+	if f.tables == nil {
+		return aesTables
+	}
+	return f.tables
 }
 
 // Add computes the addition a+b in the Galois finite field 2^8.
@@ -98,8 +225,12 @@ func (f *Field256) Add(a, b uint8) uint8 {
 // We compute the value using the logarithm approach which is fast using lookup tables, at the expense
 // of storing 512 bytes in memory.
 func (f *Field256) Multiply(a, b uint8) uint8 {
-	sum := (int(log229[a]) + int(log229[b])) % 255
-	exponentiated := exp229[sum]
+	if f.constantTime {
+		return f.multiplyConstantTime(a, b)
+	}
+	t := f.t()
+	sum := (int(t.log[a]) + int(t.log[b])) % 255
+	exponentiated := t.exp[sum]
 
 	// If a or b is 0, we must return 0.
 	// We need constant time comparison to protect against timing attacks
@@ -113,19 +244,159 @@ func (f *Field256) Multiply(a, b uint8) uint8 {
 
 // Divide computes the division a/b in the Galois finite field 2^8.
 // If g is a generator and x, y such as a = g^x and b = g^y then a/b = g^(x-y)
+// It panics with ErrDivisionByZero if b is 0.
 func (f *Field256) Divide(a, b uint8) uint8 {
 	if b == 0 {
 		// as noted by hashicorp/vault, this leaks timing info but this should never happen (programming error)
 		// https://github.com/hashicorp/vault/blob/master/shamir/shamir.go
-		panic("division by 0")
+		panic(ErrDivisionByZero)
 	}
-	difference := (int(log229[a]) - int(log229[b])) % 255
-	if difference < 0 {
-		// as we use modular arithmetic, negative means circling back into the set from the end
-		difference += 255
-	} else {
-		// this assignment's sole purpose is to not leak timing info
-		difference += 0
+	if f.constantTime {
+		return f.multiplyConstantTime(a, f.inverseConstantTime(b))
+	}
+	t := f.t()
+	// as we use modular arithmetic, adding 255 keeps the difference positive without branching on a
+	difference := (int(t.log[a]) - int(t.log[b]) + 255) % 255
+	return uint8(subtle.ConstantTimeByteEq(a, 0)^0x01) * t.exp[difference]
+}
+
+// Subtract computes the substraction a-b in the Galois finite field 2^8, which is the addition.
+func (f *Field256) Subtract(a, b uint8) uint8 {
+	return a ^ b
+}
+
+// Inverse computes the multiplicative inverse of a in the Galois finite field 2^8.
+func (f *Field256) Inverse(a uint8) uint8 {
+	return f.Divide(1, a)
+}
+
+// SelfTest checks the log and exp tables of the field, by comparing every product and quotient computed using
+// the tables with the result of the multiplication of polynomials computed bit by bit. It returns an error if
+// the tables are inconsistent, e.g. if they were corrupted in memory.
+func (f *Field256) SelfTest() error {
+	t := f.t()
+	for a := 1; a < 256; a++ {
+		if t.exp[int(t.log[a])%255] != uint8(a) {
+			return fmt.Errorf("galois: self-test failed, inconsistent log and exp tables for %#x", a)
+		}
+	}
+	for a := 0; a < 256; a++ {
+		for b := 0; b < 256; b++ {
+			product := uint8(multiplyPolynomial(a, b, t.polynomial))
+			if f.Multiply(uint8(a), uint8(b)) != product {
+				return fmt.Errorf("galois: self-test failed, invalid product %#x * %#x", a, b)
+			}
+			if b != 0 && f.Divide(product, uint8(b)) != uint8(a) {
+				return fmt.Errorf("galois: self-test failed, invalid quotient %#x / %#x", product, b)
+			}
+		}
+	}
+	return nil
+}
+
+// multiplyConstantTime computes the multiplication a*b without table lookups nor branches depending on a and b,
+// by multiplying the polynomials one bit at a time and reducing the product as it goes.
+func (f *Field256) multiplyConstantTime(a, b uint8) uint8 {
+	reduction := uint8(f.t().polynomial)
+	var product uint8
+	for i := 0; i < 8; i++ {
+		// -(b & 1) is 0xff if the lowest bit of b is set, 0x00 otherwise
+		product ^= -(b & 1) & a
+		// reduce a*x modulo the polynomial if the degree of a*x is 8
+		carry := -(a >> 7)
+		a = a<<1 ^ carry&reduction
+		b >>= 1
+	}
+	return product
+}
+
+// inverseConstantTime computes the multiplicative inverse of a as a^254, since a^255 = 1, using a fixed
+// sequence of multiplications. The inverse of 0 is 0.
+func (f *Field256) inverseConstantTime(a uint8) uint8 {
+	// a^254 = a^2 * a^4 * a^8 * ... * a^128
+	square := f.multiplyConstantTime(a, a)
+	inverse := square
+	for i := 0; i < 6; i++ {
+		square = f.multiplyConstantTime(square, square)
+		inverse = f.multiplyConstantTime(inverse, square)
+	}
+	return inverse
+}
+
+// Generator returns the generator of the multiplicative group used by Exp and Log.
+func (f *Field256) Generator() uint8 {
+	return f.t().generator
+}
+
+// Exp computes g^n in the Galois finite field 2^8, where g is the generator of the field (see Generator).
+func (f *Field256) Exp(n int) uint8 {
+	n %= 255
+	if n < 0 {
+		n += 255
+	}
+	return f.t().exp[n]
+}
+
+// Log computes the discrete logarithm of a in base g, where g is the generator of the field (see Generator),
+// i.e. the integer 0 <= n < 255 such that g^n = a. It panics if a is 0.
+func (f *Field256) Log(a uint8) int {
+	if a == 0 {
+		panic("logarithm of 0")
+	}
+	return int(f.t().log[a]) % 255
+}
+
+// Pow computes a^n in the Galois finite field 2^8. Negative exponents are powers of the inverse of a,
+// and 0^n is 0 for n > 0 and 1 for n = 0. It panics with ErrDivisionByZero if a is 0 and n is negative.
+func (f *Field256) Pow(a uint8, n int) uint8 {
+	if a == 0 {
+		if n < 0 {
+			panic(ErrDivisionByZero)
+		}
+		if n == 0 {
+			return 1
+		}
+		return 0
+	}
+	// a^n = g^(log(a)*n), and g^255 = 1
+	return f.Exp(f.Log(a) * (n % 255))
+}
+
+// Zero returns the additive identity of the Galois finite field 2^8.
+func (f *Field256) Zero() uint8 {
+	return 0
+}
+
+// One returns the multiplicative identity of the Galois finite field 2^8.
+func (f *Field256) One() uint8 {
+	return 1
+}
+
+// Equal returns true if a and b are the same element, in constant time.
+func (f *Field256) Equal(a, b uint8) bool {
+	return subtle.ConstantTimeByteEq(a, b) == 1
+}
+
+// ElementSize returns the length in bytes of an element of the Galois finite field 2^8, i.e. 1.
+func (f *Field256) ElementSize() int {
+	return 1
+}
+
+// PutElement encodes a into dst[0].
+func (f *Field256) PutElement(dst []byte, a uint8) {
+	dst[0] = a
+}
+
+// Element decodes the element src[0].
+func (f *Field256) Element(src []byte) uint8 {
+	return src[0]
+}
+
+// Random returns a uniformly random element of the Galois finite field 2^8, reading randomness from r.
+func (f *Field256) Random(r io.Reader) (uint8, error) {
+	var b [1]byte
+	if err := random.ReadFull(r, b[:]); err != nil {
+		return 0, err
 	}
-	return uint8(subtle.ConstantTimeByteEq(a, 0)^0x01) * exp229[difference]
+	return b[0], nil
 }
@@ -0,0 +1,115 @@
+package galois
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// GF(2^16) is built as the polynomials over GF(2) modulo the primitive polynomial x^16 + x^12 + x^3 + x + 1,
+// so that 2 (the polynomial x) generates the multiplicative group. The log and exp tables hold 2 * 2^16 entries
+// and are too large to be written out, they are computed when the package is loaded.
+
+// poly65536 is the reduction polynomial of GF(2^16), x^16 + x^12 + x^3 + x + 1.
+const poly65536 = 0x1100b
+
+var log65536, exp65536 [1 << 16]uint16
+
+func init() {
+	x := 1
+	for i := 0; i < 0xffff; i++ {
+		exp65536[i] = uint16(x)
+		log65536[x] = uint16(i)
+		x <<= 1
+		if x&0x10000 != 0 {
+			x ^= poly65536
+		}
+	}
+}
+
+// Field65536 represents the Galois finite field 2^16.
+// A Field65536 is immutable and safe for concurrent use.
+type Field65536 struct{}
+
+// NewField65536 returns a pointer to a new Field65536 struct.
+func NewField65536() *Field65536 {
+	return &Field65536{}
+}
+
+// Add computes the addition a+b in the Galois finite field 2^16.
+//
+// As in GF(2^8), the addition is equivalent to XOR, and the addition and the substraction are the same.
+func (f *Field65536) Add(a, b uint16) uint16 {
+	return a ^ b
+}
+
+// Multiply computes the multiplication a*b in the Galois finite field 2^16, using the log and exp tables.
+func (f *Field65536) Multiply(a, b uint16) uint16 {
+	sum := (int(log65536[a]) + int(log65536[b])) % 0xffff
+	exponentiated := exp65536[sum]
+	// see Field256.Multiply: the result is masked to 0 in constant time if a or b is 0
+	zero := subtle.ConstantTimeEq(int32(a), 0) | subtle.ConstantTimeEq(int32(b), 0)
+	return uint16(zero^0x01) * exponentiated
+}
+
+// Divide computes the division a/b in the Galois finite field 2^16.
+// If g is a generator and x, y such as a = g^x and b = g^y then a/b = g^(x-y)
+// It panics with ErrDivisionByZero if b is 0.
+func (f *Field65536) Divide(a, b uint16) uint16 {
+	if b == 0 {
+		panic(ErrDivisionByZero)
+	}
+	difference := (int(log65536[a]) - int(log65536[b]) + 0xffff) % 0xffff
+	return uint16(subtle.ConstantTimeEq(int32(a), 0)^0x01) * exp65536[difference]
+}
+
+// Subtract computes the substraction a-b in the Galois finite field 2^16, which is the addition.
+func (f *Field65536) Subtract(a, b uint16) uint16 {
+	return a ^ b
+}
+
+// Inverse computes the multiplicative inverse of a in the Galois finite field 2^16.
+func (f *Field65536) Inverse(a uint16) uint16 {
+	return f.Divide(1, a)
+}
+
+// Zero returns the additive identity of the Galois finite field 2^16.
+func (f *Field65536) Zero() uint16 {
+	return 0
+}
+
+// One returns the multiplicative identity of the Galois finite field 2^16.
+func (f *Field65536) One() uint16 {
+	return 1
+}
+
+// Equal returns true if a and b are the same element, in constant time.
+func (f *Field65536) Equal(a, b uint16) bool {
+	return subtle.ConstantTimeEq(int32(a), int32(b)) == 1
+}
+
+// ElementSize returns the length in bytes of an element of the Galois finite field 2^16, i.e. 2.
+func (f *Field65536) ElementSize() int {
+	return 2
+}
+
+// PutElement encodes a into dst[0:2].
+func (f *Field65536) PutElement(dst []byte, a uint16) {
+	binary.BigEndian.PutUint16(dst, a)
+}
+
+// Element decodes the element encoded in src[0:2].
+func (f *Field65536) Element(src []byte) uint16 {
+	return binary.BigEndian.Uint16(src)
+}
+
+// Random returns a uniformly random element of the Galois finite field 2^16, reading randomness from r.
+func (f *Field65536) Random(r io.Reader) (uint16, error) {
+	var b [2]byte
+	if err := random.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b[:]), nil
+}
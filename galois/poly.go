@@ -0,0 +1,147 @@
+package galois
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+// Poly is a polynomial with coefficients in a field. Coefficients[i] is the coefficient of degree i, so that
+// Coefficients[0] is the intercept, i.e. the secret in Shamir's scheme. Polynomials are never modified in
+// place: the operations return new polynomials.
+type Poly[E any] struct {
+	Field        Field[E]
+	Coefficients []E
+}
+
+// NewPoly returns the polynomial with the provided coefficients, starting from the intercept.
+func NewPoly[E any](field Field[E], coefficients ...E) Poly[E] {
+	return Poly[E]{Field: field, Coefficients: coefficients}
+}
+
+// RandomPoly returns a polynomial of the provided degree whose intercept is provided and whose other coefficients
+// are uniformly random, reading randomness from r (e.g. crypto/rand.Reader).
+// In a (k,n) Shamir scheme, the degree is k-1.
+func RandomPoly[E any](field Field[E], intercept E, degree int, r io.Reader) (Poly[E], error) {
+	if degree < 0 {
+		return Poly[E]{}, errors.New("galois: the degree of a polynomial cannot be negative")
+	}
+	coefficients := make([]E, degree+1)
+	coefficients[0] = intercept
+	for i := 1; i <= degree; i++ {
+		coefficient, err := field.Random(r)
+		if err != nil {
+			return Poly[E]{}, err
+		}
+		coefficients[i] = coefficient
+	}
+	return NewPoly(field, coefficients...), nil
+}
+
+// Degree returns the degree of the polynomial, i.e. the number of coefficients minus 1, even if the leading
+// coefficients are 0. The degree of the polynomial without coefficients is -1.
+func (p Poly[E]) Degree() int {
+	return len(p.Coefficients) - 1
+}
+
+// String formats the polynomial without its coefficients, which hold the secret in Shamir's scheme, such as
+// poly[deg=2 REDACTED].
+func (p Poly[E]) String() string {
+	return "poly[deg=" + strconv.Itoa(p.Degree()) + " REDACTED]"
+}
+
+// GoString formats the polynomial like String, for the %#v verb.
+func (p Poly[E]) GoString() string {
+	return p.String()
+}
+
+// Eval computes the value of the polynomial at point x, using Horner's algorithm.
+func (p Poly[E]) Eval(x E) E {
+	if len(p.Coefficients) == 0 {
+		return p.Field.Zero()
+	}
+	// initialize Horner's algorithm with the nth coefficient of the polynomial
+	// https://en.wikipedia.org/wiki/Horner%27s_method
+	value := p.Coefficients[len(p.Coefficients)-1]
+	for i := len(p.Coefficients) - 2; i >= 0; i-- {
+		value = p.Field.Add(p.Coefficients[i], p.Field.Multiply(value, x))
+	}
+	return value
+}
+
+// Add computes the sum of the polynomials p and q.
+func (p Poly[E]) Add(q Poly[E]) Poly[E] {
+	coefficients := make([]E, max(len(p.Coefficients), len(q.Coefficients)))
+	for i := range coefficients {
+		switch {
+		case i >= len(p.Coefficients):
+			coefficients[i] = q.Coefficients[i]
+		case i >= len(q.Coefficients):
+			coefficients[i] = p.Coefficients[i]
+		default:
+			coefficients[i] = p.Field.Add(p.Coefficients[i], q.Coefficients[i])
+		}
+	}
+	return NewPoly(p.Field, coefficients...)
+}
+
+// Mul computes the product of the polynomials p and q.
+func (p Poly[E]) Mul(q Poly[E]) Poly[E] {
+	if len(p.Coefficients) == 0 || len(q.Coefficients) == 0 {
+		return NewPoly[E](p.Field)
+	}
+	coefficients := make([]E, len(p.Coefficients)+len(q.Coefficients)-1)
+	for i := range coefficients {
+		coefficients[i] = p.Field.Zero()
+	}
+	for i, a := range p.Coefficients {
+		for j, b := range q.Coefficients {
+			coefficients[i+j] = p.Field.Add(coefficients[i+j], p.Field.Multiply(a, b))
+		}
+	}
+	return NewPoly(p.Field, coefficients...)
+}
+
+// ScalarMul computes the product of the polynomial p and the scalar c.
+func (p Poly[E]) ScalarMul(c E) Poly[E] {
+	coefficients := make([]E, len(p.Coefficients))
+	for i, a := range p.Coefficients {
+		coefficients[i] = p.Field.Multiply(c, a)
+	}
+	return NewPoly(p.Field, coefficients...)
+}
+
+// LagrangeBasis computes the value at point z of Lagrange's basis polynomials for the distinct coordinates x.
+// The basis does not depend on the values of the polynomial, so that it can be reused to interpolate several
+// polynomials going through the same coordinates.
+func LagrangeBasis[E any](field Field[E], x []E, z E) []E {
+	basis := make([]E, len(x))
+	for i := range x {
+		// compute Lagrange's basis ith polynomial value at point z
+		basis[i] = field.One()
+		for j := range x {
+			if j != i {
+				numerator := field.Subtract(z, x[j])
+				denominator := field.Subtract(x[i], x[j])
+				basis[i] = field.Multiply(basis[i], field.Divide(numerator, denominator))
+			}
+		}
+	}
+	return basis
+}
+
+// Interpolate computes the value at point z of the polynomial of lowest degree going through the points
+// (x[i], y[i]), using Lagrange's algorithm. The coordinates x must be distinct.
+func Interpolate[E any](field Field[E], x, y []E, z E) E {
+	result := field.Zero()
+	for i, basis := range LagrangeBasis(field, x, z) {
+		result = field.Add(result, field.Multiply(basis, y[i]))
+	}
+	return result
+}
+
+// InterpolateAtZero computes the intercept of the polynomial of lowest degree going through the points
+// (x[i], y[i]), i.e. the secret in Shamir's scheme. The coordinates x must be distinct.
+func InterpolateAtZero[E any](field Field[E], x, y []E) E {
+	return Interpolate(field, x, y, field.Zero())
+}
@@ -0,0 +1,112 @@
+package galois
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Prime fields Z_p are used by academic implementations of Shamir's scheme and by threshold cryptography,
+// where the secret is a scalar modulo the order of a group. Elements are *big.Int in [0, p), and the
+// operations always return new values. Mersenne127 and Secp256k1Order must not be modified.
+
+var (
+	// Mersenne127 is the Mersenne prime 2^127 - 1.
+	Mersenne127, _ = new(big.Int).SetString("7fffffffffffffffffffffffffffffff", 16)
+	// Secp256k1Order is the order of the group of the secp256k1 elliptic curve.
+	Secp256k1Order, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+)
+
+// FieldPrime represents the finite field of the integers modulo a prime p.
+type FieldPrime struct {
+	p *big.Int
+}
+
+// NewFieldPrime returns a pointer to a new FieldPrime struct for the prime p, e.g. Mersenne127 or
+// Secp256k1Order. It returns an error if p is not prime.
+func NewFieldPrime(p *big.Int) (*FieldPrime, error) {
+	if p.Sign() <= 0 || !p.ProbablyPrime(20) {
+		return nil, errors.New("galois: the modulus of a prime field must be prime")
+	}
+	return &FieldPrime{p: new(big.Int).Set(p)}, nil
+}
+
+// Modulus returns the prime p.
+func (f *FieldPrime) Modulus() *big.Int {
+	return new(big.Int).Set(f.p)
+}
+
+// Contains returns true if a is an element of the field, i.e. 0 <= a < p.
+func (f *FieldPrime) Contains(a *big.Int) bool {
+	return a.Sign() >= 0 && a.Cmp(f.p) < 0
+}
+
+// Add computes the addition a+b modulo p.
+func (f *FieldPrime) Add(a, b *big.Int) *big.Int {
+	sum := new(big.Int).Add(a, b)
+	return sum.Mod(sum, f.p)
+}
+
+// Subtract computes the substraction a-b modulo p.
+func (f *FieldPrime) Subtract(a, b *big.Int) *big.Int {
+	difference := new(big.Int).Sub(a, b)
+	return difference.Mod(difference, f.p)
+}
+
+// Multiply computes the multiplication a*b modulo p.
+func (f *FieldPrime) Multiply(a, b *big.Int) *big.Int {
+	product := new(big.Int).Mul(a, b)
+	return product.Mod(product, f.p)
+}
+
+// Inverse computes the multiplicative inverse of a modulo p. It panics with ErrDivisionByZero if a is 0.
+func (f *FieldPrime) Inverse(a *big.Int) *big.Int {
+	reduced := new(big.Int).Mod(a, f.p)
+	if reduced.Sign() == 0 {
+		panic(ErrDivisionByZero)
+	}
+	// p is prime, so that a^(p-2) is the inverse of a (Fermat's little theorem)
+	return reduced.Exp(reduced, new(big.Int).Sub(f.p, big.NewInt(2)), f.p)
+}
+
+// Divide computes the division a/b modulo p. It panics with ErrDivisionByZero if b is 0.
+func (f *FieldPrime) Divide(a, b *big.Int) *big.Int {
+	return f.Multiply(a, f.Inverse(b))
+}
+
+// Random returns a uniformly random element of the field, reading randomness from r.
+func (f *FieldPrime) Random(r io.Reader) (*big.Int, error) {
+	return rand.Int(r, f.p)
+}
+
+// Zero returns the additive identity, 0.
+func (f *FieldPrime) Zero() *big.Int {
+	return new(big.Int)
+}
+
+// One returns the multiplicative identity, 1.
+func (f *FieldPrime) One() *big.Int {
+	return big.NewInt(1)
+}
+
+// Equal returns true if a and b are the same element.
+func (f *FieldPrime) Equal(a, b *big.Int) bool {
+	return a.Cmp(b) == 0
+}
+
+// ElementSize returns the length in bytes of an element, i.e. the length of p.
+func (f *FieldPrime) ElementSize() int {
+	return (f.p.BitLen() + 7) / 8
+}
+
+// PutElement encodes a into the first ElementSize bytes of dst.
+func (f *FieldPrime) PutElement(dst []byte, a *big.Int) {
+	a.FillBytes(dst[:f.ElementSize()])
+}
+
+// Element decodes the element encoded in the first ElementSize bytes of src. The result is not reduced
+// modulo p, see Contains.
+func (f *FieldPrime) Element(src []byte) *big.Int {
+	return new(big.Int).SetBytes(src[:f.ElementSize()])
+}
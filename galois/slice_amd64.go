@@ -0,0 +1,26 @@
+package galois
+
+// hasSSSE3 is true if the processor supports the PSHUFB instruction.
+var hasSSSE3 = func() bool {
+	_, _, ecx, _ := cpuid(1, 0)
+	return ecx&(1<<9) != 0
+}()
+
+// cpuid executes the CPUID instruction.
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+
+// mulSliceSSSE3 multiplies the first len(in) &^ 15 bytes of in using the nibble tables, and stores or adds the
+// products in out.
+//
+//go:noescape
+func mulSliceSSSE3(t *nibbleTables, in, out []byte, add bool)
+
+// mulSlice computes the products of the bytes of in using the nibble tables, and stores or adds them in out.
+func (f *Field256) mulSlice(t *nibbleTables, in, out []byte, add bool) {
+	if hasSSSE3 {
+		n := len(in) &^ 15
+		mulSliceSSSE3(t, in[:n], out[:n], add)
+		in, out = in[n:], out[n:]
+	}
+	f.mulSliceGeneric(t, in, out, add)
+}
@@ -0,0 +1,56 @@
+#include "textflag.h"
+
+// func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL eaxArg+0(FP), AX
+	MOVL ecxArg+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func mulSliceSSSE3(t *nibbleTables, in, out []byte, add bool)
+TEXT ·mulSliceSSSE3(SB), NOSPLIT, $0-57
+	MOVQ t+0(FP), AX
+	MOVQ in_base+8(FP), SI
+	MOVQ in_len+16(FP), CX
+	MOVQ out_base+32(FP), DI
+	MOVB add+56(FP), DX
+
+	// X6 and X7 hold the low and high nibble tables, X8 the 0x0f mask
+	MOVOU 0(AX), X6
+	MOVOU 16(AX), X7
+	MOVQ  $0x0f0f0f0f0f0f0f0f, BX
+	MOVQ  BX, X8
+	PUNPCKLQDQ X8, X8
+
+	SHRQ $4, CX
+	JZ   done
+
+loop:
+	MOVOU (SI), X0
+	MOVOU X0, X1
+	PSRLQ $4, X1
+	PAND  X8, X0
+	PAND  X8, X1
+	MOVOU X6, X2
+	MOVOU X7, X3
+	PSHUFB X0, X2
+	PSHUFB X1, X3
+	PXOR   X3, X2
+	TESTB  DX, DX
+	JZ     store
+	MOVOU  (DI), X4
+	PXOR   X4, X2
+
+store:
+	MOVOU X2, (DI)
+	ADDQ  $16, SI
+	ADDQ  $16, DI
+	DECQ  CX
+	JNZ   loop
+
+done:
+	RET
@@ -0,0 +1,15 @@
+package galois
+
+// mulSliceNEON multiplies the first len(in) &^ 15 bytes of in using the nibble tables, and stores or adds the
+// products in out.
+//
+//go:noescape
+func mulSliceNEON(t *nibbleTables, in, out []byte, add bool)
+
+// mulSlice computes the products of the bytes of in using the nibble tables, and stores or adds them in out.
+// NEON is part of the baseline of arm64, so that no feature detection is needed.
+func (f *Field256) mulSlice(t *nibbleTables, in, out []byte, add bool) {
+	n := len(in) &^ 15
+	mulSliceNEON(t, in[:n], out[:n], add)
+	f.mulSliceGeneric(t, in[n:], out[n:], add)
+}
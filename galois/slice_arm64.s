@@ -0,0 +1,36 @@
+#include "textflag.h"
+
+// func mulSliceNEON(t *nibbleTables, in, out []byte, add bool)
+TEXT ·mulSliceNEON(SB), NOSPLIT, $0-57
+	MOVD  t+0(FP), R0
+	MOVD  in_base+8(FP), R1
+	MOVD  in_len+16(FP), R2
+	MOVD  out_base+32(FP), R3
+	MOVBU add+56(FP), R4
+
+	// V6 and V7 hold the low and high nibble tables, V8 the 0x0f mask
+	VLD1 (R0), [V6.B16, V7.B16]
+	MOVD $0x0f, R5
+	VDUP R5, V8.B16
+
+	LSR $4, R2
+	CBZ R2, done
+
+loop:
+	VLD1.P 16(R1), [V0.B16]
+	VUSHR  $4, V0.B16, V1.B16
+	VAND   V8.B16, V0.B16, V0.B16
+	VTBL   V0.B16, [V6.B16], V2.B16
+	VTBL   V1.B16, [V7.B16], V3.B16
+	VEOR   V3.B16, V2.B16, V2.B16
+	CBZ    R4, store
+	VLD1   (R3), [V4.B16]
+	VEOR   V4.B16, V2.B16, V2.B16
+
+store:
+	VST1.P [V2.B16], 16(R3)
+	SUBS   $1, R2, R2
+	BNE    loop
+
+done:
+	RET
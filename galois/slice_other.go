@@ -0,0 +1,8 @@
+//go:build !amd64 && !arm64
+
+package galois
+
+// mulSlice computes the products of the bytes of in using the nibble tables, and stores or adds them in out.
+func (f *Field256) mulSlice(t *nibbleTables, in, out []byte, add bool) {
+	f.mulSliceGeneric(t, in, out, add)
+}
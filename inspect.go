@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// The inspect command prints the metadata of shares, so that custodians can audit what they hold. The payload of
+// a share is never printed: only its length, which is the length of the (padded) secret, and its fingerprint.
+
+func runInspect(args []string) error {
+	flags := newFlagSet("inspect", "share file...")
+	language := flags.String("language", defaults.Language, "language of the wordlist of mnemonic shares")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	infos := make([]shareInfo, flags.NArg())
+	for i, path := range flags.Args() {
+		data, err := readInput(path)
+		if err != nil {
+			return err
+		}
+		share, err := decodeShare(data, *language)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		infos[i] = newShareInfo(path, detectFormat(data), share)
+	}
+	if jsonOutput {
+		return printJSON(infos)
+	}
+	for i, info := range infos {
+		if i > 0 {
+			fmt.Println()
+		}
+		printShareInfo(info)
+	}
+	return nil
+}
+
+// printShareInfo prints the description of a share.
+func printShareInfo(info shareInfo) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "file:\t%s\n", info.Path)
+	fmt.Fprintf(w, "encoding:\t%s\n", info.Encoding)
+	fmt.Fprintf(w, "version:\t%d\n", info.Version)
+	fmt.Fprintf(w, "index:\t%d\n", info.Index)
+	if info.Threshold == 0 {
+		fmt.Fprintf(w, "threshold:\tunknown\n")
+	} else {
+		fmt.Fprintf(w, "threshold:\t%d\n", info.Threshold)
+	}
+	fmt.Fprintf(w, "split:\t%s\n", info.SplitID)
+	fmt.Fprintf(w, "fingerprint:\t%s (%s)\n", info.Fingerprint, info.Words)
+	fmt.Fprintf(w, "payload:\t%d bytes\n", info.PayloadLength)
+	if info.CreatedAt != nil {
+		fmt.Fprintf(w, "created:\t%s\n", info.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	}
+	if info.Label != "" {
+		fmt.Fprintf(w, "label:\t%q\n", info.Label)
+	}
+	if info.Role != "" {
+		fmt.Fprintf(w, "role:\t%q\n", info.Role)
+	}
+	fmt.Fprintf(w, "signed:\t%t\n", info.Signed)
+	fmt.Fprintf(w, "padded:\t%t\n", info.Padded)
+	if info.Polynomial != 0 {
+		fmt.Fprintf(w, "polynomial:\t%#x\n", info.Polynomial)
+	}
+	if info.Policy != nil && !info.Policy.NotBefore.IsZero() {
+		fmt.Fprintf(w, "not before:\t%s\n", info.Policy.NotBefore.Format("2006-01-02 15:04:05 MST"))
+	}
+	if info.Policy != nil && len(info.Policy.Approvers) > 0 {
+		fmt.Fprintf(w, "approvers:\t%s\n", strings.Join(info.Policy.Approvers, ", "))
+	}
+}
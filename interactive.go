@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shareqr"
+)
+
+// The interactive recovery prompts for shares one at a time on stderr. A share is either pasted, or read from a
+// file whose path is entered: PNG and JPEG images are scanned for QR codes (see the shareqr package), and other
+// files hold an encoded share. Every share is checked as soon as it is entered, and an invalid share is reported
+// and can be entered again. The recovery starts once the threshold of the shares is reached, or when an empty line
+// is entered if the threshold is unknown. The secret is written to the output file, or printed only if requested.
+
+func recoverInteractive(out, language string, show bool) error {
+	input := bufio.NewReader(os.Stdin)
+	var shares []shamir.Share
+	for {
+		if len(shares) > 0 && int(shares[0].Threshold) > 0 && len(shares) >= int(shares[0].Threshold) {
+			break
+		}
+		fmt.Fprintf(os.Stderr, "share %d (paste it or enter the path of a file): ", len(shares)+1)
+		line, err := input.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+			// the missing shares are reported below
+			fmt.Fprintln(os.Stderr)
+			break
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if len(shares) >= 2 && shares[0].Threshold == 0 {
+				break
+			}
+			continue
+		}
+
+		entered, err := readEnteredShares(line, language)
+		if err == nil {
+			for _, share := range entered {
+				if err = checkShare(shares, share); err != nil {
+					break
+				}
+				shares = append(shares, share)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  rejected: %v\n", err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  accepted: %s\n", progress(shares))
+	}
+	secret, err := recoverSecret(shares)
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	fmt.Fprintf(os.Stderr, "the secret (%d bytes) was recovered from %d shares\n", len(secret), len(shares))
+	if out != "-" {
+		return os.WriteFile(out, secret, 0o600)
+	}
+	if !show {
+		fmt.Fprint(os.Stderr, "print the secret? [y/N] ")
+		answer, _ := input.ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return nil
+		}
+	}
+	_, err = os.Stdout.Write(secret)
+	return err
+}
+
+// readEnteredShares decodes the shares entered at the prompt: the path of an image holding QR codes, the path of
+// a file holding a share, or a share.
+func readEnteredShares(line, language string) ([]shamir.Share, error) {
+	info, err := os.Stat(line)
+	if err != nil || info.IsDir() {
+		// shares typed at the prompt are likely to hold typos
+		share, corrections, err := correctShare([]byte(line), language)
+		if err != nil {
+			return nil, err
+		}
+		if corrections != "" {
+			fmt.Fprintf(os.Stderr, "  %s, compare it with the written share\n", corrections)
+		}
+		return []shamir.Share{share}, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(line)) {
+	case ".png", ".jpg", ".jpeg":
+		f, err := os.Open(line)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		contents, err := shareqr.ReadImage(f)
+		if err != nil {
+			return nil, err
+		}
+		return shareqr.Assemble(contents)
+	}
+	data, err := os.ReadFile(line)
+	if err != nil {
+		return nil, err
+	}
+	share, err := decodeShare(data, language)
+	if err != nil {
+		return nil, err
+	}
+	return []shamir.Share{share}, nil
+}
+
+// progress describes the shares collected so far, without revealing their payloads.
+func progress(shares []shamir.Share) string {
+	last := shares[len(shares)-1]
+	if last.Threshold == 0 {
+		return fmt.Sprintf("share %d of split %s, %d shares collected, threshold unknown (enter an empty line "+
+			"to recover)", last.Index, last.SplitID, len(shares))
+	}
+	return fmt.Sprintf("share %d of split %s, %d of %d shares collected", last.Index, last.SplitID, len(shares),
+		last.Threshold)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/sharekeychain"
+)
+
+// The keychain command lets a custodian keep their share in the credential store of their operating system (see
+// the sharekeychain package) rather than in a file:
+//
+// 	shamir keychain store share-1.txt
+// 	shamir keychain --out share-1.txt load 3bd9d9d0-5c0e-4667-a842-54efae534ebd
+// 	shamir keychain delete 3bd9d9d0-5c0e-4667-a842-54efae534ebd
+//
+// At recovery time, recover --keychain adds the stored share of the split of the other shares, without writing it
+// to a file.
+
+const (
+	keychainStore  = "store"
+	keychainLoad   = "load"
+	keychainDelete = "delete"
+)
+
+func runKeychain(args []string) error {
+	flags := newFlagSet("keychain", "store [share file] | load <split id> | delete <split id>")
+	out := flags.String("out", "-", "file to write the loaded share to, - for stdout")
+	format := flags.String("format", defaults.Format, "format of the loaded share")
+	language := flags.String("language", defaults.Language, "language of the wordlist of mnemonic shares")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	action := flags.Arg(0)
+	if (action == keychainStore && flags.NArg() > 2) || (action != keychainStore && flags.NArg() != 2) {
+		flags.Usage()
+		return errUsage
+	}
+
+	var share shamir.Share
+	switch action {
+	case keychainStore:
+		path := flags.Arg(1)
+		if path == "" {
+			path = "-"
+		}
+		data, err := readInput(path)
+		if err != nil {
+			return err
+		}
+		if share, err = decodeShare(data, *language); err != nil {
+			return err
+		}
+		if err := sharekeychain.Store(share); err != nil {
+			return err
+		}
+	case keychainLoad, keychainDelete:
+		id, err := shamir.ParseSplitID(flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		if action == keychainDelete {
+			if err := sharekeychain.Delete(id); err != nil {
+				return err
+			}
+			share.SplitID = id
+			break
+		}
+		if share, err = sharekeychain.Load(id); err != nil {
+			return err
+		}
+	default:
+		flags.Usage()
+		return errUsage
+	}
+	defer clear(share.Payload)
+
+	report := keychainReport{Action: action, SplitID: share.SplitID}
+	if action != keychainDelete {
+		report.Index = share.Index
+		report.Fingerprint = share.Fingerprint().String()
+	}
+	if action == keychainLoad {
+		encoded, err := encodeShare(share, *format, *language)
+		if err != nil {
+			return err
+		}
+		if *out != "-" {
+			if err := os.WriteFile(*out, []byte(encoded+"\n"), 0o600); err != nil {
+				return err
+			}
+			report.Out = *out
+		} else if !jsonOutput {
+			fmt.Println(encoded)
+			return nil
+		} else {
+			report.Share = encoded
+		}
+	}
+	if jsonOutput {
+		return printJSON(report)
+	}
+	switch action {
+	case keychainStore:
+		fmt.Fprintf(os.Stderr, "share %d of split %s stored in the credential store\n", share.Index, share.SplitID)
+	case keychainDelete:
+		fmt.Fprintf(os.Stderr, "share of split %s deleted from the credential store\n", share.SplitID)
+	}
+	return nil
+}
@@ -1,28 +1,128 @@
 package main
 
 import (
-	"encoding/hex"
-	"github.com/etiennebch/shamir-sss/shamir"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"strings"
 )
 
-func main() {
-	var number, threshold uint8 = 5, 3
+// The shamir command splits secrets into shares and recovers them:
+//
+// 	shamir split --shares 5 --threshold 3 --in secret.txt --out-dir shares/
+// 	shamir recover shares/share-1.txt shares/share-2.txt shares/share-3.txt
+// 	shamir inspect shares/share-1.txt
+// 	shamir verify --manifest manifest.json shares/share-1.txt
+// 	shamir convert --from hex --to pem --in shares/share-1.txt
+// 	shamir serve --cert server.pem --key server.key --client-ca custodians.pem --sink file:secret.txt
+// 	shamir submit --ceremony-url https://coordinator:8443 --cert alice.pem --key alice.key --share share-1.txt
+// 	shamir keychain store shares/share-1.txt
+// 	shamir service --cert server.pem --key server.key --client-ca clients.pem
+// 	shamir audit --key audit.pub audit.log
+//
+// The secret is read from stdin and written to stdout unless files are provided. Shares are encoded in the binary
+// format of the shamir package, then as hex by default (see format.go for the other formats), and are
+// read back whatever their encoding. Flags must precede the arguments of a command, and --json prints a
+// report for scripts (see output.go).
 
-	log.Print("using Shamir to split secret value: hello world")
-	log.Printf("using number of shares: %d", number)
-	log.Printf("using threshold: %d", threshold)
+// command is a subcommand of the tool. run receives the arguments following the name of the subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
 
-	shares := shamir.Split([]byte("hello world"), number, threshold)
-	for i, share := range shares {
-		log.Printf("share %d: %s", i+1, hex.EncodeToString(share))
+var commands = []command{
+	{"split", "split a secret into shares", runSplit},
+	{"recover", "recover a secret from shares", runRecover},
+	{"inspect", "print the metadata of shares", runInspect},
+	{"verify", "verify shares against the manifest of the split", runVerify},
+	{"convert", "re-encode a share in another format", runConvert},
+	{"serve", "coordinate a recovery ceremony", runServe},
+	{"submit", "submit a share to a recovery ceremony", runSubmit},
+	{"keychain", "keep a share in the OS credential store", runKeychain},
+	{"service", "serve the gRPC service splitting and recovering secrets", runService},
+	{"audit", "check the events of an audit log", runAudit},
+}
+
+var (
+	// errUsage is returned by subcommands invoked with invalid arguments, once their usage has been printed.
+	errUsage = errors.New("invalid usage")
+	// errReported is returned by subcommands which failed, once the failure has been reported.
+	errReported = errors.New("failure reported")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("shamir: ")
+	if err := loadConfig(); err != nil {
+		log.Fatal(err)
+	}
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		if err := c.run(os.Args[2:]); err != nil {
+			if errors.Is(err, errUsage) {
+				os.Exit(2)
+			}
+			if errors.Is(err, errReported) {
+				os.Exit(1)
+			}
+			if jsonOutput {
+				printJSON(errorReport{Error: err.Error()})
+				os.Exit(1)
+			}
+			log.Fatal(err)
+		}
+		return
 	}
+	if os.Args[1] == "help" || os.Args[1] == "-h" || os.Args[1] == "--help" {
+		usage(os.Stdout)
+		return
+	}
+	log.Printf("unknown command %q", os.Args[1])
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+// usage prints the list of the subcommands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: shamir <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `run "shamir <command> -h" for the flags of a command.`)
+}
 
-	// attempt recovery with less than threshold
-	recv := shamir.Recover(shares[:threshold-1])
-	log.Printf("attempted recovery with %d shares (threshold = %d): %s", threshold-1, threshold, string(recv))
+// newFlagSet returns the flag set of a subcommand, printing its usage line on error.
+func newFlagSet(name, arguments string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.BoolVar(&jsonOutput, "json", false, "print a JSON report to stdout (see output.go)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), strings.TrimSpace("usage: shamir "+name+" [flags] "+arguments))
+		flags.PrintDefaults()
+	}
+	return flags
+}
 
-	// attempt revovery with threshold
-	recv = shamir.Recover(shares[:threshold])
-	log.Printf("attempted recovery with %d shares (threshold = %d): %s", threshold, threshold, string(recv))
+// parseFlags parses the arguments of a subcommand, returning errUsage if they are invalid.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		return errUsage
+	}
+	return nil
 }
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// With --json, every command prints a single JSON object (an array for inspect) to stdout, so that it can be
+// driven from scripts. Messages meant for operators are still printed to stderr, and a command failing prints
+// {"error": "..."} to stdout and exits with status 1. Shares are described by their metadata rather than by their
+// payload (see shareInfo), unless split prints them, and byte strings are base64 encoded. The reports are:
+//
+// split:
+//
+// 	{
+// 		"splitId": "3bd9d9d0-5c0e-4667-a842-54efae534ebd",
+// 		"threshold": 3,
+// 		"format": "hex",
+// 		"shares": [{"index": 42, "fingerprint": "c4f5f351", "path": "shares/share-1.txt"}, ...],
+// 		"manifest": "manifest.json",
+// 		"shred": "secret.txt",
+// 		"dryRun": true
+// 	}
+//
+// Without output directory, every share holds its encoding ("share") instead of a path. manifest, shred and dryRun
+// are omitted when unset. In a dry run, nothing is written and the paths are those which would be written.
+//
+// recover:
+//
+// 	{
+// 		"splitId": "3bd9d9d0-5c0e-4667-a842-54efae534ebd",
+// 		"shares": [{"index": 42, "fingerprint": "c4f5f351"}, ...],
+// 		"length": 11,
+// 		"out": "secret.txt",
+// 		"secret": "aGVsbG8gd29ybGQ="
+// 	}
+//
+// The secret is only printed when it is not written to a file (out).
+//
+// inspect: an array of share descriptions, see shareInfo.
+//
+// verify:
+//
+// 	{
+// 		"ok": false,
+// 		"manifest": {"path": "manifest.json", "signed": true},
+// 		"shares": [
+// 			{"path": "share-1.txt", "ok": true, "index": 42, "splitId": "...", "checks": ["manifest", "signature"]},
+// 			{"path": "share-2.txt", "ok": false, "error": "..."}
+// 		]
+// 	}
+//
+// convert:
+//
+// 	{
+// 		"index": 42,
+// 		"splitId": "3bd9d9d0-5c0e-4667-a842-54efae534ebd",
+// 		"from": "hex",
+// 		"to": "qr",
+// 		"outputs": ["share-1.png", "share-2.png"],
+// 		"share": "...",
+// 		"data": "..."
+// 	}
+//
+// The converted share is written to the files listed in outputs, or printed as text (share) or base64 encoded
+// binary data (data) for the binary and qr formats.
+//
+// keychain:
+//
+// 	{
+// 		"action": "load",
+// 		"splitId": "3bd9d9d0-5c0e-4667-a842-54efae534ebd",
+// 		"index": 42,
+// 		"fingerprint": "c4f5f351",
+// 		"out": "share-1.txt",
+// 		"share": "..."
+// 	}
+//
+// The loaded share is printed (share) when it is not written to a file (out). index and fingerprint are omitted
+// by delete.
+//
+// audit:
+//
+// 	{"events": 12, "signed": true, "error": "..."}
+//
+// error is set when the events do not follow each other or are not signed with the key.
+//
+// serve and submit: the status of the ceremony, once over for serve and once the share is accepted for submit,
+// see ceremony.Status.
+
+// jsonOutput is set by the --json flag of every command.
+var jsonOutput bool
+
+// shareInfo describes a share without its payload.
+type shareInfo struct {
+	Path          string         `json:"path,omitempty"`
+	Encoding      string         `json:"encoding,omitempty"`
+	Version       uint8          `json:"version"`
+	Index         uint8          `json:"index"`
+	Threshold     uint8          `json:"threshold"`
+	SplitID       shamir.SplitID `json:"splitId"`
+	Fingerprint   string         `json:"fingerprint"`
+	Words         string         `json:"fingerprintWords"`
+	PayloadLength int            `json:"payloadLength"`
+	CreatedAt     *time.Time     `json:"createdAt,omitempty"`
+	Label         string         `json:"label,omitempty"`
+	Signed        bool           `json:"signed"`
+	Padded        bool           `json:"padded"`
+	Polynomial    uint16         `json:"polynomial,omitempty"`
+	Policy        *shamir.Policy `json:"policy,omitempty"`
+	Role          string         `json:"role,omitempty"`
+}
+
+// newShareInfo describes a share read from path in the provided encoding.
+func newShareInfo(path, encoding string, share shamir.Share) shareInfo {
+	fingerprint := share.Fingerprint()
+	info := shareInfo{
+		Path:          path,
+		Encoding:      encoding,
+		Version:       shamir.Version,
+		Index:         share.Index,
+		Threshold:     share.Threshold,
+		SplitID:       share.SplitID,
+		Fingerprint:   fingerprint.String(),
+		Words:         fingerprint.Words(),
+		PayloadLength: len(share.Payload),
+		Label:         share.Label,
+		Signed:        share.Signature != nil,
+		Padded:        share.Padded,
+		Polynomial:    share.Polynomial,
+		Role:          share.Role,
+	}
+	if !share.CreatedAt.IsZero() {
+		info.CreatedAt = &share.CreatedAt
+	}
+	if !share.Policy.IsZero() {
+		info.Policy = &share.Policy
+	}
+	return info
+}
+
+// shareOutput identifies a share dealt or combined by a command.
+type shareOutput struct {
+	Index       uint8  `json:"index"`
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path,omitempty"`
+	Share       string `json:"share,omitempty"`
+}
+
+type splitReport struct {
+	SplitID   shamir.SplitID `json:"splitId"`
+	Threshold uint8          `json:"threshold"`
+	Format    string         `json:"format"`
+	Shares    []shareOutput  `json:"shares"`
+	Manifest  string         `json:"manifest,omitempty"`
+	Shred     string         `json:"shred,omitempty"`
+	DryRun    bool           `json:"dryRun,omitempty"`
+}
+
+type recoverReport struct {
+	SplitID shamir.SplitID `json:"splitId"`
+	Scheme  string         `json:"scheme,omitempty"`
+	Shares  []shareOutput  `json:"shares"`
+	Length  int            `json:"length"`
+	Out     string         `json:"out,omitempty"`
+	Secret  []byte         `json:"secret,omitempty"`
+}
+
+type verifyReport struct {
+	OK       bool           `json:"ok"`
+	Manifest *manifestCheck `json:"manifest,omitempty"`
+	Shares   []shareCheck   `json:"shares"`
+}
+
+type manifestCheck struct {
+	Path   string `json:"path"`
+	Signed bool   `json:"signed"`
+}
+
+type shareCheck struct {
+	Path    string          `json:"path"`
+	OK      bool            `json:"ok"`
+	Index   uint8           `json:"index,omitempty"`
+	SplitID *shamir.SplitID `json:"splitId,omitempty"`
+	Checks  []string        `json:"checks,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+type auditReport struct {
+	Events int    `json:"events"`
+	Signed bool   `json:"signed"`
+	Error  string `json:"error,omitempty"`
+}
+
+type convertReport struct {
+	Index   uint8          `json:"index"`
+	SplitID shamir.SplitID `json:"splitId"`
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Outputs []string       `json:"outputs,omitempty"`
+	Share   string         `json:"share,omitempty"`
+	Data    []byte         `json:"data,omitempty"`
+}
+
+type keychainReport struct {
+	Action      string         `json:"action"`
+	SplitID     shamir.SplitID `json:"splitId"`
+	Index       uint8          `json:"index,omitempty"`
+	Fingerprint string         `json:"fingerprint,omitempty"`
+	Out         string         `json:"out,omitempty"`
+	Share       string         `json:"share,omitempty"`
+}
+
+// errorReport is printed when a command fails.
+type errorReport struct {
+	Error string `json:"error"`
+}
+
+// printJSON prints a report to stdout.
+func printJSON(report any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(report)
+}
+
+// shareOutputs identifies shares by their index and fingerprint.
+func shareOutputs(shares []shamir.Share) []shareOutput {
+	outputs := make([]shareOutput, len(shares))
+	for i, share := range shares {
+		outputs[i] = shareOutput{Index: share.Index, Fingerprint: share.Fingerprint().String()}
+	}
+	return outputs
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// With --progress, the split and recover commands print the progress of the operation to stderr, on a single line
+// rewritten at most every progressInterval, which is only worth it for large secrets.
+
+const progressInterval = 100 * time.Millisecond
+
+// printProgress returns a progress callback printing the progress of the operation named by label to stderr.
+func printProgress(label string) func(shamir.Progress) {
+	var last time.Time
+	return func(p shamir.Progress) {
+		done := p.Done >= p.Total
+		if !done && time.Since(last) < progressInterval {
+			return
+		}
+		last = time.Now()
+		fmt.Fprintf(os.Stderr, "\r%s: %5.1f%% (%d/%d bytes, %s left)", label, p.Percent(), p.Done, p.Total,
+			p.ETA().Round(time.Second))
+		if done {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
@@ -1,32 +1,96 @@
 package random
 
 import (
+	"crypto/rand"
 	"encoding/binary"
-	"math/rand"
-
-	cryptorand "crypto/rand"
+	"io"
+	"math"
 )
 
-// source represents a randomness source suitable for cryptographic use.
-type source [8]byte
+// The package draws uniformly random bytes, integers and permutations from crypto/rand, and reads randomness
+// from other sources (e.g. an HSM) on behalf of the shamir and galois packages.
+//
+// Integers are drawn by rejection sampling: an integer in [0, n) is the remainder modulo n of a random 64-bit
+// integer, rejected when it falls in the last incomplete range of n integers, so that every integer is exactly as
+// likely. Permutations are drawn with the Fisher-Yates shuffle. crypto/rand never fails, so that neither do the
+// functions drawing from it; only ReadFull, which reads from another source, can fail.
+
+// retries is the number of times ReadFull retries a failing read.
+const retries = 3
+
+// Bytes returns n random bytes.
+func Bytes(n int) []byte {
+	b := make([]byte, n)
+	// crypto/rand.Read never fails
+	rand.Read(b)
+	return b
+}
+
+// IntN returns an integer drawn uniformly in [0, n). It panics if n <= 0.
+func IntN(n int) int {
+	if n <= 0 {
+		panic("random: the bound of IntN must be positive")
+	}
+	// limit is the largest multiple of n which is at most 2^64-1
+	limit := math.MaxUint64 - math.MaxUint64%uint64(n)
+	var b [8]byte
+	for {
+		rand.Read(b[:])
+		if v := binary.BigEndian.Uint64(b[:]); v < limit {
+			return int(v % uint64(n))
+		}
+	}
+}
+
+// Uint8NonZero returns a byte drawn uniformly in [1, 255], e.g. a coordinate of GF(2^8) other than the secret's.
+func Uint8NonZero() uint8 {
+	return uint8(IntN(255) + 1)
+}
 
-// Int63 implements the Source interface.
-// It returns a non-negative random 63-bit integer.
-func (s *source) Int63() int64 {
-	// initialize the source with cryptography-suitable randomness
-	cryptorand.Read(s[:])
-	return int64(binary.BigEndian.Uint64(s[:]) & (1<<63 - 1))
+// Perm returns a uniformly random permutation of the integers [0, n).
+func Perm(n int) []int {
+	permutation := make([]int, n)
+	for i := range permutation {
+		permutation[i] = i
+	}
+	Shuffle(n, func(i, j int) {
+		permutation[i], permutation[j] = permutation[j], permutation[i]
+	})
+	return permutation
 }
 
-// Seed implements the Source interface.
-// It panics as we draw randomness using crypto/rand (see Int63) and is therefore of no use here.
-func (s *source) Seed(seed int64) {
-	panic("seed should not be used")
+// Shuffle shuffles n elements uniformly at random, swap swapping the elements of indexes i and j.
+func Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, IntN(i+1))
+	}
 }
 
-// PermSecure generates a permutation of the integer [0,n) from our cryptographically secure source of randomness.
-// See https://stackoverflow.com/questions/40965044/using-crypto-rand-for-generating-permutations-with-rand-perm
+// PermSecure generates a permutation of the integers [0, n) from a cryptographically secure source of randomness.
+//
+// Deprecated: use Perm.
 func PermSecure(n int) []int {
-	random := rand.New(new(source))
-	return random.Perm(n)
+	return Perm(n)
+}
+
+// ReadFull reads exactly len(p) random bytes from r, the same way io.ReadFull does, except that a failing read is
+// retried up to 3 times before its error is returned, as hardware random number generators may fail transiently.
+// p is wiped on failure, so that partial randomness cannot be used by mistake.
+func ReadFull(r io.Reader, p []byte) error {
+	var n, failures int
+	for n < len(p) {
+		read, err := r.Read(p[n:])
+		n += read
+		if err == nil || n == len(p) {
+			continue
+		}
+		if failures++; failures > retries {
+			clear(p)
+			if err == io.EOF && n > 0 {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+	return nil
 }
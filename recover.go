@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shamiraudit"
+	"github.com/etiennebch/shamir-sss/sharekeychain"
+)
+
+func runRecover(args []string) error {
+	flags := newFlagSet("recover", "[share file...]")
+	out := flags.String("out", "-", "file to write the secret to, - for stdout")
+	language := flags.String("language", defaults.Language, "language of the wordlist of mnemonic shares")
+	interactive := flags.Bool("interactive", false, "prompt for the shares one at a time")
+	show := flags.Bool("show", false, "print the secret in interactive mode without asking")
+	keychain := flags.Bool("keychain", false, "add the share of the split stored in the OS credential store")
+	progress := flags.Bool("progress", false, "print the progress of the recovery to stderr")
+	requireRoles := flags.String("require-roles", "", "comma-separated roles of which at least one share is "+
+		"required, such as legal,board=2 to require two shares of the board")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *interactive {
+		if flags.NArg() != 0 || *keychain {
+			flags.Usage()
+			return errUsage
+		}
+		if jsonOutput {
+			return errors.New("--json cannot be used with --interactive")
+		}
+		return recoverInteractive(*out, *language, *show)
+	}
+
+	var shares []shamir.Share
+	// encoded holds the shares of other schemes, which are recovered with shamir.RecoverEncoded
+	var encoded [][]byte
+	if flags.NArg() == 0 || (flags.NArg() == 1 && flags.Arg(0) == "-") {
+		// the shares are read from stdin, one per line
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			if share, _, ok := decodeSchemeShare(scanner.Bytes()); ok {
+				encoded = append(encoded, share)
+				continue
+			}
+			share, err := decodeShare(scanner.Bytes(), *language)
+			if err != nil {
+				return fmt.Errorf("share %d: %w", len(shares)+len(encoded)+1, err)
+			}
+			shares = append(shares, share)
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	} else {
+		for _, path := range flags.Args() {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if share, _, ok := decodeSchemeShare(data); ok {
+				encoded = append(encoded, share)
+				continue
+			}
+			share, err := decodeShare(data, *language)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			shares = append(shares, share)
+		}
+	}
+	if len(encoded) > 0 && (len(shares) > 0 || *keychain) {
+		return errors.New("the shares use different schemes")
+	}
+	if *keychain {
+		if len(shares) == 0 {
+			return errors.New("--keychain requires at least one other share, to identify the split")
+		}
+		share, err := sharekeychain.Load(shares[0].SplitID)
+		if err != nil {
+			return err
+		}
+		shares = append(shares, share)
+	}
+	var options []shamir.RecoverOption
+	if *progress {
+		options = append(options, shamir.WithRecoveryProgress(printProgress("recover")))
+	}
+	if *requireRoles != "" {
+		quorum, err := parseRoleQuorum(*requireRoles)
+		if err != nil {
+			return err
+		}
+		options = append(options, shamir.WithRoleQuorum(quorum))
+	}
+	if len(encoded) > 0 {
+		return recoverScheme(encoded, *out, options...)
+	}
+	shares = shamir.ResolveLegacy(shares)
+	secret, err := recoverSecret(shares, options...)
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	return writeSecret(secret, recoverReport{SplitID: shares[0].SplitID, Shares: shareOutputs(shares)}, *out)
+}
+
+// recoverScheme recovers the secret from the binary encoded shares of another scheme than the default one, and
+// writes it like writeSecret.
+func recoverScheme(encoded [][]byte, out string, options ...shamir.RecoverOption) error {
+	if len(encoded) < 2 {
+		return fmt.Errorf("at least 2 shares are required, got %d", len(encoded))
+	}
+	scheme, err := shamir.SchemeOf(encoded[0])
+	if err != nil {
+		return err
+	}
+	metadata, err := scheme.Verify(encoded[0])
+	if err != nil {
+		return err
+	}
+	if len(encoded) < metadata.Threshold {
+		return fmt.Errorf("%d shares are required, got %d", metadata.Threshold, len(encoded))
+	}
+	secret, err := shamir.RecoverEncoded(encoded, options...)
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	return writeSecret(secret, recoverReport{SplitID: metadata.SplitID, Scheme: scheme.Name()}, out)
+}
+
+// parseRoleQuorum parses comma-separated roles, each optionally followed by = and the number of shares of the
+// role required, 1 by default.
+func parseRoleQuorum(s string) (shamir.RoleQuorum, error) {
+	quorum := make(shamir.RoleQuorum)
+	for _, requirement := range strings.Split(s, ",") {
+		role, count, found := strings.Cut(requirement, "=")
+		quorum[role] = 1
+		if found {
+			n, err := strconv.Atoi(count)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid number of shares of role %q: %q", role, count)
+			}
+			quorum[role] = n
+		}
+	}
+	return quorum, nil
+}
+
+// recoverSecret checks the shares and recovers the secret with the options, logging the recovery to the audit log.
+func recoverSecret(shares []shamir.Share, options ...shamir.RecoverOption) ([]byte, error) {
+	logger, closeAudit, err := openAudit()
+	if err != nil {
+		return nil, err
+	}
+	defer closeAudit()
+	shares = shamir.ResolveLegacy(shares)
+	event := shamiraudit.Event{Type: shamiraudit.RecoveryAttempted, Shares: shamiraudit.Indexes(shares)}
+	if len(shares) > 0 {
+		event.SplitID, event.Threshold = &shares[0].SplitID, shares[0].Threshold
+	}
+	if err := audit(logger, event, nil); err != nil {
+		return nil, err
+	}
+	if err := checkShares(shares); err != nil {
+		event.Type = shamiraudit.RecoveryFailed
+		return nil, errors.Join(err, audit(logger, event, err))
+	}
+	secret, err := shamir.Recover(shares, options...)
+	if err != nil {
+		event.Type = shamiraudit.RecoveryFailed
+		return nil, errors.Join(err, audit(logger, event, err))
+	}
+	event.Type = shamiraudit.RecoverySucceeded
+	if err := audit(logger, event, nil); err != nil {
+		clear(secret)
+		return nil, err
+	}
+	return secret, nil
+}
+
+// writeSecret writes the secret to the file out, or to stdout if out is "-". With --json, the secret is part of
+// the report printed to stdout.
+func writeSecret(secret []byte, report recoverReport, out string) error {
+	report.Length = len(secret)
+	if out != "-" {
+		if err := os.WriteFile(out, secret, 0o600); err != nil {
+			return err
+		}
+		report.Out = out
+	} else if !jsonOutput {
+		_, err := os.Stdout.Write(secret)
+		return err
+	} else {
+		report.Secret = secret
+	}
+	if jsonOutput {
+		return printJSON(report)
+	}
+	return nil
+}
+
+// checkShares checks that shares can be combined, reporting the shares at fault, and that they reach the threshold,
+// which shamir.Recover does not check.
+func checkShares(shares []shamir.Share) error {
+	if len(shares) < 2 {
+		return fmt.Errorf("at least 2 shares are required, got %d", len(shares))
+	}
+	for i, share := range shares {
+		if err := checkShare(shares[:i], share); err != nil {
+			return err
+		}
+	}
+	if len(shares) < int(shares[0].Threshold) {
+		return fmt.Errorf("%d shares are required, got %d", shares[0].Threshold, len(shares))
+	}
+	return nil
+}
+
+// checkShare checks that a share can be combined with the shares already collected.
+func checkShare(collected []shamir.Share, share shamir.Share) error {
+	if len(collected) == 0 {
+		return nil
+	}
+	// legacy shares are compared once completed with the metadata of the other share
+	resolved := shamir.ResolveLegacy([]shamir.Share{collected[0], share})
+	first, share := resolved[0], resolved[1]
+	if share.SplitID != first.SplitID {
+		return fmt.Errorf("share %d belongs to another split than share %d", share.Index, first.Index)
+	}
+	if len(share.Payload) != len(first.Payload) || share.Polynomial != first.Polynomial {
+		return fmt.Errorf("share %d does not match share %d", share.Index, first.Index)
+	}
+	for _, other := range collected {
+		if other.Index == share.Index {
+			return fmt.Errorf("share %d is provided twice", share.Index)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Secrets are never passed as arguments, which would leave them in the shell history and in the list of the
+// processes: they are read from a file, from stdin, or typed at a prompt which does not echo them and asks for
+// them twice. The buffers holding secrets are cleared once used, although copies made by the runtime or the
+// operating system cannot be.
+
+// readSecret reads the secret to split from the file at path if set, or from stdin. When stdin is a terminal and
+// fromStdin is false, the secret is typed at a prompt instead.
+func readSecret(path string, fromStdin bool) ([]byte, error) {
+	if path != "" && fromStdin {
+		return nil, errors.New("--in and --stdin-secret cannot be used together")
+	}
+	if path != "" {
+		return readInput(path)
+	}
+	if fromStdin || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return readInput("-")
+	}
+	return promptSecret()
+}
+
+// promptSecret reads a secret typed twice at a prompt, without echoing it.
+func promptSecret() ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	fmt.Fprint(os.Stderr, "secret: ")
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprint(os.Stderr, "confirm the secret: ")
+	confirmation, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	defer clear(confirmation)
+	if err != nil {
+		clear(secret)
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(secret, confirmation) != 1 {
+		clear(secret)
+		return nil, errors.New("the secrets do not match")
+	}
+	return secret, nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/ceremony"
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// The serve command runs the coordinator of a recovery ceremony (see the ceremony package) until the secret is
+// recovered and delivered to the sink. Custodians must present a TLS client certificate signed by the client CA,
+// and are identified by its common name.
+
+func runServe(args []string) error {
+	flags := newFlagSet("serve", "")
+	addr := flags.String("addr", ":8443", "address to listen on")
+	certFile := flags.String("cert", "", "file holding the PEM certificate of the coordinator")
+	keyFile := flags.String("key", "", "file holding the PEM private key of the coordinator")
+	clientCA := flags.String("client-ca", "", "file holding the PEM certificates of the CAs of the custodians")
+	manifestPath := flags.String("manifest", "", "file holding the manifest of the split")
+	custodians := flags.String("custodians", "", "comma-separated common names of the custodians, any if empty")
+	sink := flags.String("sink", "", "where to deliver the secret: file:PATH or exec:COMMAND")
+	requireRoles := flags.String("require-roles", "", "comma-separated roles of which shares are required, "+
+		"see shamir recover --require-roles")
+	dealerKey := flags.String("dealer-key", "", "file holding the public key of the dealer the shares must be "+
+		"signed by, required to honor their release policies and roles")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || *certFile == "" || *keyFile == "" || *clientCA == "" || *sink == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	config := ceremony.Config{}
+	var err error
+	if config.Sink, err = ceremony.ParseSink(*sink); err != nil {
+		return err
+	}
+	if *dealerKey != "" {
+		if config.DealerKey, err = readPublicKey(*dealerKey); err != nil {
+			return err
+		}
+	}
+	if *custodians != "" {
+		config.Custodians = strings.Split(*custodians, ",")
+	}
+	if *requireRoles != "" {
+		if config.Roles, err = parseRoleQuorum(*requireRoles); err != nil {
+			return err
+		}
+	}
+	if *manifestPath != "" {
+		data, err := os.ReadFile(*manifestPath)
+		if err != nil {
+			return err
+		}
+		if config.Manifest, err = shamir.ParseManifest(data); err != nil {
+			return fmt.Errorf("%s: %w", *manifestPath, err)
+		}
+	}
+	logger, closeAudit, err := openAudit()
+	if err != nil {
+		return err
+	}
+	defer closeAudit()
+	config.Audit = logger
+	tlsConfig, err := serverTLSConfig(*certFile, *keyFile, *clientCA)
+	if err != nil {
+		return err
+	}
+	coordinator, err := ceremony.New(config)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           coordinator.Handler(),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServeTLS("", "")
+	}()
+	fmt.Fprintf(os.Stderr, "waiting for the shares on %s\n", *addr)
+	select {
+	case err := <-errs:
+		return err
+	case <-coordinator.Done():
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	status := coordinator.Status()
+	if jsonOutput {
+		if err := printJSON(status); err != nil {
+			return err
+		}
+		if coordinator.Err() != nil {
+			return errReported
+		}
+		return nil
+	}
+	if err := coordinator.Err(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "the secret was recovered from the shares of %s and delivered\n",
+		strings.Join(status.Custodians, ", "))
+	return nil
+}
+
+// serverTLSConfig returns the TLS configuration of a server requiring client certificates signed by the CAs of
+// the file clientCA.
+func serverTLSConfig(certFile, keyFile, clientCA string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(clientCA)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: no PEM certificate found", clientCA)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
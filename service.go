@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/etiennebch/shamir-sss/shamirgrpc"
+)
+
+// The service command serves the gRPC service splitting and recovering secrets (see the shamirgrpc package) over
+// mutual TLS, until it is interrupted. Clients must present a TLS client certificate signed by the client CA, and
+// may be restricted to a list of common names.
+
+func runService(args []string) error {
+	flags := newFlagSet("service", "")
+	addr := flags.String("addr", ":9443", "address to listen on")
+	certFile := flags.String("cert", "", "file holding the PEM certificate of the service")
+	keyFile := flags.String("key", "", "file holding the PEM private key of the service")
+	clientCA := flags.String("client-ca", "", "file holding the PEM certificates of the CAs of the clients")
+	clients := flags.String("clients", "", "comma-separated common names of the clients allowed, any if empty")
+	dealerKey := flags.String("dealer-key", "", "file holding the public key of the dealer the shares to recover must be signed by")
+	maxSize := flags.Int("max-secret-size", shamirgrpc.DefaultMaxSecretSize, "maximum length of the secrets, in bytes")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || *certFile == "" || *keyFile == "" || *clientCA == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	config := shamirgrpc.Config{MaxSecretSize: *maxSize}
+	if *dealerKey != "" {
+		key, err := readPublicKey(*dealerKey)
+		if err != nil {
+			return err
+		}
+		config.DealerKey = key
+	}
+	if *clients != "" {
+		allowed := strings.Split(*clients, ",")
+		config.Authorize = func(ctx context.Context, request shamirgrpc.Request) error {
+			name, err := shamirgrpc.PeerCommonName(ctx)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(allowed, name) {
+				return fmt.Errorf("%s may not call %s", name, request.Method)
+			}
+			return nil
+		}
+	}
+	logger, closeAudit, err := openAudit()
+	if err != nil {
+		return err
+	}
+	defer closeAudit()
+	config.Audit = logger
+	tlsConfig, err := serverTLSConfig(*certFile, *keyFile, *clientCA)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(tlsConfig)))
+	shamirgrpc.New(config).Register(server)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	fmt.Fprintf(os.Stderr, "serving on %s\n", listener.Addr())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package shamir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Services splitting many small secrets, e.g. API keys or data encryption keys, spend most of the time of Split
+// validating the parameters, setting up the field and picking the coordinates rather than splitting. SplitBatch
+// does so once for all the secrets of a batch, and splits the secrets concurrently.
+//
+// The secrets of a batch share their coordinates: the ith share of every secret has the same index, so that a
+// participant can be handed the ith share of every secret. This does not weaken the scheme, the coordinates not
+// being secret, but every secret still has its own split identifier and polynomials.
+
+// SplitBatch splits every secret into n shares the same way Split does, such that threshold shares of a secret
+// are required to recover it, and returns the shares of the ith secret at index i. The secrets are split by a
+// pool of runtime.GOMAXPROCS(0) goroutines, or of the number of goroutines set by WithParallelism. WithProgress is
+// ignored, and the reader of WithRandom must be safe for concurrent use. If a secret cannot be split, the shares
+// dealt are wiped and the error is returned.
+func SplitBatch(secrets [][]byte, n, threshold uint8, options ...SplitOption) (shares [][]Share, err error) {
+	var c splitConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if c.wipe {
+		defer zeroizeAll(secrets)
+	}
+	defer func() {
+		if err != nil {
+			trace("batch split failed", "secrets", len(secrets), "shares", n, "threshold", threshold, "error", err)
+			return
+		}
+		trace("batch split", "secrets", len(secrets), "shares", n, "threshold", threshold, "padded", c.padding)
+	}()
+	field, err := newField256(c.polynomial, c.constantTime)
+	if err != nil {
+		return nil, err
+	}
+	if threshold > n {
+		return nil, errors.New("shamir: the threshold cannot be greater than the number of shares to deal")
+	}
+	if threshold < minThreshold {
+		return nil, errors.New("shamir: the threshold must be at least 2")
+	}
+	for i, secret := range secrets {
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("shamir: secret %d: the secret cannot be empty", i)
+		}
+	}
+
+	// every secret is split by a single goroutine of the pool
+	pool := workers(c.workers)
+	c.workers, c.progress, c.wipe = 1, nil, false
+	x := pickCoordinates(n)
+	created := time.Now().UTC().Truncate(time.Second)
+	shares = make([][]Share, len(secrets))
+	errs := make([]error, len(secrets))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(pool, len(secrets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(secrets); i = int(next.Add(1) - 1) {
+				shares[i] = make([]Share, n)
+				errs[i] = deal(context.Background(), &c, field, shares[i], secrets[i], threshold, x, created)
+			}
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			for _, dealt := range shares {
+				for _, share := range dealt {
+					Zeroize(share.Payload)
+				}
+			}
+			return nil, fmt.Errorf("shamir: secret %d: %w", i, err)
+		}
+	}
+	return shares, nil
+}
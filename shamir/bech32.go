@@ -0,0 +1,384 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Shares can be encoded using Bech32m (BIP-350), e.g. share1qyps..., which is convenient for hand transcription:
+// the character set avoids ambiguous characters and the checksum detects any error affecting up to 4 characters.
+// When the checksum does not match, DecodeBech32m tries to locate the mistyped characters, and CorrectBech32m
+// fixes them when they can be located unambiguously.
+//
+// The data part holds the format version, the threshold, the share index, the split identifier and the payload.
+// The creation time and the label of the share are not encoded. Shares split using a custom reduction polynomial
+// (see WithPolynomial), from a padded secret (see WithPadding), with a release policy (see WithReleasePolicy) or
+// with roles (see WithRoles) cannot be encoded.
+//
+// Note that the error detection guarantees of Bech32m only hold for strings of up to 90 characters, that is
+// secrets of up to about 32 bytes. Longer strings are accepted, with weaker guarantees.
+
+// DefaultHRP is the human-readable part used to encode shares with Bech32m.
+const DefaultHRP = "share"
+
+const (
+	bech32Charset        = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32mConst         = 0x2bc830a3
+	bech32ChecksumLength = 6
+	bech32MaxLength      = 1023
+	// bech32MaxErrors is the maximum number of mistyped characters DecodeBech32m tries to locate
+	bech32MaxErrors = 2
+)
+
+// TranscriptionError is returned when decoding a share whose checksum does not match.
+// When the mistyped characters could be located, Positions holds their positions in the string, starting from 1.
+type TranscriptionError struct {
+	Positions []int
+}
+
+func (e *TranscriptionError) Error() string {
+	if len(e.Positions) == 0 {
+		return "shamir: invalid checksum, the share was not transcribed correctly"
+	}
+	if len(e.Positions) == 1 {
+		return fmt.Sprintf("shamir: invalid checksum, check the character at position %d", e.Positions[0])
+	}
+	positions := make([]string, len(e.Positions))
+	for i, position := range e.Positions {
+		positions[i] = fmt.Sprint(position)
+	}
+	return fmt.Sprintf("shamir: invalid checksum, check the characters at positions %s", strings.Join(positions, ", "))
+}
+
+// EncodeBech32m encodes a share using Bech32m with the provided human-readable part (e.g. DefaultHRP).
+func EncodeBech32m(share Share, hrp string) (string, error) {
+	if len(hrp) == 0 || len(hrp) > 83 {
+		return "", errors.New("shamir: the human-readable part must be between 1 and 83 characters long")
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", errors.New("shamir: invalid character in the human-readable part")
+		}
+	}
+	hrp = strings.ToLower(hrp)
+	if share.Polynomial != 0 {
+		return "", errors.New("shamir: shares using a custom reduction polynomial cannot be encoded with Bech32m")
+	}
+	if share.Padded {
+		return "", errors.New("shamir: shares of a padded secret cannot be encoded with Bech32m")
+	}
+	if !share.Policy.IsZero() {
+		return "", errors.New("shamir: shares with a release policy cannot be encoded with Bech32m")
+	}
+	if share.Role != "" {
+		return "", errors.New("shamir: shares with a role cannot be encoded with Bech32m")
+	}
+
+	data := make([]byte, 0, 3+len(share.SplitID)+len(share.Payload))
+	data = append(data, Version, share.Threshold, share.Index)
+	data = append(data, share.SplitID[:]...)
+	data = append(data, share.Payload...)
+	values := regroupBits(data, 8, 5)
+
+	if len(hrp)+1+len(values)+bech32ChecksumLength > bech32MaxLength {
+		return "", errors.New("shamir: the share is too long to be encoded with Bech32m")
+	}
+
+	polymod := bech32Polymod(append(append(hrpExpand(hrp), values...), make([]byte, bech32ChecksumLength)...)) ^ bech32mConst
+	var b strings.Builder
+	b.WriteString(hrp)
+	b.WriteByte('1')
+	for _, value := range values {
+		b.WriteByte(bech32Charset[value])
+	}
+	for i := 0; i < bech32ChecksumLength; i++ {
+		b.WriteByte(bech32Charset[polymod>>(5*(5-i))&31])
+	}
+	return b.String(), nil
+}
+
+// DecodeBech32m decodes a share encoded with EncodeBech32m, and returns its human-readable part.
+// If the checksum does not match, a *TranscriptionError is returned.
+func DecodeBech32m(s string) (Share, string, error) {
+	if strings.ToLower(s) != s && strings.ToUpper(s) != s {
+		return Share{}, "", errors.New("shamir: mixed case Bech32m string")
+	}
+	s = strings.ToLower(s)
+	if len(s) > bech32MaxLength {
+		return Share{}, "", errors.New("shamir: the Bech32m string is too long")
+	}
+	separator := strings.LastIndexByte(s, '1')
+	if separator < 1 || separator+bech32ChecksumLength+1 > len(s) {
+		return Share{}, "", errors.New("shamir: invalid Bech32m string")
+	}
+
+	hrp := s[:separator]
+	values := make([]byte, len(s)-separator-1)
+	for i := range values {
+		value := strings.IndexByte(bech32Charset, s[separator+1+i])
+		if value < 0 {
+			return Share{}, hrp, &TranscriptionError{Positions: []int{separator + 2 + i}}
+		}
+		values[i] = byte(value)
+	}
+
+	expanded := append(hrpExpand(hrp), values...)
+	if residue := bech32Polymod(expanded) ^ bech32mConst; residue != 0 {
+		positions := locateErrors(len(hrpExpand(hrp)), len(values), residue)
+		for i := range positions {
+			// convert the positions in the data part to positions in the string
+			positions[i] += separator + 2
+		}
+		return Share{}, hrp, &TranscriptionError{Positions: positions}
+	}
+
+	data := values[:len(values)-bech32ChecksumLength]
+	if len(data)*5%8 > 4 {
+		return Share{}, hrp, ErrInvalidFormat
+	}
+	decoded := regroupBits(data, 5, 8)
+	if len(decoded) < 3+len(SplitID{})+minSecretLength {
+		return Share{}, hrp, ErrInvalidFormat
+	}
+	if decoded[0] != Version {
+		return Share{}, hrp, ErrUnsupportedVersion
+	}
+
+	share := Share{
+		Threshold: decoded[1],
+		Index:     decoded[2],
+		Payload:   decoded[3+len(SplitID{}):],
+	}
+	copy(share.SplitID[:], decoded[3:])
+	return share, hrp, nil
+}
+
+// CorrectBech32m decodes a share encoded with EncodeBech32m like DecodeBech32m, but fixes up to 2 mistyped
+// characters when the checksum designates a single correction. Characters which are not part of the Bech32
+// character set count as mistyped characters. It returns the positions of the corrected characters in the string,
+// starting from 1, which are worth checking against the original: a string holding more errors may be corrected
+// into another valid share. If the share cannot be corrected, a *TranscriptionError is returned.
+func CorrectBech32m(s string) (Share, string, []int, error) {
+	share, hrp, err := DecodeBech32m(s)
+	var transcription *TranscriptionError
+	if !errors.As(err, &transcription) {
+		return share, hrp, nil, err
+	}
+
+	s = strings.ToLower(s)
+	separator := strings.LastIndexByte(s, '1')
+	values := make([]byte, len(s)-separator-1)
+	var erased []int
+	for i := range values {
+		value := strings.IndexByte(bech32Charset, s[separator+1+i])
+		if value < 0 {
+			erased = append(erased, i)
+			value = 0
+		}
+		values[i] = byte(value)
+	}
+	positions := correctValues(hrpExpand(hrp), values, erased)
+	if positions == nil {
+		return Share{}, hrp, nil, transcription
+	}
+
+	corrected := []byte(s)
+	for i, position := range positions {
+		corrected[separator+1+position] = bech32Charset[values[position]]
+		// convert the positions in the data part to positions in the string
+		positions[i] += separator + 2
+	}
+	share, hrp, err = DecodeBech32m(string(corrected))
+	if err != nil {
+		return Share{}, hrp, nil, err
+	}
+	return share, hrp, positions, nil
+}
+
+// bech32Error is the substitution of a character of the data part: value is XORed into the value at position.
+type bech32Error struct {
+	position int
+	value    byte
+}
+
+// bech32Contributions computes the contribution to the checksum of every possible substitution of a character
+// of the data part, whose values are preceded by offset values.
+//
+// The checksum is an affine function of the values: the residue of a string with errors is the XOR of the
+// contributions of every error. The contribution of every possible error is computed once, so that pairs of
+// errors can be matched using a lookup table.
+func bech32Contributions(offset, length int) map[uint32][]bech32Error {
+	zeros := make([]byte, offset+length)
+	base := bech32Polymod(zeros)
+	contributions := make(map[uint32][]bech32Error)
+	for position := 0; position < length; position++ {
+		for value := byte(1); value < 32; value++ {
+			zeros[offset+position] = value
+			contribution := bech32Polymod(zeros) ^ base
+			contributions[contribution] = append(contributions[contribution], bech32Error{position, value})
+		}
+		zeros[offset+position] = 0
+	}
+	return contributions
+}
+
+// explainResidue returns every set of at most maxErrors substitutions explaining the residue, preferring single
+// substitutions: pairs of substitutions are only looked for when no single substitution explains the residue.
+func explainResidue(contributions map[uint32][]bech32Error, residue uint32, maxErrors int) [][]bech32Error {
+	if maxErrors < 1 {
+		return nil
+	}
+	if single := contributions[residue]; len(single) > 0 {
+		explanations := make([][]bech32Error, len(single))
+		for i, e := range single {
+			explanations[i] = []bech32Error{e}
+		}
+		return explanations
+	}
+	if maxErrors < 2 {
+		return nil
+	}
+
+	var explanations [][]bech32Error
+	for contribution, first := range contributions {
+		for _, other := range contributions[residue^contribution] {
+			for _, e := range first {
+				if e.position < other.position {
+					explanations = append(explanations, []bech32Error{e, other})
+				}
+			}
+		}
+	}
+	return explanations
+}
+
+// locateErrors looks for at most bech32MaxErrors substitutions in the data part explaining the residue.
+// It returns the positions of the substituted characters in the data part (starting from 0) if a single
+// explanation was found, or nil otherwise.
+func locateErrors(offset, length int, residue uint32) []int {
+	explanations := explainResidue(bech32Contributions(offset, length), residue, bech32MaxErrors)
+	if len(explanations) == 0 {
+		return nil
+	}
+	positions := make([]int, len(explanations[0]))
+	for i, e := range explanations[0] {
+		positions[i] = e.position
+	}
+	for _, explanation := range explanations[1:] {
+		for i, e := range explanation {
+			if len(explanation) != len(positions) || e.position != positions[i] {
+				// several explanations, the errors cannot be located reliably
+				return nil
+			}
+		}
+	}
+	return positions
+}
+
+// correctValues looks for the values of the erased positions of the data part, and for substitutions of other
+// values, so that the checksum matches while changing at most bech32MaxErrors values. If a single correction
+// changing the fewest values was found, it is applied to the values and the corrected positions (starting from
+// 0) are returned in increasing order. Otherwise, correctValues returns nil.
+func correctValues(prefix, values []byte, erased []int) []int {
+	if len(erased) > bech32MaxErrors {
+		return nil
+	}
+	contributions := bech32Contributions(len(prefix), len(values))
+	expanded := append(prefix[:len(prefix):len(prefix)], values...)
+	data := expanded[len(prefix):]
+
+	var best [][]byte
+	var fewest int
+	for assignment := 0; assignment < 1<<(5*len(erased)); assignment++ {
+		for i, position := range erased {
+			data[position] = byte(assignment >> (5 * i) & 31)
+		}
+		residue := bech32Polymod(expanded) ^ bech32mConst
+		explanations := [][]bech32Error{nil}
+		if residue != 0 {
+			explanations = explainResidue(contributions, residue, bech32MaxErrors-len(erased))
+		}
+	next:
+		for _, explanation := range explanations {
+			corrected := bytes.Clone(data)
+			for _, e := range explanation {
+				if slices.Contains(erased, e.position) {
+					// covered by another assignment of the erased values
+					continue next
+				}
+				corrected[e.position] ^= e.value
+			}
+			switch changed := len(erased) + len(explanation); {
+			case best == nil || changed < fewest:
+				best, fewest = [][]byte{corrected}, changed
+			case changed == fewest:
+				best = append(best, corrected)
+			}
+		}
+	}
+	if len(best) != 1 {
+		return nil
+	}
+
+	var positions []int
+	for i := range values {
+		if values[i] != best[0][i] || slices.Contains(erased, i) {
+			positions = append(positions, i)
+		}
+	}
+	copy(values, best[0])
+	return positions
+}
+
+// bech32Polymod computes the Bech32 checksum polynomial of the values.
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	checksum := uint32(1)
+	for _, value := range values {
+		top := checksum >> 25
+		checksum = (checksum&0x1ffffff)<<5 ^ uint32(value)
+		for i := 0; i < 5; i++ {
+			if top>>i&1 == 1 {
+				checksum ^= generator[i]
+			}
+		}
+	}
+	return checksum
+}
+
+// hrpExpand expands the human-readable part for the checksum computation.
+func hrpExpand(hrp string) []byte {
+	expanded := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+// regroupBits regroups the bits of data from groups of from bits to groups of to bits.
+// When encoding (from 8 to 5 bits), the last group is padded with zero bits. When decoding, the padding
+// bits are dropped.
+func regroupBits(data []byte, from, to uint) []byte {
+	var accumulator, bits uint
+	var result []byte
+	for _, value := range data {
+		accumulator = accumulator<<from | uint(value)
+		bits += from
+		for bits >= to {
+			bits -= to
+			result = append(result, byte(accumulator>>bits&(1<<to-1)))
+		}
+		accumulator &= 1<<bits - 1
+	}
+	if to == 5 && bits > 0 {
+		result = append(result, byte(accumulator<<(to-bits)&(1<<to-1)))
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// bech32Values decodes the human-readable part and the values of the data part of a Bech32 string.
+func bech32Values(t *testing.T, s string) (string, []byte) {
+	t.Helper()
+	s = strings.ToLower(s)
+	separator := strings.LastIndexByte(s, '1')
+	values := make([]byte, len(s)-separator-1)
+	for i := range values {
+		value := strings.IndexByte(bech32Charset, s[separator+1+i])
+		if value < 0 {
+			t.Fatalf("invalid character in %q", s)
+		}
+		values[i] = byte(value)
+	}
+	return s[:separator], values
+}
+
+// TestBech32Checksum checks the checksum against the valid test vectors of BIP-350 (Bech32m) and BIP-173
+// (Bech32, whose constant is 1).
+func TestBech32Checksum(t *testing.T) {
+	for _, tt := range []struct {
+		s        string
+		constant uint32
+	}{
+		{"A1LQFN3A", bech32mConst},
+		{"a1lqfn3a", bech32mConst},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", bech32mConst},
+		{"split1checkupstagehandshakeupstreamerranterredcaperredlc445v", bech32mConst},
+		{"?1v759aa", bech32mConst},
+		{"A12UEL5L", 1},
+		{"a12uel5l", 1},
+		{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", 1},
+		{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", 1},
+		{"?1ezyfcl", 1},
+	} {
+		hrp, values := bech32Values(t, tt.s)
+		if got := bech32Polymod(append(hrpExpand(hrp), values...)); got != tt.constant {
+			t.Errorf("%s: checksum residue = %#x, want %#x", tt.s, got, tt.constant)
+		}
+	}
+}
+
+// TestRegroupBits decodes the witness program of a segwit address of BIP-173.
+func TestRegroupBits(t *testing.T) {
+	_, values := bech32Values(t, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4")
+	// the first value is the witness version, and the last ones the checksum
+	program := regroupBits(values[1:len(values)-bech32ChecksumLength], 5, 8)
+	if got := hex.EncodeToString(program); got != "751e76e8199196d454941c45d1b3a323f1433bd6" {
+		t.Errorf("regroupBits() = %s", got)
+	}
+	if encoded := regroupBits(program, 8, 5); !bytes.Equal(encoded, values[1:len(values)-bech32ChecksumLength]) {
+		t.Errorf("regroupBits() = %v, want %v", encoded, values[1:len(values)-bech32ChecksumLength])
+	}
+}
+
+func TestBech32mRoundTrip(t *testing.T) {
+	shares, err := Split([]byte("correct horse battery staple"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	share := shares[1]
+	encoded, err := EncodeBech32m(share, DefaultHRP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{encoded, strings.ToUpper(encoded)} {
+		decoded, hrp, err := DecodeBech32m(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hrp != DefaultHRP || decoded.Index != share.Index || decoded.Threshold != share.Threshold ||
+			decoded.SplitID != share.SplitID || !bytes.Equal(decoded.Payload, share.Payload) {
+			t.Errorf("DecodeBech32m() = %+v, %s, want %+v", decoded, hrp, share)
+		}
+	}
+
+	// a mistyped character is located and corrected
+	position := len(DefaultHRP) + 10
+	mistyped := []byte(encoded)
+	mistyped[position-1] = bech32Charset[(strings.IndexByte(bech32Charset, mistyped[position-1])+1)%32]
+	var transcription *TranscriptionError
+	if _, _, err := DecodeBech32m(string(mistyped)); !errors.As(err, &transcription) ||
+		len(transcription.Positions) != 1 || transcription.Positions[0] != position {
+		t.Errorf("DecodeBech32m() error = %v, want a transcription error at position %d", err, position)
+	}
+	corrected, _, positions, err := CorrectBech32m(string(mistyped))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 1 || positions[0] != position || !bytes.Equal(corrected.Payload, share.Payload) {
+		t.Errorf("CorrectBech32m() = %+v, %v", corrected, positions)
+	}
+}
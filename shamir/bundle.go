@@ -0,0 +1,98 @@
+package shamir
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Several named secrets can be split in one operation, e.g. the keys of a service. Every participant then
+// receives a single bundle holding their share of every secret, which can be stored as a single file:
+//
+// 	{
+// 		"shares": {
+// 			"database": {"version": 1, "index": 42, ...},
+// 			"signing-key": {"version": 1, "index": 7, ...}
+// 		}
+// 	}
+//
+// Every secret is split independently, and can be recovered on its own.
+
+// Bundle holds the shares of several named secrets dealt to a single participant.
+type Bundle struct {
+	Shares map[string]Share `json:"shares"`
+}
+
+// Names returns the names of the secrets of the bundle, sorted.
+func (b Bundle) Names() []string {
+	names := make([]string, 0, len(b.Shares))
+	for name := range b.Shares {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SplitBundle splits every named secret into n shares, such that threshold shares are required to recover it,
+// and returns one bundle per participant.
+func SplitBundle(secrets map[string][]byte, n, threshold uint8) ([]Bundle, error) {
+	bundles := make([]Bundle, n)
+	for i := range bundles {
+		bundles[i].Shares = make(map[string]Share, len(secrets))
+	}
+	for name, secret := range secrets {
+		shares, err := Split(secret, n, threshold)
+		if err != nil {
+			return nil, fmt.Errorf("shamir: secret %q: %w", name, err)
+		}
+		for i, share := range shares {
+			bundles[i].Shares[name] = share
+		}
+	}
+	return bundles, nil
+}
+
+// RecoverBundle recovers the named secrets from the bundles of the participants, or every secret if no name is
+// provided. A secret can be recovered as long as enough bundles hold a share of it.
+func RecoverBundle(bundles []Bundle, names ...string) (map[string][]byte, error) {
+	if len(bundles) == 0 {
+		return nil, errors.New("shamir: no bundle provided")
+	}
+	if len(names) == 0 {
+		seen := make(map[string]bool)
+		for _, bundle := range bundles {
+			for _, name := range bundle.Names() {
+				if !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	secrets := make(map[string][]byte, len(names))
+	for _, name := range names {
+		var shares []Share
+		for _, bundle := range bundles {
+			if share, ok := bundle.Shares[name]; ok {
+				shares = append(shares, share)
+			}
+		}
+		if len(shares) == 0 {
+			return nil, fmt.Errorf("shamir: unknown secret %q", name)
+		}
+		if len(shares) < int(max(shares[0].Threshold, minThreshold)) {
+			return nil, fmt.Errorf("shamir: %d shares of secret %q are required, got %d", shares[0].Threshold, name,
+				len(shares))
+		}
+		secret, err := Recover(shares)
+		if err != nil {
+			for _, secret := range secrets {
+				Zeroize(secret)
+			}
+			return nil, fmt.Errorf("shamir: secret %q: %w", name, err)
+		}
+		secrets[name] = secret
+	}
+	return secrets, nil
+}
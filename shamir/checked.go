@@ -0,0 +1,130 @@
+package shamir
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// Shares can carry authentication values, so that a participant presenting a forged share during recovery is
+// detected and identified, in the spirit of the information checking of Rabin and Ben-Or ("Verifiable Secret
+// Sharing and Multiparty Protocols with Honest Majority", 1989): for every pair of participants (i, j), the
+// dealer draws a random key held by j, and gives i the tag of its share under that key. During recovery, every
+// share checks the tags of the other shares using its keys. A forged share cannot carry valid tags without the
+// keys of the other participants, so that the honest participants reject it, while a participant lying about its
+// keys only rejects the shares it checks.
+//
+// The tags are HMAC-SHA256 of the index, split identifier and payload of the share, rather than the linear tags
+// of the original scheme, so that a single key checks a payload of any length. A share is rejected if most of the
+// other shares presented reject it, ties being resolved in its favour: the cheaters are identified as long as they
+// are a minority of the shares, e.g. a single cheater among three shares, as an honest share is only rejected by
+// the cheaters and accepted by the other honest shares, which are at least as many.
+
+const (
+	checkKeySize = 32
+	checkDomain  = "shamir-sss share check\x00"
+)
+
+// CheckedShare is a share along with the values authenticating it to the other participants, and checking theirs.
+type CheckedShare struct {
+	Share Share `json:"share"`
+	// Keys holds the keys checking the tags of the other shares, by share index.
+	Keys map[uint8][]byte `json:"keys"`
+	// Tags holds the tags of the share under the keys of the other shares, by share index.
+	Tags map[uint8][]byte `json:"tags"`
+}
+
+// CheatingError is returned by RecoverChecked when some shares are rejected by most of the other shares.
+type CheatingError struct {
+	// Cheaters are the indexes of the rejected shares.
+	Cheaters []uint8
+}
+
+func (e *CheatingError) Error() string {
+	cheaters := make([]string, len(e.Cheaters))
+	for i, index := range e.Cheaters {
+		cheaters[i] = fmt.Sprint(index)
+	}
+	return "shamir: forged shares detected, rejected shares: " + strings.Join(cheaters, ", ")
+}
+
+// SplitChecked splits a secret into n shares like Split does, along with the keys and tags allowing
+// RecoverChecked to detect forged shares.
+func SplitChecked(secret []byte, n, threshold uint8, options ...SplitOption) ([]CheckedShare, error) {
+	shares, err := Split(secret, n, threshold, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := make([]CheckedShare, len(shares))
+	for i, share := range shares {
+		checked[i] = CheckedShare{
+			Share: share,
+			Keys:  make(map[uint8][]byte, len(shares)-1),
+			Tags:  make(map[uint8][]byte, len(shares)-1),
+		}
+	}
+	for i := range checked {
+		for j := range checked {
+			if i == j {
+				continue
+			}
+			key := make([]byte, checkKeySize)
+			if err := random.ReadFull(entropy(), key); err != nil {
+				return nil, fmt.Errorf("shamir: failed to generate a check key: %w", err)
+			}
+			checked[j].Keys[shares[i].Index] = key
+			checked[i].Tags[shares[j].Index] = checkTag(shares[i], key)
+		}
+	}
+	return checked, nil
+}
+
+// RecoverChecked checks the shares against each other and recovers the secret using the same options as Recover.
+// If some shares are rejected by more than half of the other shares, a *CheatingError identifying them is
+// returned.
+func RecoverChecked(shares []CheckedShare, options ...RecoverOption) ([]byte, error) {
+	if len(shares) < int(minThreshold) {
+		return nil, errors.New("shamir: the number of shares provided is below the minimum threshold")
+	}
+
+	var cheaters []uint8
+	for i, share := range shares {
+		var accepted int
+		for j, checker := range shares {
+			if i == j {
+				continue
+			}
+			tag := checkTag(share.Share, checker.Keys[share.Share.Index])
+			if hmac.Equal(share.Tags[checker.Share.Index], tag) {
+				accepted++
+			}
+		}
+		if 2*accepted < len(shares)-1 {
+			cheaters = append(cheaters, share.Share.Index)
+		}
+	}
+	if len(cheaters) > 0 {
+		return nil, &CheatingError{Cheaters: cheaters}
+	}
+
+	plain := make([]Share, len(shares))
+	for i, share := range shares {
+		plain[i] = share.Share
+	}
+	return Recover(plain, options...)
+}
+
+// checkTag computes the tag of a share under a key.
+func checkTag(share Share, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(checkDomain))
+	mac.Write([]byte{share.Index})
+	mac.Write(share.SplitID[:])
+	mac.Write(share.Payload)
+	return mac.Sum(nil)
+}
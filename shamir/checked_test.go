@@ -0,0 +1,42 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRecoverCheckedOneCheater(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+	shares, err := SplitChecked(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	presented := slices.Clone(shares[:3])
+	recovered, err := RecoverChecked(presented)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Errorf("RecoverChecked() = %q, want %q", recovered, secret)
+	}
+
+	// the cheater forges its payload and zeroes its keys, so that it rejects the honest shares
+	cheater := presented[0]
+	cheater.Share.Payload = bytes.Repeat([]byte{0x42}, len(cheater.Share.Payload))
+	cheater.Keys = make(map[uint8][]byte)
+	for index := range shares[0].Keys {
+		cheater.Keys[index] = make([]byte, checkKeySize)
+	}
+	presented[0] = cheater
+	_, err = RecoverChecked(presented)
+	var cheating *CheatingError
+	if !errors.As(err, &cheating) {
+		t.Fatalf("RecoverChecked() error = %v, want a *CheatingError", err)
+	}
+	if !slices.Equal(cheating.Cheaters, []uint8{cheater.Share.Index}) {
+		t.Errorf("RecoverChecked() rejected the shares %v, want only the cheater %d", cheating.Cheaters,
+			cheater.Share.Index)
+	}
+}
@@ -0,0 +1,151 @@
+//go:build dudect
+
+package shamir
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// The operations handling secret bytes are checked for timing leaks the way dudect does (Reparaz et al., "Dude,
+// is my code constant time?", 2017): the operation is timed on inputs drawn at random from two classes, usually
+// fixed inputs and random inputs, and Welch's t-test checks whether the two timing distributions differ. The
+// measurements are noisy, so that the tests are built with the dudect tag only:
+//
+// 	go test -tags dudect -run ConstantTime ./shamir/
+
+const (
+	// timingMeasurements is the number of timed batches per test.
+	timingMeasurements = 200_000
+	// timingBatch is the number of calls per timed batch, to get above the resolution of the clock.
+	timingBatch = 16
+	// timingThreshold is the absolute value of the t statistic above which the timings leak, as in dudect.
+	timingThreshold = 10
+)
+
+// timingSink receives the results of the timed operations, so that they are not optimized away.
+var timingSink byte
+
+// testConstantTime times op on inputs of class 0 and 1 returned by input, and fails if the timings depend on
+// the class of the inputs.
+func testConstantTime[I any](t *testing.T, input func(class int, r *rand.Rand) I, op func(I) byte) {
+	r := rand.New(rand.NewChaCha8([32]byte{7}))
+	classes := make([]int, timingMeasurements)
+	inputs := make([]I, timingMeasurements)
+	for i := range inputs {
+		classes[i] = r.IntN(2)
+		inputs[i] = input(classes[i], r)
+	}
+	timings := make([]float64, timingMeasurements)
+	for i, in := range inputs {
+		start := time.Now()
+		for range timingBatch {
+			timingSink ^= op(in)
+		}
+		timings[i] = float64(time.Since(start))
+	}
+
+	// the first measurements warm up the caches, and the slowest ones are interrupted by the scheduler or the
+	// garbage collector: both are discarded before the test
+	warmup := timingMeasurements / 10
+	sorted := slices.Sorted(slices.Values(timings[warmup:]))
+	cutoff := sorted[len(sorted)*9/10]
+	var n, mean, m2 [2]float64
+	for i := warmup; i < timingMeasurements; i++ {
+		if timings[i] > cutoff {
+			continue
+		}
+		// Welford's online algorithm for the mean and the variance
+		c := classes[i]
+		n[c]++
+		delta := timings[i] - mean[c]
+		mean[c] += delta / n[c]
+		m2[c] += delta * (timings[i] - mean[c])
+	}
+	variance0, variance1 := m2[0]/(n[0]-1), m2[1]/(n[1]-1)
+	statistic := (mean[0] - mean[1]) / math.Sqrt(variance0/n[0]+variance1/n[1])
+	t.Logf("t = %.2f, mean = %.1fns / %.1fns per batch", statistic, mean[0], mean[1])
+	if math.Abs(statistic) > timingThreshold {
+		t.Errorf("the timings depend on the inputs: |t| = %.2f > %d", math.Abs(statistic), timingThreshold)
+	}
+}
+
+// randomBytes returns n random bytes.
+func randomBytes(r *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(r.Uint32())
+	}
+	return b
+}
+
+func TestConstantTimeMultiply(t *testing.T) {
+	field := galois.NewField256(galois.WithConstantTime())
+	testConstantTime(t, func(class int, r *rand.Rand) [2]byte {
+		if class == 0 {
+			return [2]byte{}
+		}
+		return [2]byte{byte(r.Uint32()), byte(r.Uint32())}
+	}, func(in [2]byte) byte {
+		return field.Multiply(in[0], in[1])
+	})
+}
+
+func TestConstantTimeInverse(t *testing.T) {
+	field := galois.NewField256(galois.WithConstantTime())
+	testConstantTime(t, func(class int, r *rand.Rand) byte {
+		if class == 0 {
+			return 1
+		}
+		return byte(r.UintN(255)) + 1
+	}, func(in byte) byte {
+		return field.Inverse(in)
+	})
+}
+
+func TestConstantTimeCombine(t *testing.T) {
+	field, err := newField256(galois.PolynomialAES, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// shares of a 32-byte secret at the coordinates 1, 2 and 3, whose values are all zero or random
+	testConstantTime(t, func(class int, r *rand.Rand) [][]byte {
+		shares := make([][]byte, 3)
+		for i := range shares {
+			if class == 0 {
+				shares[i] = make([]byte, 33)
+			} else {
+				shares[i] = randomBytes(r, 33)
+			}
+			shares[i][32] = byte(i + 1)
+		}
+		return shares
+	}, func(shares [][]byte) byte {
+		return combine(field, shares, 1)[0]
+	})
+}
+
+func TestConstantTimeUnpad(t *testing.T) {
+	// padded secrets of 64 bytes, which are empty or hold a random secret of random length
+	testConstantTime(t, func(class int, r *rand.Rand) []byte {
+		padded := make([]byte, 64)
+		var length int
+		if class == 1 {
+			length = r.IntN(len(padded))
+			copy(padded, randomBytes(r, length))
+		}
+		padded[length] = 0x80
+		return padded
+	}, func(padded []byte) byte {
+		secret, err := unpad(padded)
+		if err != nil {
+			panic(err)
+		}
+		return byte(len(secret))
+	})
+}
@@ -0,0 +1,168 @@
+package shamir
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// Any threshold shares of a split lie on the same polynomials, so that shares beyond the threshold are redundant:
+// when more shares than the threshold recorded in the shares are provided, Recover checks that the extra shares
+// lie on the polynomials interpolated from the first threshold shares, rather than silently recovering garbage
+// if a share is corrupted or forged. If they do not, the subsets of threshold shares are tried in turn (up to
+// maxCrossCheckSubsets of them), and the shares disagreeing with the subset agreeing with the most shares are
+// reported as suspect.
+//
+// Diagnose runs the same vote on its own, to sort out the shares to keep when some are known to be wrong, e.g. a
+// paper backup transcribed wrong: every subset of threshold shares votes for the polynomials going through its
+// shares, and the shares lying on the polynomials which get the most votes are deemed good.
+
+// maxCrossCheckSubsets bounds the number of subsets of shares tried to identify the suspect shares.
+const maxCrossCheckSubsets = 1024
+
+// InconsistentSharesError is returned when the shares provided to Recover do not lie on the same polynomials.
+type InconsistentSharesError struct {
+	// Suspects are the indexes of the shares disagreeing with the largest set of consistent shares, or of all the
+	// shares if no more than threshold shares are consistent.
+	Suspects []uint8
+}
+
+func (e *InconsistentSharesError) Error() string {
+	suspects := make([]string, len(e.Suspects))
+	for i, index := range e.Suspects {
+		suspects[i] = fmt.Sprint(index)
+	}
+	return "shamir: the shares are inconsistent, suspect shares: " + strings.Join(suspects, ", ")
+}
+
+// crossCheck checks that the shares beyond the threshold agree with the first threshold shares, and returns an
+// InconsistentSharesError identifying the suspect shares otherwise.
+func crossCheck(field *galois.Field256, shares []Share) error {
+	threshold := int(shares[0].Threshold)
+	if threshold < int(minThreshold) || len(shares) <= threshold {
+		return nil
+	}
+	best, bestCount := bestSubset(field, shares, threshold)
+	if bestCount == len(shares) {
+		return nil
+	}
+	err := new(InconsistentSharesError)
+	for i, share := range shares {
+		if !best[i] || bestCount == threshold {
+			err.Suspects = append(err.Suspects, share.Index)
+		}
+	}
+	return err
+}
+
+// Diagnose sorts out the good shares from the bad ones, given at least threshold+1 shares of a split. goodIdx and
+// badIdx are positions in shares. An error is returned if the shares cannot be combined, or if no more than
+// threshold shares are consistent, in which case the bad shares cannot be told from the good ones.
+func Diagnose(shares []Share) (goodIdx, badIdx []int, err error) {
+	if err := checkShares(shares, new(recoverConfig)); err != nil {
+		return nil, nil, err
+	}
+	threshold := int(shares[0].Threshold)
+	if threshold < int(minThreshold) {
+		return nil, nil, errors.New("shamir: the threshold of the shares is unknown")
+	}
+	if len(shares) <= threshold {
+		return nil, nil, fmt.Errorf("shamir: %d shares are required to diagnose them, got %d", threshold+1,
+			len(shares))
+	}
+	field, err := newField256(shares[0].Polynomial, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	best, bestCount := bestSubset(field, shares, threshold)
+	if bestCount == threshold {
+		return nil, nil, errors.New("shamir: no more than threshold shares are consistent")
+	}
+	for i := range shares {
+		if best[i] {
+			goodIdx = append(goodIdx, i)
+		} else {
+			badIdx = append(badIdx, i)
+		}
+	}
+	trace("diagnosed", "split", shares[0].SplitID, "shares", len(shares), "bad", len(badIdx))
+	return goodIdx, badIdx, nil
+}
+
+// bestSubset tries the subsets of threshold shares, up to maxCrossCheckSubsets of them, and returns for every
+// share whether it agrees with the subset agreeing with the most shares, along with the number of such shares.
+func bestSubset(field *galois.Field256, shares []Share, threshold int) ([]bool, int) {
+	subset := make([]int, threshold)
+	for i := range subset {
+		subset[i] = i
+	}
+	best := agreeing(field, shares, subset)
+	bestCount := count(best)
+	for tried := 1; tried < maxCrossCheckSubsets && bestCount < len(shares); tried++ {
+		if !nextSubset(subset, len(shares)) {
+			break
+		}
+		agree := agreeing(field, shares, subset)
+		if n := count(agree); n > bestCount {
+			best, bestCount = agree, n
+		}
+	}
+	return best, bestCount
+}
+
+// agreeing returns, for every share, whether it lies on the polynomials interpolated from the subset of shares.
+func agreeing(field *galois.Field256, shares []Share, subset []int) []bool {
+	coordinates := make([]byte, len(subset))
+	for i, j := range subset {
+		coordinates[i] = shares[j].Index
+	}
+	agree := make([]bool, len(shares))
+	for _, j := range subset {
+		agree[j] = true
+	}
+	predicted := make([]byte, len(shares[0].Payload))
+	for j, share := range shares {
+		if agree[j] {
+			continue
+		}
+		basis := galois.LagrangeBasis(field, coordinates, share.Index)
+		clear(predicted)
+		for i, k := range subset {
+			field.MulAddSlice(basis[i], shares[k].Payload, predicted)
+		}
+		agree[j] = subtle.ConstantTimeCompare(predicted, share.Payload) == 1
+	}
+	return agree
+}
+
+// count returns the number of true values.
+func count(values []bool) int {
+	var n int
+	for _, value := range values {
+		if value {
+			n++
+		}
+	}
+	return n
+}
+
+// nextSubset advances subset, a sorted subset of [0, n), to the next subset in lexicographic order, and returns
+// false once all the subsets were enumerated.
+func nextSubset(subset []int, n int) bool {
+	k := len(subset)
+	i := k - 1
+	for i >= 0 && subset[i] == n-k+i {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+	subset[i]++
+	for j := i + 1; j < k; j++ {
+		subset[j] = subset[j-1] + 1
+	}
+	return true
+}
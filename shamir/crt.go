@@ -0,0 +1,154 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// The Asmuth-Bloom scheme ("A modular approach to key safeguarding", 1983) shares a secret using the Chinese
+// remainder theorem rather than polynomials. The secret is cut into blocks of 32 bytes, after a 0x80 byte and
+// zero bytes, and every block is a number s below m0 = 2^256. For a threshold k, the moduli m1 < m2 < ... of the
+// shares are the smallest primes of B = 256+k+64 bits: the product M of the k smallest moduli exceeds m0 times
+// the product of the k-1 largest ones by at least 2^64. The dealer picks a random α below M/m0, and the share of
+// index i holds y mod mi, where y = s + α·m0 < M: any k shares recover y, hence s = y mod m0, while k-1 shares
+// leave at least 2^64 candidates for y equally likely, spread evenly modulo m0. The payload of a share holds
+// its residues, of ceil(B/8) bytes each in big-endian order, one per block.
+//
+// The arithmetic uses math/big, which does not run in constant time, and whose memory cannot be wiped.
+
+const (
+	// crtBlockLength is the length in bytes of the blocks of the secret shared by SplitCRT.
+	crtBlockLength = 32
+	// crtMargin is the number of bits by which the moduli exceed what the threshold requires, which bounds the
+	// information revealed by threshold-1 shares.
+	crtMargin = 64
+)
+
+// SplitCRT splits a secret into n shares using the Asmuth-Bloom scheme, such that threshold shares are required
+// to recover it. Only WithRandom and WithWipe are supported.
+func SplitCRT(secret []byte, n, threshold uint8, options ...SplitOption) ([]SchemeShare, error) {
+	reader, wipe, err := schemeSplitConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	if wipe {
+		defer Zeroize(secret)
+	}
+	if err := checkSchemeParameters(secret, n, threshold); err != nil {
+		return nil, err
+	}
+
+	padded := padBlocks(secret, crtBlockLength)
+	defer Zeroize(padded)
+	moduli := crtModuli(threshold, n)
+	size := crtResidueLength(threshold)
+	// α is below the product of the threshold smallest moduli divided by m0
+	bound := crtProduct(moduli[:threshold])
+	bound.Rsh(bound, 8*crtBlockLength)
+
+	shares := newSchemeShares(VersionCRT, n, threshold, 0)
+	y, residue := new(big.Int), new(big.Int)
+	for start := 0; start < len(padded); start += crtBlockLength {
+		alpha, err := rand.Int(reader, bound)
+		if err != nil {
+			return nil, fmt.Errorf("shamir: failed to generate random number: %w", err)
+		}
+		y.Lsh(alpha, 8*crtBlockLength)
+		y.Or(y, new(big.Int).SetBytes(padded[start:start+crtBlockLength]))
+		for i := range shares {
+			residue.Mod(y, moduli[i])
+			shares[i].Payload = append(shares[i].Payload, residue.FillBytes(make([]byte, size))...)
+		}
+	}
+	trace("split", "split", shares[0].SplitID, "shares", n, "threshold", threshold, "scheme", "crt")
+	return shares, nil
+}
+
+// RecoverCRT combines shares dealt by SplitCRT in order to reconstruct the secret.
+func RecoverCRT(shares []SchemeShare) ([]byte, error) {
+	shares, err := checkSchemeShares(shares, VersionCRT)
+	if err != nil {
+		return nil, err
+	}
+	threshold := shares[0].Threshold
+	size := crtResidueLength(threshold)
+	if shares[0].Pieces != 0 || len(shares[0].Payload) == 0 || len(shares[0].Payload)%size != 0 {
+		return nil, ErrInvalidFormat
+	}
+	moduli := make([]*big.Int, len(shares))
+	all := crtModuli(threshold, slices.Max(schemeIndexes(shares)))
+	for i, share := range shares {
+		moduli[i] = all[share.Index-1]
+	}
+
+	// y = Σ ri·Ni·(Ni^-1 mod mi) mod N, where N is the product of the moduli and Ni = N/mi
+	product := crtProduct(moduli)
+	coefficients := make([]*big.Int, len(moduli))
+	for i, modulus := range moduli {
+		quotient := new(big.Int).Quo(product, modulus)
+		inverse := new(big.Int).ModInverse(quotient, modulus)
+		coefficients[i] = quotient.Mul(quotient, inverse)
+	}
+	blocks := len(shares[0].Payload) / size
+	padded := make([]byte, blocks*crtBlockLength)
+	y, term, residue := new(big.Int), new(big.Int), new(big.Int)
+	m0 := new(big.Int).Lsh(big.NewInt(1), 8*crtBlockLength)
+	for b := range blocks {
+		y.SetInt64(0)
+		for i, share := range shares {
+			residue.SetBytes(share.Payload[b*size : (b+1)*size])
+			if residue.Cmp(moduli[i]) >= 0 {
+				return nil, ErrInvalidFormat
+			}
+			y.Add(y, term.Mul(residue, coefficients[i]))
+		}
+		y.Mod(y, product)
+		y.Mod(y, m0).FillBytes(padded[b*crtBlockLength : (b+1)*crtBlockLength])
+	}
+	secret, err := unpad(padded)
+	if err != nil {
+		Zeroize(padded)
+		return nil, errors.New("shamir: the shares do not hold a secret")
+	}
+	trace("recovered", "split", shares[0].SplitID, "shares", len(shares), "scheme", "crt")
+	return secret, nil
+}
+
+// crtModuli returns the moduli of the shares of indexes 1 to n of the Asmuth-Bloom scheme for the threshold,
+// i.e. the n smallest primes of crtModulusBits(threshold) bits.
+func crtModuli(threshold, n uint8) []*big.Int {
+	moduli := make([]*big.Int, n)
+	candidate := new(big.Int).Lsh(big.NewInt(1), uint(crtModulusBits(threshold)-1))
+	candidate.Add(candidate, big.NewInt(1))
+	two := big.NewInt(2)
+	for i := range moduli {
+		for !candidate.ProbablyPrime(20) {
+			candidate.Add(candidate, two)
+		}
+		moduli[i] = new(big.Int).Set(candidate)
+		candidate.Add(candidate, two)
+	}
+	return moduli
+}
+
+// crtModulusBits returns the number of bits of the moduli of the Asmuth-Bloom scheme for the threshold.
+func crtModulusBits(threshold uint8) int {
+	return 8*crtBlockLength + int(threshold) + crtMargin
+}
+
+// crtResidueLength returns the length in bytes of the residues held by the shares for the threshold.
+func crtResidueLength(threshold uint8) int {
+	return (crtModulusBits(threshold) + 7) / 8
+}
+
+// crtProduct returns the product of the moduli.
+func crtProduct(moduli []*big.Int) *big.Int {
+	product := big.NewInt(1)
+	for _, modulus := range moduli {
+		product.Mul(product, modulus)
+	}
+	return product
+}
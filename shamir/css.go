@@ -0,0 +1,99 @@
+package shamir
+
+import (
+	"context"
+
+	"github.com/etiennebch/shamir-sss/galois"
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// Computational secret sharing (Krawczyk, "Secret Sharing Made Short", 1993) combines encryption, IDA and
+// Shamir's scheme, so that every share is about k times smaller than the secret, while threshold k-1 shares
+// reveal nothing about it under the security of the cipher: the secret is encrypted with a fresh AES-256-GCM key,
+// the ciphertext is dispersed with IDA (see SplitIDA), and the key is split with Shamir's scheme in GF(2^8). The
+// payload of a share is:
+//
+// 	32    share of the key, i.e. the values at the share index of the polynomials of its bytes
+// 	-     IDA share of the nonce (12 bytes) and the ciphertext, including its tag
+//
+// The split identifier is authenticated along with the secret, as by SealLarge.
+
+// SplitCSS splits a secret into n shares such that threshold shares are required to recover it, every share
+// being about threshold times smaller than the secret. Only WithRandom and WithWipe are supported.
+func SplitCSS(secret []byte, n, threshold uint8, options ...SplitOption) ([]SchemeShare, error) {
+	reader, wipe, err := schemeSplitConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	if wipe {
+		defer Zeroize(secret)
+	}
+	if err := checkSchemeParameters(secret, n, threshold); err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, largeKeySize)
+	defer Zeroize(key)
+	if err := random.ReadFull(reader, key); err != nil {
+		return nil, err
+	}
+	aead, err := newLargeAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	shares := newSchemeShares(VersionCSS, n, threshold, largeKeySize)
+	x := make([]byte, n)
+	values := make([][]byte, n)
+	for i := range shares {
+		x[i], values[i] = shares[i].Index, shares[i].Payload
+	}
+	if err := evaluate(context.Background(), field256, key, x, threshold, 1, reader, values, nil, nil); err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if err := random.ReadFull(reader, nonce); err != nil {
+		return nil, err
+	}
+	disperse(aead.Seal(nonce, nonce, secret, shares[0].SplitID[:]), shares)
+	trace("split", "split", shares[0].SplitID, "shares", n, "threshold", threshold, "scheme", "css")
+	return shares, nil
+}
+
+// RecoverCSS combines shares dealt by SplitCSS in order to reconstruct the secret. It returns ErrDecryption if
+// the shares were altered.
+func RecoverCSS(shares []SchemeShare) ([]byte, error) {
+	shares, err := checkSchemeShares(shares, VersionCSS)
+	if err != nil {
+		return nil, err
+	}
+	if shares[0].Pieces != 0 || len(shares[0].Payload) <= largeKeySize {
+		return nil, ErrInvalidFormat
+	}
+	indexes := schemeIndexes(shares)
+	key := make([]byte, largeKeySize)
+	defer Zeroize(key)
+	payloads := make([][]byte, len(shares))
+	for i, coefficient := range galois.LagrangeBasis(field256, indexes, 0) {
+		field256.MulAddSlice(coefficient, shares[i].Payload[:largeKeySize], key)
+		payloads[i] = shares[i].Payload[largeKeySize:]
+	}
+	ciphertext, err := reassemble(indexes, payloads)
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	aead, err := newLargeAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidFormat
+	}
+	nonce := ciphertext[:aead.NonceSize()]
+	secret, err := aead.Open(nil, nonce, ciphertext[aead.NonceSize():], shares[0].SplitID[:])
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	trace("recovered", "split", shares[0].SplitID, "shares", len(shares), "scheme", "css")
+	return secret, nil
+}
@@ -0,0 +1,137 @@
+package shamir
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// Splitting a secret twice normally yields unrelated shares. SplitDeterministic instead draws the randomness of
+// the split from a seed, so that a ceremony can be reproduced: the same secret, seed and parameters always yield
+// the same shares, e.g. to re-derive a lost share on an air-gapped machine, or to let an auditor check the
+// shares dealt. The randomness is the AES-256-CTR keystream (with a zero IV) of the key
+//
+// 	HKDF-SHA256(IKM = secret, salt = seed, info = "shamir-sss deterministic split\x00")
+//
+// read in the order described for test vectors (coordinates, coefficients, then split identifier). The secret is
+// part of the key so that reusing a seed for different secrets does not relate their shares.
+//
+// This trades freshness for determinism: anyone knowing the seed and fewer than threshold shares can check a
+// guess of the secret, so that the seed must be kept as secret as the secret itself, and a split should only be
+// made deterministic when it has to be reproduced.
+
+const deterministicInfo = "shamir-sss deterministic split\x00"
+
+// minSeedLength is the minimum length of a seed, for the keystream to be unpredictable.
+const minSeedLength = 16
+
+// SplitDeterministic splits a secret into n shares the same way Split does, drawing the randomness from the seed
+// (of at least 16 bytes) rather than crypto/rand. WithPolynomial and WithPadding are honored, the other options
+// are ignored. The shares have no creation time, so that they only depend on the parameters.
+func SplitDeterministic(secret []byte, n, threshold uint8, seed []byte, options ...SplitOption) ([]Share, error) {
+	var c splitConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if threshold > n {
+		return nil, errors.New("shamir: the threshold cannot be greater than the number of shares to deal")
+	}
+	if len(secret) < minSecretLength {
+		return nil, errors.New("shamir: the secret cannot be empty")
+	}
+	if threshold < minThreshold {
+		return nil, errors.New("shamir: the threshold must be at least 2")
+	}
+	if len(seed) < minSeedLength {
+		return nil, errors.New("shamir: the seed must be at least 16 bytes long")
+	}
+	field, err := newField256(c.polynomial, false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hkdf.Key(sha256.New, secret, seed, deterministicInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	stream := cipher.StreamReader{S: cipher.NewCTR(block, make([]byte, aes.BlockSize)), R: zeroReader{}}
+	var polynomial uint16
+	if field.Polynomial() != galois.PolynomialAES {
+		polynomial = field.Polynomial()
+	}
+	shares, err := dealFromStream(field, secret, n, threshold, c.padding, polynomial, stream)
+	if err != nil {
+		return nil, err
+	}
+	trace("split", "split", shares[0].SplitID, "shares", n, "threshold", threshold, "padded", c.padding,
+		"deterministic", true)
+	return shares, nil
+}
+
+// dealFromStream splits a secret into n shares, drawing the coordinates, the coefficients and the split
+// identifier from stream, in that order (see TestVector). polynomial is recorded in the shares.
+func dealFromStream(field *galois.Field256, secret []byte, n, threshold uint8, padded bool, polynomial uint16,
+	stream io.Reader) ([]Share, error) {
+	list := make([]byte, 255)
+	for i := range list {
+		list[i] = byte(i + 1)
+	}
+	for i := 0; i < int(n); i++ {
+		j, err := uniform(stream, 255-i)
+		if err != nil {
+			return nil, err
+		}
+		list[i], list[i+j] = list[i+j], list[i]
+	}
+	x := list[:n]
+
+	if padded {
+		secret = pad(secret)
+		defer Zeroize(secret)
+	}
+	values := make([][]byte, n)
+	for i := range values {
+		values[i] = make([]byte, len(secret))
+	}
+	if err := evaluate(context.Background(), field, secret, x, threshold, 1, stream, values, nil, nil); err != nil {
+		return nil, err
+	}
+
+	var id SplitID
+	if _, err := io.ReadFull(stream, id[:]); err != nil {
+		return nil, err
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{
+			Threshold:  threshold,
+			Index:      x[i],
+			SplitID:    id,
+			Payload:    values[i],
+			Padded:     padded,
+			Polynomial: polynomial,
+		}
+	}
+	return shares, nil
+}
+
+// zeroReader reads zeros, so that a stream cipher reading from it yields its keystream.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,180 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// The secrecy of the scheme rests on the randomness of the coefficients of the polynomials. They are drawn from
+// the entropy source of the package, crypto/rand by default, which can be replaced with SetEntropySource, e.g. by
+// the random number generator of an HSM, for all the splits of the process (see WithRandom for a single split).
+// The entropy source also provides the keys of SealLarge and the coefficients of Split16, SplitPrime and
+// SplitSSSS, while the coordinates, split identifiers, salts and nonces, which are not secret, are still drawn
+// from crypto/rand.
+//
+// Regulated environments may have to demonstrate the health of their random number generator. A
+// HealthTestedSource runs the continuous health tests of NIST SP 800-90B (section 4.4) on every byte read from a
+// source: the repetition count test, which detects a source stuck on a value, and the adaptive proportion test,
+// which detects a source producing a value too often over a window of 512 bytes. The cutoffs are derived from
+// the min-entropy per byte claimed for the source, for a false positive probability of 2^-40 per byte. The
+// first 1024 bytes are read and tested before any byte is returned (start-up test). Once a test fails, the
+// source fails closed: every read fails with ErrEntropyHealth, so that no split can use it anymore.
+
+// ErrEntropyHealth is returned when the entropy source fails its health tests.
+var ErrEntropyHealth = errors.New("shamir: the entropy source failed its health tests")
+
+// EntropySource is a source of random bytes, which must be safe for concurrent use. Read must fill p entirely or
+// return an error.
+type EntropySource interface {
+	io.Reader
+}
+
+// entropySourceHolder holds the entropy source set by SetEntropySource.
+type entropySourceHolder struct {
+	source EntropySource
+}
+
+var entropySource atomic.Pointer[entropySourceHolder]
+
+// SetEntropySource sets the entropy source of the package, or restores crypto/rand if source is nil.
+func SetEntropySource(source EntropySource) {
+	if source == nil {
+		entropySource.Store(nil)
+		return
+	}
+	entropySource.Store(&entropySourceHolder{source})
+}
+
+// entropy returns the entropy source of the package.
+func entropy() EntropySource {
+	if holder := entropySource.Load(); holder != nil {
+		return holder.source
+	}
+	return rand.Reader
+}
+
+const (
+	// healthFalsePositive is the false positive probability of the health tests, per byte.
+	healthFalsePositive = 0x1p-40
+	// aptWindow is the window of the adaptive proportion test for non-binary sources.
+	aptWindow = 512
+	// startupLength is the number of bytes tested before a HealthTestedSource returns any byte.
+	startupLength = 1024
+)
+
+// HealthTestedSource is an entropy source running continuous health tests on the bytes read from another source.
+type HealthTestedSource struct {
+	source    EntropySource
+	rctCutoff int
+	aptCutoff int
+
+	mu      sync.Mutex
+	started bool
+	failed  bool
+	// state of the repetition count test: the last byte and the number of times it was repeated in a row
+	last        byte
+	repetitions int
+	// state of the adaptive proportion test: the first byte of the window, the number of times it was seen in the
+	// window and the number of bytes of the window seen so far
+	first   byte
+	matches int
+	seen    int
+}
+
+// NewHealthTestedSource returns an entropy source testing the bytes read from source, which is assessed to provide
+// minEntropy bits of min-entropy per byte, 0 < minEntropy <= 8 (8 for crypto/rand).
+func NewHealthTestedSource(source EntropySource, minEntropy float64) (*HealthTestedSource, error) {
+	if !(minEntropy > 0 && minEntropy <= 8) {
+		return nil, errors.New("shamir: the min-entropy of a source must be between 0 and 8 bits per byte")
+	}
+	return &HealthTestedSource{
+		source:    source,
+		rctCutoff: 1 + int(math.Ceil(-math.Log2(healthFalsePositive)/minEntropy)),
+		aptCutoff: aptCutoff(math.Exp2(-minEntropy)),
+	}, nil
+}
+
+// aptCutoff returns the cutoff of the adaptive proportion test for a source producing its most likely byte with
+// probability p: the smallest number of occurrences of the first byte of a window whose probability is at most
+// healthFalsePositive.
+func aptCutoff(p float64) int {
+	var cdf float64
+	for k := 0; k < aptWindow; k++ {
+		// the probability that the first byte of the window is seen k more times among the W-1 other bytes
+		n := aptWindow - 1
+		logBinomial, _ := math.Lgamma(float64(n + 1))
+		logK, _ := math.Lgamma(float64(k + 1))
+		logNK, _ := math.Lgamma(float64(n - k + 1))
+		cdf += math.Exp(logBinomial - logK - logNK + float64(k)*math.Log(p) + float64(n-k)*math.Log1p(-p))
+		if cdf >= 1-healthFalsePositive {
+			// the first byte of the window is counted along with its k repetitions
+			return k + 2
+		}
+	}
+	return aptWindow
+}
+
+// Read reads len(p) bytes from the source once they passed the health tests.
+func (s *HealthTestedSource) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started && !s.failed {
+		startup := make([]byte, startupLength)
+		err := s.fill(startup)
+		Zeroize(startup)
+		if err != nil {
+			return 0, err
+		}
+		s.started = true
+	}
+	if err := s.fill(p); err != nil {
+		clear(p)
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Failed returns true if the source failed its health tests.
+func (s *HealthTestedSource) Failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failed
+}
+
+// fill reads p from the source and tests its bytes. Their values are tested without branching, so that the time
+// taken does not leak them.
+func (s *HealthTestedSource) fill(p []byte) error {
+	if s.failed {
+		return ErrEntropyHealth
+	}
+	if err := random.ReadFull(s.source, p); err != nil {
+		return fmt.Errorf("shamir: cannot read the entropy source: %w", err)
+	}
+	var failed int
+	for _, b := range p {
+		// repetition count test
+		s.repetitions = subtle.ConstantTimeSelect(subtle.ConstantTimeByteEq(b, s.last), s.repetitions+1, 1)
+		s.last = b
+		failed |= subtle.ConstantTimeLessOrEq(s.rctCutoff, s.repetitions)
+		// adaptive proportion test
+		start := subtle.ConstantTimeEq(int32(s.seen), 0)
+		s.first = byte(subtle.ConstantTimeSelect(start, int(b), int(s.first)))
+		s.matches = subtle.ConstantTimeSelect(start, 0, s.matches) + subtle.ConstantTimeByteEq(b, s.first)
+		failed |= subtle.ConstantTimeLessOrEq(s.aptCutoff, s.matches)
+		s.seen = (s.seen + 1) % aptWindow
+	}
+	if failed == 1 {
+		s.failed = true
+		trace("entropy source failed its health tests")
+		return ErrEntropyHealth
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package shamir
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/wordlist"
+)
+
+// Fingerprints are short digests which custodians can compare out-of-band, e.g. over the phone:
+//
+// 	- the fingerprint of a share identifies the share, so that a custodian can check they hold the share
+// 	  recorded by the dealer, without disclosing it
+// 	- the fingerprint of a share set only depends on the split identifier and the threshold, so that every
+// 	  custodian can compute it from their own share and check they all hold shares from the same split
+//
+// Fingerprints are truncated SHA-256 hashes: they detect mistakes, not forgeries. The fingerprint of a share
+// covers its release policy and role, so that a share whose policy or role was stripped or edited does not match
+// the fingerprint recorded by the manifest; shares without either keep the fingerprint of earlier versions.
+
+const (
+	shareFingerprintDomain      = "shamir-sss share fingerprint"
+	restrictedFingerprintDomain = "shamir-sss restricted share fingerprint"
+	setFingerprintDomain        = "shamir-sss set fingerprint"
+)
+
+// Fingerprint is a short digest, displayed as 8 hexadecimal characters (String) or 4 words (Words).
+type Fingerprint [6]byte
+
+// String returns the first 32 bits of the fingerprint as 8 hexadecimal characters.
+func (f Fingerprint) String() string {
+	return hex.EncodeToString(f[:4])
+}
+
+// Words returns the first 44 bits of the fingerprint as 4 words of the English BIP-39 wordlist.
+func (f Fingerprint) Words() string {
+	words := make([]string, 4)
+	for i := range words {
+		words[i] = wordlist.English[readWord(f[:], i)]
+	}
+	return strings.Join(words, " ")
+}
+
+// Fingerprint computes the fingerprint of the share. The creation time and the label are not part of the
+// fingerprint, unlike the release policy and the role.
+func (s Share) Fingerprint() Fingerprint {
+	header := []byte{Version, s.Threshold, s.Index}
+	if s.Policy.IsZero() && s.Role == "" {
+		return fingerprint(shareFingerprintDomain, header, s.SplitID[:], s.Payload)
+	}
+	restrictions := append(s.Policy.appendBinary(nil), uint8(len(s.Role)))
+	return fingerprint(restrictedFingerprintDomain, header, s.SplitID[:], restrictions, []byte(s.Role), s.Payload)
+}
+
+// SetFingerprint computes the fingerprint of a share set. The shares must belong to the same split.
+func SetFingerprint(shares []Share) (Fingerprint, error) {
+	if len(shares) == 0 {
+		return Fingerprint{}, errors.New("shamir: no share provided")
+	}
+	for _, share := range shares[1:] {
+		if share.SplitID != shares[0].SplitID || share.Threshold != shares[0].Threshold {
+			return Fingerprint{}, errors.New("shamir: the shares do not belong to the same split")
+		}
+	}
+	return fingerprint(setFingerprintDomain, []byte{Version, shares[0].Threshold}, shares[0].SplitID[:]), nil
+}
+
+// fingerprint hashes the parts of the data, prefixed with a domain separation string.
+func fingerprint(domain string, parts ...[]byte) Fingerprint {
+	hash := sha256.New()
+	hash.Write([]byte(domain))
+	for _, part := range parts {
+		hash.Write(part)
+	}
+	var f Fingerprint
+	copy(f[:], hash.Sum(nil))
+	return f
+}
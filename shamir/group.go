@@ -0,0 +1,127 @@
+package shamir
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Group describes a group of participants in a two-level Shamir scheme.
+// Members is the number of shares dealt within the group and Threshold is the number of
+// member shares required to recover the group share.
+type Group struct {
+	Members   uint8
+	Threshold uint8
+}
+
+// SplitGroups splits a secret using a two-level Shamir scheme, similar to the one described by SLIP-0039.
+//
+// The secret is first split into one share per group, such that groupThreshold group shares are
+// required to recover the secret. Then, every group share is itself split among the members of the
+// group using the group's member threshold.
+//
+// The result is indexed by group, then by member: SplitGroups(...)[g][m] is the share of member m in group g.
+// The payload of a member share is two bytes longer than the secret, since it is the share of a group share
+// along with its coordinate and the group threshold, which RecoverGroups checks.
+//
+// Unlike Split, a member threshold of 1 is allowed: every member of such a group can recover the group
+// share on their own.
+func SplitGroups(secret []byte, groupThreshold uint8, groups []Group) ([][]Share, error) {
+	if len(secret) < minSecretLength {
+		return nil, errors.New("shamir: the secret cannot be empty")
+	}
+	if len(groups) == 0 || len(groups) > 255 {
+		return nil, errors.New("shamir: the number of groups must be between 1 and 255")
+	}
+	if groupThreshold == 0 || int(groupThreshold) > len(groups) {
+		return nil, errors.New("shamir: the group threshold must be between 1 and the number of groups")
+	}
+	for _, group := range groups {
+		if group.Threshold == 0 || group.Threshold > group.Members {
+			return nil, errors.New("shamir: the member threshold of a group must be between 1 and the number of members")
+		}
+	}
+
+	groupShares, err := split(field256, secret, uint8(len(groups)), groupThreshold, 1)
+	if err != nil {
+		return nil, err
+	}
+	// the secrets of the groups are only needed to split them among the members
+	defer zeroizeAll(groupShares)
+	shares := make([][]Share, len(groups))
+	for g, group := range groups {
+		groupSecret := append(slices.Clip(groupShares[g]), groupThreshold)
+		members, err := split(field256, groupSecret, group.Members, group.Threshold, 1)
+		Zeroize(groupSecret)
+		if err != nil {
+			return nil, err
+		}
+		shares[g] = newShares(members, group.Threshold)
+	}
+	return shares, nil
+}
+
+// RecoverGroups recovers a secret split with SplitGroups.
+// Every entry of shares holds the member shares of a single group. Each group must provide at least
+// its member threshold of shares, and at least the group threshold of groups must be provided, otherwise an error
+// is returned.
+func RecoverGroups(shares [][]Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("shamir: at least one group must be provided")
+	}
+	groupShares := make([][]byte, len(shares))
+	defer zeroizeAll(groupShares)
+	var groupThreshold uint8
+	for g, members := range shares {
+		if len(members) == 0 {
+			return nil, errors.New("shamir: every group must provide at least one member share")
+		}
+		for _, member := range members {
+			if member.SplitID != members[0].SplitID || member.Threshold != members[0].Threshold {
+				return nil, ErrMixedSplits
+			}
+		}
+		if threshold := int(members[0].Threshold); len(members) < threshold {
+			return nil, fmt.Errorf("shamir: group %d: %d member shares are required to recover the group share, "+
+				"got %d", g+1, threshold, len(members))
+		}
+		matrix := shareMatrix(members)
+		recovered, err := recoverLenient(matrix)
+		zeroizeAll(matrix)
+		if err != nil {
+			return nil, err
+		}
+		// the group share is followed by the group threshold
+		groupShares[g] = recovered[:len(recovered)-1]
+		if g > 0 && recovered[len(recovered)-1] != groupThreshold {
+			Zeroize(recovered)
+			return nil, ErrMixedSplits
+		}
+		groupThreshold = recovered[len(recovered)-1]
+	}
+	if groupThreshold == 0 || len(shares) < int(groupThreshold) {
+		return nil, fmt.Errorf("shamir: %d groups are required to recover the secret, got %d", groupThreshold,
+			len(shares))
+	}
+	return recoverLenient(groupShares)
+}
+
+// recoverLenient validates shares the same way Recover does, except that a single share is accepted
+// for schemes using a threshold of 1.
+func recoverLenient(shares [][]byte) ([]byte, error) {
+	shareLength := len(shares[0])
+	if shareLength < minSecretLength+1 {
+		return nil, errors.New("shamir: the shares are too short")
+	}
+	for i, share := range shares {
+		if len(share) != shareLength {
+			return nil, errors.New("shamir: all shares must be the same length")
+		}
+		for _, other := range shares[:i] {
+			if share[shareLength-1] == other[shareLength-1] {
+				return nil, errors.New("shamir: all shares must have distinct indexes")
+			}
+		}
+	}
+	return combine(field256, shares, 1), nil
+}
@@ -0,0 +1,47 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecoverGroups(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+	groups := []Group{{Members: 1, Threshold: 1}, {Members: 3, Threshold: 2}, {Members: 5, Threshold: 3}}
+	shares, err := SplitGroups(bytes.Clone(secret), 2, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares[1][0].Payload) != len(secret)+2 {
+		t.Errorf("the member shares hold %d bytes, want %d", len(shares[1][0].Payload), len(secret)+2)
+	}
+
+	for _, subset := range [][][]Share{
+		{shares[0], shares[1][1:]},
+		{shares[2][:3], shares[1][:2]},
+		{shares[0], shares[1][:2], {shares[2][4], shares[2][0], shares[2][2]}},
+	} {
+		recovered, err := RecoverGroups(subset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recovered, secret) {
+			t.Errorf("RecoverGroups() = %q, want %q", recovered, secret)
+		}
+	}
+
+	if _, err := RecoverGroups([][]Share{shares[0], shares[1][:1]}); err == nil {
+		t.Error("RecoverGroups() accepted a group with fewer shares than its member threshold")
+	}
+	if _, err := RecoverGroups([][]Share{shares[2][1:4]}); err == nil {
+		t.Error("RecoverGroups() accepted fewer groups than the group threshold")
+	}
+
+	other, err := SplitGroups(bytes.Clone(secret), 1, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RecoverGroups([][]Share{shares[0], other[1][:2]}); err == nil {
+		t.Error("RecoverGroups() accepted groups of different splits")
+	}
+}
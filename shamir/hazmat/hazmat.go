@@ -0,0 +1,57 @@
+package hazmat
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"github.com/etiennebch/shamir-sss/galois"
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// This package exposes the low-level primitives Shamir's scheme is built on in GF(2^8), for researchers and
+// implementers of protocols such as distributed key generation or resharing.
+//
+// WARNING: these primitives do not validate their inputs the way shamir.Split and shamir.Recover do, and misusing
+// them breaks the security of the scheme. For instance, a polynomial must never be reused across secrets, its
+// coefficients must be kept secret and erased once the shares are computed, no coordinate may be 0 (the value
+// at 0 is the secret), and the coordinates of the participants must be distinct. Use the shamir package unless
+// you know exactly why you need this one.
+
+var field = galois.NewField256()
+
+// RandomPolynomial returns a polynomial of the provided degree over GF(2^8) whose intercept is provided and whose
+// other coefficients are uniformly random. polynomial[i] is the coefficient of degree i.
+// In a (k,n) Shamir scheme, the degree is k-1 and the intercept is a byte of the secret.
+func RandomPolynomial(intercept byte, degree int) ([]byte, error) {
+	polynomial, err := galois.RandomPoly[uint8](field, intercept, degree, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return polynomial.Coefficients, nil
+}
+
+// EvaluatePolynomial computes the value of a polynomial over GF(2^8) at point x, using Horner's algorithm.
+func EvaluatePolynomial(polynomial []byte, x byte) byte {
+	return galois.NewPoly(field, polynomial...).Eval(x)
+}
+
+// InterpolatePolynomial computes the value at point z of the polynomial of lowest degree over GF(2^8) going
+// through the points (x[i], y[i]), using Lagrange's algorithm. The value at 0 is the secret. It panics with
+// galois.ErrDivisionByZero if the coordinates x are not distinct.
+func InterpolatePolynomial(x, y []byte, z byte) byte {
+	return galois.Interpolate[uint8](field, x, y, z)
+}
+
+// PickCoordinates picks n distinct non-zero coordinates in GF(2^8) uniformly at random, one for every
+// participant. n cannot exceed 255.
+func PickCoordinates(n int) ([]byte, error) {
+	if n < 0 || n > 255 {
+		return nil, errors.New("hazmat: at most 255 coordinates can be picked in GF(2^8)")
+	}
+	coordinates := make([]byte, n)
+	for i, x := range random.Perm(255)[:n] {
+		// +1 since 0 cannot be picked as it corresponds to the secret
+		coordinates[i] = byte(x + 1)
+	}
+	return coordinates, nil
+}
@@ -0,0 +1,99 @@
+package shamir
+
+import (
+	"errors"
+)
+
+// The information dispersal algorithm (Rabin, "Efficient Dispersal of Information for Security, Load Balancing,
+// and Fault Tolerance", 1989) splits data into n shares such that any threshold k of them recover it, every share
+// being k times smaller than the data. Unlike secret sharing, it provides no secrecy: every share reveals part of
+// the data. It stores data redundantly, e.g. across failure domains, and is the building block of CSS (see
+// SplitCSS).
+//
+// SplitIDA computes in GF(2^8), byte by byte: the data is cut into k pieces, and for every byte position, the
+// polynomial of degree k-1 takes the bytes of the pieces at the points 1 to k, so that the first k shares are
+// the pieces themselves. The share of index i holds the values of the polynomials at i. The data is followed by
+// a 0x80 byte and zero bytes up to a multiple of k bytes, which RecoverIDA removes.
+
+// SplitIDA disperses data into n shares such that any threshold shares recover it. The shares do not keep the
+// data secret. Only WithRandom and WithWipe are supported, though the data is dispersed without randomness.
+func SplitIDA(data []byte, n, threshold uint8, options ...SplitOption) ([]SchemeShare, error) {
+	_, wipe, err := schemeSplitConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	if wipe {
+		defer Zeroize(data)
+	}
+	if err := checkSchemeParameters(data, n, threshold); err != nil {
+		return nil, err
+	}
+	shares := newSchemeShares(VersionIDA, n, threshold, 0)
+	disperse(data, shares)
+	trace("split", "split", shares[0].SplitID, "shares", n, "threshold", threshold, "scheme", "ida")
+	return shares, nil
+}
+
+// RecoverIDA combines shares dealt by SplitIDA in order to reconstruct the data.
+func RecoverIDA(shares []SchemeShare) ([]byte, error) {
+	shares, err := checkSchemeShares(shares, VersionIDA)
+	if err != nil {
+		return nil, err
+	}
+	if shares[0].Pieces != 0 {
+		return nil, ErrInvalidFormat
+	}
+	payloads := make([][]byte, len(shares))
+	for i, share := range shares {
+		payloads[i] = share.Payload
+	}
+	data, err := reassemble(schemeIndexes(shares), payloads)
+	if err != nil {
+		return nil, err
+	}
+	trace("recovered", "split", shares[0].SplitID, "shares", len(shares), "scheme", "ida")
+	return data, nil
+}
+
+// disperse cuts the padded data into as many pieces as the threshold of the shares, and appends the values at
+// the indexes of the shares to their payloads.
+func disperse(data []byte, shares []SchemeShare) {
+	threshold := int(shares[0].Threshold)
+	padded := padBlocks(data, threshold)
+	defer Zeroize(padded)
+	length := len(padded) / threshold
+	points := make([]byte, threshold)
+	pieces := make([][]byte, threshold)
+	for j := range pieces {
+		points[j] = byte(j + 1)
+		pieces[j] = padded[j*length : (j+1)*length]
+	}
+	at := make([]byte, len(shares))
+	out := make([][]byte, len(shares))
+	for i := range shares {
+		start := len(shares[i].Payload)
+		shares[i].Payload = append(shares[i].Payload, make([]byte, length)...)
+		at[i], out[i] = shares[i].Index, shares[i].Payload[start:]
+	}
+	extend(field256, points, pieces, at, out)
+}
+
+// reassemble recovers the data dispersed by disperse from the payloads of as many shares as the threshold, of
+// the provided indexes.
+func reassemble(indexes []byte, payloads [][]byte) ([]byte, error) {
+	length := len(payloads[0])
+	padded := make([]byte, len(indexes)*length)
+	points := make([]byte, len(indexes))
+	out := make([][]byte, len(indexes))
+	for j := range out {
+		points[j] = byte(j + 1)
+		out[j] = padded[j*length : (j+1)*length]
+	}
+	extend(field256, indexes, payloads, points, out)
+	data, err := unpad(padded)
+	if err != nil {
+		Zeroize(padded)
+		return nil, errors.New("shamir: the shares do not hold dispersed data")
+	}
+	return data, nil
+}
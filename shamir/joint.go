@@ -0,0 +1,170 @@
+package shamir
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// A secret can be generated jointly by n participants, without a trusted dealer: every participant splits a
+// random contribution among all the participants, and adds up the shares of the contributions it receives. Since
+// the shares are linear, the sums are shares of the sum of the contributions, a secret that nobody ever sees in
+// full, which can be recovered with Recover like any other secret:
+//
+// 	1. Every participant creates a JointParticipant with the same JointConfig, calls Deal and sends every
+// 	   JointMessage to its recipient over a private channel.
+// 	2. Every participant passes the messages it received to Receive, and calls Finalize once it received the
+// 	   contributions of all the other participants to get its share.
+//
+// The protocol is transport agnostic: the messages are plain structs which can be encoded in JSON, and the
+// caller is responsible for the channels, which must be encrypted and authenticated, e.g. using the sharecrypt
+// package. The participants are assumed to follow the protocol: a participant dealing inconsistent shares of its
+// contribution goes unnoticed until the secret is recovered from different sets of shares, see the sharescalar
+// package for a verifiable protocol generating scalars.
+
+// JointConfig holds the parameters of a joint generation, which all the participants must agree on.
+type JointConfig struct {
+	// SplitID identifies the generation and the resulting shares, e.g. drawn by one of the participants.
+	SplitID SplitID `json:"splitId"`
+	// Participants holds the distinct, non-zero indexes of the participants, which are the indexes of their shares.
+	Participants []uint8 `json:"participants"`
+	// Threshold is the number of shares required to recover the secret.
+	Threshold uint8 `json:"threshold"`
+	// Length is the length of the secret in bytes.
+	Length int `json:"length"`
+}
+
+// JointMessage carries the share of the contribution of a participant sent to another participant.
+type JointMessage struct {
+	SplitID SplitID `json:"splitId"`
+	// From is the index of the participant which dealt the contribution.
+	From uint8 `json:"from"`
+	// To is the index of the recipient.
+	To uint8 `json:"to"`
+	// Payload is the share of the contribution of From held by To.
+	Payload []byte `json:"payload"`
+}
+
+// JointParticipant is the state of a participant to a joint generation.
+type JointParticipant struct {
+	config JointConfig
+	index  uint8
+	// sum is the sum of the shares of the contributions received so far, including its own
+	sum      []byte
+	dealt    bool
+	received map[uint8]bool
+}
+
+// NewJointParticipant returns the state of the participant of the provided index to a joint generation.
+func NewJointParticipant(index uint8, config JointConfig) (*JointParticipant, error) {
+	if err := checkIndexes(config.Participants); err != nil {
+		return nil, err
+	}
+	n := len(config.Participants)
+	if config.Threshold < minThreshold {
+		return nil, errors.New("shamir: the threshold must be at least 2")
+	}
+	if int(config.Threshold) > n {
+		return nil, errors.New("shamir: the threshold cannot be greater than the number of participants")
+	}
+	if config.Length < minSecretLength {
+		return nil, errors.New("shamir: the secret cannot be empty")
+	}
+	if !slices.Contains(config.Participants, index) {
+		return nil, fmt.Errorf("shamir: %d is not the index of a participant", index)
+	}
+	config.Participants = append([]uint8(nil), config.Participants...)
+	return &JointParticipant{
+		config:   config,
+		index:    index,
+		sum:      make([]byte, config.Length),
+		received: make(map[uint8]bool, n-1),
+	}, nil
+}
+
+// Deal draws the contribution of the participant and splits it, returning the messages to send to the other
+// participants. It can only be called once.
+func (p *JointParticipant) Deal() ([]JointMessage, error) {
+	if p.dealt {
+		return nil, errors.New("shamir: the contribution was already dealt")
+	}
+	field, err := newField256(0, false)
+	if err != nil {
+		return nil, err
+	}
+	contribution := make([]byte, p.config.Length)
+	defer Zeroize(contribution)
+	if err := random.ReadFull(entropy(), contribution); err != nil {
+		return nil, fmt.Errorf("shamir: failed to generate the contribution: %w", err)
+	}
+	shares := make([]Share, len(p.config.Participants))
+	err = deal(context.Background(), &splitConfig{}, field, shares, contribution, p.config.Threshold,
+		p.config.Participants, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	p.dealt = true
+
+	messages := make([]JointMessage, 0, len(shares)-1)
+	for _, share := range shares {
+		if share.Index == p.index {
+			field.AddSlice(share.Payload, p.sum)
+			Zeroize(share.Payload)
+			continue
+		}
+		messages = append(messages, JointMessage{
+			SplitID: p.config.SplitID,
+			From:    p.index,
+			To:      share.Index,
+			Payload: share.Payload,
+		})
+	}
+	return messages, nil
+}
+
+// Receive adds the share of the contribution of another participant.
+func (p *JointParticipant) Receive(m JointMessage) error {
+	switch {
+	case m.SplitID != p.config.SplitID:
+		return ErrMixedSplits
+	case m.To != p.index:
+		return fmt.Errorf("shamir: the message is addressed to participant %d", m.To)
+	case m.From == p.index || !slices.Contains(p.config.Participants, m.From):
+		return fmt.Errorf("shamir: unexpected message from participant %d", m.From)
+	case p.received[m.From]:
+		return fmt.Errorf("shamir: the contribution of participant %d was already received", m.From)
+	case len(m.Payload) != p.config.Length:
+		return fmt.Errorf("shamir: the contribution of participant %d has an invalid length", m.From)
+	}
+	field, err := newField256(0, false)
+	if err != nil {
+		return err
+	}
+	field.AddSlice(m.Payload, p.sum)
+	p.received[m.From] = true
+	return nil
+}
+
+// Finalize returns the share of the participant once it dealt its contribution and received the contributions
+// of all the other participants.
+func (p *JointParticipant) Finalize() (Share, error) {
+	if !p.dealt {
+		return Share{}, errors.New("shamir: the contribution was not dealt")
+	}
+	for _, other := range p.config.Participants {
+		if other != p.index && !p.received[other] {
+			return Share{}, fmt.Errorf("shamir: the contribution of participant %d was not received", other)
+		}
+	}
+	return Share{
+		Threshold: p.config.Threshold,
+		Index:     p.index,
+		SplitID:   p.config.SplitID,
+		Payload:   append([]byte(nil), p.sum...),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}, nil
+}
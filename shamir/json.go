@@ -0,0 +1,98 @@
+package shamir
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Shares are encoded in JSON as an object with explicit fields, the payload being base64 encoded:
+//
+// 	{
+// 		"version": 1,
+// 		"index": 42,
+// 		"threshold": 3,
+// 		"splitId": "3bd9d9d0-5c0e-4667-a842-54efae534ebd",
+// 		"createdAt": "2020-05-01T10:00:00Z",
+// 		"label": "vault A",
+// 		"payload": "aGVsbG8gd29ybGQ=",
+// 		"signature": "...",
+// 		"padded": true,
+// 		"polynomial": 285,
+// 		"policy": {"notBefore": "2030-01-01T00:00:00Z", "approvers": ["notary"]},
+// 		"role": "legal"
+// 	}
+//
+// createdAt, label, signature, padded, polynomial, policy and role are omitted when unset. The version follows the version of the binary format.
+
+// jsonShare is the JSON representation of a share.
+type jsonShare struct {
+	Version    uint8      `json:"version"`
+	Index      uint8      `json:"index"`
+	Threshold  uint8      `json:"threshold"`
+	SplitID    SplitID    `json:"splitId"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	Label      string     `json:"label,omitempty"`
+	Payload    []byte     `json:"payload"`
+	Signature  []byte     `json:"signature,omitempty"`
+	Padded     bool       `json:"padded,omitempty"`
+	Polynomial uint16     `json:"polynomial,omitempty"`
+	Policy     *Policy    `json:"policy,omitempty"`
+	Role       string     `json:"role,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (s Share) MarshalJSON() ([]byte, error) {
+	encoded := jsonShare{
+		Version:    Version,
+		Index:      s.Index,
+		Threshold:  s.Threshold,
+		SplitID:    s.SplitID,
+		Label:      s.Label,
+		Payload:    s.Payload,
+		Signature:  s.Signature,
+		Padded:     s.Padded,
+		Polynomial: s.Polynomial,
+		Role:       s.Role,
+	}
+	if !s.CreatedAt.IsZero() {
+		encoded.CreatedAt = &s.CreatedAt
+	}
+	if !s.Policy.IsZero() {
+		encoded.Policy = &s.Policy
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *Share) UnmarshalJSON(data []byte) error {
+	var decoded jsonShare
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version != Version {
+		return ErrUnsupportedVersion
+	}
+	if len(decoded.Payload) < minSecretLength {
+		return errors.New("shamir: the payload of the share cannot be empty")
+	}
+
+	*s = Share{
+		Threshold:  decoded.Threshold,
+		Index:      decoded.Index,
+		SplitID:    decoded.SplitID,
+		Payload:    decoded.Payload,
+		Label:      decoded.Label,
+		Signature:  decoded.Signature,
+		Padded:     decoded.Padded,
+		Polynomial: decoded.Polynomial,
+		Role:       decoded.Role,
+	}
+	if decoded.CreatedAt != nil {
+		s.CreatedAt = *decoded.CreatedAt
+	}
+	if decoded.Policy != nil {
+		s.Policy = *decoded.Policy
+	}
+	return nil
+}
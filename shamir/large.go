@@ -0,0 +1,82 @@
+package shamir
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// Large secrets should not be split directly: every share would be as large as the secret. Instead, SealLarge
+// encrypts the secret with a fresh AES-256-GCM key, and only splits the key. The ciphertext can then be stored
+// anywhere, e.g. alongside every share, since it is useless without the key:
+//
+// 	nonce (12 bytes) || AES-256-GCM(key, nonce, secret, split identifier)
+//
+// The split identifier of the key shares is authenticated along with the secret, which ties the ciphertext
+// to its shares.
+
+const largeKeySize = 32
+
+// ErrDecryption is returned when a ciphertext cannot be decrypted with the key recovered from the shares.
+var ErrDecryption = errors.New("shamir: the ciphertext cannot be decrypted with the recovered key")
+
+// SealLarge encrypts a secret of any size with a fresh key, and splits the key into n shares such that
+// threshold shares are required to decrypt the ciphertext with OpenLarge.
+func SealLarge(secret []byte, n, threshold uint8) ([]byte, []Share, error) {
+	key := make([]byte, largeKeySize)
+	defer Zeroize(key)
+	if err := random.ReadFull(entropy(), key); err != nil {
+		return nil, nil, err
+	}
+	aead, err := newLargeAEAD(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	shares, err := Split(key, n, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := random.Bytes(aead.NonceSize())
+	return aead.Seal(nonce, nonce, secret, shares[0].SplitID[:]), shares, nil
+}
+
+// OpenLarge recovers the key from the shares and decrypts a ciphertext produced by SealLarge.
+func OpenLarge(ciphertext []byte, shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("shamir: no share provided")
+	}
+	key, err := Recover(shares)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(key)
+	if len(key) != largeKeySize {
+		return nil, errors.New("shamir: the shares do not hold an encryption key")
+	}
+	aead, err := newLargeAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidFormat
+	}
+	nonce := ciphertext[:aead.NonceSize()]
+	secret, err := aead.Open(nil, nonce, ciphertext[aead.NonceSize():], shares[0].SplitID[:])
+	if err != nil {
+		return nil, ErrDecryption
+	}
+	return secret, nil
+}
+
+// newLargeAEAD returns the AES-256-GCM cipher used by SealLarge.
+func newLargeAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
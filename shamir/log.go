@@ -0,0 +1,28 @@
+package shamir
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// The package never writes to the global logger nor exits the process: invalid parameters and shares are
+// reported as errors. Callers wanting diagnostic traces of the splits and recoveries set a logger with SetLogger.
+// The traces are logged at the debug level and only hold the parameters of the operations (split identifier,
+// number of shares, threshold and options) and the errors, never the secrets, the payloads of the shares nor the
+// randomness.
+
+var logger atomic.Pointer[slog.Logger]
+
+// SetLogger sets the logger receiving the diagnostic traces of the package, or disables the traces if l is nil,
+// which is the default.
+func SetLogger(l *slog.Logger) {
+	logger.Store(l)
+}
+
+// trace logs a diagnostic trace, if a logger is set. args must not hold sensitive data.
+func trace(msg string, args ...any) {
+	if l := logger.Load(); l != nil {
+		l.Log(context.Background(), slog.LevelDebug, "shamir: "+msg, args...)
+	}
+}
@@ -0,0 +1,185 @@
+package shamir
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// A manifest can be emitted alongside the shares of a split, and presented during recovery ceremonies to check
+// the shares before combining them. It is encoded in JSON:
+//
+// 	{
+// 		"version": 2,
+// 		"splitId": "3bd9d9d0-5c0e-4667-a842-54efae534ebd",
+// 		"shares": 5,
+// 		"threshold": 3,
+// 		"createdAt": "2020-05-01T10:00:00Z",
+// 		"salt": "2Fz0UM8OQp8q7yW3qPKIbmC0dLzB5S1Uc3e5y1hzyqE=",
+// 		"commitment": "X7pQ0SKfTa3eSQ2Mnt5uOqkh5BvGg6Rzp5sEc3eYoXw=",
+// 		"fingerprints": {"1": "c4f5f351", "2": "f65bfda5", ...},
+// 		"signature": "..."
+// 	}
+//
+// The commitment is derived from the secret and a random salt using Argon2id, so that the recovered secret can be
+// checked against it (see Commit). The salt is published along with the commitment, so that anyone holding the
+// manifest can test guesses of the secret: the cost of Argon2id makes this impractical for keys and other random
+// secrets, but not for low-entropy secrets such as passwords or PINs, whose manifest must be kept as private as
+// the secret. Fingerprints are the share fingerprints (see Share.Fingerprint) by share index. The signature of
+// the dealer is omitted when unset.
+
+const (
+	manifestVersion  = 2
+	manifestSaltSize = 32
+	commitmentDomain = "shamir-sss secret commitment"
+	commitmentSize   = 32
+)
+
+// The Argon2id parameters of the commitments are fixed, as recommended by RFC 9106 for memory-constrained
+// environments, so that a crafted manifest cannot make its verification arbitrarily expensive.
+const (
+	commitmentTime    = 3
+	commitmentMemory  = 64 << 10
+	commitmentThreads = 4
+)
+
+// ErrManifestMismatch is returned when a share or a secret does not match the manifest of the split.
+var ErrManifestMismatch = errors.New("shamir: mismatch with the split manifest")
+
+// ErrCommitmentMismatch is returned when a recovered secret does not match its commitment.
+var ErrCommitmentMismatch = errors.New("shamir: the secret does not match the commitment")
+
+// Manifest describes a split: its parameters, a commitment to the secret, and the fingerprints of the shares.
+type Manifest struct {
+	Version      int              `json:"version"`
+	SplitID      SplitID          `json:"splitId"`
+	Shares       int              `json:"shares"`
+	Threshold    uint8            `json:"threshold"`
+	CreatedAt    time.Time        `json:"createdAt"`
+	Salt         []byte           `json:"salt"`
+	Commitment   []byte           `json:"commitment"`
+	Fingerprints map[uint8]string `json:"fingerprints"`
+	// Signature is the Ed25519 signature of the manifest by the dealer (see Manifest.Sign), if any.
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// NewManifest builds the manifest of a split from the secret and all of its shares.
+func NewManifest(secret []byte, shares []Share) (*Manifest, error) {
+	if _, err := SetFingerprint(shares); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Version:      manifestVersion,
+		SplitID:      shares[0].SplitID,
+		Shares:       len(shares),
+		Threshold:    shares[0].Threshold,
+		CreatedAt:    shares[0].CreatedAt,
+		Fingerprints: make(map[uint8]string, len(shares)),
+	}
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	}
+	m.Commitment, m.Salt = Commit(secret)
+	for _, share := range shares {
+		if _, ok := m.Fingerprints[share.Index]; ok {
+			return nil, fmt.Errorf("shamir: duplicate share index %d", share.Index)
+		}
+		m.Fingerprints[share.Index] = share.Fingerprint().String()
+	}
+	return m, nil
+}
+
+// ParseManifest decodes a manifest encoded in JSON.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.Version != manifestVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if len(m.Salt) != manifestSaltSize || len(m.Commitment) != commitmentSize || len(m.Fingerprints) != m.Shares {
+		return nil, errors.New("shamir: invalid manifest")
+	}
+	return &m, nil
+}
+
+// Validate checks that the shares presented during a recovery ceremony were dealt by the split of the manifest
+// and were not altered. The error wraps ErrManifestMismatch if a share does not match.
+func (m *Manifest) Validate(shares []Share) error {
+	for _, share := range shares {
+		if share.SplitID != m.SplitID || share.Threshold != m.Threshold {
+			return fmt.Errorf("share %d: %w: the share belongs to another split", share.Index, ErrManifestMismatch)
+		}
+		fingerprint, ok := m.Fingerprints[share.Index]
+		if !ok {
+			return fmt.Errorf("share %d: %w: unknown share index", share.Index, ErrManifestMismatch)
+		}
+		if share.Fingerprint().String() != fingerprint {
+			return fmt.Errorf("share %d: %w: invalid fingerprint", share.Index, ErrManifestMismatch)
+		}
+	}
+	return nil
+}
+
+// VerifySecret checks that a recovered secret matches the commitment of the manifest.
+func (m *Manifest) VerifySecret(secret []byte) error {
+	if subtle.ConstantTimeCompare(commit(secret, m.Salt), m.Commitment) != 1 {
+		return fmt.Errorf("%w: the recovered secret does not match the commitment", ErrManifestMismatch)
+	}
+	return nil
+}
+
+// Commit commits to a secret: the commitment is derived from the secret and a random salt, the opening, using
+// Argon2id. Both are published when splitting the secret (NewManifest stores them as the commitment and the salt
+// of the manifest), so that observers of a recovery ceremony can check that the recovered secret is the one which
+// was split using VerifyRecovered. The commitment hides random secrets only: a low-entropy secret can be found
+// from its commitment and opening by trying its likely values, so that they must not be published.
+func Commit(secret []byte) (commitment, opening []byte) {
+	opening = random.Bytes(manifestSaltSize)
+	return commit(secret, opening), opening
+}
+
+// VerifyRecovered checks that a recovered secret matches a commitment made by Commit. It returns
+// ErrCommitmentMismatch if it does not.
+func VerifyRecovered(secret, commitment, opening []byte) error {
+	if subtle.ConstantTimeCompare(commit(secret, opening), commitment) != 1 {
+		return ErrCommitmentMismatch
+	}
+	return nil
+}
+
+// commit computes the commitment to a secret using the opening as salt.
+func commit(secret, opening []byte) []byte {
+	salt := append([]byte(commitmentDomain), opening...)
+	return argon2.IDKey(secret, salt, commitmentTime, commitmentMemory, commitmentThreads, commitmentSize)
+}
+
+// Recover validates the shares against the manifest, recovers the secret and checks it against the commitment.
+func (m *Manifest) Recover(shares []Share, options ...RecoverOption) ([]byte, error) {
+	return m.RecoverContext(context.Background(), shares, options...)
+}
+
+// RecoverContext recovers the secret the same way Recover does, stopping with the error of ctx once ctx is done
+// (see RecoverContext).
+func (m *Manifest) RecoverContext(ctx context.Context, shares []Share, options ...RecoverOption) ([]byte, error) {
+	if err := m.Validate(shares); err != nil {
+		return nil, err
+	}
+	secret, err := RecoverContext(ctx, shares, options...)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.VerifySecret(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
@@ -0,0 +1,25 @@
+package shamir
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestManifestCommitment(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManifest(secret, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.VerifySecret(secret); err != nil {
+		t.Errorf("VerifySecret() = %v", err)
+	}
+	if err := m.VerifySecret([]byte("incorrect horse battery staple")); !errors.Is(err, ErrManifestMismatch) {
+		t.Errorf("VerifySecret() = %v, want ErrManifestMismatch", err)
+	}
+
+}
@@ -0,0 +1,89 @@
+package shamir
+
+import (
+	"context"
+	"errors"
+)
+
+// Zeroizing the secrets (see Zeroize) does not prevent the operating system from swapping them to disk while they
+// are in use. A LockedBuffer is allocated outside of the Go heap, in pages locked into memory (mlock on Linux and
+// macOS, VirtualLock on Windows) so that they are never swapped, and surrounded by inaccessible guard pages so that
+// a read or write overflowing a neighbouring buffer faults rather than reaching the secret. The garbage collector
+// never moves nor copies the buffer, which is wiped and released by Destroy.
+//
+// With WithLockedMemory, the (padded) secret and the coefficients of the polynomials are kept in locked memory
+// while the secret is split, and RecoverLocked recovers a secret into locked memory. Shares are not secret on
+// their own, and are not locked.
+//
+// The amount of memory a process can lock is limited, e.g. by RLIMIT_MEMLOCK on Linux, which makes locked memory
+// only suitable for small secrets such as keys. Locked memory is not supported on other platforms, where
+// NewLockedBuffer fails.
+
+// LockedBuffer is a buffer kept in locked memory. It must be released with Destroy.
+type LockedBuffer struct {
+	region []byte // the pages allocated, guard pages included
+	pages  []byte // the pages locked
+	data   []byte
+}
+
+// NewLockedBuffer allocates a buffer of length bytes in locked memory, filled with zeros.
+func NewLockedBuffer(length int) (*LockedBuffer, error) {
+	if length < 0 {
+		return nil, errors.New("shamir: the length of a buffer cannot be negative")
+	}
+	region, pages, err := lockMemory(length)
+	if err != nil {
+		return nil, err
+	}
+	return &LockedBuffer{region: region, pages: pages, data: pages[:length:length]}, nil
+}
+
+// Bytes returns the content of the buffer, which must not be used once the buffer is destroyed.
+func (b *LockedBuffer) Bytes() []byte {
+	return b.data
+}
+
+// Destroy wipes the buffer, unlocks and releases its memory. It does nothing if the buffer is already destroyed.
+func (b *LockedBuffer) Destroy() error {
+	if b.region == nil {
+		return nil
+	}
+	Zeroize(b.pages)
+	err := unlockMemory(b.region, b.pages)
+	b.region, b.pages, b.data = nil, nil, nil
+	return err
+}
+
+// WithLockedMemory keeps the secret, once padded, and the coefficients of the polynomials in locked memory while
+// the secret is split (see LockedBuffer). Split fails if the memory cannot be locked. Split16 does not support it.
+func WithLockedMemory() SplitOption {
+	return func(c *splitConfig) {
+		c.locked = true
+	}
+}
+
+// SplitLocked splits a secret held in locked memory the same way Split does, keeping the values derived from the
+// secret in locked memory (see WithLockedMemory).
+func SplitLocked(secret *LockedBuffer, n, threshold uint8, options ...SplitOption) ([]Share, error) {
+	return Split(secret.Bytes(), n, threshold, append(options, WithLockedMemory())...)
+}
+
+// RecoverLocked recovers a secret the same way Recover does, into locked memory. The caller must Destroy the
+// buffer holding the secret once done with it.
+func RecoverLocked(shares []Share, options ...RecoverOption) (*LockedBuffer, error) {
+	var length int
+	if len(shares) > 0 {
+		length = len(shares[0].Payload)
+	}
+	buffer, err := NewLockedBuffer(length)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := recoverInto(context.Background(), buffer.Bytes(), shares, options...)
+	if err != nil {
+		return nil, errors.Join(err, buffer.Destroy())
+	}
+	// the padding, if any, is removed: the secret is a prefix of the buffer
+	buffer.data = secret
+	return buffer, nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !windows
+
+package shamir
+
+import "errors"
+
+// lockMemory fails, as locked memory is not supported on this platform.
+func lockMemory(length int) (region, pages []byte, err error) {
+	return nil, nil, errors.New("shamir: locked memory is not supported on this platform")
+}
+
+// unlockMemory is never called, as lockMemory always fails.
+func unlockMemory(region, pages []byte) error {
+	return nil
+}
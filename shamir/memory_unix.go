@@ -0,0 +1,47 @@
+//go:build linux || darwin
+
+package shamir
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockMemory maps length bytes, rounded up to whole pages, between two guard pages, and locks them into memory.
+// It returns the whole mapping and the pages locked.
+func lockMemory(length int) (region, pages []byte, err error) {
+	page := os.Getpagesize()
+	size := max((length+page-1)/page*page, page)
+	region, err = syscall.Mmap(-1, 0, size+2*page, syscall.PROT_READ|syscall.PROT_WRITE,
+		syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, nil, fmt.Errorf("shamir: cannot allocate locked memory: %w", err)
+	}
+	pages = region[page : page+size : page+size]
+	if err := syscall.Mprotect(region[:page], syscall.PROT_NONE); err != nil {
+		syscall.Munmap(region)
+		return nil, nil, fmt.Errorf("shamir: cannot protect the guard pages: %w", err)
+	}
+	if err := syscall.Mprotect(region[page+size:], syscall.PROT_NONE); err != nil {
+		syscall.Munmap(region)
+		return nil, nil, fmt.Errorf("shamir: cannot protect the guard pages: %w", err)
+	}
+	if err := syscall.Mlock(pages); err != nil {
+		syscall.Munmap(region)
+		return nil, nil, fmt.Errorf("shamir: cannot lock memory (see RLIMIT_MEMLOCK): %w", err)
+	}
+	return region, pages, nil
+}
+
+// unlockMemory unlocks the pages and unmaps the region mapped by lockMemory.
+func unlockMemory(region, pages []byte) error {
+	if err := syscall.Munlock(pages); err != nil {
+		syscall.Munmap(region)
+		return fmt.Errorf("shamir: cannot unlock memory: %w", err)
+	}
+	if err := syscall.Munmap(region); err != nil {
+		return fmt.Errorf("shamir: cannot release locked memory: %w", err)
+	}
+	return nil
+}
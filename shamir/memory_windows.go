@@ -0,0 +1,80 @@
+//go:build windows
+
+package shamir
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	memCommit     = 0x1000
+	memReserve    = 0x2000
+	memRelease    = 0x8000
+	pageNoAccess  = 0x01
+	pageReadWrite = 0x04
+)
+
+var (
+	kernel32       = syscall.NewLazyDLL("kernel32.dll")
+	virtualAlloc   = kernel32.NewProc("VirtualAlloc")
+	virtualProtect = kernel32.NewProc("VirtualProtect")
+	virtualFree    = kernel32.NewProc("VirtualFree")
+)
+
+// lockMemory allocates length bytes, rounded up to whole pages, between two guard pages, and locks them into
+// memory. It returns the whole allocation and the pages locked.
+func lockMemory(length int) (region, pages []byte, err error) {
+	page := os.Getpagesize()
+	size := max((length+page-1)/page*page, page)
+	address, _, err := virtualAlloc.Call(0, uintptr(size+2*page), memCommit|memReserve, pageReadWrite)
+	if address == 0 {
+		return nil, nil, fmt.Errorf("shamir: cannot allocate locked memory: %w", err)
+	}
+	region = unsafe.Slice((*byte)(*(*unsafe.Pointer)(unsafe.Pointer(&address))), size+2*page)
+	pages = region[page : page+size : page+size]
+	if err := protect(region[:page]); err != nil {
+		release(region)
+		return nil, nil, err
+	}
+	if err := protect(region[page+size:]); err != nil {
+		release(region)
+		return nil, nil, err
+	}
+	if err := syscall.VirtualLock(uintptr(unsafe.Pointer(&pages[0])), uintptr(size)); err != nil {
+		release(region)
+		return nil, nil, fmt.Errorf("shamir: cannot lock memory: %w", err)
+	}
+	return region, pages, nil
+}
+
+// unlockMemory unlocks the pages and releases the region allocated by lockMemory.
+func unlockMemory(region, pages []byte) error {
+	if err := syscall.VirtualUnlock(uintptr(unsafe.Pointer(&pages[0])), uintptr(len(pages))); err != nil {
+		release(region)
+		return fmt.Errorf("shamir: cannot unlock memory: %w", err)
+	}
+	return release(region)
+}
+
+// protect makes a guard page inaccessible.
+func protect(guard []byte) error {
+	var previous uint32
+	ok, _, err := virtualProtect.Call(uintptr(unsafe.Pointer(&guard[0])), uintptr(len(guard)), pageNoAccess,
+		uintptr(unsafe.Pointer(&previous)))
+	if ok == 0 {
+		return fmt.Errorf("shamir: cannot protect the guard pages: %w", err)
+	}
+	return nil
+}
+
+// release releases a region allocated by lockMemory.
+func release(region []byte) error {
+	ok, _, err := virtualFree.Call(uintptr(unsafe.Pointer(&region[0])), 0, memRelease)
+	if ok == 0 {
+		return fmt.Errorf("shamir: cannot release locked memory: %w", err)
+	}
+	return nil
+}
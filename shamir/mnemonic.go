@@ -0,0 +1,339 @@
+package shamir
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/wordlist"
+)
+
+// Shares can be encoded as a sequence of words, which is practical to dictate over the phone or to write down
+// on paper. Every word encodes 11 bits, using a wordlist of 2048 words: the English BIP-39 wordlist by default, or
+// any of the other BIP-39 wordlists (see WithLanguage) so that custodians can transcribe shares in their language.
+//
+// The words encode the format version, the threshold, the share index, the payload length (modulo 256), the
+// split identifier and the payload, followed by a checksum made of the first 4 bytes of their SHA-256 hash.
+// The last word is padded with zero bits. The creation time and the label of the share are not encoded, and
+// shares split using a custom reduction polynomial (see WithPolynomial), from a padded secret (see WithPadding),
+// with a release policy (see WithReleasePolicy) or with roles (see WithRoles) cannot be encoded.
+//
+// Words are compared after NFKD normalization, and the words of a Japanese mnemonic are separated by
+// ideographic spaces. When a word is not part of the wordlist, DecodeMnemonic reports its position along with
+// the closest words of the wordlist, and CorrectMnemonic fixes a single mistyped word using the checksum.
+
+const (
+	// mnemonicHeaderLength is the length of the version, threshold, index, payload length and split identifier
+	mnemonicHeaderLength = 4 + len(SplitID{})
+	mnemonicChecksumSize = 4
+	wordBits             = 11
+	// maxSuggestions is the maximum number of words suggested for an unknown word
+	maxSuggestions = 3
+	// mnemonicMaxErrors is the maximum number of mistyped words CorrectMnemonic fixes
+	mnemonicMaxErrors = 1
+)
+
+// MnemonicOption configures the encoding of shares as words.
+type MnemonicOption func(*mnemonicConfig)
+
+type mnemonicConfig struct {
+	words []string
+}
+
+// WithWordlist sets the wordlist used to encode shares as words. The wordlist must be made of 2048 distinct
+// words, see the wordlist package.
+func WithWordlist(words []string) MnemonicOption {
+	return func(c *mnemonicConfig) {
+		c.words = words
+	}
+}
+
+// WithLanguage sets the BIP-39 wordlist used to encode shares as words by the name of its language,
+// as listed in wordlist.Languages (e.g. "japanese" or "spanish").
+func WithLanguage(language string) MnemonicOption {
+	return func(c *mnemonicConfig) {
+		c.words = wordlist.Languages[language]
+	}
+}
+
+func newMnemonicConfig(options []MnemonicOption) (*mnemonicConfig, error) {
+	c := &mnemonicConfig{words: wordlist.English}
+	for _, option := range options {
+		option(c)
+	}
+	if len(c.words) != wordlist.Size || len(wordlist.Index(c.words)) != wordlist.Size {
+		return nil, fmt.Errorf("shamir: a wordlist must be made of %d distinct words", wordlist.Size)
+	}
+	return c, nil
+}
+
+// WordError describes a word which is not part of the wordlist.
+// Position is the position of the word in the mnemonic, starting from 1, and Suggestions holds the closest
+// words of the wordlist, if any.
+type WordError struct {
+	Position    int
+	Word        string
+	Suggestions []string
+}
+
+func (e WordError) String() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown word %q at position %d", e.Word, e.Position)
+	}
+	return fmt.Sprintf("unknown word %q at position %d (did you mean %s?)", e.Word, e.Position,
+		strings.Join(e.Suggestions, ", "))
+}
+
+// MnemonicError is returned when decoding a mnemonic containing words which are not part of the wordlist.
+type MnemonicError struct {
+	Words []WordError
+}
+
+func (e *MnemonicError) Error() string {
+	descriptions := make([]string, len(e.Words))
+	for i, word := range e.Words {
+		descriptions[i] = word.String()
+	}
+	return "shamir: " + strings.Join(descriptions, "; ")
+}
+
+// EncodeMnemonic encodes a share as a sequence of words.
+func EncodeMnemonic(share Share, options ...MnemonicOption) (string, error) {
+	c, err := newMnemonicConfig(options)
+	if err != nil {
+		return "", err
+	}
+	if len(share.Payload) < minSecretLength {
+		return "", errors.New("shamir: the share has no payload")
+	}
+	if share.Polynomial != 0 {
+		return "", errors.New("shamir: shares using a custom reduction polynomial cannot be encoded as words")
+	}
+	if share.Padded {
+		return "", errors.New("shamir: shares of a padded secret cannot be encoded as words")
+	}
+	if !share.Policy.IsZero() {
+		return "", errors.New("shamir: shares with a release policy cannot be encoded as words")
+	}
+	if share.Role != "" {
+		return "", errors.New("shamir: shares with a role cannot be encoded as words")
+	}
+
+	data := make([]byte, 0, mnemonicHeaderLength+len(share.Payload)+mnemonicChecksumSize)
+	data = append(data, Version, share.Threshold, share.Index, byte(len(share.Payload)))
+	data = append(data, share.SplitID[:]...)
+	data = append(data, share.Payload...)
+	checksum := sha256.Sum256(data)
+	data = append(data, checksum[:mnemonicChecksumSize]...)
+
+	words := make([]string, (len(data)*8+wordBits-1)/wordBits)
+	for i := range words {
+		words[i] = c.words[readWord(data, i)]
+	}
+	return strings.Join(words, wordlist.Separator(c.words)), nil
+}
+
+// DecodeMnemonic decodes a share encoded with EncodeMnemonic, using the same wordlist.
+// If some words are not part of the wordlist, a *MnemonicError is returned. If the checksum does not match,
+// ErrChecksum is returned.
+func DecodeMnemonic(mnemonic string, options ...MnemonicOption) (Share, error) {
+	c, err := newMnemonicConfig(options)
+	if err != nil {
+		return Share{}, err
+	}
+	values, unknown := c.values(mnemonic)
+	if len(unknown) > 0 {
+		return Share{}, &MnemonicError{Words: unknown}
+	}
+	return decodeWords(values)
+}
+
+// CorrectMnemonic decodes a share encoded with EncodeMnemonic like DecodeMnemonic, but fixes a mistyped or
+// unknown word when a single word of the wordlist makes the checksum match. It returns the positions of the
+// corrected words, starting from 1, which are worth checking against the original: a mnemonic holding more
+// errors may be corrected into another valid share. If the share cannot be corrected, the error returned by
+// DecodeMnemonic is returned.
+//
+// Every word of the wordlist is tried at every position, so that correcting the words of long shares takes a while.
+func CorrectMnemonic(mnemonic string, options ...MnemonicOption) (Share, []int, error) {
+	c, err := newMnemonicConfig(options)
+	if err != nil {
+		return Share{}, nil, err
+	}
+	values, unknown := c.values(mnemonic)
+	var positions []int
+	if len(unknown) > 0 {
+		err = &MnemonicError{Words: unknown}
+		if len(unknown) > mnemonicMaxErrors {
+			return Share{}, nil, err
+		}
+		// the unknown word is the mistyped one
+		positions = []int{unknown[0].Position - 1}
+	} else {
+		var share Share
+		if share, err = decodeWords(values); err == nil || (err != ErrChecksum && err != ErrInvalidFormat) {
+			return share, nil, err
+		}
+		positions = make([]int, len(values))
+		for i := range positions {
+			positions[i] = i
+		}
+	}
+
+	var share Share
+	var corrected []int
+	for _, position := range positions {
+		original := values[position]
+		for value := 0; value < wordlist.Size; value++ {
+			if value == original && len(unknown) == 0 {
+				continue
+			}
+			values[position] = value
+			if candidate, err := decodeWords(values); err == nil {
+				share = candidate
+				corrected = append(corrected, position+1)
+			}
+		}
+		values[position] = original
+	}
+	if len(corrected) != 1 {
+		return Share{}, nil, err
+	}
+	return share, corrected, nil
+}
+
+// values looks up the words of a mnemonic in the wordlist. The value of an unknown word is 0.
+func (c *mnemonicConfig) values(mnemonic string) ([]int, []WordError) {
+	index := wordlist.Index(c.words)
+	words := strings.Fields(wordlist.Normalize(mnemonic))
+	values := make([]int, len(words))
+	var unknown []WordError
+	for i, word := range words {
+		value, ok := index[word]
+		if !ok {
+			unknown = append(unknown, WordError{Position: i + 1, Word: word, Suggestions: suggestWords(word, c.words)})
+		}
+		values[i] = value
+	}
+	return values, unknown
+}
+
+// decodeWords decodes a share from the values of its words.
+func decodeWords(values []int) (Share, error) {
+	data := make([]byte, (len(values)*wordBits+7)/8)
+	for i, value := range values {
+		writeWord(data, i, value)
+	}
+	// the padding of the last word may be longer than a byte: the payload length tells the two cases apart
+	length := len(values) * wordBits / 8
+	if length < mnemonicHeaderLength+minSecretLength+mnemonicChecksumSize {
+		return Share{}, ErrInvalidFormat
+	}
+	if byte(length-mnemonicHeaderLength-mnemonicChecksumSize) != data[3] {
+		length--
+	}
+	for i := length * 8; i < len(values)*wordBits; i++ {
+		if data[i/8]>>(7-i%8)&1 != 0 {
+			return Share{}, ErrInvalidFormat
+		}
+	}
+	if length < mnemonicHeaderLength+minSecretLength+mnemonicChecksumSize ||
+		byte(length-mnemonicHeaderLength-mnemonicChecksumSize) != data[3] {
+		return Share{}, ErrInvalidFormat
+	}
+
+	data = data[:length]
+	checksum := sha256.Sum256(data[:length-mnemonicChecksumSize])
+	if string(checksum[:mnemonicChecksumSize]) != string(data[length-mnemonicChecksumSize:]) {
+		return Share{}, ErrChecksum
+	}
+	if data[0] != Version {
+		return Share{}, ErrUnsupportedVersion
+	}
+
+	share := Share{
+		Threshold: data[1],
+		Index:     data[2],
+		Payload:   data[mnemonicHeaderLength : length-mnemonicChecksumSize],
+	}
+	copy(share.SplitID[:], data[4:])
+	return share, nil
+}
+
+// readWord reads the i-th group of 11 bits of data (most significant bit first), padding data with zero bits.
+func readWord(data []byte, i int) int {
+	var value int
+	for bit := i * wordBits; bit < (i+1)*wordBits; bit++ {
+		value <<= 1
+		if bit/8 < len(data) {
+			value |= int(data[bit/8] >> (7 - bit%8) & 1)
+		}
+	}
+	return value
+}
+
+// writeWord writes value as the i-th group of 11 bits of data.
+func writeWord(data []byte, i, value int) {
+	for bit := 0; bit < wordBits; bit++ {
+		if value>>(wordBits-1-bit)&1 == 1 {
+			offset := i*wordBits + bit
+			data[offset/8] |= 1 << (7 - offset%8)
+		}
+	}
+}
+
+// suggestWords returns the words of the wordlist closest to an unknown word: words sharing its first 4 letters
+// (which identify a word of the BIP-39 wordlists), or else words within an edit distance of 2.
+func suggestWords(word string, words []string) []string {
+	type suggestion struct {
+		word     string
+		distance int
+	}
+
+	var suggestions []suggestion
+	for _, candidate := range words {
+		normalized := wordlist.Normalize(candidate)
+		distance := editDistance(word, normalized)
+		prefix := len([]rune(word)) >= 4 && strings.HasPrefix(normalized, string([]rune(word)[:4]))
+		if prefix {
+			// favor words sharing a prefix, since the BIP-39 words are identified by their first 4 letters
+			distance = 0
+		}
+		if distance <= 2 {
+			suggestions = append(suggestions, suggestion{candidate, distance})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	var result []string
+	for i := 0; i < len(suggestions) && i < maxSuggestions; i++ {
+		result = append(result, suggestions[i].word)
+	}
+	return result
+}
+
+// editDistance computes the Levenshtein distance between two words.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	previous := make([]int, len(t)+1)
+	current := make([]int, len(t)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		current[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(t)]
+}
@@ -0,0 +1,68 @@
+package shamir
+
+import (
+	"crypto/subtle"
+	"errors"
+	"math/bits"
+)
+
+// Shares are as long as the secret, which leaks its exact length. With WithPadding, the secret is padded before
+// being split, so that shares only leak a length bucket: the secret is followed by a 0x80 byte and zero bytes
+// (ISO/IEC 7816-4 padding), up to the length given by the Padmé scheme (Nikitin et al., "Reducing Metadata
+// Leakage from Encrypted Files and Communication with PURBs", 2019). Padmé leaks O(log log L) bits of the length
+// L, for an overhead of at most 12%.
+//
+// Padded shares are flagged (see Share.Padded), so that Recover removes the padding transparently. The flag is
+// kept by the binary, JSON, PEM, URI, CBOR and protobuf encodings, but not by the Bech32m and mnemonic encodings.
+
+// WithPadding pads the secret before splitting it, to hide its exact length.
+func WithPadding() SplitOption {
+	return func(c *splitConfig) {
+		c.padding = true
+	}
+}
+
+// padmeLength returns the padded length of a message of length l using the Padmé scheme.
+func padmeLength(l int) int {
+	if l < 2 {
+		return l
+	}
+	// e is floor(log2(l)), s the number of bits of e
+	e := bits.Len(uint(l)) - 1
+	s := bits.Len(uint(e))
+	mask := 1<<(e-s) - 1
+	return (l + mask) &^ mask
+}
+
+// pad pads a secret to the Padmé length of the secret followed by the 0x80 marker.
+func pad(secret []byte) []byte {
+	return padInto(nil, secret)
+}
+
+// padInto pads a secret the same way pad does, reusing the dst buffer when it is large enough. dst must not
+// overlap the secret.
+func padInto(dst, secret []byte) []byte {
+	padded := resize(dst, padmeLength(len(secret)+1))
+	copy(padded, secret)
+	padded[len(secret)] = 0x80
+	clear(padded[len(secret)+1:])
+	return padded
+}
+
+// unpad removes the padding added by pad. All the bytes are scanned without branching on their values, so that
+// the time taken does not leak the length of the secret nor its content.
+func unpad(padded []byte) ([]byte, error) {
+	// found is 1 once the marker is found scanning from the end, invalid is 1 if a byte other than 0 precedes it
+	var end, found, invalid int
+	for i := len(padded) - 1; i >= 0; i-- {
+		marker := subtle.ConstantTimeByteEq(padded[i], 0x80) & (found ^ 1)
+		zero := subtle.ConstantTimeByteEq(padded[i], 0)
+		invalid |= (found ^ 1) & (marker ^ 1) & (zero ^ 1)
+		end = subtle.ConstantTimeSelect(marker, i, end)
+		found |= marker
+	}
+	if found&(invalid^1) != 1 {
+		return nil, errors.New("shamir: invalid padding")
+	}
+	return padded[:end], nil
+}
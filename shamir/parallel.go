@@ -0,0 +1,86 @@
+package shamir
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Every byte of the secret is split and recovered independently, so that large secrets can be processed by
+// several goroutines, each handling a contiguous range of bytes. The coefficients of the polynomials are read
+// beforehand, in the same order whatever the number of goroutines, so that the shares do not depend on it. The
+// goroutines check the context of the operation between blocks of the secret (see SplitContext), so that long
+// operations stop soon after it is done.
+
+// minChunkLength is the minimum number of bytes of the secret handled by a goroutine, below which the cost of
+// starting the goroutine is not worth it.
+const minChunkLength = 64 << 10
+
+// blockLength is the number of bytes of the secret processed between two checks of the context.
+const blockLength = 64 << 10
+
+// WithParallelism splits the secret using up to n goroutines, or runtime.GOMAXPROCS(0) goroutines if n is 0.
+// It only speeds up secrets larger than a few hundred kilobytes.
+func WithParallelism(n int) SplitOption {
+	return func(c *splitConfig) {
+		c.workers = workers(n)
+	}
+}
+
+// WithParallelRecovery recovers the secret using up to n goroutines, or runtime.GOMAXPROCS(0) goroutines if n
+// is 0 (see WithParallelism).
+func WithParallelRecovery(n int) RecoverOption {
+	return func(c *recoverConfig) {
+		c.workers = workers(n)
+	}
+}
+
+// workers returns the number of goroutines requested, defaulting to runtime.GOMAXPROCS(0).
+func workers(n int) int {
+	if n <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// parallelize calls fn for contiguous ranges [start, end) covering [0, length), using up to workers goroutines,
+// waits for all the calls to return, and returns the first error in the order of the ranges.
+func parallelize(length, workers int, fn func(start, end int) error) error {
+	chunks := min(workers, length/minChunkLength)
+	if chunks <= 1 {
+		return fn(0, length)
+	}
+	errs := make([]error, chunks)
+	var wg sync.WaitGroup
+	for chunk := 0; chunk < chunks; chunk++ {
+		start, end := chunk*length/chunks, (chunk+1)*length/chunks
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[chunk] = fn(start, end)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forBlocks calls fn for contiguous blocks of at most blockLength bytes covering [start, end), in order, adding
+// every block processed to counter, and stops with the error of fn, or with the error of ctx once ctx is done.
+func forBlocks(ctx context.Context, counter *progressCounter, start, end int, fn func(start, end int) error) error {
+	for ; start < end; start += blockLength {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		blockEnd := min(start+blockLength, end)
+		if err := fn(start, blockEnd); err != nil {
+			return err
+		}
+		counter.add(blockEnd - start)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package shamir
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// parallelLengths are the lengths of the secrets split and recovered by the parallel benchmarks.
+var parallelLengths = []int{1 << 20, 4 << 20, 16 << 20, 64 << 20}
+
+// parallelSecret returns a secret of length bytes, large enough to be split by several goroutines.
+func parallelSecret(length int) []byte {
+	secret := make([]byte, length)
+	rand.NewChaCha8([32]byte{1}).Read(secret)
+	return secret
+}
+
+func TestParallelSplitMatchesSequential(t *testing.T) {
+	secret := parallelSecret(4 << 20)
+	x := []byte{1, 2, 3, 4, 5}
+	var payloads [][][]byte
+	for _, workers := range []int{1, 8} {
+		var c splitConfig
+		WithRandom(rand.NewChaCha8([32]byte{2}))(&c)
+		WithParallelism(workers)(&c)
+		shares := make([]Share, len(x))
+		if err := deal(context.Background(), &c, field256, shares, secret, 3, x, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		dealt := make([][]byte, len(shares))
+		for i, share := range shares {
+			dealt[i] = share.Payload
+		}
+		payloads = append(payloads, dealt)
+	}
+	for i := range x {
+		if !bytes.Equal(payloads[0][i], payloads[1][i]) {
+			t.Errorf("share %d differs between the sequential and parallel splits", x[i])
+		}
+	}
+}
+
+func TestParallelRecover(t *testing.T) {
+	secret := parallelSecret(4 << 20)
+	shares, err := Split(secret, 5, 3, WithParallelism(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sequential, err := Recover(shares[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	parallel, err := Recover(shares[2:], WithParallelRecovery(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sequential, secret) || !bytes.Equal(parallel, secret) {
+		t.Error("the recovered secret differs from the secret split")
+	}
+}
+
+func BenchmarkSplitParallel(b *testing.B) {
+	for _, length := range parallelLengths {
+		secret := parallelSecret(length)
+		for _, parallel := range []bool{false, true} {
+			var options []SplitOption
+			name := fmt.Sprintf("sequential/%dMiB", length>>20)
+			if parallel {
+				options = append(options, WithParallelism(0))
+				name = fmt.Sprintf("parallel/%dMiB", length>>20)
+			}
+			b.Run(name, func(b *testing.B) {
+				b.SetBytes(int64(length))
+				for b.Loop() {
+					if _, err := Split(secret, 5, 3, options...); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRecoverParallel(b *testing.B) {
+	for _, length := range parallelLengths {
+		shares, err := Split(parallelSecret(length), 5, 3, WithParallelism(0))
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, parallel := range []bool{false, true} {
+			var options []RecoverOption
+			name := fmt.Sprintf("sequential/%dMiB", length>>20)
+			if parallel {
+				options = append(options, WithParallelRecovery(0))
+				name = fmt.Sprintf("parallel/%dMiB", length>>20)
+			}
+			b.Run(name, func(b *testing.B) {
+				b.SetBytes(int64(length))
+				for b.Loop() {
+					if _, err := Recover(shares[:3], options...); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
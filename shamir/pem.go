@@ -0,0 +1,219 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shares can be exported as ASCII-armored blocks, so that they can be printed, emailed or stored in
+// text-only systems. The armor follows the PEM layout with an OpenPGP-style CRC-24 line (RFC 4880):
+//
+// 	-----BEGIN SHAMIR SHARE-----
+// 	Version: 1
+// 	Index: 42
+// 	Threshold: 3
+// 	Split-ID: 3bd9d9d0-5c0e-4667-a842-54efae534ebd
+// 	Created-At: 2020-05-01T10:00:00Z
+// 	Label: vault A
+// 	Signature: ...
+// 	Padded: true
+// 	Polynomial: 0x11d
+// 	Policy: ...
+// 	Role: legal
+//
+// 	aGVsbG8gd29ybGQ=
+// 	=sDy3
+// 	-----END SHAMIR SHARE-----
+//
+// The body holds the base64 encoded payload, and the CRC-24 line its checksum.
+// Created-At, Label, Signature (base64 encoded), Padded, Polynomial, Policy (the base64 encoded release policy,
+// in the layout of the binary format) and Role are omitted when unset.
+
+const (
+	pemBegin      = "-----BEGIN SHAMIR SHARE-----"
+	pemEnd        = "-----END SHAMIR SHARE-----"
+	pemLineLength = 64
+)
+
+// EncodePEM encodes a share as an ASCII-armored block.
+func EncodePEM(share Share) ([]byte, error) {
+	if strings.ContainsAny(share.Label, "\r\n") {
+		return nil, errors.New("shamir: the label of a share cannot contain line breaks")
+	}
+	if strings.ContainsAny(share.Role, "\r\n") {
+		return nil, errors.New("shamir: the role of a share cannot contain line breaks")
+	}
+
+	var b bytes.Buffer
+	b.WriteString(pemBegin + "\n")
+	fmt.Fprintf(&b, "Version: %d\n", Version)
+	fmt.Fprintf(&b, "Index: %d\n", share.Index)
+	fmt.Fprintf(&b, "Threshold: %d\n", share.Threshold)
+	fmt.Fprintf(&b, "Split-ID: %s\n", share.SplitID)
+	if !share.CreatedAt.IsZero() {
+		fmt.Fprintf(&b, "Created-At: %s\n", share.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	if share.Label != "" {
+		fmt.Fprintf(&b, "Label: %s\n", share.Label)
+	}
+	if share.Signature != nil {
+		fmt.Fprintf(&b, "Signature: %s\n", base64.StdEncoding.EncodeToString(share.Signature))
+	}
+	if share.Padded {
+		b.WriteString("Padded: true\n")
+	}
+	if share.Polynomial != 0 {
+		fmt.Fprintf(&b, "Polynomial: %#x\n", share.Polynomial)
+	}
+	if !share.Policy.IsZero() {
+		if err := share.Policy.validate(); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "Policy: %s\n", base64.StdEncoding.EncodeToString(share.Policy.appendBinary(nil)))
+	}
+	if share.Role != "" {
+		fmt.Fprintf(&b, "Role: %s\n", share.Role)
+	}
+	b.WriteString("\n")
+
+	body := base64.StdEncoding.EncodeToString(share.Payload)
+	for len(body) > pemLineLength {
+		b.WriteString(body[:pemLineLength] + "\n")
+		body = body[pemLineLength:]
+	}
+	b.WriteString(body + "\n")
+
+	checksum := crc24(share.Payload)
+	b.WriteString("=" + base64.StdEncoding.EncodeToString([]byte{byte(checksum >> 16), byte(checksum >> 8), byte(checksum)}) + "\n")
+	b.WriteString(pemEnd + "\n")
+	return b.Bytes(), nil
+}
+
+// DecodePEM decodes the first ASCII-armored share found in data, and returns the remaining data
+// following the block so that several shares can be read from the same input.
+func DecodePEM(data []byte) (Share, []byte, error) {
+	start := bytes.Index(data, []byte(pemBegin))
+	if start < 0 {
+		return Share{}, data, errors.New("shamir: no armored share found")
+	}
+	end := bytes.Index(data[start:], []byte(pemEnd))
+	if end < 0 {
+		return Share{}, data, errors.New("shamir: unterminated armored share")
+	}
+	rest := data[start+end+len(pemEnd):]
+	lines := strings.Split(string(data[start+len(pemBegin):start+end]), "\n")
+
+	headers := make(map[string]string)
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			if len(headers) > 0 {
+				break
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return Share{}, rest, errors.New("shamir: invalid armor header")
+		}
+		headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	var body, checksumLine string
+	for _, line := range lines[i:] {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "=") {
+			checksumLine = line[1:]
+		} else {
+			body += line
+		}
+	}
+
+	share, err := parsePEMHeaders(headers)
+	if err != nil {
+		return Share{}, rest, err
+	}
+	share.Payload, err = base64.StdEncoding.DecodeString(body)
+	if err != nil || len(share.Payload) < minSecretLength {
+		return Share{}, rest, errors.New("shamir: invalid armored payload")
+	}
+	checksum, err := base64.StdEncoding.DecodeString(checksumLine)
+	if err != nil || len(checksum) != 3 {
+		return Share{}, rest, errors.New("shamir: missing or invalid armor checksum")
+	}
+	if uint32(checksum[0])<<16|uint32(checksum[1])<<8|uint32(checksum[2]) != crc24(share.Payload) {
+		return Share{}, rest, ErrChecksum
+	}
+	return share, rest, nil
+}
+
+// parsePEMHeaders parses the metadata of an armored share.
+func parsePEMHeaders(headers map[string]string) (Share, error) {
+	var share Share
+	if headers["Version"] != strconv.Itoa(int(Version)) {
+		return share, ErrUnsupportedVersion
+	}
+	index, err := strconv.ParseUint(headers["Index"], 10, 8)
+	if err != nil {
+		return share, errors.New("shamir: invalid Index armor header")
+	}
+	threshold, err := strconv.ParseUint(headers["Threshold"], 10, 8)
+	if err != nil {
+		return share, errors.New("shamir: invalid Threshold armor header")
+	}
+	share.Index, share.Threshold = uint8(index), uint8(threshold)
+	if share.SplitID, err = ParseSplitID(headers["Split-ID"]); err != nil {
+		return share, err
+	}
+	if created, ok := headers["Created-At"]; ok {
+		if share.CreatedAt, err = time.Parse(time.RFC3339, created); err != nil {
+			return share, errors.New("shamir: invalid Created-At armor header")
+		}
+	}
+	share.Label = headers["Label"]
+	share.Role = headers["Role"]
+	share.Padded = headers["Padded"] == "true"
+	if polynomial, ok := headers["Polynomial"]; ok {
+		value, err := strconv.ParseUint(polynomial, 0, 16)
+		if err != nil {
+			return share, errors.New("shamir: invalid Polynomial armor header")
+		}
+		share.Polynomial = uint16(value)
+	}
+	if signature, ok := headers["Signature"]; ok {
+		if share.Signature, err = base64.StdEncoding.DecodeString(signature); err != nil {
+			return share, errors.New("shamir: invalid Signature armor header")
+		}
+	}
+	if encoded, ok := headers["Policy"]; ok {
+		if share.Policy, err = decodePolicy(encoded); err != nil {
+			return share, errors.New("shamir: invalid Policy armor header")
+		}
+	}
+	return share, nil
+}
+
+// crc24 computes the CRC-24 checksum used by OpenPGP armor (RFC 4880, section 6.1).
+func crc24(data []byte) uint32 {
+	const (
+		init = 0xb704ce
+		poly = 0x1864cfb
+	)
+	crc := uint32(init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= poly
+			}
+		}
+	}
+	return crc & 0xffffff
+}
@@ -0,0 +1,214 @@
+package shamir
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Shares can carry a release policy, which recovery coordinators enforce on top of the threshold, to build dead
+// man's switches and inheritance workflows: a share must not be used before its NotBefore time, and its use
+// requires the approval of every one of its Approvers, e.g. a notary or the executor of a will. The policy is only
+// enforced by the coordinators which check it (see CheckPolicies and the ceremony package): it does not prevent
+// the holders of threshold shares from combining them by themselves. The signature of a signed share (see Sign)
+// covers its policy, so that it cannot be altered.
+//
+// Shares can also be given roles, e.g. "legal", "engineering" or "board" (see WithRoles), so that recovery can
+// require shares of several roles on top of the threshold, e.g. at least one share of every role (see
+// WithRoleQuorum and RoleQuorum.Check).
+//
+// The policy and the role are kept by the binary, JSON, PEM, URI, CBOR and protobuf encodings. The Bech32m and
+// mnemonic encodings have no room for them, and return an error for shares with a policy or a role.
+
+const (
+	// maxApprovers is the maximum number of approvers of a policy
+	maxApprovers = 255
+	// maxApproverLength is the maximum length in bytes of the identity of an approver
+	maxApproverLength = 255
+	// maxRoleLength is the maximum length in bytes of the role of a share
+	maxRoleLength = 255
+)
+
+var (
+	// ErrPolicy is returned when the release policy of a share is not satisfied.
+	ErrPolicy = errors.New("shamir: the release policy of the share is not satisfied")
+	// ErrRoleQuorum is returned when the shares presented for recovery do not hold enough shares of some role.
+	ErrRoleQuorum = errors.New("shamir: not enough shares of a role")
+)
+
+// Policy is the release policy of a share.
+type Policy struct {
+	// NotBefore is the time before which the share must not be used, or zero.
+	NotBefore time.Time `json:"notBefore,omitzero"`
+	// Approvers lists the identities whose approval is required to use the share.
+	Approvers []string `json:"approvers,omitempty"`
+}
+
+// IsZero reports whether the policy sets no constraint.
+func (p Policy) IsZero() bool {
+	return p.NotBefore.IsZero() && len(p.Approvers) == 0
+}
+
+// Check checks that the policy is satisfied at time now, given the identities which approved the use of the
+// share. The error wraps ErrPolicy.
+func (p Policy) Check(now time.Time, approvals []string) error {
+	if now.Before(p.NotBefore) {
+		return fmt.Errorf("%w: the share cannot be used before %s", ErrPolicy, p.NotBefore.Format(time.RFC3339))
+	}
+	for _, approver := range p.Approvers {
+		if !slices.Contains(approvals, approver) {
+			return fmt.Errorf("%w: the approval of %q is missing", ErrPolicy, approver)
+		}
+	}
+	return nil
+}
+
+// CheckPolicies checks the policies of the shares presented for recovery, see Policy.Check.
+func CheckPolicies(shares []Share, now time.Time, approvals []string) error {
+	for _, share := range shares {
+		if err := share.Policy.Check(now, approvals); err != nil {
+			return fmt.Errorf("share %d: %w", share.Index, err)
+		}
+	}
+	return nil
+}
+
+// WithReleasePolicy sets the release policy of the shares dealt.
+func WithReleasePolicy(policy Policy) SplitOption {
+	return func(c *splitConfig) {
+		c.policy = policy
+	}
+}
+
+// RoleQuorum is the minimum number of shares of every role required to recover the secret, by role.
+type RoleQuorum map[string]int
+
+// EveryRole requires at least one share of every one of the roles.
+func EveryRole(roles ...string) RoleQuorum {
+	q := make(RoleQuorum, len(roles))
+	for _, role := range roles {
+		q[role] = 1
+	}
+	return q
+}
+
+// Check checks that the shares hold the minimum number of shares of every role. The error wraps ErrRoleQuorum.
+func (q RoleQuorum) Check(shares []Share) error {
+	if missing := q.Missing(shares); len(missing) > 0 {
+		role := missing[0]
+		return fmt.Errorf("%w: at least %d shares of role %q are required, got %d", ErrRoleQuorum, q[role], role,
+			countRole(shares, role))
+	}
+	return nil
+}
+
+// Missing returns the roles, sorted, of which the shares hold less than the minimum number of shares.
+func (q RoleQuorum) Missing(shares []Share) []string {
+	var missing []string
+	for role, minimum := range q {
+		if countRole(shares, role) < minimum {
+			missing = append(missing, role)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// countRole counts the shares of a role.
+func countRole(shares []Share, role string) int {
+	var count int
+	for _, share := range shares {
+		if share.Role == role {
+			count++
+		}
+	}
+	return count
+}
+
+// WithRoles sets the roles of the shares dealt, one for every share in order. Several shares may have the same
+// role.
+func WithRoles(roles ...string) SplitOption {
+	return func(c *splitConfig) {
+		c.roles = roles
+	}
+}
+
+// WithRoleQuorum requires the shares presented for recovery to hold the minimum number of shares of every role of
+// the quorum. Recover returns an error wrapping ErrRoleQuorum otherwise.
+//
+// The role of a share is only trustworthy if the share is signed by the dealer and the recovery checks the
+// signature with WithDealerKey: otherwise, the holder of a share can change its role at will, e.g. to pass as a
+// member of a role whose shares are missing.
+func WithRoleQuorum(quorum RoleQuorum) RecoverOption {
+	return func(c *recoverConfig) {
+		c.roles = quorum
+	}
+}
+
+// validate checks that the policy can be encoded.
+func (p Policy) validate() error {
+	if len(p.Approvers) > maxApprovers {
+		return errors.New("shamir: a policy cannot have more than 255 approvers")
+	}
+	for _, approver := range p.Approvers {
+		if approver == "" || len(approver) > maxApproverLength {
+			return errors.New("shamir: the identity of an approver must be between 1 and 255 bytes long")
+		}
+	}
+	return nil
+}
+
+// appendBinary appends the binary encoding of the policy to data.
+func (p Policy) appendBinary(data []byte) []byte {
+	var notBefore int64
+	if !p.NotBefore.IsZero() {
+		notBefore = p.NotBefore.Unix()
+	}
+	data = binary.BigEndian.AppendUint64(data, uint64(notBefore))
+	data = append(data, uint8(len(p.Approvers)))
+	for _, approver := range p.Approvers {
+		data = append(data, uint8(len(approver)))
+		data = append(data, approver...)
+	}
+	return data
+}
+
+// decodePolicy decodes a policy encoded by appendBinary, then in base64 as by the armored shares.
+func decodePolicy(s string) (Policy, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return Policy{}, err
+	}
+	p, rest, err := parsePolicy(data)
+	if err != nil {
+		return Policy{}, err
+	}
+	if len(rest) > 0 {
+		return Policy{}, ErrInvalidFormat
+	}
+	return p, nil
+}
+
+// parsePolicy decodes a policy encoded by appendBinary at the start of data, and returns the remaining bytes.
+func parsePolicy(data []byte) (Policy, []byte, error) {
+	var p Policy
+	if len(data) < 9 {
+		return p, nil, ErrInvalidFormat
+	}
+	if seconds := int64(binary.BigEndian.Uint64(data)); seconds != 0 {
+		p.NotBefore = time.Unix(seconds, 0).UTC()
+	}
+	count := int(data[8])
+	data = data[9:]
+	for range count {
+		if len(data) < 1 || data[0] == 0 || len(data) < 1+int(data[0]) {
+			return p, nil, ErrInvalidFormat
+		}
+		p.Approvers = append(p.Approvers, string(data[1:1+int(data[0])]))
+		data = data[1+int(data[0]):]
+	}
+	return p, data, nil
+}
@@ -0,0 +1,81 @@
+package shamir
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPolicyAndRoleEncodings(t *testing.T) {
+	policy := Policy{
+		NotBefore: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Approvers: []string{"notary", "exécuteur, testamentaire"},
+	}
+	shares, err := Split([]byte("correct horse battery staple"), 3, 2, WithReleasePolicy(policy),
+		WithRoles("legal", "board", "board"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	share := shares[0]
+
+	armored, err := EncodePEM(share)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, _, err := DecodePEM(armored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Policy, policy) || decoded.Role != "legal" {
+		t.Errorf("DecodePEM() policy = %+v, role = %q, want %+v, %q", decoded.Policy, decoded.Role, policy, "legal")
+	}
+
+	if decoded, err = ParseURI(FormatURI(share)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Policy, policy) || decoded.Role != "legal" {
+		t.Errorf("ParseURI() policy = %+v, role = %q, want %+v, %q", decoded.Policy, decoded.Role, policy, "legal")
+	}
+
+	withPolicy, withRole := share, share
+	withPolicy.Role, withRole.Policy = "", Policy{}
+	for _, share := range []Share{withPolicy, withRole} {
+		if _, err := EncodeBech32m(share, DefaultHRP); err == nil {
+			t.Errorf("EncodeBech32m() encoded a share with policy %+v and role %q", share.Policy, share.Role)
+		}
+		if _, err := EncodeMnemonic(share); err == nil {
+			t.Errorf("EncodeMnemonic() encoded a share with policy %+v and role %q", share.Policy, share.Role)
+		}
+	}
+}
+
+func TestFingerprintRestrictions(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+	shares, err := Split(secret, 3, 2, WithReleasePolicy(Policy{Approvers: []string{"notary"}}),
+		WithRoles("legal", "board", "board"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManifest(secret, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stripped, edited := shares[0], shares[1]
+	stripped.Policy, stripped.Role = Policy{}, ""
+	edited.Role = "legal"
+	for i, share := range []Share{stripped, edited} {
+		if share.Fingerprint() == shares[i].Fingerprint() {
+			t.Errorf("Fingerprint() does not cover the policy %+v and the role %q", share.Policy, share.Role)
+		}
+		if err := m.Validate([]Share{share}); err == nil {
+			t.Errorf("Validate() accepted a share with the policy %+v and the role %q", share.Policy, share.Role)
+		}
+	}
+
+	// the fingerprint of a share without policy nor role is unchanged
+	want := fingerprint(shareFingerprintDomain, []byte{Version, stripped.Threshold, stripped.Index},
+		stripped.SplitID[:], stripped.Payload)
+	if stripped.Fingerprint() != want {
+		t.Errorf("Fingerprint() = %s, want %s", stripped.Fingerprint(), want)
+	}
+}
@@ -0,0 +1,74 @@
+package shamir
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// SplitPrime shares a secret element of a prime field rather than a byte string, e.g. a private key scalar
+// modulo the order of an elliptic curve group (see galois.Secp256k1Order). This is the scheme described by
+// Shamir and implemented by most academic libraries: participant i receives the point (i, f(i)) of a random
+// polynomial f of degree threshold-1 such that f(0) is the secret, so shares can be exchanged with
+// implementations using the same prime.
+
+// PrimeShare is the share of a secret split in a prime field, i.e. the point (X, Y) of the polynomial.
+type PrimeShare struct {
+	X *big.Int
+	Y *big.Int
+}
+
+// SplitPrime splits a secret element of the field into n shares, such that threshold shares are required to
+// recover it. The participants are assigned the coordinates 1 to n.
+func SplitPrime(secret *big.Int, field *galois.FieldPrime, n, threshold uint8) ([]PrimeShare, error) {
+	if threshold > n {
+		return nil, errors.New("shamir: the threshold cannot be greater than the number of shares")
+	}
+	if threshold < minThreshold {
+		return nil, errors.New("shamir: the threshold must be at least 2")
+	}
+	if !field.Contains(secret) {
+		return nil, errors.New("shamir: the secret is not an element of the field")
+	}
+	if big.NewInt(int64(n)).Cmp(field.Modulus()) >= 0 {
+		return nil, errors.New("shamir: the field is too small for the number of shares")
+	}
+
+	polynomial, err := galois.RandomPoly(field, secret, int(threshold)-1, entropy())
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]PrimeShare, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		shares[i] = PrimeShare{X: x, Y: polynomial.Eval(x)}
+	}
+	return shares, nil
+}
+
+// RecoverPrime combines shares dealt by SplitPrime using Lagrange's interpolation in order to reconstruct the
+// secret. At least threshold shares must be provided, otherwise an unrelated element is returned.
+func RecoverPrime(shares []PrimeShare, field *galois.FieldPrime) (*big.Int, error) {
+	if len(shares) < int(minThreshold) {
+		return nil, errors.New("shamir: the number of shares provided is below the minimum threshold")
+	}
+	for i, share := range shares {
+		if share.X == nil || share.Y == nil || share.X.Sign() == 0 || !field.Contains(share.X) ||
+			!field.Contains(share.Y) {
+			return nil, errors.New("shamir: a share is not a point of the field")
+		}
+		for _, other := range shares[:i] {
+			if share.X.Cmp(other.X) == 0 {
+				return nil, errors.New("shamir: all shares must have distinct coordinates")
+			}
+		}
+	}
+
+	x := make([]*big.Int, len(shares))
+	y := make([]*big.Int, len(shares))
+	for i, share := range shares {
+		x[i], y[i] = share.X, share.Y
+	}
+	return galois.InterpolateAtZero(field, x, y), nil
+}
@@ -0,0 +1,82 @@
+package shamir
+
+import (
+	"sync"
+	"time"
+)
+
+// Splitting or recovering a large secret takes a while, so that frontends may want to show its progress. The
+// progress is reported every time a block of the secret is processed (see blockLength), the last report having
+// Done equal to Total. The bytes processed are those of the secret, padding included, whose shares are computed
+// (split) or which are recovered (recovery); reading the randomness is not accounted for.
+
+// Progress is the progress of a split or a recovery.
+type Progress struct {
+	// Done is the number of bytes of the secret processed so far.
+	Done int64
+	// Total is the number of bytes of the secret to process.
+	Total int64
+	// Elapsed is the time elapsed since the operation started.
+	Elapsed time.Duration
+}
+
+// Percent returns the percentage of the secret processed, from 0 to 100.
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 100
+	}
+	return 100 * float64(p.Done) / float64(p.Total)
+}
+
+// ETA estimates the time left before the operation completes, assuming the rate observed so far. It is 0 when
+// the rate is not known yet.
+func (p Progress) ETA() time.Duration {
+	if p.Done == 0 || p.Done >= p.Total {
+		return 0
+	}
+	return time.Duration(float64(p.Elapsed) * float64(p.Total-p.Done) / float64(p.Done))
+}
+
+// WithProgress calls fn with the progress of the split. The calls are serialized, even with WithParallelism, and
+// fn should return quickly as it holds up the splitting.
+func WithProgress(fn func(Progress)) SplitOption {
+	return func(c *splitConfig) {
+		c.progress = fn
+	}
+}
+
+// WithRecoveryProgress calls fn with the progress of the recovery (see WithProgress).
+func WithRecoveryProgress(fn func(Progress)) RecoverOption {
+	return func(c *recoverConfig) {
+		c.progress = fn
+	}
+}
+
+// progressCounter counts the bytes processed by an operation and reports its progress. A nil counter reports
+// nothing.
+type progressCounter struct {
+	fn    func(Progress)
+	mu    sync.Mutex
+	done  int64
+	total int64
+	start time.Time
+}
+
+// newProgressCounter returns the counter of an operation processing total bytes, or nil if fn is nil.
+func newProgressCounter(fn func(Progress), total int) *progressCounter {
+	if fn == nil {
+		return nil
+	}
+	return &progressCounter{fn: fn, total: int64(total), start: time.Now()}
+}
+
+// add reports that n more bytes were processed.
+func (p *progressCounter) add(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done += int64(n)
+	p.fn(Progress{Done: p.done, Total: p.total, Elapsed: time.Since(p.start)})
+}
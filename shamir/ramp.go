@@ -0,0 +1,111 @@
+package shamir
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// Every Shamir share is as large as the secret. A ramp scheme (Blakley and Meadows, "Security of Ramp Schemes",
+// 1984) trades some secrecy for smaller shares: the secret is cut into L pieces, and every share is as large as
+// a piece. Any threshold k shares recover the secret, and any k-L shares reveal nothing about it, but between
+// k-L and k shares reveal partial information.
+//
+// SplitRamp computes in GF(2^8), byte by byte: for every byte position, the polynomial of degree k-1 takes the
+// bytes of the L pieces at the points 0, 255, ..., 257-L, and random values at the points 1 to k-L. The share of
+// index i holds its values at i. The secret is followed by a 0x80 byte and zero bytes up to a multiple of L
+// bytes, so that the shares do not leak its exact length; the padding is removed by RecoverRamp.
+
+// SplitRamp splits a secret into n shares such that threshold shares are required to recover it, and that
+// privacy shares reveal nothing about it. Every share is about threshold-privacy times smaller than the secret.
+// The number of shares cannot exceed 256-threshold+privacy. Only WithRandom and WithWipe are supported.
+func SplitRamp(secret []byte, n, threshold, privacy uint8, options ...SplitOption) ([]SchemeShare, error) {
+	reader, wipe, err := schemeSplitConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	if wipe {
+		defer Zeroize(secret)
+	}
+	if err := checkSchemeParameters(secret, n, threshold); err != nil {
+		return nil, err
+	}
+	if privacy == 0 || privacy >= threshold {
+		return nil, errors.New("shamir: the privacy threshold must be between 1 and the threshold minus 1")
+	}
+	pieces := threshold - privacy
+	if int(n) > 256-int(pieces) {
+		return nil, fmt.Errorf("shamir: at most %d shares can be dealt for a secret cut into %d pieces",
+			256-int(pieces), pieces)
+	}
+
+	padded := padBlocks(secret, int(pieces))
+	defer Zeroize(padded)
+	length := len(padded) / int(pieces)
+	// the polynomials are defined by the pieces of the secret and random values at the first privacy shares
+	points := rampPoints(pieces)
+	values := make([][]byte, threshold)
+	for j := range pieces {
+		values[j] = padded[int(j)*length : int(j+1)*length]
+	}
+	for i := range privacy {
+		points = append(points, i+1)
+		values[pieces+i] = make([]byte, length)
+		if err := random.ReadFull(reader, values[pieces+i]); err != nil {
+			return nil, fmt.Errorf("shamir: failed to generate random polynomial: %w", err)
+		}
+	}
+	defer zeroizeAll(values[pieces:])
+
+	shares := newSchemeShares(VersionRamp, n, threshold, length)
+	at := make([]byte, n)
+	out := make([][]byte, n)
+	for i := range shares {
+		shares[i].Pieces = pieces
+		at[i], out[i] = shares[i].Index, shares[i].Payload
+	}
+	extend(field256, points, values, at, out)
+	trace("split", "split", shares[0].SplitID, "shares", n, "threshold", threshold, "scheme", "ramp")
+	return shares, nil
+}
+
+// RecoverRamp combines shares dealt by SplitRamp in order to reconstruct the secret.
+func RecoverRamp(shares []SchemeShare) ([]byte, error) {
+	shares, err := checkSchemeShares(shares, VersionRamp)
+	if err != nil {
+		return nil, err
+	}
+	pieces := shares[0].Pieces
+	if pieces == 0 || pieces >= shares[0].Threshold {
+		return nil, ErrInvalidFormat
+	}
+	length := len(shares[0].Payload)
+	values := make([][]byte, len(shares))
+	for i, share := range shares {
+		values[i] = share.Payload
+	}
+	padded := make([]byte, int(pieces)*length)
+	out := make([][]byte, pieces)
+	for j := range out {
+		out[j] = padded[j*length : (j+1)*length]
+	}
+	extend(field256, schemeIndexes(shares), values, rampPoints(pieces), out)
+	secret, err := unpad(padded)
+	if err != nil {
+		Zeroize(padded)
+		return nil, err
+	}
+	trace("recovered", "split", shares[0].SplitID, "shares", len(shares), "scheme", "ramp")
+	return secret, nil
+}
+
+// rampPoints returns the points at which the polynomials take the bytes of the pieces of the secret: 0, then
+// 255 downwards, which are never share indexes.
+func rampPoints(pieces uint8) []byte {
+	points := make([]byte, pieces, 256)
+	for j := 1; j < int(pieces); j++ {
+		points[j] = byte(256 - j)
+	}
+	return points
+}
@@ -0,0 +1,37 @@
+package shamir
+
+import (
+	"context"
+	"fmt"
+)
+
+// Every byte of the secret is split independently, so that a range of bytes of the secret can be recovered from the
+// same range of bytes of the shares. RecoverRange recovers only the bytes that are needed, e.g. to check a known
+// prefix or to read a field of a structured secret, without the rest of the secret being reconstructed in memory.
+
+// RecoverRange recovers length bytes of the secret starting at offset, using the same options as Recover.
+// The shares are cross-checked on the range only.
+//
+// The padding of padded shares (see WithPadding) is not removed: the range is checked against the padded length,
+// so that the bytes past the end of the secret are padding bytes.
+func RecoverRange(shares []Share, offset, length int, options ...RecoverOption) ([]byte, error) {
+	var c recoverConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if c.wipe {
+		defer func() {
+			for _, share := range shares {
+				Zeroize(share.Payload)
+			}
+		}()
+	}
+	if err := checkShares(shares, &c); err != nil {
+		return nil, err
+	}
+	if offset < 0 || length < 0 || length > len(shares[0].Payload)-offset {
+		return nil, fmt.Errorf("shamir: the range of %d bytes at offset %d is out of the bounds of the secret (%d bytes)",
+			length, offset, len(shares[0].Payload))
+	}
+	return combineRange(context.Background(), nil, shares, offset, offset+length, &c)
+}
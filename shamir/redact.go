@@ -0,0 +1,122 @@
+package shamir
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// The types holding shares or secrets print without them, so that formatting them with %v, %s or %#v, e.g. in a
+// log line, does not disclose them: a share prints as share[idx=3 k=5 fp=ab12cd34 REDACTED], where fp is its
+// fingerprint (see Fingerprint). DangerousHex returns the hexadecimal encoding of the material which is redacted,
+// for the callers which need to display it on purpose.
+
+// String formats the share without its payload, such as share[idx=3 k=5 fp=ab12cd34 REDACTED].
+func (s Share) String() string {
+	return fmt.Sprintf("share[idx=%d k=%d fp=%s REDACTED]", s.Index, s.Threshold, s.Fingerprint())
+}
+
+// GoString formats the share like String, for the %#v verb.
+func (s Share) GoString() string {
+	return s.String()
+}
+
+// DangerousHex returns the payload of the share encoded in hexadecimal.
+func (s Share) DangerousHex() string {
+	return hex.EncodeToString(s.Payload)
+}
+
+// GoString formats the set like String, for the %#v verb.
+func (set ShareSet) GoString() string {
+	return set.String()
+}
+
+// String formats the share without its payload, such as share16[idx=3 k=5 REDACTED].
+func (s Share16) String() string {
+	return fmt.Sprintf("share16[idx=%d k=%d REDACTED]", s.Index, s.Threshold)
+}
+
+// GoString formats the share like String, for the %#v verb.
+func (s Share16) GoString() string {
+	return s.String()
+}
+
+// DangerousHex returns the payload of the share encoded in hexadecimal.
+func (s Share16) DangerousHex() string {
+	return hex.EncodeToString(s.Payload)
+}
+
+// String formats the share without its payload, such as scheme[v=5 idx=3 k=5 REDACTED].
+func (s SchemeShare) String() string {
+	return fmt.Sprintf("scheme[v=%d idx=%d k=%d REDACTED]", s.Version, s.Index, s.Threshold)
+}
+
+// GoString formats the share like String, for the %#v verb.
+func (s SchemeShare) GoString() string {
+	return s.String()
+}
+
+// DangerousHex returns the payload of the share encoded in hexadecimal.
+func (s SchemeShare) DangerousHex() string {
+	return hex.EncodeToString(s.Payload)
+}
+
+// String formats the share without its payload, keys and tags, such as checked[idx=3 k=5 fp=ab12cd34 REDACTED].
+func (s CheckedShare) String() string {
+	return fmt.Sprintf("checked[idx=%d k=%d fp=%s REDACTED]", s.Share.Index, s.Share.Threshold, s.Share.Fingerprint())
+}
+
+// GoString formats the share like String, for the %#v verb.
+func (s CheckedShare) GoString() string {
+	return s.String()
+}
+
+// String formats the share without its value, such as prime[x=3 REDACTED].
+func (s PrimeShare) String() string {
+	return fmt.Sprintf("prime[x=%v REDACTED]", s.X)
+}
+
+// GoString formats the share like String, for the %#v verb.
+func (s PrimeShare) GoString() string {
+	return s.String()
+}
+
+// DangerousHex returns the value of the share encoded in hexadecimal.
+func (s PrimeShare) DangerousHex() string {
+	if s.Y == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.Y.Bytes())
+}
+
+// String formats the share without its exponent, such as rsa[idx=3 REDACTED].
+func (s RSAKeyShare) String() string {
+	return fmt.Sprintf("rsa[idx=%d REDACTED]", s.Index)
+}
+
+// GoString formats the share like String, for the %#v verb.
+func (s RSAKeyShare) GoString() string {
+	return s.String()
+}
+
+// DangerousHex returns the share of the private exponent encoded in hexadecimal.
+func (s RSAKeyShare) DangerousHex() string {
+	if s.Exponent == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.Exponent.Bytes())
+}
+
+// String formats the buffer without its content, such as secret[len=32 REDACTED].
+func (b *LockedBuffer) String() string {
+	return fmt.Sprintf("secret[len=%d REDACTED]", len(b.Bytes()))
+}
+
+// GoString formats the buffer like String, for the %#v verb.
+func (b *LockedBuffer) GoString() string {
+	return b.String()
+}
+
+// DangerousHex returns the content of the buffer encoded in hexadecimal.
+func (b *LockedBuffer) DangerousHex() string {
+	return hex.EncodeToString(b.Bytes())
+}
@@ -0,0 +1,248 @@
+package shamir
+
+import (
+	"context"
+	"crypto/hpke"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// The holders of threshold shares or more can hand the secret over without ever reconstructing it: the secret is
+// the sum of the contributions l[i]*y[i] of the holders, where l[i] is the value at 0 of the Lagrange basis
+// polynomial of holder i over the coordinates of the holders. A single contribution reveals nothing about the
+// secret, and the contributions are only ever summed by their final recipient:
+//
+//   - Reshare splits the contribution of a holder among a new committee, with a new threshold. Every member of the
+//     committee passes the messages it received from all the holders to CombineReshares, which sums them into a
+//     share of the same secret. The shares of the old and new committees cannot be combined together.
+//   - EncryptContribution encrypts the contribution of a holder to the HPKE public key of a destination, e.g. a
+//     hardware security module, which passes the ciphertexts of all the holders to RecoverEncrypted. The
+//     ciphertexts can be relayed by a coordinator which learns nothing.
+//
+// As for the joint generation of secrets (see JointParticipant), the caller is responsible for the transport of
+// the messages, which must be encrypted and authenticated, and the holders are assumed to follow the protocol.
+
+const reshareInfo = "shamir-sss encrypted contribution\x00"
+
+// ReshareConfig holds the parameters of a resharing, which all the holders and members of the new committee
+// must agree on.
+type ReshareConfig struct {
+	// Holders holds the indexes of the shares taking part in the resharing, at least as many as their threshold.
+	Holders []uint8 `json:"holders"`
+	// SplitID identifies the shares of the new committee.
+	SplitID SplitID `json:"splitId"`
+	// Committee holds the distinct, non-zero indexes of the members of the new committee.
+	Committee []uint8 `json:"committee"`
+	// Threshold is the number of shares of the new committee required to recover the secret.
+	Threshold uint8 `json:"threshold"`
+}
+
+// ReshareMessage carries the share of the contribution of a holder sent to a member of the new committee.
+type ReshareMessage struct {
+	SplitID SplitID `json:"splitId"`
+	// From is the index of the share of the holder.
+	From uint8 `json:"from"`
+	// To is the index of the member of the new committee.
+	To uint8 `json:"to"`
+	// Payload is the share of the contribution of From held by To.
+	Payload []byte `json:"payload"`
+	// Padded and Polynomial are copied from the share of the holder.
+	Padded     bool   `json:"padded,omitempty"`
+	Polynomial uint16 `json:"polynomial,omitempty"`
+}
+
+// Reshare splits the contribution of a share to the secret among the new committee, returning the messages to
+// send to its members.
+func Reshare(share Share, config ReshareConfig) ([]ReshareMessage, error) {
+	if err := checkIndexes(config.Committee); err != nil {
+		return nil, err
+	}
+	if config.Threshold < minThreshold {
+		return nil, errors.New("shamir: the threshold must be at least 2")
+	}
+	if int(config.Threshold) > len(config.Committee) {
+		return nil, errors.New("shamir: the threshold cannot be greater than the number of shares to deal")
+	}
+	field, contribution, err := contribute(share, config.Holders)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(contribution)
+
+	shares := make([]Share, len(config.Committee))
+	err = deal(context.Background(), &splitConfig{}, field, shares, contribution, config.Threshold,
+		config.Committee, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]ReshareMessage, len(shares))
+	for i, dealt := range shares {
+		messages[i] = ReshareMessage{
+			SplitID:    config.SplitID,
+			From:       share.Index,
+			To:         dealt.Index,
+			Payload:    dealt.Payload,
+			Padded:     share.Padded,
+			Polynomial: share.Polynomial,
+		}
+	}
+	return messages, nil
+}
+
+// CombineReshares sums the messages received by the member of the new committee of the provided index from all
+// the holders into its share of the secret.
+func CombineReshares(index uint8, config ReshareConfig, messages []ReshareMessage) (Share, error) {
+	if err := checkIndexes(config.Holders); err != nil {
+		return Share{}, err
+	}
+	if !slices.Contains(config.Committee, index) {
+		return Share{}, fmt.Errorf("shamir: %d is not the index of a member of the committee", index)
+	}
+	if len(messages) != len(config.Holders) {
+		return Share{}, fmt.Errorf("shamir: %d messages are required, one from every holder, got %d",
+			len(config.Holders), len(messages))
+	}
+
+	first := messages[0]
+	share := Share{
+		Threshold:  config.Threshold,
+		Index:      index,
+		SplitID:    config.SplitID,
+		Payload:    make([]byte, len(first.Payload)),
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		Padded:     first.Padded,
+		Polynomial: first.Polynomial,
+	}
+	received := make(map[uint8]bool, len(messages))
+	for _, m := range messages {
+		switch {
+		case m.SplitID != config.SplitID:
+			return Share{}, ErrMixedSplits
+		case m.To != index:
+			return Share{}, fmt.Errorf("shamir: the message is addressed to member %d", m.To)
+		case !slices.Contains(config.Holders, m.From) || received[m.From]:
+			return Share{}, fmt.Errorf("shamir: unexpected message from holder %d", m.From)
+		case len(m.Payload) != len(share.Payload) || m.Padded != share.Padded || m.Polynomial != share.Polynomial:
+			return Share{}, fmt.Errorf("shamir: the message of holder %d does not match the others", m.From)
+		}
+		subtle.XORBytes(share.Payload, share.Payload, m.Payload)
+		received[m.From] = true
+	}
+	return share, nil
+}
+
+// EncryptContribution encrypts the contribution of a share to the secret to the HPKE public key of the
+// destination, using HKDF-SHA256 and ChaCha20-Poly1305. holders holds the indexes of the shares taking part.
+func EncryptContribution(share Share, holders []uint8, recipient hpke.PublicKey) ([]byte, error) {
+	_, contribution, err := contribute(share, holders)
+	if err != nil {
+		return nil, err
+	}
+	defer Zeroize(contribution)
+
+	// the contribution is preceded by the padding flag of the share
+	plaintext := make([]byte, 1+len(contribution))
+	defer Zeroize(plaintext)
+	if share.Padded {
+		plaintext[0] = 1
+	}
+	copy(plaintext[1:], contribution)
+	return hpke.Seal(recipient, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), contributionInfo(share.SplitID, holders),
+		plaintext)
+}
+
+// RecoverEncrypted decrypts the contributions encrypted by all the holders with EncryptContribution and
+// recovers the secret.
+func RecoverEncrypted(ciphertexts [][]byte, id SplitID, holders []uint8, key hpke.PrivateKey) ([]byte, error) {
+	if len(ciphertexts) != len(holders) {
+		return nil, fmt.Errorf("shamir: %d contributions are required, one from every holder, got %d",
+			len(holders), len(ciphertexts))
+	}
+	info := contributionInfo(id, holders)
+	var secret []byte
+	var padded byte
+	for i, ciphertext := range ciphertexts {
+		plaintext, err := hpke.Open(key, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), info, ciphertext)
+		if err != nil {
+			Zeroize(secret)
+			return nil, fmt.Errorf("shamir: contribution %d cannot be decrypted: %w", i, err)
+		}
+		if i == 0 && len(plaintext) > minSecretLength {
+			secret, padded = make([]byte, len(plaintext)-1), plaintext[0]
+		}
+		if len(plaintext) != len(secret)+1 || plaintext[0] != padded {
+			Zeroize(plaintext)
+			Zeroize(secret)
+			return nil, fmt.Errorf("shamir: contribution %d does not match the others", i)
+		}
+		subtle.XORBytes(secret, secret, plaintext[1:])
+		Zeroize(plaintext)
+	}
+
+	if padded == 1 {
+		unpadded, err := unpad(secret)
+		if err != nil {
+			Zeroize(secret)
+			return nil, err
+		}
+		secret = unpadded
+	}
+	return secret, nil
+}
+
+// contribute computes the contribution of a share to the secret: its payload multiplied by the value at 0 of the
+// Lagrange basis polynomial of the share over the coordinates of the holders.
+func contribute(share Share, holders []uint8) (*galois.Field256, []byte, error) {
+	if err := checkIndexes(holders); err != nil {
+		return nil, nil, err
+	}
+	if share.Threshold == 0 {
+		return nil, nil, errors.New("shamir: the threshold of the share is unknown")
+	}
+	if len(holders) < int(share.Threshold) {
+		return nil, nil, fmt.Errorf("shamir: %d holders are required, got %d", share.Threshold, len(holders))
+	}
+	position := slices.Index(holders, share.Index)
+	if position < 0 {
+		return nil, nil, fmt.Errorf("shamir: share %d is not one of the holders", share.Index)
+	}
+	if len(share.Payload) < minSecretLength {
+		return nil, nil, errors.New("shamir: the share has no payload")
+	}
+	field, err := newField256(share.Polynomial, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	basis := galois.LagrangeBasis(field, holders, 0)
+	contribution := make([]byte, len(share.Payload))
+	field.MulSlice(basis[position], share.Payload, contribution)
+	return field, contribution, nil
+}
+
+// contributionInfo returns the HPKE info binding the encrypted contributions to the split and the holders.
+func contributionInfo(id SplitID, holders []uint8) []byte {
+	sorted := slices.Clone(holders)
+	slices.Sort(sorted)
+	info := append([]byte(reshareInfo), id[:]...)
+	return append(info, sorted...)
+}
+
+// checkIndexes checks that the indexes of shares are distinct and non-zero.
+func checkIndexes(indexes []uint8) error {
+	if len(indexes) > 255 {
+		return errors.New("shamir: the number of shares cannot be greater than 255")
+	}
+	seen := make(map[uint8]bool, len(indexes))
+	for _, index := range indexes {
+		if index == 0 || seen[index] {
+			return errors.New("shamir: the indexes of the shares must be distinct and non-zero")
+		}
+		seen[index] = true
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package shamir
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"math/big"
+)
+
+// RSA private keys can be split in two ways. SplitRSAKey encrypts the PKCS#8 encoding of the key and splits the
+// encryption key (see SealLarge), so that threshold shares reassemble the key, e.g. to sign in a ceremony.
+//
+// SplitRSAExponent shares the private exponent additively instead: d = d[1] + ... + d[n] modulo (p-1)(q-1), so
+// that all the n holders sign partially with m^d[i] and the product of the partial signatures is the signature,
+// without ever reassembling the key. The partial signatures are computed using math/big, which does not run in
+// constant time, so the holders should sign on dedicated machines.
+
+// pkcs1Prefixes are the DER encodings of the DigestInfo prefixes of PKCS #1 v1.5 signatures.
+var pkcs1Prefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+	crypto.SHA384: {0x30, 0x41, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x02, 0x05, 0x00, 0x04, 0x30},
+	crypto.SHA512: {0x30, 0x51, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03, 0x05, 0x00, 0x04, 0x40},
+}
+
+// SplitRSAKey encrypts an RSA private key and splits the encryption key into n shares, such that threshold shares
+// are required to recover the private key with RecoverRSAKey.
+func SplitRSAKey(key *rsa.PrivateKey, n, threshold uint8) ([]byte, []Share, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, shares, err := SealLarge(der, n, threshold)
+	for i := range der {
+		der[i] = 0
+	}
+	return ciphertext, shares, err
+}
+
+// RecoverRSAKey decrypts an RSA private key encrypted by SplitRSAKey using the shares of the encryption key. The
+// private key is a crypto.Signer.
+func RecoverRSAKey(ciphertext []byte, shares []Share) (*rsa.PrivateKey, error) {
+	der, err := OpenLarge(ciphertext, shares)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	for i := range der {
+		der[i] = 0
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("shamir: the recovered key is not an RSA private key")
+	}
+	return key, nil
+}
+
+// RSAKeyShare is the additive share of the private exponent of an RSA key held by a participant.
+type RSAKeyShare struct {
+	// Index is the index of the share, from 1 to the number of shares.
+	Index uint8
+	// PublicKey is the public key of the RSA key.
+	PublicKey *rsa.PublicKey
+	// Exponent is the share d[i] of the private exponent.
+	Exponent *big.Int
+}
+
+// SplitRSAExponent splits the private exponent of an RSA key into n additive shares, such that all the shares
+// are required to sign. The shares of the exponent are uniformly random modulo (p-1)(q-1).
+func SplitRSAExponent(key *rsa.PrivateKey, n uint8) ([]RSAKeyShare, error) {
+	if n < minThreshold {
+		return nil, errors.New("shamir: the number of shares must be at least 2")
+	}
+	if len(key.Primes) != 2 {
+		return nil, errors.New("shamir: multi-prime RSA keys are not supported")
+	}
+	one := big.NewInt(1)
+	phi := new(big.Int).Mul(new(big.Int).Sub(key.Primes[0], one), new(big.Int).Sub(key.Primes[1], one))
+
+	shares := make([]RSAKeyShare, n)
+	last := new(big.Int).Set(key.D)
+	for i := range shares {
+		exponent := last
+		if i < len(shares)-1 {
+			var err error
+			if exponent, err = rand.Int(rand.Reader, phi); err != nil {
+				return nil, err
+			}
+			last.Sub(last, exponent).Mod(last, phi)
+		}
+		shares[i] = RSAKeyShare{Index: uint8(i + 1), PublicKey: &key.PublicKey, Exponent: exponent}
+	}
+	return shares, nil
+}
+
+// SignPKCS1v15 computes the partial PKCS #1 v1.5 signature of a digest with the share of the exponent. Only
+// SHA-256, SHA-384 and SHA-512 digests are supported.
+func (s RSAKeyShare) SignPKCS1v15(hash crypto.Hash, digest []byte) ([]byte, error) {
+	encoded, err := encodePKCS1v15(s.PublicKey, hash, digest)
+	if err != nil {
+		return nil, err
+	}
+	partial := new(big.Int).Exp(encoded, s.Exponent, s.PublicKey.N)
+	return partial.FillBytes(make([]byte, s.PublicKey.Size())), nil
+}
+
+// CombineRSASignatures multiplies the partial signatures of all the holders of the shares of the exponent into
+// the PKCS #1 v1.5 signature of the digest, which is verified with the public key.
+func CombineRSASignatures(public *rsa.PublicKey, hash crypto.Hash, digest []byte, partials [][]byte) ([]byte, error) {
+	if len(partials) < int(minThreshold) {
+		return nil, errors.New("shamir: the number of partial signatures is below the minimum threshold")
+	}
+	signature := big.NewInt(1)
+	for _, partial := range partials {
+		if len(partial) != public.Size() {
+			return nil, errors.New("shamir: invalid partial signature")
+		}
+		signature.Mul(signature, new(big.Int).SetBytes(partial)).Mod(signature, public.N)
+	}
+	encoded := signature.FillBytes(make([]byte, public.Size()))
+	if err := rsa.VerifyPKCS1v15(public, hash, digest, encoded); err != nil {
+		return nil, errors.New("shamir: the partial signatures do not combine into a valid signature")
+	}
+	return encoded, nil
+}
+
+// encodePKCS1v15 returns the EMSA-PKCS1-v1_5 encoding of a digest, 0x00 0x01 0xff...0xff 0x00 DigestInfo.
+func encodePKCS1v15(public *rsa.PublicKey, hash crypto.Hash, digest []byte) (*big.Int, error) {
+	prefix, ok := pkcs1Prefixes[hash]
+	if !ok {
+		return nil, errors.New("shamir: unsupported hash function")
+	}
+	if len(digest) != hash.Size() {
+		return nil, errors.New("shamir: the length of the digest does not match the hash function")
+	}
+	size := public.Size()
+	if size < len(prefix)+len(digest)+11 {
+		return nil, errors.New("shamir: the RSA key is too short")
+	}
+	encoded := make([]byte, size)
+	encoded[1] = 0x01
+	for i := 2; i < size-len(prefix)-len(digest)-1; i++ {
+		encoded[i] = 0xff
+	}
+	copy(encoded[size-len(prefix)-len(digest):], prefix)
+	copy(encoded[size-len(digest):], digest)
+	return new(big.Int).SetBytes(encoded), nil
+}
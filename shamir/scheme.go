@@ -0,0 +1,321 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// The sharing schemes of the package share a common binary envelope: the shares start with the "SHMR" magic
+// bytes, followed by the format version, which identifies the scheme. The Scheme interface handles the shares of
+// every scheme uniformly, as binary encoded shares, so that tools can split and recover secrets whatever the
+// scheme, which is selected by name when splitting and read from the header of the shares when recovering:
+//
+// 	name            ID  shares
+// 	shamir-gf256     1  Share, see Split
+// 	shamir-gf65536   2  Share16, see Split16
+// 	ramp-gf256       3  SchemeShare, see SplitRamp
+// 	ida-gf256        4  SchemeShare, see SplitIDA
+// 	css-gf256        5  SchemeShare, see SplitCSS
+// 	crt-asmuth-bloom 6  SchemeShare, see SplitCRT
+//
+// The ramp scheme cuts the secret into threshold/2 pieces, rounded down, so that threshold-threshold/2 shares
+// reveal nothing about it. The schemes of SchemeShare support WithRandom and WithWipe only, and no recovery
+// options.
+//
+// Other schemes can be added with RegisterScheme, using format versions unused by the package.
+//
+// RecoverEncoded dispatches the shares to their scheme. The shares which do not start with the magic bytes are
+// legacy shares of the shamir-gf256 scheme (see ParseLegacy), which can be mixed with shares of the binary format
+// dealt by the same split: they are completed with the metadata of the latter, see ResolveLegacy.
+
+// Scheme is a secret sharing scheme whose shares are encoded in binary.
+type Scheme interface {
+	// Name returns the name of the scheme.
+	Name() string
+	// ID returns the identifier of the scheme, which is the format version recorded in the header of its shares.
+	ID() uint8
+	// Split splits a secret into n encoded shares, any threshold of which recover the secret.
+	Split(secret []byte, n, threshold int, options ...SplitOption) ([][]byte, error)
+	// Recover recovers the secret from encoded shares.
+	Recover(shares [][]byte, options ...RecoverOption) ([]byte, error)
+	// Verify decodes a share, checking its integrity, and returns its metadata.
+	Verify(share []byte) (ShareMetadata, error)
+}
+
+// ShareMetadata describes an encoded share, without its payload.
+type ShareMetadata struct {
+	Scheme    string    `json:"scheme"`
+	Index     int       `json:"index"`
+	Threshold int       `json:"threshold"`
+	SplitID   SplitID   `json:"splitId"`
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+	Label     string    `json:"label,omitempty"`
+	// PayloadLength is the length of the payload of the share in bytes.
+	PayloadLength int `json:"payloadLength"`
+}
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = map[uint8]Scheme{
+		Version:   scheme256{},
+		Version16: scheme65536{},
+		VersionRamp: schemeShares{name: "ramp-gf256", id: VersionRamp, recover: RecoverRamp,
+			split: func(secret []byte, n, threshold uint8, options ...SplitOption) ([]SchemeShare, error) {
+				return SplitRamp(secret, n, threshold, threshold-threshold/2, options...)
+			}},
+		VersionIDA: schemeShares{name: "ida-gf256", id: VersionIDA, split: SplitIDA, recover: RecoverIDA},
+		VersionCSS: schemeShares{name: "css-gf256", id: VersionCSS, split: SplitCSS, recover: RecoverCSS},
+		VersionCRT: schemeShares{name: "crt-asmuth-bloom", id: VersionCRT, split: SplitCRT, recover: RecoverCRT},
+	}
+)
+
+// RegisterScheme makes a scheme available by its name and identifier. It panics if a scheme with the same name
+// or identifier is already registered.
+func RegisterScheme(scheme Scheme) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+	if _, ok := schemes[scheme.ID()]; ok {
+		panic(fmt.Sprintf("shamir: a scheme with identifier %d is already registered", scheme.ID()))
+	}
+	for _, registered := range schemes {
+		if registered.Name() == scheme.Name() {
+			panic(fmt.Sprintf("shamir: a scheme named %q is already registered", scheme.Name()))
+		}
+	}
+	schemes[scheme.ID()] = scheme
+}
+
+// Schemes returns the registered schemes, sorted by identifier.
+func Schemes() []Scheme {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	registered := make([]Scheme, 0, len(schemes))
+	for _, scheme := range schemes {
+		registered = append(registered, scheme)
+	}
+	slices.SortFunc(registered, func(a, b Scheme) int {
+		return int(a.ID()) - int(b.ID())
+	})
+	return registered
+}
+
+// SchemeByName returns the registered scheme of the provided name.
+func SchemeByName(name string) (Scheme, error) {
+	for _, scheme := range Schemes() {
+		if scheme.Name() == name {
+			return scheme, nil
+		}
+	}
+	return nil, fmt.Errorf("shamir: unknown scheme %q", name)
+}
+
+// SchemeOf returns the scheme of an encoded share, read from its header.
+func SchemeOf(share []byte) (Scheme, error) {
+	if len(share) < 5 || !bytes.Equal(share[:4], magic) {
+		return nil, ErrInvalidFormat
+	}
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+	scheme, ok := schemes[share[4]]
+	if !ok {
+		return nil, ErrUnsupportedVersion
+	}
+	return scheme, nil
+}
+
+// RecoverEncoded recovers a secret from encoded shares, using the scheme read from the header of the shares. Legacy
+// shares are recovered with the shamir-gf256 scheme.
+func RecoverEncoded(shares [][]byte, options ...RecoverOption) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("shamir: the number of shares provided is below the minimum threshold")
+	}
+	var scheme Scheme
+	for i, share := range shares {
+		current, err := encodedScheme(share)
+		if err != nil {
+			return nil, fmt.Errorf("shamir: share %d: %w", i+1, err)
+		}
+		if scheme != nil && current.ID() != scheme.ID() {
+			return nil, errors.New("shamir: all shares must use the same scheme")
+		}
+		scheme = current
+	}
+	return scheme.Recover(shares, options...)
+}
+
+// encodedScheme returns the scheme of an encoded share, which is shamir-gf256 for legacy shares.
+func encodedScheme(share []byte) (Scheme, error) {
+	if !bytes.HasPrefix(share, magic) {
+		return scheme256{}, nil
+	}
+	return SchemeOf(share)
+}
+
+// scheme256 is the Shamir secret sharing scheme in GF(2^8), see Split.
+type scheme256 struct{}
+
+func (scheme256) Name() string { return "shamir-gf256" }
+
+func (scheme256) ID() uint8 { return Version }
+
+func (scheme256) Split(secret []byte, n, threshold int, options ...SplitOption) ([][]byte, error) {
+	if n < 0 || n > 255 || threshold < 0 || threshold > 255 {
+		return nil, errors.New("shamir: the number of shares to deal cannot be greater than 255")
+	}
+	shares, err := Split(secret, uint8(n), uint8(threshold), options...)
+	if err != nil {
+		return nil, err
+	}
+	return marshalShares(shares, Share.MarshalBinary)
+}
+
+func (scheme256) Recover(encoded [][]byte, options ...RecoverOption) ([]byte, error) {
+	shares := make([]Share, len(encoded))
+	for i, data := range encoded {
+		var err error
+		if shares[i], err = ParseShare(data); err != nil {
+			return nil, fmt.Errorf("shamir: share %d: %w", i+1, err)
+		}
+	}
+	return Recover(ResolveLegacy(shares), options...)
+}
+
+func (scheme256) Verify(encoded []byte) (ShareMetadata, error) {
+	var share Share
+	if err := share.UnmarshalBinary(encoded); err != nil {
+		return ShareMetadata{}, err
+	}
+	return ShareMetadata{
+		Scheme:        scheme256{}.Name(),
+		Index:         int(share.Index),
+		Threshold:     int(share.Threshold),
+		SplitID:       share.SplitID,
+		CreatedAt:     share.CreatedAt,
+		Label:         share.Label,
+		PayloadLength: len(share.Payload),
+	}, nil
+}
+
+// scheme65536 is the Shamir secret sharing scheme in GF(2^16), see Split16.
+type scheme65536 struct{}
+
+func (scheme65536) Name() string { return "shamir-gf65536" }
+
+func (scheme65536) ID() uint8 { return Version16 }
+
+func (scheme65536) Split(secret []byte, n, threshold int, options ...SplitOption) ([][]byte, error) {
+	if n < 0 || n > 65535 || threshold < 0 || threshold > 65535 {
+		return nil, errors.New("shamir: the number of shares to deal cannot be greater than 65535")
+	}
+	shares, err := Split16(secret, uint16(n), uint16(threshold), options...)
+	if err != nil {
+		return nil, err
+	}
+	return marshalShares(shares, Share16.MarshalBinary)
+}
+
+func (scheme65536) Recover(encoded [][]byte, options ...RecoverOption) ([]byte, error) {
+	shares, err := unmarshalShares16(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return Recover16(shares, options...)
+}
+
+func (scheme65536) Verify(encoded []byte) (ShareMetadata, error) {
+	var share Share16
+	if err := share.UnmarshalBinary(encoded); err != nil {
+		return ShareMetadata{}, err
+	}
+	return ShareMetadata{
+		Scheme:        scheme65536{}.Name(),
+		Index:         int(share.Index),
+		Threshold:     int(share.Threshold),
+		SplitID:       share.SplitID,
+		CreatedAt:     share.CreatedAt,
+		Label:         share.Label,
+		PayloadLength: len(share.Payload),
+	}, nil
+}
+
+// schemeShares is a scheme whose shares are SchemeShare, see SplitRamp, SplitIDA, SplitCSS and SplitCRT.
+type schemeShares struct {
+	name    string
+	id      uint8
+	split   func(secret []byte, n, threshold uint8, options ...SplitOption) ([]SchemeShare, error)
+	recover func(shares []SchemeShare) ([]byte, error)
+}
+
+func (s schemeShares) Name() string { return s.name }
+
+func (s schemeShares) ID() uint8 { return s.id }
+
+func (s schemeShares) Split(secret []byte, n, threshold int, options ...SplitOption) ([][]byte, error) {
+	if n < 0 || n > 255 || threshold < 0 || threshold > 255 {
+		return nil, errors.New("shamir: the number of shares to deal cannot be greater than 255")
+	}
+	shares, err := s.split(secret, uint8(n), uint8(threshold), options...)
+	if err != nil {
+		return nil, err
+	}
+	return marshalShares(shares, SchemeShare.MarshalBinary)
+}
+
+func (s schemeShares) Recover(encoded [][]byte, options ...RecoverOption) ([]byte, error) {
+	if len(options) != 0 {
+		return nil, fmt.Errorf("shamir: the %s scheme does not support recovery options", s.name)
+	}
+	shares := make([]SchemeShare, len(encoded))
+	for i, data := range encoded {
+		if err := shares[i].UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("shamir: share %d: %w", i+1, err)
+		}
+	}
+	return s.recover(shares)
+}
+
+func (s schemeShares) Verify(encoded []byte) (ShareMetadata, error) {
+	var share SchemeShare
+	if err := share.UnmarshalBinary(encoded); err != nil {
+		return ShareMetadata{}, err
+	}
+	if share.Version != s.id {
+		return ShareMetadata{}, ErrUnsupportedVersion
+	}
+	return ShareMetadata{
+		Scheme:        s.name,
+		Index:         int(share.Index),
+		Threshold:     int(share.Threshold),
+		SplitID:       share.SplitID,
+		CreatedAt:     share.CreatedAt,
+		Label:         share.Label,
+		PayloadLength: len(share.Payload),
+	}, nil
+}
+
+// marshalShares encodes shares in binary.
+func marshalShares[S any](shares []S, marshal func(S) ([]byte, error)) ([][]byte, error) {
+	encoded := make([][]byte, len(shares))
+	for i, share := range shares {
+		var err error
+		if encoded[i], err = marshal(share); err != nil {
+			return nil, err
+		}
+	}
+	return encoded, nil
+}
+
+// unmarshalShares16 decodes GF(2^16) shares encoded in binary.
+func unmarshalShares16(encoded [][]byte) ([]Share16, error) {
+	shares := make([]Share16, len(encoded))
+	for i, data := range encoded {
+		if err := shares[i].UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("shamir: share %d: %w", i+1, err)
+		}
+	}
+	return shares, nil
+}
@@ -0,0 +1,129 @@
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSchemes(t *testing.T) {
+	secret := []byte("the quick brown fox jumps over the lazy dog, 0123456789")
+	for _, name := range []string{"shamir-gf256", "shamir-gf65536", "ramp-gf256", "ida-gf256", "css-gf256",
+		"crt-asmuth-bloom"} {
+		t.Run(name, func(t *testing.T) {
+			scheme, err := SchemeByName(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shares, err := scheme.Split(bytes.Clone(secret), 5, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, subset := range [][][]byte{shares[:3], shares[2:], {shares[4], shares[0], shares[2]}} {
+				recovered, err := RecoverEncoded(subset)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(recovered, secret) {
+					t.Errorf("RecoverEncoded() = %q, want %q", recovered, secret)
+				}
+			}
+			if _, err := RecoverEncoded(shares[:2]); err == nil {
+				t.Error("RecoverEncoded() recovered a secret from fewer shares than the threshold")
+			}
+
+			metadata, err := scheme.Verify(shares[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if metadata.Scheme != name || metadata.Index == 0 || metadata.Threshold != 3 {
+				t.Errorf("Verify() = %+v, want scheme %s and threshold 3", metadata, name)
+			}
+			if found, err := SchemeOf(shares[1]); err != nil || found.ID() != scheme.ID() {
+				t.Errorf("SchemeOf() = %v, %v, want %s", found, err, name)
+			}
+		})
+	}
+}
+
+func TestSchemeShares(t *testing.T) {
+	secret := bytes.Repeat([]byte{0xa5}, 100)
+	splits := map[string]func() ([]SchemeShare, error){
+		"ramp": func() ([]SchemeShare, error) { return SplitRamp(bytes.Clone(secret), 6, 4, 1) },
+		"ida":  func() ([]SchemeShare, error) { return SplitIDA(bytes.Clone(secret), 6, 4) },
+		"css":  func() ([]SchemeShare, error) { return SplitCSS(bytes.Clone(secret), 6, 4) },
+		"crt":  func() ([]SchemeShare, error) { return SplitCRT(bytes.Clone(secret), 6, 4) },
+	}
+	recovers := map[string]func([]SchemeShare) ([]byte, error){
+		"ramp": RecoverRamp, "ida": RecoverIDA, "css": RecoverCSS, "crt": RecoverCRT,
+	}
+	for name, split := range splits {
+		t.Run(name, func(t *testing.T) {
+			shares, err := split()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != "crt" && len(shares[0].Payload) >= len(secret) {
+				t.Errorf("the shares hold %d bytes, want fewer than the %d bytes of the secret",
+					len(shares[0].Payload), len(secret))
+			}
+			shares[0].Label = "alice"
+			data, err := shares[0].MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded SchemeShare
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Label != "alice" || decoded.Index != 1 || !bytes.Equal(decoded.Payload, shares[0].Payload) {
+				t.Errorf("UnmarshalBinary() = %#v, want %#v", decoded, shares[0])
+			}
+			data[len(data)/2] ^= 1
+			if err := decoded.UnmarshalBinary(data); !errors.Is(err, ErrChecksum) {
+				t.Errorf("UnmarshalBinary() error = %v, want %v", err, ErrChecksum)
+			}
+
+			recovered, err := recovers[name]([]SchemeShare{shares[5], shares[1], shares[3], shares[2]})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(recovered, secret) {
+				t.Errorf("recovered %x, want %x", recovered, secret)
+			}
+			mixed, err := split()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := recovers[name]([]SchemeShare{shares[0], shares[1], shares[2], mixed[3]}); !errors.Is(err,
+				ErrMixedSplits) {
+				t.Errorf("recovered shares of different splits, error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRecoverCSSAltered(t *testing.T) {
+	shares, err := SplitCSS([]byte("correct horse battery staple"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares[1].Payload[len(shares[1].Payload)-1] ^= 1
+	if _, err := RecoverCSS(shares); !errors.Is(err, ErrDecryption) {
+		t.Errorf("RecoverCSS() error = %v, want %v", err, ErrDecryption)
+	}
+}
+
+func TestSplitRampParameters(t *testing.T) {
+	for _, privacy := range []uint8{0, 3} {
+		if _, err := SplitRamp([]byte("secret"), 5, 3, privacy); err == nil {
+			t.Errorf("SplitRamp() accepted the privacy threshold %d for the threshold 3", privacy)
+		}
+	}
+	if _, err := SplitRamp([]byte("secret"), 255, 4, 1); err == nil {
+		t.Error("SplitRamp() dealt more shares than the field allows")
+	}
+	if _, err := SplitIDA([]byte("data"), 5, 3, WithPadding()); err == nil {
+		t.Error("SplitIDA() accepted an unsupported option")
+	}
+}
@@ -0,0 +1,255 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// Besides Shamir's scheme, the package implements the ramp, IDA, CSS and CRT schemes (see SplitRamp, SplitIDA,
+// SplitCSS and SplitCRT), whose shares are SchemeShare. They use versions 3 to 6 of the binary format, one per
+// scheme, in which the payload is preceded by a parameter of the scheme:
+//
+// 	offset  size  field
+// 	0       4     magic bytes "SHMR"
+// 	4       1     format version, i.e. the scheme (3: ramp, 4: IDA, 5: CSS, 6: CRT)
+// 	5       1     flags, only the metadata flag (0x01) is defined
+// 	6       1     threshold
+// 	7       1     share index
+// 	8       16    split identifier (UUID)
+// 	24      1     number of pieces of the secret for ramp shares, 0 otherwise
+// 	25      4     payload length p
+// 	29      p     payload
+// 	29+p    4     CRC-32C of all the preceding bytes
+//
+// The metadata is encoded as in version 1, between the payload and the CRC. The share indexes are the
+// coordinates 1 to n.
+
+// The format versions of the shares of the ramp, IDA, CSS and CRT schemes.
+const (
+	VersionRamp uint8 = 3
+	VersionIDA  uint8 = 4
+	VersionCSS  uint8 = 5
+	VersionCRT  uint8 = 6
+)
+
+const headerLengthScheme = 29
+
+// SchemeShare is the share of a secret split with the ramp, IDA, CSS or CRT scheme dealt to a single participant.
+type SchemeShare struct {
+	// Version is the format version of the share, which identifies its scheme (see VersionRamp).
+	Version uint8
+	// Threshold is the number of shares required to recover the secret.
+	Threshold uint8
+	// Index is the coordinate of the participant, from 1 to the number of shares.
+	Index uint8
+	// SplitID identifies the shares dealt along with this share.
+	SplitID SplitID
+	// Pieces is the number of pieces the secret was cut into by SplitRamp, and 0 for the other schemes.
+	Pieces uint8
+	// Payload holds the share of the participant, whose layout depends on the scheme.
+	Payload []byte
+	// CreatedAt is the time the share was dealt, or zero if unknown.
+	CreatedAt time.Time
+	// Label is a free-form description of the share, e.g. the name of its custodian.
+	// It is limited to 255 bytes.
+	Label string
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s SchemeShare) MarshalBinary() ([]byte, error) {
+	if s.Version < VersionRamp || s.Version > VersionCRT {
+		return nil, ErrUnsupportedVersion
+	}
+	if len(s.Label) > maxLabelLength {
+		return nil, errors.New("shamir: the label of a share cannot exceed 255 bytes")
+	}
+	var flags uint8
+	if !s.CreatedAt.IsZero() || s.Label != "" {
+		flags |= flagMetadata
+	}
+
+	data := make([]byte, headerLengthScheme, headerLengthScheme+len(s.Payload)+9+len(s.Label)+crcLength)
+	copy(data, magic)
+	data[4] = s.Version
+	data[5] = flags
+	data[6] = s.Threshold
+	data[7] = s.Index
+	copy(data[8:24], s.SplitID[:])
+	data[24] = s.Pieces
+	binary.BigEndian.PutUint32(data[25:29], uint32(len(s.Payload)))
+	data = append(data, s.Payload...)
+	if flags&flagMetadata != 0 {
+		var created int64
+		if !s.CreatedAt.IsZero() {
+			created = s.CreatedAt.Unix()
+		}
+		data = binary.BigEndian.AppendUint64(data, uint64(created))
+		data = append(data, uint8(len(s.Label)))
+		data = append(data, s.Label...)
+	}
+	return binary.BigEndian.AppendUint32(data, crc32.Checksum(data, castagnoli)), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *SchemeShare) UnmarshalBinary(data []byte) error {
+	if len(data) < headerLengthScheme+crcLength || !bytes.Equal(data[:4], magic) {
+		return ErrInvalidFormat
+	}
+	if data[4] < VersionRamp || data[4] > VersionCRT || data[5]&^flagMetadata != 0 {
+		return ErrUnsupportedVersion
+	}
+	end := len(data) - crcLength
+	if crc32.Checksum(data[:end], castagnoli) != binary.BigEndian.Uint32(data[end:]) {
+		return ErrChecksum
+	}
+	length := binary.BigEndian.Uint32(data[25:29])
+	if uint64(length) > uint64(end-headerLengthScheme) {
+		return ErrInvalidFormat
+	}
+	payloadEnd := headerLengthScheme + int(length)
+
+	var created time.Time
+	var label string
+	if data[5]&flagMetadata != 0 {
+		metadata := data[payloadEnd:end]
+		if len(metadata) < 9 || len(metadata) != 9+int(metadata[8]) {
+			return ErrInvalidFormat
+		}
+		if seconds := int64(binary.BigEndian.Uint64(metadata)); seconds != 0 {
+			created = time.Unix(seconds, 0).UTC()
+		}
+		label = string(metadata[9:])
+	} else if payloadEnd != end {
+		return ErrInvalidFormat
+	}
+
+	s.Version = data[4]
+	s.Threshold = data[6]
+	s.Index = data[7]
+	copy(s.SplitID[:], data[8:24])
+	s.Pieces = data[24]
+	s.Payload = append([]byte{}, data[headerLengthScheme:payloadEnd]...)
+	s.CreatedAt = created
+	s.Label = label
+	return nil
+}
+
+// schemeSplitConfig applies the options of a split by the ramp, IDA, CSS or CRT scheme, which honor WithRandom
+// and WithWipe only, and returns the source of randomness of the split.
+func schemeSplitConfig(options []SplitOption) (io.Reader, bool, error) {
+	var c splitConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if c.padding || c.polynomial != 0 || c.constantTime || c.workers != 0 || c.progress != nil || c.locked ||
+		!c.policy.IsZero() || c.roles != nil {
+		return nil, false, errors.New("shamir: only WithRandom and WithWipe are supported by this scheme")
+	}
+	if c.random == nil {
+		return entropy(), c.wipe, nil
+	}
+	return c.random, c.wipe, nil
+}
+
+// checkSchemeParameters checks the number of shares and the threshold of a split.
+func checkSchemeParameters(secret []byte, n, threshold uint8) error {
+	if threshold > n {
+		return errors.New("shamir: the threshold cannot be greater than the number of shares to deal")
+	}
+	if threshold < minThreshold {
+		return errors.New("shamir: the threshold must be at least 2")
+	}
+	if len(secret) < minSecretLength {
+		return errors.New("shamir: the secret cannot be empty")
+	}
+	return nil
+}
+
+// newSchemeShares returns n shares of a new split of the scheme, with empty payloads of the provided length.
+func newSchemeShares(version, n, threshold uint8, length int) []SchemeShare {
+	id := newSplitID()
+	created := time.Now().UTC().Truncate(time.Second)
+	shares := make([]SchemeShare, n)
+	for i := range shares {
+		shares[i] = SchemeShare{
+			Version:   version,
+			Threshold: threshold,
+			Index:     uint8(i + 1),
+			SplitID:   id,
+			Payload:   make([]byte, length),
+			CreatedAt: created,
+		}
+	}
+	return shares
+}
+
+// checkSchemeShares checks that the shares were dealt by the same split of the scheme, and returns the first
+// threshold of them, which are enough to recover the secret.
+func checkSchemeShares(shares []SchemeShare, version uint8) ([]SchemeShare, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("shamir: the number of shares provided is below the minimum threshold")
+	}
+	first := shares[0]
+	if threshold := int(first.Threshold); threshold < int(minThreshold) || len(shares) < threshold {
+		return nil, fmt.Errorf("shamir: %d shares are required to recover the secret, got %d", threshold,
+			len(shares))
+	}
+	for i, share := range shares {
+		if share.Version != version {
+			return nil, ErrUnsupportedVersion
+		}
+		if share.SplitID != first.SplitID || share.Threshold != first.Threshold || share.Pieces != first.Pieces {
+			return nil, ErrMixedSplits
+		}
+		if len(share.Payload) != len(first.Payload) {
+			return nil, errors.New("shamir: all shares must be the same length")
+		}
+		if share.Index == 0 {
+			return nil, errors.New("shamir: the index of a share cannot be 0")
+		}
+		for _, other := range shares[:i] {
+			if share.Index == other.Index {
+				return nil, errors.New("shamir: all shares must have distinct indexes")
+			}
+		}
+	}
+	return shares[:first.Threshold], nil
+}
+
+// padBlocks returns a copy of the secret followed by a 0x80 byte and zero bytes up to a multiple of size, which
+// unpad removes.
+func padBlocks(secret []byte, size int) []byte {
+	length := (len(secret)/size + 1) * size
+	padded := make([]byte, length)
+	copy(padded, secret)
+	padded[len(secret)] = 0x80
+	return padded
+}
+
+// extend computes, byte by byte, the values at the points at of the polynomials of degree less than len(points)
+// which take the values values[i] at points[i]: out[j] receives the values at at[j].
+func extend(field *galois.Field256, points []byte, values [][]byte, at []byte, out [][]byte) {
+	for j, x := range at {
+		clear(out[j])
+		basis := galois.LagrangeBasis(field, points, x)
+		for i, value := range values {
+			field.MulAddSlice(basis[i], value, out[j])
+		}
+	}
+}
+
+// schemeIndexes returns the indexes of the shares.
+func schemeIndexes(shares []SchemeShare) []byte {
+	indexes := make([]byte, len(shares))
+	for i, share := range shares {
+		indexes[i] = share.Index
+	}
+	return indexes
+}
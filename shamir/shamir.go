@@ -1,8 +1,14 @@
 package shamir
 
 import (
-	"crypto/rand"
-	"log"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/etiennebch/shamir-sss/galois"
 	"github.com/etiennebch/shamir-sss/random"
@@ -11,6 +17,20 @@ import (
 const minSecretLength int = 1
 const minThreshold uint8 = 2
 
+// The fields are immutable and safe for concurrent use, so that a single instance of every field is shared by
+// all the calls. Fields using another reduction polynomial are cached in fields256 once built.
+var (
+	field256   = galois.NewField256()
+	field65536 = galois.NewField65536()
+	fields256  sync.Map // map[field256Key]*galois.Field256
+)
+
+// field256Key identifies a GF(2^8) field in fields256.
+type field256Key struct {
+	polynomial   uint16
+	constantTime bool
+}
+
 // Split splits a secret of length p into n shares using Shamir secret sharing scheme, such
 // that at least 2 <= k <= n shares (known as the threshold) must be combined in order to recover
 // the secret.
@@ -18,7 +38,7 @@ const minThreshold uint8 = 2
 //
 // All computation is done in the Galois finite field 2^8 - GF(2^8) - as it is convenient for
 // byte-oriented computation, and is the de-facto field used by the AES cipher.
-// The maximum number of shares that can be dealt is the 2^8-1.
+// The maximum number of shares that can be dealt is the 2^8-1 (see Split16 for larger schemes).
 //
 // The secret is processed one byte at a time. Every byte of the secret is split using Shamir's scheme.
 // In a (k,n) Shamir scheme, each byte of the secret yields n "mini-shares".
@@ -37,10 +57,10 @@ const minThreshold uint8 = 2
 // learn anything about the secret. However, Shamir's scheme does leak the size of the secret
 // since the length of the share is p + 1, unless the secret is padded somehow.
 // Padding the secret would still leak the information that the secret is at most the length of the
-// padded secret + 1.
+// padded secret + 1 (see WithPadding).
 //
 // For large secrets, a common approach is to first encrypt the secret using a strong cipher, and to
-// use Shamir secret sharing on the decryption key rather than on the underlying secret.
+// use Shamir secret sharing on the decryption key rather than on the underlying secret (see SealLarge).
 //
 // The algorithm used is as follows:
 //
@@ -53,96 +73,495 @@ const minThreshold uint8 = 2
 //
 // For all participants i, append x[i] to the corresponding column in the share matrix.
 // Recipient i would receive the column [y[0], y[1], ... y[p-1], x[i]].
-// Return the share matrix.
-func Split(secret []byte, n, threshold uint8) [][]byte {
+// Every column is returned as a Share, which also records the threshold and a random identifier
+// of the split (see Share).
+//
+// An error is returned if the parameters of the scheme are invalid, or if the randomness cannot be read.
+func Split(secret []byte, n, threshold uint8, options ...SplitOption) ([]Share, error) {
+	return SplitContext(context.Background(), secret, n, threshold, options...)
+}
+
+// SplitContext splits a secret the same way Split does, so that the splitting of large secrets can be cancelled
+// or bounded by a deadline: if ctx is done before the shares are dealt, the payloads computed so far are cleared
+// and the error of ctx is returned.
+func SplitContext(ctx context.Context, secret []byte, n, threshold uint8, options ...SplitOption) ([]Share, error) {
+	shares := make([]Share, n)
+	if err := splitInto(ctx, shares, secret, threshold, options...); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// SplitInto splits a secret the same way Split does, into len(dst) shares written to dst.
+// The payloads of the shares held by dst are reused when they are large enough, so that services splitting
+// many secrets do not allocate new payloads every time: the shares previously held by dst are overwritten.
+func SplitInto(dst []Share, secret []byte, threshold uint8, options ...SplitOption) error {
+	return splitInto(context.Background(), dst, secret, threshold, options...)
+}
+
+// splitInto implements SplitInto and SplitContext.
+func splitInto(ctx context.Context, dst []Share, secret []byte, threshold uint8, options ...SplitOption) (err error) {
+	var c splitConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if c.wipe {
+		defer Zeroize(secret)
+	}
+	defer func() {
+		if err != nil {
+			trace("split failed", "shares", len(dst), "threshold", threshold, "error", err)
+			return
+		}
+		trace("split", "split", dst[0].SplitID, "shares", len(dst), "threshold", threshold, "padded", c.padding,
+			"polynomial", dst[0].Polynomial)
+	}()
+	field, err := newField256(c.polynomial, c.constantTime)
+	if err != nil {
+		return err
+	}
+	if len(dst) > 255 {
+		return errors.New("shamir: the number of shares to deal cannot be greater than 255")
+	}
+	n := uint8(len(dst))
 	if threshold > n {
-		log.Fatal("the threshold value cannot be greater than the number of shares to deal.")
+		return errors.New("shamir: the threshold cannot be greater than the number of shares to deal")
 	}
 	if len(secret) < minSecretLength {
-		log.Fatal("the secret cannot be empty.")
+		return errors.New("shamir: the secret cannot be empty")
 	}
 	if threshold < minThreshold {
-		log.Fatal("the threshold value must be at least 2.")
+		return errors.New("shamir: the threshold must be at least 2")
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return deal(ctx, &c, field, dst, secret, threshold, pickCoordinates(n), time.Now().UTC().Truncate(time.Second))
+}
 
-	shares := initShareMatrix(n, uint(len(secret)))
-	x := pickCoordinates(n)
-
-	for j, chunk := range secret {
-		polynomial, err := randomPolynomial(threshold)
+// deal splits a validated secret into len(dst) shares, whose coordinates are x and creation time created.
+func deal(ctx context.Context, c *splitConfig, field *galois.Field256, dst []Share, secret []byte, threshold uint8,
+	x []byte, created time.Time) error {
+	if c.roles != nil && len(c.roles) != len(dst) {
+		return fmt.Errorf("shamir: %d roles are required, one for every share, got %d", len(dst), len(c.roles))
+	}
+	var scratch []byte
+	if c.locked {
+		// the padded secret is followed by the coefficients of the polynomials
+		length := len(secret)
+		if c.padding {
+			length = padmeLength(length + 1)
+		}
+		buffer, err := NewLockedBuffer(length * int(threshold))
 		if err != nil {
-			log.Fatalf("failed to generate random polynomial.")
+			return err
 		}
-		// set the polynomial intercept to the secret chunk
-		polynomial[0] = chunk
-		// compute the value of the polynomial for every coordinate x[i]
-		for i := 0; uint8(i) < n; i++ {
-			share := evaluatePolynomial(x[i], polynomial)
-			shares[i][j] = share
+		defer buffer.Destroy()
+		scratch = buffer.Bytes()
+	}
+	if c.padding {
+		secret = padInto(scratch, secret)
+		defer Zeroize(secret)
+		if scratch != nil {
+			scratch = scratch[len(secret):]
 		}
 	}
+	var polynomial uint16
+	if field.Polynomial() != galois.PolynomialAES {
+		polynomial = field.Polynomial()
+	}
+
+	id := newSplitID()
+	values := make([][]byte, len(dst))
+	for i := range dst {
+		dst[i] = Share{
+			Threshold:  threshold,
+			Index:      x[i],
+			SplitID:    id,
+			Payload:    resize(dst[i].Payload, len(secret)),
+			CreatedAt:  created,
+			Padded:     c.padding,
+			Polynomial: polynomial,
+			Policy:     Policy{NotBefore: c.policy.NotBefore, Approvers: slices.Clone(c.policy.Approvers)},
+		}
+		if c.roles != nil {
+			dst[i].Role = c.roles[i]
+		}
+		values[i] = dst[i].Payload
+	}
+	reader := c.random
+	if reader == nil {
+		reader = entropy()
+	}
+	counter := newProgressCounter(c.progress, len(secret))
+	err := evaluate(ctx, field, secret, x, threshold, max(c.workers, 1), reader, values, scratch, counter)
+	if err != nil {
+		zeroizeAll(values)
+		return err
+	}
+	return nil
+}
+
+// SplitOption configures the splitting of a secret.
+type SplitOption func(*splitConfig)
+
+type splitConfig struct {
+	padding      bool
+	polynomial   uint16
+	constantTime bool
+	workers      int
+	random       io.Reader
+	progress     func(Progress)
+	wipe         bool
+	locked       bool
+	policy       Policy
+	roles        []string
+}
+
+// WithPolynomial computes in GF(2^8) using the provided reduction polynomial rather than the AES polynomial, e.g.
+// galois.PolynomialReedSolomon, to interoperate with implementations using another polynomial. The polynomial is
+// recorded in the shares (see Share.Polynomial).
+func WithPolynomial(polynomial uint16) SplitOption {
+	return func(c *splitConfig) {
+		c.polynomial = polynomial
+	}
+}
+
+// WithConstantTime computes in GF(2^8) in constant time (see galois.WithConstantTime), at the expense of speed,
+// so that the secret cannot leak through cache-timing side channels while it is split.
+func WithConstantTime() SplitOption {
+	return func(c *splitConfig) {
+		c.constantTime = true
+	}
+}
+
+// WithRandom draws the coefficients of the polynomials from r rather than the entropy source of the package (see
+// SetEntropySource), e.g. from the random number generator of an HSM (see sharepkcs11.Token.Reader). The
+// coefficients are the only randomness which must be kept secret: the coordinates and the split identifier are
+// still drawn from crypto/rand. Split reads r from a single goroutine, even with WithParallelism, and fails if r
+// does.
+func WithRandom(r io.Reader) SplitOption {
+	return func(c *splitConfig) {
+		c.random = r
+	}
+}
+
+// newField256 returns GF(2^8) using the reduction polynomial, or the AES polynomial if it is 0.
+func newField256(polynomial uint16, constantTime bool) (*galois.Field256, error) {
+	if polynomial == 0 {
+		polynomial = galois.PolynomialAES
+	}
+	if polynomial == galois.PolynomialAES && !constantTime {
+		return field256, nil
+	}
+	key := field256Key{polynomial, constantTime}
+	if field, ok := fields256.Load(key); ok {
+		return field.(*galois.Field256), nil
+	}
+	var options []galois.Option
+	if constantTime {
+		options = append(options, galois.WithConstantTime())
+	}
+	field, err := galois.NewField256WithPolynomial(polynomial, options...)
+	if err != nil {
+		return nil, err
+	}
+	fields256.Store(key, field)
+	return field, nil
+}
+
+// split implements Split without validating the scheme parameters, so that it can be reused by
+// schemes with different requirements (e.g. a threshold of 1 within a group of a two-level scheme).
+// The secret is processed by up to workers goroutines (see WithParallelism).
+func split(field *galois.Field256, secret []byte, n, threshold uint8, workers int) ([][]byte, error) {
+	shares := initShareMatrix(n, uint(len(secret)))
+	x := pickCoordinates(n)
+	values := make([][]byte, n)
+	for i := range shares {
+		values[i] = shares[i][:len(secret)]
+	}
+	if err := evaluate(context.Background(), field, secret, x, threshold, workers, entropy(), values, nil,
+		nil); err != nil {
+		return nil, err
+	}
 
 	// append the point x[i] to each participant's share.
 	for i := 0; uint8(i) < n; i++ {
 		shares[i][len(secret)] = x[i]
 	}
-	return shares
+	return shares, nil
+}
+
+// evaluate picks a random polynomial of the provided order for every byte of the secret, whose intercept is the
+// byte, and writes the values of the polynomials at x[i] to values[i]. The coefficients are read from reader
+// before the polynomials are evaluated by up to workers goroutines: the coefficients of degree 1 of all the bytes
+// first, then those of degree 2, and so on, so that the values do not depend on workers. It stops with the error
+// of ctx once ctx is done, and adds the bytes of the secret evaluated to counter. The coefficients are stored in
+// scratch, which holds at least (threshold-1)*len(secret) bytes, or in memory allocated by evaluate if scratch is
+// nil, and wiped on return.
+func evaluate(ctx context.Context, field *galois.Field256, secret, x []byte, threshold uint8, workers int,
+	reader io.Reader, values [][]byte, scratch []byte, counter *progressCounter) error {
+	// the polynomials of all the bytes of the secret are processed at once: coefficients[d][j] is the coefficient
+	// of degree d of the polynomial of the jth byte, whose intercept is the byte itself
+	coefficients := make([][]byte, threshold)
+	coefficients[0] = secret
+	if scratch == nil {
+		scratch = make([]byte, (int(threshold)-1)*len(secret))
+	}
+	for d := 1; d < int(threshold); d++ {
+		coefficients[d] = scratch[(d-1)*len(secret) : d*len(secret)]
+	}
+	defer zeroizeAll(coefficients[1:])
+	for d := 1; d < int(threshold); d++ {
+		err := forBlocks(ctx, nil, 0, len(secret), func(start, end int) error {
+			if err := random.ReadFull(reader, coefficients[d][start:end]); err != nil {
+				return fmt.Errorf("shamir: failed to generate random polynomial: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return parallelize(len(secret), workers, func(start, end int) error {
+		// compute the value of the polynomials for every coordinate x[i], using Horner's algorithm on whole slices
+		return forBlocks(ctx, counter, start, end, func(start, end int) error {
+			for i := range x {
+				chunk := values[i][start:end]
+				copy(chunk, coefficients[threshold-1][start:end])
+				for d := int(threshold) - 2; d >= 0; d-- {
+					field.MulSlice(x[i], chunk, chunk)
+					field.AddSlice(coefficients[d][start:end], chunk)
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// RecoverOption configures the recovery of a secret.
+type RecoverOption func(*recoverConfig)
+
+type recoverConfig struct {
+	dealerKey    ed25519.PublicKey
+	constantTime bool
+	workers      int
+	progress     func(Progress)
+	wipe         bool
+	mixedSplits  bool
+	roles        RoleQuorum
+}
+
+// ErrMixedSplits is returned when shares of different splits are combined, which would silently recover garbage.
+var ErrMixedSplits = errors.New("shamir: the shares belong to different splits")
+
+// WithMixedSplits combines shares whatever their split identifier, e.g. shares whose identifier was lost when
+// converted from another format. The secret recovered is garbage unless the shares were dealt by the same split.
+func WithMixedSplits() RecoverOption {
+	return func(c *recoverConfig) {
+		c.mixedSplits = true
+	}
+}
+
+// WithDealerKey requires every share to be signed by the dealer, and checks the signatures using the public key
+// of the dealer (see Sign).
+func WithDealerKey(key ed25519.PublicKey) RecoverOption {
+	return func(c *recoverConfig) {
+		c.dealerKey = key
+	}
+}
+
+// WithConstantTimeRecovery computes in GF(2^8) in constant time while recovering the secret (see WithConstantTime).
+func WithConstantTimeRecovery() RecoverOption {
+	return func(c *recoverConfig) {
+		c.constantTime = true
+	}
 }
 
 // Recover takes shares as input and combines them using Lagrange's interpolation in order to
 // reconstruct the secret.
-// All shares must be the same size and are assumed to follow the structure provided by the Split
-// function: [y[0], ..., y[p-1],x[i]].
-func Recover(shares [][]byte) []byte {
+// All shares must be the same size and are assumed to be produced by the Split function.
+// An error is returned if the shares cannot be combined, if fewer shares than their threshold are provided, and
+// ErrMixedSplits if they belong to different splits (see WithMixedSplits).
+// When more shares than their threshold are provided, they must all lie on the same polynomials, or an
+// InconsistentSharesError identifying the suspect shares is returned.
+func Recover(shares []Share, options ...RecoverOption) ([]byte, error) {
+	return recoverInto(context.Background(), nil, shares, options...)
+}
+
+// RecoverContext recovers a secret the same way Recover does, so that the recovery of large secrets can be
+// cancelled or bounded by a deadline: if ctx is done before the secret is recovered, the part of the secret
+// recovered so far is cleared and the error of ctx is returned.
+func RecoverContext(ctx context.Context, shares []Share, options ...RecoverOption) ([]byte, error) {
+	return recoverInto(ctx, nil, shares, options...)
+}
+
+// RecoverInto recovers a secret the same way Recover does, reusing the dst buffer to store the secret when it
+// is large enough. It returns the secret, which is a prefix of dst if dst was reused. dst must not overlap the
+// payloads of the shares.
+func RecoverInto(dst []byte, shares []Share, options ...RecoverOption) ([]byte, error) {
+	return recoverInto(context.Background(), dst, shares, options...)
+}
+
+// recoverInto implements RecoverInto and RecoverContext.
+func recoverInto(ctx context.Context, dst []byte, shares []Share, options ...RecoverOption) (secret []byte,
+	err error) {
+	var c recoverConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if c.wipe {
+		defer func() {
+			for _, share := range shares {
+				Zeroize(share.Payload)
+			}
+		}()
+	}
+	defer func() {
+		if err != nil {
+			trace("recovery failed", "shares", len(shares), "error", err)
+			return
+		}
+		trace("recovered", "split", shares[0].SplitID, "shares", len(shares))
+	}()
+	if err := checkShares(shares, &c); err != nil {
+		return nil, err
+	}
+	secret, err = combineRange(ctx, dst, shares, 0, len(shares[0].Payload), &c)
+	if err != nil {
+		return nil, err
+	}
+	if shares[0].Padded {
+		unpadded, err := unpad(secret)
+		if err != nil {
+			Zeroize(secret)
+			return nil, err
+		}
+		secret = unpadded
+	}
+	return secret, nil
+}
+
+// checkShares checks that the shares can be combined with the options of the recovery.
+func checkShares(shares []Share, c *recoverConfig) error {
 	if len(shares) < int(minThreshold) {
-		log.Fatal("the number of shares provided is below the minimum threshold.")
+		return errors.New("shamir: the number of shares provided is below the minimum threshold")
 	}
-	shareLength := len(shares[0])
-	for _, share := range shares {
-		if len(share) != shareLength {
-			log.Fatal("all shares must be the same length.")
+	// the shares record the threshold of their split, except legacy shares: fewer shares would silently recover
+	// an unrelated secret
+	if threshold := int(shares[0].Threshold); len(shares) < threshold {
+		return fmt.Errorf("shamir: %d shares are required to recover the secret, got %d", threshold, len(shares))
+	}
+	shareLength := len(shares[0].Payload)
+	for i, share := range shares {
+		if len(share.Payload) != shareLength {
+			return errors.New("shamir: all shares must be the same length")
+		}
+		// the Lagrange basis divides by the differences of the coordinates, which must not be 0
+		for _, other := range shares[:i] {
+			if share.Index == other.Index {
+				return errors.New("shamir: all shares must have distinct indexes")
+			}
+		}
+		if share.Polynomial != shares[0].Polynomial {
+			return errors.New("shamir: all shares must use the same reduction polynomial")
+		}
+		if share.SplitID != shares[0].SplitID && !c.mixedSplits {
+			return ErrMixedSplits
 		}
+		if c.dealerKey != nil && Verify(share, c.dealerKey) != nil {
+			return errors.New("shamir: the signature of a share is missing or invalid")
+		}
+	}
+	if c.roles != nil {
+		return c.roles.Check(shares)
 	}
+	return nil
+}
+
+// combineRange reconstructs the bytes of the secret from offset to end using the checked shares, reusing the dst
+// buffer when it is large enough.
+func combineRange(ctx context.Context, dst []byte, shares []Share, offset, end int, c *recoverConfig) ([]byte,
+	error) {
+	field, err := newField256(shares[0].Polynomial, c.constantTime)
+	if err != nil {
+		return nil, err
+	}
+	ranged := make([]Share, len(shares))
+	for i, share := range shares {
+		ranged[i] = share
+		ranged[i].Payload = share.Payload[offset:end]
+	}
+	if err := crossCheck(field, ranged); err != nil {
+		return nil, err
+	}
+	coordinates := make([]byte, len(shares))
+	values := make([][]byte, len(shares))
+	for i, share := range ranged {
+		coordinates[i], values[i] = share.Index, share.Payload
+	}
+	secret := resize(dst, end-offset)
+	counter := newProgressCounter(c.progress, end-offset)
+	if err := combineInto(ctx, field, secret, coordinates, values, max(c.workers, 1), counter); err != nil {
+		Zeroize(secret)
+		return nil, err
+	}
+	return secret, nil
+}
+
+// combine implements Recover without validating the shares.
+// The shares follow the structure of the share matrix: [y[0], ..., y[p-1], x[i]].
+// The secret is recovered by up to workers goroutines (see WithParallelRecovery).
+func combine(field *galois.Field256, shares [][]byte, workers int) []byte {
+	shareLength := len(shares[0])
 
 	// buffer to store the recovered secret
 	secret := make([]byte, shareLength-1)
 
 	// buffer to store the participant coordinates (the last component of each participant's share)
 	coordinates := make([]byte, len(shares))
+	values := make([][]byte, len(shares))
 	for i, share := range shares {
-		coordinates[i] = share[shareLength-1]
+		coordinates[i], values[i] = share[shareLength-1], share[:shareLength-1]
 	}
-
-	// recover the secret byte by byte
-	for j := range secret {
-		// buffer to store the values of the polynomial provided by the participant's shares
-		values := make([]byte, len(shares))
-		for i, share := range shares {
-			values[i] = share[j]
-		}
-		secret[j] = interpolatePolynomial(coordinates, values, 0)
-	}
-
+	// cannot fail, as the context is never done
+	combineInto(context.Background(), field, secret, coordinates, values, workers, nil)
 	return secret
 }
 
-// randomPolynomial generates a polynomial of the provided order with random coefficients in GF(2^8)
-// In the context of a (k,n) Shamir scheme, the polynomial order must be k. As we use GF(2^8),
-// the maximum polynomial order is the maximum number of distributable shares, that is 2^8-1.
-func randomPolynomial(order uint8) ([]byte, error) {
-	coefficients := make([]byte, order)
-	_, err := rand.Read(coefficients[1:])
-	if err != nil {
-		return nil, err
+// combineInto interpolates the polynomials going through the points (coordinates[i], values[i][j]) at 0, and
+// writes the results to secret[j]. It stops with the error of ctx once ctx is done, and adds the bytes of the
+// secret recovered to counter.
+func combineInto(ctx context.Context, field *galois.Field256, secret, coordinates []byte, values [][]byte,
+	workers int, counter *progressCounter) error {
+	clear(secret)
+	// the coordinates are the same for every byte of the secret, so that the Lagrange basis polynomials evaluated
+	// at 0 are computed once. Every byte of the secret is then the sum of basis[i]*y[i] over the participants i,
+	// which is computed for all the bytes at once.
+	basis := galois.LagrangeBasis(field, coordinates, 0)
+	return parallelize(len(secret), workers, func(start, end int) error {
+		return forBlocks(ctx, counter, start, end, func(start, end int) error {
+			for i := range values {
+				field.MulAddSlice(basis[i], values[i][start:end], secret[start:end])
+			}
+			return nil
+		})
+	})
+}
+
+// resize returns a slice of length n, reusing the buffer if it is large enough.
+func resize(buffer []byte, n int) []byte {
+	if cap(buffer) < n {
+		return make([]byte, n)
 	}
-	return coefficients, nil
+	return buffer[:n]
 }
 
 // pickCoordinates picks n distinct point in GF(2^8).
 // As we operate in GF(2^8), it holds that 0 <= n <= 255.
 func pickCoordinates(n uint8) []byte {
 	coordinates := make([]byte, 255, 255)
-	permutation := random.PermSecure(255)
+	permutation := random.Perm(255)
 	for i, x := range permutation {
 		// +1 since 0 cannot be picked as it corresponds to the secret
 		coordinates[i] = byte(x + 1)
@@ -150,24 +569,6 @@ func pickCoordinates(n uint8) []byte {
 	return coordinates[0:n]
 }
 
-// evaluatePolynomial computes the value of a polynomial at point x, using Horner's algorithm.
-// computation is performed in GF(2^8).
-func evaluatePolynomial(x byte, polynomial []byte) byte {
-	if x == 0 {
-		return polynomial[0]
-	}
-
-	degree := len(polynomial) - 1
-	// initialize Horner's algorithm with the nth coefficient of the polynomial
-	// https://en.wikipedia.org/wiki/Horner%27s_method
-	value := polynomial[degree]
-	field := galois.NewField256()
-	for i := degree - 1; i >= 0; i-- {
-		value = field.Add(polynomial[i], field.Multiply(value, x))
-	}
-	return value
-}
-
 // initShareMatrix initializes an empty share matrix.
 // the matrix is of dimensions [(secretLength+1) * n].
 func initShareMatrix(n uint8, secretLength uint) [][]byte {
@@ -177,30 +578,3 @@ func initShareMatrix(n uint8, secretLength uint) [][]byte {
 	}
 	return matrix
 }
-
-// interpolatePolynomial interpolates a polynomial using Lagrange's algorithm.
-// computation is performed in GF(2^8).
-// x and y are vectors holding coordinates and corresponding values to interpolate the polynomial.
-// the function return the value of the polynomial evaluated at z.
-func interpolatePolynomial(x, y []byte, z uint8) byte {
-	// maximum order of the polynomial
-	order := len(x)
-	var result uint8
-	field := galois.NewField256()
-
-	for i := 0; i < order; i++ {
-		// compute Lagrange's basis ith polynomial value at point z
-		var basis uint8 = 1
-		for j := 0; j < order; j++ {
-			if j != i {
-				numerator := field.Add(z, x[j])
-				denominator := field.Add(x[i], x[j])
-				basis = field.Multiply(basis, field.Divide(numerator, denominator))
-			} else {
-				continue
-			}
-		}
-		result = field.Add(field.Multiply(basis, y[i]), result)
-	}
-	return result
-}
@@ -0,0 +1,113 @@
+package shamir
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// benchmarkLengths are the lengths of the secrets split and recovered by the benchmarks.
+var benchmarkLengths = []int{32, 1 << 10, 64 << 10}
+
+// benchmarkFields returns the field instances compared by the benchmarks: the instance shared by all the calls,
+// and a new instance built for every call.
+func benchmarkFields() []struct {
+	name  string
+	field func() *galois.Field256
+} {
+	return []struct {
+		name  string
+		field func() *galois.Field256
+	}{
+		{"shared", func() *galois.Field256 { return field256 }},
+		{"per-call", func() *galois.Field256 { return galois.NewField256() }},
+	}
+}
+
+func TestSplitRecover(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := Recover(shares[1:4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, secret) {
+		t.Errorf("Recover() = %q, want %q", recovered, secret)
+	}
+}
+
+func TestRecoverBelowThreshold(t *testing.T) {
+	shares, err := Split([]byte("correct horse battery staple"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Recover(shares[:2]); err == nil {
+		t.Error("Recover() succeeded with fewer shares than the threshold")
+	}
+	shares16, err := Split16([]byte("correct horse battery staple"), 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Recover16(shares16[:2]); err == nil {
+		t.Error("Recover16() succeeded with fewer shares than the threshold")
+	}
+}
+
+func BenchmarkSplit(b *testing.B) {
+	for _, length := range benchmarkLengths {
+		secret := bytes.Repeat([]byte{0xa5}, length)
+		for _, f := range benchmarkFields() {
+			b.Run(fmt.Sprintf("%s/%dB", f.name, length), func(b *testing.B) {
+				b.SetBytes(int64(length))
+				for b.Loop() {
+					if _, err := split(f.field(), secret, 5, 3, 1); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+		b.Run(fmt.Sprintf("Split/%dB", length), func(b *testing.B) {
+			b.SetBytes(int64(length))
+			for b.Loop() {
+				if _, err := Split(secret, 5, 3); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRecover(b *testing.B) {
+	for _, length := range benchmarkLengths {
+		secret := bytes.Repeat([]byte{0xa5}, length)
+		matrix, err := split(field256, secret, 5, 3, 1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, f := range benchmarkFields() {
+			b.Run(fmt.Sprintf("%s/%dB", f.name, length), func(b *testing.B) {
+				b.SetBytes(int64(length))
+				for b.Loop() {
+					combine(f.field(), matrix[:3], 1)
+				}
+			})
+		}
+		shares, err := Split(secret, 5, 3)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("Recover/%dB", length), func(b *testing.B) {
+			b.SetBytes(int64(length))
+			for b.Loop() {
+				if _, err := Recover(shares[:3]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
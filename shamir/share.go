@@ -0,0 +1,409 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"slices"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// Shares are serialized using a versioned binary envelope, so that new fields can be introduced without
+// breaking the shares already dealt. All integers are big-endian.
+//
+// 	offset  size  field
+// 	0       4     magic bytes "SHMR"
+// 	4       1     format version
+// 	5       1     flags, see below
+// 	6       1     threshold, 0 if unknown
+// 	7       1     share index, i.e. the coordinate x[i] of the participant
+// 	8       16    split identifier (UUID)
+// 	24      4     payload length p
+// 	28      p     payload, i.e. the values [y[0], ..., y[p-1]]
+// 	28+p    4     CRC-32C of all the preceding bytes
+//
+// When the polynomial flag (0x08) is set, the payload is followed by the low byte of the reduction polynomial of
+// GF(2^8), see WithPolynomial.
+//
+// When the metadata flag (0x01) is set, the following fields are inserted before the CRC:
+//
+// 	8     creation time, in seconds since the Unix epoch (0 if unknown)
+// 	1     label length l
+// 	l     label, UTF-8 encoded
+//
+// When the policy flag (0x10) is set, the release policy of the share (see Policy) is inserted before the CRC,
+// after the metadata:
+//
+// 	8     not-before time, in seconds since the Unix epoch (0 if unset)
+// 	1     number of approvers a
+// 	      for each of the a approvers: 1 byte of length l, and the l bytes of its identity
+//
+// When the role flag (0x20) is set, the role of the share (see WithRoles) is inserted before the CRC, after the
+// policy: 1 byte of length r, and the r bytes of the role.
+//
+// When the signature flag (0x02) is set, the Ed25519 signature of the dealer (64 bytes) is inserted before
+// the CRC, see Sign. The padded flag (0x04) is set when the secret was padded before being split, see WithPadding.
+//
+// Shares dealt before the envelope was introduced are the raw column of the share matrix,
+// [y[0], ..., y[p-1], x[i]], and can still be read using ParseLegacy.
+
+// Version is the current version of the binary share format.
+const Version uint8 = 1
+
+const (
+	headerLength = 28
+	crcLength    = 4
+	// flagMetadata is set when the creation time and label of the share are encoded
+	flagMetadata uint8 = 1 << 0
+	// flagSignature is set when the signature of the dealer is encoded
+	flagSignature uint8 = 1 << 1
+	// flagPadded is set when the secret was padded before being split
+	flagPadded uint8 = 1 << 2
+	// flagPolynomial is set when the secret was split using another reduction polynomial than the AES one
+	flagPolynomial uint8 = 1 << 3
+	// flagPolicy is set when the release policy of the share is encoded
+	flagPolicy uint8 = 1 << 4
+	// flagRole is set when the role of the share is encoded
+	flagRole uint8 = 1 << 5
+	// knownFlags holds the flags understood by this version of the package
+	knownFlags = flagMetadata | flagSignature | flagPadded | flagPolynomial | flagPolicy | flagRole
+	// maxLabelLength is the maximum length in bytes of the label of a share
+	maxLabelLength = 255
+)
+
+var magic = []byte("SHMR")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// ErrInvalidFormat is returned when decoding a share that does not follow the binary format.
+	ErrInvalidFormat = errors.New("shamir: invalid share format")
+	// ErrUnsupportedVersion is returned when decoding a share using a format version or flags
+	// unknown to this package.
+	ErrUnsupportedVersion = errors.New("shamir: unsupported share format version")
+	// ErrChecksum is returned when the checksum of a share does not match its content.
+	ErrChecksum = errors.New("shamir: invalid share checksum")
+)
+
+// SplitID identifies the shares dealt by a single call to Split.
+// It is a random (version 4) UUID.
+type SplitID [16]byte
+
+// String formats the identifier as a UUID.
+func (id SplitID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf[:])
+}
+
+// ParseSplitID parses a split identifier formatted as a UUID.
+func ParseSplitID(s string) (SplitID, error) {
+	var id SplitID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return id, errors.New("shamir: invalid split identifier")
+	}
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(id[:], []byte(digits)); err != nil {
+		return id, errors.New("shamir: invalid split identifier")
+	}
+	return id, nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (id SplitID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (id *SplitID) UnmarshalText(text []byte) error {
+	parsed, err := ParseSplitID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// newSplitID generates a random split identifier.
+func newSplitID() SplitID {
+	var id SplitID
+	copy(id[:], random.Bytes(len(id)))
+	// set the version (4) and variant (RFC 4122) bits
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return id
+}
+
+// Share is the share of a secret dealt to a single participant.
+type Share struct {
+	// Threshold is the number of shares required to recover the secret, or 0 if unknown.
+	Threshold uint8
+	// Index is the coordinate x[i] used to evaluate the polynomials for the participant.
+	Index uint8
+	// SplitID identifies the shares dealt along with this share, or is zero if unknown.
+	SplitID SplitID
+	// Payload holds the values of the polynomials at x[i], one for every byte of the secret.
+	Payload []byte
+	// CreatedAt is the time the share was dealt, or zero if unknown.
+	CreatedAt time.Time
+	// Label is a free-form description of the share, e.g. the name of its custodian.
+	// It is limited to 255 bytes.
+	Label string
+	// Signature is the Ed25519 signature of the share by the dealer (see Sign), or nil if the share is not signed.
+	Signature []byte
+	// Padded is true if the secret was padded before being split (see WithPadding).
+	Padded bool
+	// Polynomial is the reduction polynomial of GF(2^8) used to split the secret (see WithPolynomial),
+	// or 0 for the AES polynomial.
+	Polynomial uint16
+	// Policy is the release policy of the share (see WithReleasePolicy), enforced by recovery coordinators.
+	Policy Policy
+	// Role is the role of the share, e.g. the team of its custodian (see WithRoles), or empty.
+	// It is limited to 255 bytes.
+	Role string
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s Share) MarshalBinary() ([]byte, error) {
+	if len(s.Label) > maxLabelLength {
+		return nil, errors.New("shamir: the label of a share cannot exceed 255 bytes")
+	}
+	var flags uint8
+	if !s.CreatedAt.IsZero() || s.Label != "" {
+		flags |= flagMetadata
+	}
+	if s.Signature != nil {
+		if len(s.Signature) != ed25519.SignatureSize {
+			return nil, errors.New("shamir: invalid share signature length")
+		}
+		flags |= flagSignature
+	}
+	if s.Padded {
+		flags |= flagPadded
+	}
+	if s.Polynomial != 0 {
+		if s.Polynomial&0xff00 != 0x100 {
+			return nil, errors.New("shamir: invalid reduction polynomial")
+		}
+		flags |= flagPolynomial
+	}
+	if !s.Policy.IsZero() {
+		if err := s.Policy.validate(); err != nil {
+			return nil, err
+		}
+		flags |= flagPolicy
+	}
+	if s.Role != "" {
+		if len(s.Role) > maxRoleLength {
+			return nil, errors.New("shamir: the role of a share cannot exceed 255 bytes")
+		}
+		flags |= flagRole
+	}
+
+	data := make([]byte, headerLength, headerLength+len(s.Payload)+10+len(s.Label)+len(s.Signature)+crcLength)
+	copy(data, magic)
+	data[4] = Version
+	data[5] = flags
+	data[6] = s.Threshold
+	data[7] = s.Index
+	copy(data[8:24], s.SplitID[:])
+	binary.BigEndian.PutUint32(data[24:28], uint32(len(s.Payload)))
+	data = append(data, s.Payload...)
+	if flags&flagPolynomial != 0 {
+		data = append(data, uint8(s.Polynomial))
+	}
+	if flags&flagMetadata != 0 {
+		var created int64
+		if !s.CreatedAt.IsZero() {
+			created = s.CreatedAt.Unix()
+		}
+		data = binary.BigEndian.AppendUint64(data, uint64(created))
+		data = append(data, uint8(len(s.Label)))
+		data = append(data, s.Label...)
+	}
+	if flags&flagPolicy != 0 {
+		data = s.Policy.appendBinary(data)
+	}
+	if flags&flagRole != 0 {
+		data = append(data, uint8(len(s.Role)))
+		data = append(data, s.Role...)
+	}
+	data = append(data, s.Signature...)
+	return binary.BigEndian.AppendUint32(data, crc32.Checksum(data, castagnoli)), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *Share) UnmarshalBinary(data []byte) error {
+	if len(data) < headerLength+crcLength || !bytes.Equal(data[:4], magic) {
+		return ErrInvalidFormat
+	}
+	if data[4] != Version || data[5]&^knownFlags != 0 {
+		return ErrUnsupportedVersion
+	}
+	end := len(data) - crcLength
+	if crc32.Checksum(data[:end], castagnoli) != binary.BigEndian.Uint32(data[end:]) {
+		return ErrChecksum
+	}
+	length := binary.BigEndian.Uint32(data[24:28])
+	if uint64(length) > uint64(end-headerLength) {
+		return ErrInvalidFormat
+	}
+	payloadEnd := headerLength + int(length)
+
+	var signature []byte
+	if data[5]&flagSignature != 0 {
+		if end-payloadEnd < ed25519.SignatureSize {
+			return ErrInvalidFormat
+		}
+		end -= ed25519.SignatureSize
+		signature = append([]byte{}, data[end:end+ed25519.SignatureSize]...)
+	}
+
+	var polynomial uint16
+	metadataStart := payloadEnd
+	if data[5]&flagPolynomial != 0 {
+		if metadataStart >= end {
+			return ErrInvalidFormat
+		}
+		polynomial = 0x100 | uint16(data[metadataStart])
+		metadataStart++
+	}
+
+	var created time.Time
+	var label string
+	rest := data[metadataStart:end]
+	if data[5]&flagMetadata != 0 {
+		if len(rest) < 9 || len(rest) < 9+int(rest[8]) {
+			return ErrInvalidFormat
+		}
+		if seconds := int64(binary.BigEndian.Uint64(rest)); seconds != 0 {
+			created = time.Unix(seconds, 0).UTC()
+		}
+		label = string(rest[9 : 9+int(rest[8])])
+		rest = rest[9+int(rest[8]):]
+	}
+	var policy Policy
+	if data[5]&flagPolicy != 0 {
+		var err error
+		if policy, rest, err = parsePolicy(rest); err != nil {
+			return err
+		}
+	}
+	var role string
+	if data[5]&flagRole != 0 {
+		if len(rest) < 1 || rest[0] == 0 || len(rest) < 1+int(rest[0]) {
+			return ErrInvalidFormat
+		}
+		role = string(rest[1 : 1+int(rest[0])])
+		rest = rest[1+int(rest[0]):]
+	}
+	if len(rest) != 0 {
+		return ErrInvalidFormat
+	}
+
+	s.Threshold = data[6]
+	s.Index = data[7]
+	copy(s.SplitID[:], data[8:24])
+	s.Payload = append([]byte{}, data[headerLength:payloadEnd]...)
+	s.CreatedAt = created
+	s.Label = label
+	s.Signature = signature
+	s.Padded = data[5]&flagPadded != 0
+	s.Polynomial = polynomial
+	s.Policy = policy
+	s.Role = role
+	return nil
+}
+
+// ParseLegacy parses a share in the raw format used before the binary envelope was introduced,
+// i.e. [y[0], ..., y[p-1], x[i]]. The threshold and the split identifier of such shares are unknown.
+func ParseLegacy(raw []byte) (Share, error) {
+	if len(raw) < minSecretLength+1 {
+		return Share{}, ErrInvalidFormat
+	}
+	return Share{
+		Index:   raw[len(raw)-1],
+		Payload: append([]byte{}, raw[:len(raw)-1]...),
+	}, nil
+}
+
+// ParseShare decodes a share whatever the version of its format: the shares starting with the magic bytes of the
+// binary format are decoded with UnmarshalBinary, and the others are parsed as legacy shares, see ParseLegacy.
+func ParseShare(data []byte) (Share, error) {
+	if bytes.HasPrefix(data, magic) {
+		var s Share
+		err := s.UnmarshalBinary(data)
+		return s, err
+	}
+	return ParseLegacy(data)
+}
+
+// ResolveLegacy completes the legacy shares, whose threshold and split identifier are unknown, with the metadata
+// of the first share of the binary format, so that a split can be recovered from a mix of legacy shares and shares
+// of the binary format. The shares are assumed to belong to the same split. The shares passed are not modified.
+func ResolveLegacy(shares []Share) []Share {
+	i := slices.IndexFunc(shares, func(s Share) bool { return !s.isLegacy() })
+	if i < 0 {
+		return shares
+	}
+	reference := shares[i]
+	resolved := slices.Clone(shares)
+	for i, share := range resolved {
+		if share.isLegacy() {
+			resolved[i].Threshold = reference.Threshold
+			resolved[i].SplitID = reference.SplitID
+			resolved[i].Padded = reference.Padded
+			resolved[i].Polynomial = reference.Polynomial
+		}
+	}
+	return resolved
+}
+
+// isLegacy reports whether the share was parsed from the legacy raw format.
+func (s Share) isLegacy() bool {
+	return s.Threshold == 0 && s.SplitID == SplitID{}
+}
+
+// Legacy returns the share in the raw format used before the binary envelope was introduced.
+func (s Share) Legacy() []byte {
+	return append(append(make([]byte, 0, len(s.Payload)+1), s.Payload...), s.Index)
+}
+
+// newShares converts the columns of a share matrix into shares, identified by a new split identifier.
+func newShares(matrix [][]byte, threshold uint8) []Share {
+	id := newSplitID()
+	created := time.Now().UTC().Truncate(time.Second)
+	shares := make([]Share, len(matrix))
+	for i, column := range matrix {
+		shares[i] = Share{
+			Threshold: threshold,
+			Index:     column[len(column)-1],
+			SplitID:   id,
+			Payload:   column[:len(column)-1],
+			CreatedAt: created,
+		}
+	}
+	return shares
+}
+
+// shareMatrix converts shares back to the columns of a share matrix.
+func shareMatrix(shares []Share) [][]byte {
+	matrix := make([][]byte, len(shares))
+	for i, share := range shares {
+		matrix[i] = share.Legacy()
+	}
+	return matrix
+}
@@ -0,0 +1,175 @@
+package shamir
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Shares and share sets implement the standard marshaling interfaces, so that they can be stored and parsed
+// without conversion code, e.g. by encoding/json, flag.TextVar or database/sql:
+//
+// 	- the binary encoding of a share is the binary format (see share.go), and the binary encoding of a share set
+// 	  is the sequence of the binary encodings of its shares, each preceded by its length on 4 bytes
+// 	- the text encoding of a share is the standard base64 encoding of its binary encoding, and the text encoding
+// 	  of a share set is the comma-separated text encodings of its shares
+// 	- shares and share sets are stored by database drivers in their binary encoding, and can be scanned from their
+// 	  binary or text encoding
+//
+// Shares and share sets are encoded in JSON as described in json.go, a share set being an array of shares.
+
+// ShareSet holds shares, usually of the same split.
+type ShareSet []Share
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s Share) MarshalText() ([]byte, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.AppendEncode(nil, data), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *Share) UnmarshalText(text []byte) error {
+	data, err := base64.StdEncoding.AppendDecode(nil, text)
+	if err != nil {
+		return ErrInvalidFormat
+	}
+	return s.UnmarshalBinary(data)
+}
+
+// Value implements the driver.Valuer interface, returning the binary encoding of the share.
+func (s Share) Value() (driver.Value, error) {
+	return s.MarshalBinary()
+}
+
+// Scan implements the sql.Scanner interface, decoding the binary or text encoding of a share.
+func (s *Share) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		return s.UnmarshalBinary(src)
+	case string:
+		return s.UnmarshalText([]byte(src))
+	}
+	return fmt.Errorf("shamir: cannot scan a share from %T", src)
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (set ShareSet) MarshalBinary() ([]byte, error) {
+	var data []byte
+	for _, share := range set {
+		encoded, err := share.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = binary.BigEndian.AppendUint32(data, uint32(len(encoded)))
+		data = append(data, encoded...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (set *ShareSet) UnmarshalBinary(data []byte) error {
+	var shares ShareSet
+	for len(data) > 0 {
+		if len(data) < 4 || uint64(len(data)-4) < uint64(binary.BigEndian.Uint32(data)) {
+			return ErrInvalidFormat
+		}
+		length := int(binary.BigEndian.Uint32(data))
+		var share Share
+		if err := share.UnmarshalBinary(data[4 : 4+length]); err != nil {
+			return err
+		}
+		shares = append(shares, share)
+		data = data[4+length:]
+	}
+	*set = shares
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (set ShareSet) MarshalText() ([]byte, error) {
+	var text []byte
+	for i, share := range set {
+		if i > 0 {
+			text = append(text, ',')
+		}
+		encoded, err := share.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		text = append(text, encoded...)
+	}
+	return text, nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (set *ShareSet) UnmarshalText(text []byte) error {
+	var shares ShareSet
+	if len(text) > 0 {
+		for encoded := range strings.SplitSeq(string(text), ",") {
+			var share Share
+			if err := share.UnmarshalText([]byte(strings.TrimSpace(encoded))); err != nil {
+				return err
+			}
+			shares = append(shares, share)
+		}
+	}
+	*set = shares
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding the set as an array of shares.
+func (set ShareSet) MarshalJSON() ([]byte, error) {
+	if set == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Share(set))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (set *ShareSet) UnmarshalJSON(data []byte) error {
+	var shares []Share
+	if err := json.Unmarshal(data, &shares); err != nil {
+		return err
+	}
+	*set = shares
+	return nil
+}
+
+// String formats the set without the payloads of the shares, such as shares[n=3 k=5 fp=ab12cd34 REDACTED], where
+// fp is the fingerprint of the set (see SetFingerprint), omitted if the shares belong to different splits.
+func (set ShareSet) String() string {
+	var threshold uint8
+	if len(set) > 0 {
+		threshold = set[0].Threshold
+	}
+	fp, err := SetFingerprint(set)
+	if err != nil {
+		return fmt.Sprintf("shares[n=%d k=%d REDACTED]", len(set), threshold)
+	}
+	return fmt.Sprintf("shares[n=%d k=%d fp=%s REDACTED]", len(set), threshold, fp)
+}
+
+// Value implements the driver.Valuer interface, returning the binary encoding of the set.
+func (set ShareSet) Value() (driver.Value, error) {
+	return set.MarshalBinary()
+}
+
+// Scan implements the sql.Scanner interface, decoding the binary or text encoding of a set.
+func (set *ShareSet) Scan(src any) error {
+	switch src := src.(type) {
+	case []byte:
+		return set.UnmarshalBinary(src)
+	case string:
+		return set.UnmarshalText([]byte(src))
+	case nil:
+		*set = nil
+		return nil
+	}
+	return fmt.Errorf("shamir: cannot scan a share set from %T", src)
+}
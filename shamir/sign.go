@@ -0,0 +1,93 @@
+package shamir
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+)
+
+// The dealer can sign every share, and the manifest of the split, using an Ed25519 key, so that custodians can
+// detect tampered or forged shares before a recovery ceremony.
+//
+// The signature of a share covers its binary encoding without the signature (see Share.MarshalBinary), that is
+// its parameters, payload and metadata. The signature of a manifest covers its JSON encoding without the
+// signature. Both are prefixed with a domain separation string.
+
+const (
+	shareSignatureDomain    = "shamir-sss share signature\x00"
+	manifestSignatureDomain = "shamir-sss manifest signature\x00"
+)
+
+// ErrInvalidSignature is returned when the signature of a share or a manifest is missing or invalid.
+var ErrInvalidSignature = errors.New("shamir: invalid dealer signature")
+
+// Sign returns a copy of the share signed with the private key of the dealer.
+func Sign(share Share, key ed25519.PrivateKey) (Share, error) {
+	message, err := share.signedMessage()
+	if err != nil {
+		return Share{}, err
+	}
+	share.Signature = ed25519.Sign(key, message)
+	return share, nil
+}
+
+// Verify checks the signature of a share using the public key of the dealer.
+func Verify(share Share, key ed25519.PublicKey) error {
+	if len(share.Signature) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+	message, err := share.signedMessage()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, message, share.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signedMessage returns the message signed by the dealer for the share.
+func (s Share) signedMessage() ([]byte, error) {
+	s.Signature = nil
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(shareSignatureDomain), data...), nil
+}
+
+// Sign signs the manifest with the private key of the dealer.
+func (m *Manifest) Sign(key ed25519.PrivateKey) error {
+	message, err := m.signedMessage()
+	if err != nil {
+		return err
+	}
+	m.Signature = ed25519.Sign(key, message)
+	return nil
+}
+
+// Verify checks the signature of the manifest using the public key of the dealer.
+func (m *Manifest) Verify(key ed25519.PublicKey) error {
+	if len(m.Signature) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+	message, err := m.signedMessage()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, message, m.Signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signedMessage returns the message signed by the dealer for the manifest.
+func (m *Manifest) signedMessage() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = nil
+	data, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(manifestSignatureDomain), data...), nil
+}
@@ -0,0 +1,286 @@
+package shamir
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/galois"
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// GF(2^8) limits a scheme to 255 shares. Large consortiums can use Split16 instead, which computes in GF(2^16)
+// and deals up to 65535 shares. The secret is processed two bytes at a time, and the coordinate of every
+// participant is two bytes long.
+//
+// The secret is always followed by a 0x80 byte and zero bytes up to an even length (see WithPadding, which
+// is also supported), and the padding is removed by Recover16.
+//
+// Such shares do not fit in the GF(2^8) share format and use version 2 of the binary format, in which the
+// threshold and the share index are 2 bytes long:
+//
+// 	offset  size  field
+// 	0       4     magic bytes "SHMR"
+// 	4       1     format version (2)
+// 	5       1     flags, only the metadata flag (0x01) is defined
+// 	6       2     threshold
+// 	8       2     share index, i.e. the coordinate x[i] of the participant
+// 	10      16    split identifier (UUID)
+// 	26      4     payload length p, in bytes
+// 	30      p     payload, i.e. the values [y[0], ..., y[p/2-1]], 2 bytes each
+// 	30+p    4     CRC-32C of all the preceding bytes
+//
+// The metadata is encoded as in version 1, between the payload and the CRC.
+
+// Version16 is the version of the binary format of the shares dealt by Split16.
+const Version16 uint8 = 2
+
+const headerLength16 = 30
+
+// Share16 is the share of a secret split in GF(2^16) dealt to a single participant.
+type Share16 struct {
+	// Threshold is the number of shares required to recover the secret.
+	Threshold uint16
+	// Index is the coordinate x[i] used to evaluate the polynomials for the participant.
+	Index uint16
+	// SplitID identifies the shares dealt along with this share.
+	SplitID SplitID
+	// Payload holds the values of the polynomials at x[i], 2 bytes for every 2 bytes of the padded secret.
+	Payload []byte
+	// CreatedAt is the time the share was dealt, or zero if unknown.
+	CreatedAt time.Time
+	// Label is a free-form description of the share, e.g. the name of its custodian.
+	// It is limited to 255 bytes.
+	Label string
+}
+
+// Split16 splits a secret into n shares using Shamir secret sharing scheme in GF(2^16), such that at least
+// 2 <= k <= n shares must be combined in order to recover the secret. Up to 2^16-1 shares can be dealt.
+// WithPadding, WithRandom, WithParallelism, WithProgress and WithWipe are honored; the other options are not
+// supported and return an error.
+func Split16(secret []byte, n, threshold uint16, options ...SplitOption) ([]Share16, error) {
+	return Split16Context(context.Background(), secret, n, threshold, options...)
+}
+
+// Split16Context splits a secret the same way Split16 does, and stops with the error of ctx once ctx is done.
+func Split16Context(ctx context.Context, secret []byte, n, threshold uint16, options ...SplitOption) ([]Share16,
+	error) {
+	var c splitConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if c.wipe {
+		defer Zeroize(secret)
+	}
+	switch {
+	case c.polynomial != 0:
+		return nil, errors.New("shamir: Split16 does not support custom reduction polynomials")
+	case c.constantTime:
+		return nil, errors.New("shamir: Split16 does not support constant-time computations")
+	case c.locked:
+		return nil, errors.New("shamir: Split16 does not support locked memory")
+	case !c.policy.IsZero() || c.roles != nil:
+		return nil, errors.New("shamir: the shares dealt by Split16 cannot carry release policies nor roles")
+	}
+	if threshold > n {
+		return nil, errors.New("shamir: the threshold cannot be greater than the number of shares to deal")
+	}
+	if len(secret) < minSecretLength {
+		return nil, errors.New("shamir: the secret cannot be empty")
+	}
+	if threshold < uint16(minThreshold) {
+		return nil, errors.New("shamir: the threshold must be at least 2")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.padding {
+		secret = pad(secret)
+	} else {
+		secret = append(append(make([]byte, 0, len(secret)+2), secret...), 0x80)
+	}
+	if len(secret)%2 != 0 {
+		secret = append(secret, 0)
+	}
+	defer Zeroize(secret)
+
+	field := field65536
+	x := random.Perm(0xffff)[:n]
+	id := newSplitID()
+	created := time.Now().UTC().Truncate(time.Second)
+	shares := make([]Share16, n)
+	for i := range shares {
+		shares[i] = Share16{
+			Threshold: threshold,
+			// +1 since 0 cannot be picked as it corresponds to the secret
+			Index:     uint16(x[i] + 1),
+			SplitID:   id,
+			Payload:   make([]byte, len(secret)),
+			CreatedAt: created,
+		}
+	}
+
+	reader := c.random
+	if reader == nil {
+		reader = entropy()
+	}
+	counter := newProgressCounter(c.progress, len(secret))
+	// the goroutines handle ranges of elements, i.e. of pairs of bytes, and the blocks hold an even number of bytes
+	err := parallelize(len(secret)/2, max(c.workers, 1), func(start, end int) error {
+		return forBlocks(ctx, counter, 2*start, 2*end, func(start, end int) error {
+			for j := start; j < end; j += 2 {
+				polynomial, err := galois.RandomPoly(field, field.Element(secret[j:]), int(threshold)-1, reader)
+				if err != nil {
+					return fmt.Errorf("shamir: failed to generate random polynomial: %w", err)
+				}
+				for i := range shares {
+					field.PutElement(shares[i].Payload[j:], polynomial.Eval(shares[i].Index))
+				}
+				clear(polynomial.Coefficients)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		for i := range shares {
+			Zeroize(shares[i].Payload)
+		}
+		return nil, err
+	}
+	trace("split", "split", id, "shares", n, "threshold", threshold, "padded", c.padding, "field", "GF(2^16)")
+	return shares, nil
+}
+
+// Recover16 combines shares dealt by Split16 using Lagrange's interpolation in order to reconstruct the secret.
+// WithMixedSplits is honored, the other options are ignored.
+func Recover16(shares []Share16, options ...RecoverOption) ([]byte, error) {
+	var c recoverConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if len(shares) < int(minThreshold) {
+		return nil, errors.New("shamir: the number of shares provided is below the minimum threshold")
+	}
+	if threshold := int(shares[0].Threshold); len(shares) < threshold {
+		return nil, fmt.Errorf("shamir: %d shares are required to recover the secret, got %d", threshold,
+			len(shares))
+	}
+	shareLength := len(shares[0].Payload)
+	if shareLength%2 != 0 {
+		return nil, errors.New("shamir: the length of a share must be even")
+	}
+	for _, share := range shares {
+		if len(share.Payload) != shareLength {
+			return nil, errors.New("shamir: all shares must be the same length")
+		}
+		if share.SplitID != shares[0].SplitID && !c.mixedSplits {
+			return nil, ErrMixedSplits
+		}
+	}
+
+	field := field65536
+	coordinates := make([]uint16, len(shares))
+	for i, share := range shares {
+		for _, other := range shares[:i] {
+			if share.Index == other.Index {
+				return nil, errors.New("shamir: all shares must have distinct indexes")
+			}
+		}
+		coordinates[i] = share.Index
+	}
+	// the Lagrange basis polynomials evaluated at 0 do not depend on the values, compute them once
+	basis := galois.LagrangeBasis(field, coordinates, 0)
+
+	padded := make([]byte, shareLength)
+	for j := 0; j < shareLength; j += 2 {
+		var value uint16
+		for i, share := range shares {
+			value = field.Add(value, field.Multiply(basis[i], field.Element(share.Payload[j:])))
+		}
+		field.PutElement(padded[j:], value)
+	}
+	secret, err := unpad(padded)
+	if err != nil {
+		Zeroize(padded)
+		return nil, err
+	}
+	trace("recovered", "split", shares[0].SplitID, "shares", len(shares), "field", "GF(2^16)")
+	return secret, nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (s Share16) MarshalBinary() ([]byte, error) {
+	if len(s.Label) > maxLabelLength {
+		return nil, errors.New("shamir: the label of a share cannot exceed 255 bytes")
+	}
+	var flags uint8
+	if !s.CreatedAt.IsZero() || s.Label != "" {
+		flags |= flagMetadata
+	}
+
+	data := make([]byte, headerLength16, headerLength16+len(s.Payload)+9+len(s.Label)+crcLength)
+	copy(data, magic)
+	data[4] = Version16
+	data[5] = flags
+	binary.BigEndian.PutUint16(data[6:8], s.Threshold)
+	binary.BigEndian.PutUint16(data[8:10], s.Index)
+	copy(data[10:26], s.SplitID[:])
+	binary.BigEndian.PutUint32(data[26:30], uint32(len(s.Payload)))
+	data = append(data, s.Payload...)
+	if flags&flagMetadata != 0 {
+		var created int64
+		if !s.CreatedAt.IsZero() {
+			created = s.CreatedAt.Unix()
+		}
+		data = binary.BigEndian.AppendUint64(data, uint64(created))
+		data = append(data, uint8(len(s.Label)))
+		data = append(data, s.Label...)
+	}
+	return binary.BigEndian.AppendUint32(data, crc32.Checksum(data, castagnoli)), nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+func (s *Share16) UnmarshalBinary(data []byte) error {
+	if len(data) < headerLength16+crcLength || !bytes.Equal(data[:4], magic) {
+		return ErrInvalidFormat
+	}
+	if data[4] != Version16 || data[5]&^flagMetadata != 0 {
+		return ErrUnsupportedVersion
+	}
+	end := len(data) - crcLength
+	if crc32.Checksum(data[:end], castagnoli) != binary.BigEndian.Uint32(data[end:]) {
+		return ErrChecksum
+	}
+	length := binary.BigEndian.Uint32(data[26:30])
+	if uint64(length) > uint64(end-headerLength16) {
+		return ErrInvalidFormat
+	}
+	payloadEnd := headerLength16 + int(length)
+
+	var created time.Time
+	var label string
+	if data[5]&flagMetadata != 0 {
+		metadata := data[payloadEnd:end]
+		if len(metadata) < 9 || len(metadata) != 9+int(metadata[8]) {
+			return ErrInvalidFormat
+		}
+		if seconds := int64(binary.BigEndian.Uint64(metadata)); seconds != 0 {
+			created = time.Unix(seconds, 0).UTC()
+		}
+		label = string(metadata[9:])
+	} else if payloadEnd != end {
+		return ErrInvalidFormat
+	}
+
+	s.Threshold = binary.BigEndian.Uint16(data[6:8])
+	s.Index = binary.BigEndian.Uint16(data[8:10])
+	copy(s.SplitID[:], data[10:26])
+	s.Payload = append([]byte{}, data[headerLength16:payloadEnd]...)
+	s.CreatedAt = created
+	s.Label = label
+	return nil
+}
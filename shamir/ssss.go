@@ -0,0 +1,307 @@
+package shamir
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/random"
+)
+
+// The ssss-split and ssss-combine tools of B. Poettering (http://point-at-infinity.org/ssss/) share a secret as a
+// single element of GF(2^m), where m is the security level, 8 bits per byte of the secret by default and up to
+// 1024 bits. The field is built using the irreducible pentanomial x^m + x^a + x^b + x^c + 1 listed by the tools
+// for m, participant i is assigned the coordinate i, and a share is printed as "[token-]index-value", where
+// value is the hexadecimal encoding of the m-bit element:
+//
+// 	3-fa1c3a9c6df8af0779c36de6c33f6e36e989d0e0b91309
+//
+// The polynomials of ssss are monic: for a threshold t, participant i receives
+// f(i) = i^t + c[t-1]·i^(t-1) + ... + c[1]·i + c[0], where c[0] is the secret, and ssss-combine adds i^t to the
+// values of the shares before interpolating.
+//
+// Unless the -D flag is used, the tools apply a diffusion layer to secrets of 64 bits or more before splitting
+// them: 40*m/8 rounds of XTEA with a zero key over overlapping 64-bit slices of the secret, which SplitSSSS and
+// CombineSSSS reproduce when diffusion is true.
+//
+// The arithmetic uses math/big and does not run in constant time. The format is provided to recover backups made
+// with the tools, and to produce shares that they can combine.
+
+const (
+	ssssMaxDegree = 1024
+	xteaDelta     = 0x9e3779b9
+)
+
+// ssssPentanomials holds the exponents (a, b, c) of the irreducible pentanomials used by ssss for the degrees
+// 8, 16, ..., 1024.
+var ssssPentanomials = [...]uint8{
+	4, 3, 1, 5, 3, 1, 4, 3, 1, 7, 3, 2, 5, 4, 3, 5, 3, 2, 7, 4, 2, 4, 3, 1, 10, 9, 3, 9, 4, 2, 7, 6, 2, 10, 9,
+	6, 4, 3, 1, 5, 4, 3, 4, 3, 1, 7, 2, 1, 5, 3, 2, 7, 4, 2, 6, 3, 2, 5, 3, 2, 15, 3, 2, 11, 3, 2, 9, 8, 7, 7,
+	2, 1, 5, 3, 2, 9, 3, 1, 7, 3, 1, 9, 8, 3, 9, 4, 2, 8, 5, 3, 15, 14, 10, 10, 5, 2, 9, 6, 2, 9, 3, 2, 9, 5,
+	2, 11, 10, 1, 7, 3, 2, 11, 2, 1, 9, 7, 4, 4, 3, 1, 8, 3, 1, 7, 4, 1, 7, 2, 1, 13, 11, 6, 5, 3, 2, 7, 3, 2,
+	8, 7, 5, 12, 3, 2, 13, 10, 6, 5, 3, 2, 5, 3, 2, 9, 5, 2, 9, 7, 2, 13, 4, 3, 4, 3, 1, 11, 6, 4, 18, 9, 6,
+	19, 18, 13, 11, 3, 2, 15, 9, 6, 4, 3, 1, 16, 5, 2, 15, 14, 6, 8, 5, 2, 15, 11, 2, 11, 6, 2, 7, 5, 3, 8,
+	3, 1, 19, 16, 9, 11, 9, 6, 15, 7, 6, 13, 4, 3, 14, 13, 3, 13, 6, 3, 9, 5, 2, 19, 13, 6, 19, 10, 3, 11,
+	6, 5, 9, 2, 1, 14, 3, 2, 13, 3, 1, 7, 5, 4, 11, 9, 8, 11, 6, 5, 23, 16, 9, 19, 14, 6, 23, 10, 2, 8, 3,
+	2, 5, 4, 3, 9, 6, 4, 4, 3, 2, 13, 8, 6, 13, 11, 1, 13, 10, 3, 11, 6, 5, 19, 17, 4, 15, 14, 7, 13, 9, 6,
+	9, 7, 3, 9, 7, 1, 14, 3, 2, 11, 8, 2, 11, 6, 4, 13, 5, 2, 11, 5, 1, 11, 4, 1, 19, 10, 3, 21, 10, 6, 13,
+	3, 1, 15, 7, 5, 19, 18, 10, 7, 5, 3, 12, 7, 2, 7, 5, 1, 14, 9, 6, 10, 3, 2, 15, 13, 12, 12, 11, 9, 16,
+	9, 7, 12, 9, 3, 9, 5, 2, 17, 10, 6, 24, 9, 3, 17, 15, 13, 5, 4, 3, 19, 17, 8, 15, 6, 3, 19, 6, 1,
+}
+
+// ssssField is GF(2^degree) as built by ssss, whose elements are *big.Int.
+type ssssField struct {
+	degree  int
+	modulus *big.Int
+}
+
+// newSSSSField returns the field of ssss for a degree, which must be a multiple of 8 up to 1024.
+func newSSSSField(degree int) (*ssssField, error) {
+	if degree < 8 || degree > ssssMaxDegree || degree%8 != 0 {
+		return nil, errors.New("shamir: the ssss security level must be a multiple of 8 bits, up to 1024 bits")
+	}
+	i := 3 * (degree/8 - 1)
+	modulus := new(big.Int).SetBit(big.NewInt(1), degree, 1)
+	for _, exponent := range ssssPentanomials[i : i+3] {
+		modulus.SetBit(modulus, int(exponent), 1)
+	}
+	return &ssssField{degree: degree, modulus: modulus}, nil
+}
+
+// multiply computes a*b modulo the pentanomial, using shifts and additions.
+func (f *ssssField) multiply(a, b *big.Int) *big.Int {
+	product := new(big.Int)
+	shifted := new(big.Int).Set(a)
+	for i := 0; i < b.BitLen(); i++ {
+		if b.Bit(i) == 1 {
+			product.Xor(product, shifted)
+		}
+		shifted.Lsh(shifted, 1)
+		if shifted.Bit(f.degree) == 1 {
+			shifted.Xor(shifted, f.modulus)
+		}
+	}
+	return product
+}
+
+// inverse computes the inverse of a non-zero element as a^(2^m - 2), i.e. the product of a^(2^k) for k from 1
+// to m-1.
+func (f *ssssField) inverse(a *big.Int) *big.Int {
+	inverse := big.NewInt(1)
+	square := new(big.Int).Set(a)
+	for k := 1; k < f.degree; k++ {
+		square = f.multiply(square, square)
+		inverse = f.multiply(inverse, square)
+	}
+	return inverse
+}
+
+// SplitSSSS splits a secret into n shares in the format of ssss-split, such that threshold shares are required
+// to recover it with ssss-combine or CombineSSSS. The security level is 8 bits per byte of the secret, as the
+// dynamic security level of ssss-split. If token is not empty, it prefixes every share. If diffusion is false,
+// the shares are those of ssss-split -D.
+func SplitSSSS(secret []byte, n, threshold int, token string, diffusion bool) ([]string, error) {
+	return splitSSSS(secret, n, threshold, token, diffusion, entropy())
+}
+
+// splitSSSS is SplitSSSS reading the coefficients of the polynomial from r, in order, as ssss-split reads them
+// from /dev/urandom.
+func splitSSSS(secret []byte, n, threshold int, token string, diffusion bool, r io.Reader) ([]string, error) {
+	if threshold < int(minThreshold) || threshold > n {
+		return nil, errors.New("shamir: the threshold must be at least 2 and at most the number of shares")
+	}
+	if n > 0xffff {
+		return nil, errors.New("shamir: ssss cannot deal more than 65535 shares")
+	}
+	if strings.Contains(token, "-") {
+		return nil, errors.New("shamir: an ssss token cannot contain '-'")
+	}
+	field, err := newSSSSField(8 * len(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	coefficients := make([]*big.Int, threshold)
+	coefficients[0] = new(big.Int).SetBytes(secret)
+	if diffusion && field.degree >= 64 {
+		coefficients[0] = ssssDiffuse(coefficients[0], field.degree, true)
+	}
+	coefficient := make([]byte, len(secret))
+	defer Zeroize(coefficient)
+	for i := 1; i < threshold; i++ {
+		if err := random.ReadFull(r, coefficient); err != nil {
+			return nil, err
+		}
+		coefficients[i] = new(big.Int).SetBytes(coefficient)
+	}
+
+	width := len(strconv.Itoa(n))
+	shares := make([]string, n)
+	for i := range shares {
+		// Horner's method, starting from the leading coefficient 1 as ssss-split
+		x := big.NewInt(int64(i + 1))
+		y := new(big.Int).Set(x)
+		for j := threshold - 1; j > 0; j-- {
+			y = field.multiply(y.Xor(y, coefficients[j]), x)
+		}
+		y.Xor(y, coefficients[0])
+		prefix := ""
+		if token != "" {
+			prefix = token + "-"
+		}
+		shares[i] = fmt.Sprintf("%s%0*d-%x", prefix, width, i+1, y.FillBytes(make([]byte, len(secret))))
+	}
+	return shares, nil
+}
+
+// CombineSSSS recovers a secret from shares in the format of ssss-split, with or without token. Exactly threshold
+// shares must be provided, as to ssss-combine -t threshold: the polynomials of ssss depend on the threshold, which
+// the shares do not record. The security level is inferred from the length of the shares. If diffusion is false,
+// the shares are combined as by ssss-combine -D. As ssss-combine, the leading zero bytes of the secret are
+// removed.
+func CombineSSSS(shares []string, diffusion bool) ([]byte, error) {
+	if len(shares) < int(minThreshold) {
+		return nil, errors.New("shamir: the number of shares provided is below the minimum threshold")
+	}
+	x := make([]*big.Int, len(shares))
+	y := make([]*big.Int, len(shares))
+	var field *ssssField
+	for i, share := range shares {
+		index, value, err := parseSSSSShare(share)
+		if err != nil {
+			return nil, err
+		}
+		if field == nil {
+			if field, err = newSSSSField(8 * len(value)); err != nil {
+				return nil, err
+			}
+		} else if 8*len(value) != field.degree {
+			return nil, errors.New("shamir: all ssss shares must have the same security level")
+		}
+		x[i] = big.NewInt(int64(index))
+		y[i] = new(big.Int).SetBytes(value)
+		// the values of the monic polynomial, less x^t, are those of a polynomial of degree t-1
+		power := big.NewInt(1)
+		for range shares {
+			power = field.multiply(power, x[i])
+		}
+		y[i].Xor(y[i], power)
+		for _, other := range x[:i] {
+			if other.Cmp(x[i]) == 0 {
+				return nil, errors.New("shamir: all shares must have distinct indexes")
+			}
+		}
+	}
+
+	// Lagrange's interpolation at 0, where the substraction is the addition: l[i](0) = prod x[j] / (x[j] + x[i])
+	secret := new(big.Int)
+	for i := range x {
+		numerator, denominator := big.NewInt(1), big.NewInt(1)
+		for j := range x {
+			if i == j {
+				continue
+			}
+			numerator = field.multiply(numerator, x[j])
+			denominator = field.multiply(denominator, new(big.Int).Xor(x[j], x[i]))
+		}
+		basis := field.multiply(numerator, field.inverse(denominator))
+		secret.Xor(secret, field.multiply(basis, y[i]))
+	}
+	if diffusion && field.degree >= 64 {
+		secret = ssssDiffuse(secret, field.degree, false)
+	}
+	return secret.Bytes(), nil
+}
+
+// parseSSSSShare parses a share "[token-]index-value" and returns its index and value.
+func parseSSSSShare(share string) (int, []byte, error) {
+	share = strings.TrimSpace(share)
+	separator := strings.LastIndexByte(share, '-')
+	if separator < 0 {
+		return 0, nil, fmt.Errorf("shamir: invalid ssss share %q", share)
+	}
+	head := share[:separator]
+	if i := strings.LastIndexByte(head, '-'); i >= 0 {
+		head = head[i+1:]
+	}
+	index, err := strconv.Atoi(head)
+	if err != nil || index < 1 || index > 0xffff {
+		return 0, nil, fmt.Errorf("shamir: invalid ssss share index %q", head)
+	}
+	value, err := hex.DecodeString(share[separator+1:])
+	if err != nil || len(value) == 0 {
+		return 0, nil, fmt.Errorf("shamir: invalid ssss share value %q", share[separator+1:])
+	}
+	return index, value, nil
+}
+
+// ssssDiffuse applies the diffusion layer of ssss to an element of degree bits, or reverts it if encode is false.
+// The element is laid out as by mpz_export in 16-bit big-endian words, least significant word first, and XTEA
+// with a zero key is applied to the 64-bit slice starting at every even offset, wrapping around the element.
+func ssssDiffuse(x *big.Int, degree int, encode bool) *big.Int {
+	words := (degree + 8) / 16
+	length := degree / 8
+	exported := x.FillBytes(make([]byte, 2*words))
+	v := make([]byte, 2*words)
+	for k := 0; k < words; k++ {
+		copy(v[2*k:2*k+2], exported[2*(words-1-k):2*(words-k)])
+	}
+	// with an odd number of bytes, the most significant byte is moved next to the others
+	if degree%16 == 8 {
+		v[length-1] = v[length]
+	}
+
+	if encode {
+		for i := 0; i < 40*length; i += 2 {
+			xteaSlice(v[:length], i, true)
+		}
+	} else {
+		for i := 40*length - 2; i >= 0; i -= 2 {
+			xteaSlice(v[:length], i, false)
+		}
+	}
+
+	if degree%16 == 8 {
+		v[length] = v[length-1]
+		v[length-1] = 0
+	}
+	for k := 0; k < words; k++ {
+		copy(exported[2*(words-1-k):2*(words-k)], v[2*k:2*k+2])
+	}
+	return new(big.Int).SetBytes(exported)
+}
+
+// xteaSlice enciphers or deciphers the 8 bytes of data starting at offset, wrapping around, with 32 cycles of
+// XTEA and a zero key.
+func xteaSlice(data []byte, offset int, encipher bool) {
+	var block [8]byte
+	for i := range block {
+		block[i] = data[(offset+i)%len(data)]
+	}
+	v0, v1 := binary.BigEndian.Uint32(block[:4]), binary.BigEndian.Uint32(block[4:])
+	if encipher {
+		var sum uint32
+		for i := 0; i < 32; i++ {
+			v0 += ((v1<<4 ^ v1>>5) + v1) ^ sum
+			sum += xteaDelta
+			v1 += ((v0<<4 ^ v0>>5) + v0) ^ sum
+		}
+	} else {
+		sum := uint32(0xc6ef3720) // 32 * delta
+		for i := 0; i < 32; i++ {
+			v1 -= ((v0<<4 ^ v0>>5) + v0) ^ sum
+			sum -= xteaDelta
+			v0 -= ((v1<<4 ^ v1>>5) + v1) ^ sum
+		}
+	}
+	binary.BigEndian.PutUint32(block[:4], v0)
+	binary.BigEndian.PutUint32(block[4:], v1)
+	for i := range block {
+		data[(offset+i)%len(data)] = block[i]
+	}
+}
@@ -0,0 +1,89 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"slices"
+	"testing"
+)
+
+// The shares of the example of the ssss website, dealt by ssss-split -t 3 -n 5 with diffusion, at a 184-bit
+// security level.
+var (
+	ssssSecret = []byte("my secret root password")
+	ssssShares = []string{
+		"1-1c41ef496eccfbeba439714085df8437236298da8dd824",
+		"2-fbc74a03a50e14ab406c225afb5f45c40ae11976d2b665",
+		"3-fa1c3a9c6df8af0779c36de6c33f6e36e989d0e0b91309",
+		"4-468de7d6eb36674c9cf008c8e8fc8c566537ad6301eb9e",
+	}
+	// ssssDiffused is the secret after the diffusion layer, i.e. the secret of the same shares without diffusion.
+	ssssDiffused = "1d9a9fd6a63a40479d963efcbdbfafc5c00a514ce67d4e"
+	// ssssCoefficients are the random coefficients of degree 1 and 2 of the polynomial of the example.
+	ssssCoefficients = "af8806b30fd2cb1a0b6170e6ce901e5ce163ed10bb1b02" +
+		"ae53762cc72470b632ce3f5af6f035ae020b2486d0be69"
+)
+
+func TestCombineSSSS(t *testing.T) {
+	diffused, _ := hex.DecodeString(ssssDiffused)
+	for _, subset := range [][]int{{0, 1, 2}, {0, 1, 3}, {0, 2, 3}, {1, 2, 3}, {3, 0, 2}} {
+		shares := make([]string, len(subset))
+		for i, j := range subset {
+			shares[i] = ssssShares[j]
+		}
+		secret, err := CombineSSSS(shares, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(secret, ssssSecret) {
+			t.Errorf("CombineSSSS(%v) = %q, want %q", shares, secret, ssssSecret)
+		}
+		if secret, err = CombineSSSS(shares, false); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(secret, diffused) {
+			t.Errorf("CombineSSSS(%v) without diffusion = %x, want %x", shares, secret, diffused)
+		}
+	}
+}
+
+func TestSplitSSSS(t *testing.T) {
+	coefficients, _ := hex.DecodeString(ssssCoefficients)
+	shares, err := splitSSSS(bytes.Clone(ssssSecret), 4, 3, "", true, bytes.NewReader(coefficients))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(shares, ssssShares) {
+		t.Errorf("splitSSSS() = %q, want %q", shares, ssssShares)
+	}
+
+	// ssss-split -D deals the same shares for the diffused secret
+	diffused, _ := hex.DecodeString(ssssDiffused)
+	if shares, err = splitSSSS(diffused, 4, 3, "", false, bytes.NewReader(coefficients)); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(shares, ssssShares) {
+		t.Errorf("splitSSSS() without diffusion = %q, want %q", shares, ssssShares)
+	}
+}
+
+func TestSplitSSSSRoundTrip(t *testing.T) {
+	for _, secret := range [][]byte{[]byte("short"), []byte("a secret of more than 64 bits")} {
+		for _, diffusion := range []bool{false, true} {
+			shares, err := SplitSSSS(secret, 12, 4, "token", diffusion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shares[0][:9] != "token-01-" {
+				t.Errorf("SplitSSSS() share = %q, want the prefix %q", shares[0], "token-01-")
+			}
+			recovered, err := CombineSSSS([]string{shares[11], shares[3], shares[0], shares[7]}, diffusion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(recovered, secret) {
+				t.Errorf("CombineSSSS() = %q, want %q", recovered, secret)
+			}
+		}
+	}
+}
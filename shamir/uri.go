@@ -0,0 +1,136 @@
+package shamir
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shares can be represented as URIs, so that they can be embedded in QR codes, deep links or inventory systems:
+//
+// 	shamir://v1/3bd9d9d0-5c0e-4667-a842-54efae534ebd/42?data=aGVsbG8gd29ybGQ&k=3
+//
+// The host holds the format version, the path the split identifier and the share index. The query holds the
+// threshold (k), the unpadded base64url encoded payload (data) and optionally the creation time in seconds since
+// the Unix epoch (t), the label (label), whether the secret was padded (pad=1, see WithPadding), the reduction
+// polynomial (poly, see WithPolynomial) and the release policy (see WithReleasePolicy): the time before which the
+// share must not be used in seconds since the Unix epoch (nbf), and one approver parameter per approver, the
+// role (role, see WithRoles) and the unpadded base64url encoded signature of the dealer (sig, see Sign).
+
+// URIScheme is the scheme of share URIs.
+const URIScheme = "shamir"
+
+// FormatURI formats the share as a URI.
+func FormatURI(share Share) string {
+	query := url.Values{}
+	query.Set("k", strconv.Itoa(int(share.Threshold)))
+	query.Set("data", base64.RawURLEncoding.EncodeToString(share.Payload))
+	if !share.CreatedAt.IsZero() {
+		query.Set("t", strconv.FormatInt(share.CreatedAt.Unix(), 10))
+	}
+	if share.Label != "" {
+		query.Set("label", share.Label)
+	}
+	if share.Padded {
+		query.Set("pad", "1")
+	}
+	if share.Polynomial != 0 {
+		query.Set("poly", fmt.Sprintf("%#x", share.Polynomial))
+	}
+	if !share.Policy.NotBefore.IsZero() {
+		query.Set("nbf", strconv.FormatInt(share.Policy.NotBefore.Unix(), 10))
+	}
+	for _, approver := range share.Policy.Approvers {
+		query.Add("approver", approver)
+	}
+	if share.Role != "" {
+		query.Set("role", share.Role)
+	}
+	if share.Signature != nil {
+		query.Set("sig", base64.RawURLEncoding.EncodeToString(share.Signature))
+	}
+
+	uri := url.URL{
+		Scheme:   URIScheme,
+		Host:     fmt.Sprintf("v%d", Version),
+		Path:     fmt.Sprintf("/%s/%d", share.SplitID, share.Index),
+		RawQuery: query.Encode(),
+	}
+	return uri.String()
+}
+
+// ParseURI parses a share URI formatted with FormatURI.
+func ParseURI(s string) (Share, error) {
+	uri, err := url.Parse(s)
+	if err != nil {
+		return Share{}, err
+	}
+	if uri.Scheme != URIScheme {
+		return Share{}, errors.New("shamir: invalid URI scheme")
+	}
+	if uri.Host != fmt.Sprintf("v%d", Version) {
+		return Share{}, ErrUnsupportedVersion
+	}
+
+	segments := strings.Split(strings.TrimPrefix(uri.Path, "/"), "/")
+	if len(segments) != 2 {
+		return Share{}, errors.New("shamir: invalid URI path")
+	}
+	var share Share
+	if share.SplitID, err = ParseSplitID(segments[0]); err != nil {
+		return Share{}, err
+	}
+	index, err := strconv.ParseUint(segments[1], 10, 8)
+	if err != nil {
+		return Share{}, errors.New("shamir: invalid share index")
+	}
+	share.Index = uint8(index)
+
+	query := uri.Query()
+	threshold, err := strconv.ParseUint(query.Get("k"), 10, 8)
+	if err != nil {
+		return Share{}, errors.New("shamir: invalid threshold")
+	}
+	share.Threshold = uint8(threshold)
+	share.Payload, err = base64.RawURLEncoding.DecodeString(query.Get("data"))
+	if err != nil || len(share.Payload) < minSecretLength {
+		return Share{}, errors.New("shamir: invalid payload")
+	}
+	if t := query.Get("t"); t != "" {
+		seconds, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return Share{}, errors.New("shamir: invalid creation time")
+		}
+		share.CreatedAt = time.Unix(seconds, 0).UTC()
+	}
+	share.Label = query.Get("label")
+	share.Padded = query.Get("pad") == "1"
+	if polynomial := query.Get("poly"); polynomial != "" {
+		value, err := strconv.ParseUint(polynomial, 0, 16)
+		if err != nil {
+			return Share{}, errors.New("shamir: invalid reduction polynomial")
+		}
+		share.Polynomial = uint16(value)
+	}
+	if nbf := query.Get("nbf"); nbf != "" {
+		seconds, err := strconv.ParseInt(nbf, 10, 64)
+		if err != nil {
+			return Share{}, errors.New("shamir: invalid release time")
+		}
+		share.Policy.NotBefore = time.Unix(seconds, 0).UTC()
+	}
+	share.Policy.Approvers = query["approver"]
+	share.Role = query.Get("role")
+	if sig := query.Get("sig"); sig != "" {
+		share.Signature, err = base64.RawURLEncoding.DecodeString(sig)
+		if err != nil || len(share.Signature) != ed25519.SignatureSize {
+			return Share{}, errors.New("shamir: invalid signature")
+		}
+	}
+	return share, nil
+}
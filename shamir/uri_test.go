@@ -0,0 +1,40 @@
+package shamir
+
+import (
+	"crypto/ed25519"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestURIRoundTrip(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := Split([]byte("correct horse battery staple"), 3, 2, WithPadding(), WithRoles("legal", "board",
+		"board"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	share := shares[0]
+	share.Label = "alice & bob"
+	if share, err = Sign(share, private); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := ParseURI(FormatURI(share))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, share) {
+		t.Errorf("ParseURI() = %#v, want %#v", decoded, share)
+	}
+	if err := Verify(decoded, public); err != nil {
+		t.Errorf("Verify() = %v for a share decoded from its URI", err)
+	}
+
+	if _, err := ParseURI(strings.Replace(FormatURI(share), "sig=", "sig=AAAA", 1)); err == nil {
+		t.Error("ParseURI() accepted a signature of the wrong length")
+	}
+}
@@ -0,0 +1,72 @@
+package shamir
+
+import "errors"
+
+// HashiCorp Vault splits its unseal keys with its own shamir package, which computes in GF(2^8) with the AES
+// polynomial and assigns the coordinates at random, as Split does. A Vault share is the raw byte string
+// [y[0], ..., y[p-1], x[i]], i.e. the payload of a share followed by its index, without any header. The functions
+// below produce and consume such shares, so that unseal keys can be migrated between Vault and this package.
+// Vault encodes the shares in base64 or hex, which is left to the caller.
+
+// SplitVault splits a secret into parts shares in the format of Vault, such that threshold shares are required
+// to recover it. It follows the signature and the validation of the Split function of Vault.
+func SplitVault(secret []byte, parts, threshold int) ([][]byte, error) {
+	if parts < threshold {
+		return nil, errors.New("shamir: the number of parts cannot be less than the threshold")
+	}
+	if parts > 255 {
+		return nil, errors.New("shamir: the number of parts cannot exceed 255")
+	}
+	if threshold < int(minThreshold) {
+		return nil, errors.New("shamir: the threshold must be at least 2")
+	}
+	if len(secret) < minSecretLength {
+		return nil, errors.New("shamir: the secret cannot be empty")
+	}
+	shares, err := Split(secret, uint8(parts), uint8(threshold))
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]byte, len(shares))
+	for i, share := range shares {
+		out[i] = share.VaultBytes()
+	}
+	return out, nil
+}
+
+// CombineVault recovers a secret from shares in the format of Vault, produced by Vault or by SplitVault.
+func CombineVault(parts [][]byte) ([]byte, error) {
+	if len(parts) < int(minThreshold) {
+		return nil, errors.New("shamir: the number of parts is below the minimum threshold")
+	}
+	length := len(parts[0])
+	if length < 2 {
+		return nil, errors.New("shamir: the parts must be at least 2 bytes long")
+	}
+	for i, part := range parts {
+		if len(part) != length {
+			return nil, errors.New("shamir: all parts must be the same length")
+		}
+		for _, other := range parts[:i] {
+			if part[length-1] == other[length-1] {
+				return nil, errors.New("shamir: all parts must have distinct indexes")
+			}
+		}
+	}
+	return combine(field256, parts, 1), nil
+}
+
+// VaultBytes returns the share in the format of Vault. The threshold, the split identifier and the metadata of
+// the share are lost, and the share must not be padded or use another polynomial than the AES one.
+func (s Share) VaultBytes() []byte {
+	return append(append(make([]byte, 0, len(s.Payload)+1), s.Payload...), s.Index)
+}
+
+// ParseVaultShare converts a share in the format of Vault into a Share, whose threshold and split identifier
+// are unknown.
+func ParseVaultShare(part []byte) (Share, error) {
+	if len(part) < 2 {
+		return Share{}, ErrInvalidFormat
+	}
+	return Share{Index: part[len(part)-1], Payload: append([]byte{}, part[:len(part)-1]...)}, nil
+}
@@ -0,0 +1,111 @@
+package shamir
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// The tests below follow the tests of the shamir package of Vault.
+
+func TestVaultField(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		got, want uint8
+	}{
+		{"add(16, 16)", field256.Add(16, 16), 0},
+		{"add(3, 4)", field256.Add(3, 4), 7},
+		{"mult(3, 7)", field256.Multiply(3, 7), 9},
+		{"mult(3, 0)", field256.Multiply(3, 0), 0},
+		{"mult(0, 3)", field256.Multiply(0, 3), 0},
+		{"div(0, 7)", field256.Divide(0, 7), 0},
+		{"div(3, 3)", field256.Divide(3, 3), 1},
+		{"div(6, 3)", field256.Divide(6, 3), 2},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSplitVaultInvalid(t *testing.T) {
+	secret := []byte("test")
+	for _, tt := range []struct {
+		secret           []byte
+		parts, threshold int
+	}{
+		{secret, 0, 0},
+		{secret, 2, 3},
+		{secret, 1000, 3},
+		{secret, 10, 1},
+		{nil, 3, 2},
+	} {
+		if _, err := SplitVault(tt.secret, tt.parts, tt.threshold); err == nil {
+			t.Errorf("SplitVault(%q, %d, %d) succeeded", tt.secret, tt.parts, tt.threshold)
+		}
+	}
+}
+
+func TestCombineVaultInvalid(t *testing.T) {
+	for _, parts := range [][][]byte{
+		nil,
+		{[]byte("foo"), []byte("ba")},
+		{[]byte("f"), []byte("b")},
+		{[]byte("foo"), []byte("foo")},
+	} {
+		if _, err := CombineVault(parts); err == nil {
+			t.Errorf("CombineVault(%q) succeeded", parts)
+		}
+	}
+}
+
+// TestCombineVaultVector recovers the secret "test" from the Vault shares of the polynomial "test" + 01020304*x,
+// computed with the arithmetic of Vault.
+func TestCombineVaultVector(t *testing.T) {
+	parts := []string{"7567707001", "7661757c02", "7763767803", "f47ee84280"}
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		decoded[i], _ = hex.DecodeString(part)
+	}
+	for _, subset := range [][][]byte{decoded[:2], decoded[2:], {decoded[3], decoded[0], decoded[1]}} {
+		secret, err := CombineVault(subset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(secret, []byte("test")) {
+			t.Errorf("CombineVault() = %q, want %q", secret, "test")
+		}
+	}
+	share, err := ParseVaultShare(decoded[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if share.Index != 0x80 || !bytes.Equal(share.VaultBytes(), decoded[3]) {
+		t.Errorf("ParseVaultShare() = %+v", share)
+	}
+}
+
+func TestSplitVaultRoundTrip(t *testing.T) {
+	secret := []byte("test")
+	parts, err := SplitVault(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// as in Vault, every choice of 3 parts recovers the secret
+	for i := range parts {
+		for j := range parts {
+			for k := range parts {
+				if i == j || j == k || i == k {
+					continue
+				}
+				recovered, err := CombineVault([][]byte{parts[i], parts[j], parts[k]})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(recovered, secret) {
+					t.Errorf("CombineVault() = %q, want %q", recovered, secret)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package shamir
+
+import (
+	"bytes"
+	"crypto/sha3"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// Test vectors pin the shares dealt for a secret, so that other implementations of the scheme and future versions
+// of this package can be checked against them. The randomness of a split is drawn from a seed, using the stream
+// SHAKE256("shamir-sss test vector\x00" || seed), in the following order:
+//
+// 	- the coordinates: for i = 0..n-1, an integer j is drawn uniformly in [i, 254] by reading one byte at a time
+// 	  and rejecting the bytes that would bias the draw, and the entries i and j of the list [1, ..., 255] are
+// 	  swapped. The coordinate of the ith share is the ith entry of the list.
+// 	- the coefficients: the coefficients of degree 1 of the polynomials of all the bytes of the (padded) secret,
+// 	  then those of degree 2, and so on up to degree threshold-1, one byte each.
+// 	- the split identifier: 16 bytes, whose version and variant bits are then set as in a random UUID.
+//
+// Vectors are encoded in JSON, byte strings being hex encoded:
+//
+// 	{
+// 		"description": "3-of-5, 11 bytes",
+// 		"version": 1,
+// 		"secret": "68656c6c6f20776f726c64",
+// 		"seed": "000102030405060708090a0b0c0d0e0f",
+// 		"shares": 5,
+// 		"threshold": 3,
+// 		"polynomial": 285,
+// 		"padded": true,
+// 		"expected": [
+// 			{"index": 94, "payload": "...", "encoded": "..."},
+// 			...
+// 		]
+// 	}
+//
+// polynomial and padded are omitted when unset. The encoded shares follow the binary format of the version of
+// the vector (see Share.MarshalBinary), without creation time.
+
+const vectorDomain = "shamir-sss test vector\x00"
+
+// ErrVectorMismatch is returned when shares do not match a test vector.
+var ErrVectorMismatch = errors.New("shamir: mismatch with the test vector")
+
+// TestVector is a split of a secret whose randomness is drawn from a seed, along with the expected shares.
+type TestVector struct {
+	Description string
+	// Version is the version of the binary format of the expected shares.
+	Version   uint8
+	Secret    []byte
+	Seed      []byte
+	Shares    uint8
+	Threshold uint8
+	// Polynomial is the reduction polynomial of GF(2^8), or 0 for the AES polynomial.
+	Polynomial uint16
+	Padded     bool
+	Expected   []Share
+}
+
+// jsonVector is the JSON representation of a test vector.
+type jsonVector struct {
+	Description string              `json:"description"`
+	Version     uint8               `json:"version"`
+	Secret      string              `json:"secret"`
+	Seed        string              `json:"seed"`
+	Shares      uint8               `json:"shares"`
+	Threshold   uint8               `json:"threshold"`
+	Polynomial  uint16              `json:"polynomial,omitempty"`
+	Padded      bool                `json:"padded,omitempty"`
+	Expected    []jsonExpectedShare `json:"expected"`
+}
+
+// jsonExpectedShare is the JSON representation of an expected share of a test vector.
+type jsonExpectedShare struct {
+	Index   uint8  `json:"index"`
+	Payload string `json:"payload"`
+	Encoded string `json:"encoded"`
+}
+
+// NewTestVector splits a secret into n shares, drawing the randomness from the seed, and returns the test vector.
+// WithPolynomial and WithPadding are honored, the other options are ignored.
+func NewTestVector(description string, secret, seed []byte, n, threshold uint8,
+	options ...SplitOption) (TestVector, error) {
+	var c splitConfig
+	for _, option := range options {
+		option(&c)
+	}
+	if len(secret) < minSecretLength || threshold < minThreshold || threshold > n {
+		return TestVector{}, errors.New("shamir: invalid test vector parameters")
+	}
+	field, err := newField256(c.polynomial, false)
+	if err != nil {
+		return TestVector{}, err
+	}
+	var polynomial uint16
+	if field.Polynomial() != galois.PolynomialAES {
+		polynomial = field.Polynomial()
+	}
+	v := TestVector{
+		Description: description,
+		Version:     Version,
+		Secret:      append([]byte{}, secret...),
+		Seed:        append([]byte{}, seed...),
+		Shares:      n,
+		Threshold:   threshold,
+		Polynomial:  polynomial,
+		Padded:      c.padding,
+	}
+	v.Expected, err = v.split(field)
+	if err != nil {
+		return TestVector{}, err
+	}
+	return v, nil
+}
+
+// DefaultTestVectors returns the test vectors published with this package, covering the edges of the scheme.
+func DefaultTestVectors() ([]TestVector, error) {
+	seed := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	parameters := []struct {
+		description  string
+		secret       []byte
+		n, threshold uint8
+		options      []SplitOption
+	}{
+		{"2-of-2, 1 byte", []byte{0x42}, 2, 2, nil},
+		{"3-of-5, 11 bytes", []byte("hello world"), 5, 3, nil},
+		{"5-of-5, 32 bytes", bytes.Repeat([]byte{0xff}, 32), 5, 5, nil},
+		{"2-of-255, 16 bytes", seed, 255, 2, nil},
+		{"255-of-255, 4 bytes", []byte{0x00, 0x00, 0x00, 0x00}, 255, 255, nil},
+		{"3-of-5, 11 bytes, padded", []byte("hello world"), 5, 3, []SplitOption{WithPadding()}},
+		{"3-of-5, 11 bytes, polynomial 0x11d", []byte("hello world"), 5, 3, []SplitOption{WithPolynomial(0x11d)}},
+	}
+	vectors := make([]TestVector, len(parameters))
+	for i, p := range parameters {
+		v, err := NewTestVector(p.description, p.secret, seed, p.n, p.threshold, p.options...)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+// ParseTestVectors decodes a list of test vectors encoded in JSON.
+func ParseTestVectors(data []byte) ([]TestVector, error) {
+	var vectors []TestVector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// Verify checks that the expected shares of the vector are the shares dealt by this package from its secret and
+// seed, that their binary encoding can be read back, and that the secret is recovered from any threshold of
+// consecutive shares. The error wraps ErrVectorMismatch if a share does not match.
+func (v TestVector) Verify() error {
+	if v.Version != Version {
+		return ErrUnsupportedVersion
+	}
+	actual, err := NewTestVector(v.Description, v.Secret, v.Seed, v.Shares, v.Threshold, v.options()...)
+	if err != nil {
+		return err
+	}
+	for _, share := range v.Expected {
+		encoded, err := share.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		var decoded Share
+		if err := decoded.UnmarshalBinary(encoded); err != nil {
+			return fmt.Errorf("share %d: %w", share.Index, err)
+		}
+	}
+	return actual.Check(v.Expected)
+}
+
+// Check checks shares dealt by another implementation from the secret and seed of the vector against the expected
+// shares, and checks that the secret is recovered from any threshold of consecutive shares. All the shares of the
+// vector must be provided, in order. The error wraps ErrVectorMismatch if a share does not match.
+func (v TestVector) Check(shares []Share) error {
+	if len(shares) != len(v.Expected) {
+		return fmt.Errorf("%w: expected %d shares, got %d", ErrVectorMismatch, len(v.Expected), len(shares))
+	}
+	for i, share := range shares {
+		expected := v.Expected[i]
+		if share.Index != expected.Index || !bytes.Equal(share.Payload, expected.Payload) {
+			return fmt.Errorf("share %d: %w: invalid index or payload", i+1, ErrVectorMismatch)
+		}
+		if share.Threshold != expected.Threshold || share.SplitID != expected.SplitID ||
+			share.Padded != expected.Padded || share.Polynomial != expected.Polynomial {
+			return fmt.Errorf("share %d: %w: invalid parameters", i+1, ErrVectorMismatch)
+		}
+	}
+
+	field, err := newField256(v.Polynomial, false)
+	if err != nil {
+		return err
+	}
+	for start := 0; start+int(v.Threshold) <= len(shares); start++ {
+		subset := shareMatrix(shares[start : start+int(v.Threshold)])
+		secret := combine(field, subset, 1)
+		if v.Padded {
+			if secret, err = unpad(secret); err != nil {
+				return fmt.Errorf("%w: %w", ErrVectorMismatch, err)
+			}
+		}
+		if !bytes.Equal(secret, v.Secret) {
+			return fmt.Errorf("%w: shares %d to %d do not recover the secret", ErrVectorMismatch, start+1,
+				start+int(v.Threshold))
+		}
+	}
+	return nil
+}
+
+// CheckEncoded checks shares dealt by another implementation and encoded in the binary format, see Check.
+func (v TestVector) CheckEncoded(encoded [][]byte) error {
+	shares := make([]Share, len(encoded))
+	for i, data := range encoded {
+		if err := shares[i].UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("share %d: %w", i+1, err)
+		}
+	}
+	return v.Check(shares)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (v TestVector) MarshalJSON() ([]byte, error) {
+	encoded := jsonVector{
+		Description: v.Description,
+		Version:     v.Version,
+		Secret:      hex.EncodeToString(v.Secret),
+		Seed:        hex.EncodeToString(v.Seed),
+		Shares:      v.Shares,
+		Threshold:   v.Threshold,
+		Polynomial:  v.Polynomial,
+		Padded:      v.Padded,
+		Expected:    make([]jsonExpectedShare, len(v.Expected)),
+	}
+	for i, share := range v.Expected {
+		data, err := share.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		encoded.Expected[i] = jsonExpectedShare{
+			Index:   share.Index,
+			Payload: hex.EncodeToString(share.Payload),
+			Encoded: hex.EncodeToString(data),
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// The expected shares are decoded from their binary encoding, which must match their index and payload.
+func (v *TestVector) UnmarshalJSON(data []byte) error {
+	var decoded jsonVector
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Version != Version {
+		return ErrUnsupportedVersion
+	}
+	secret, err := hex.DecodeString(decoded.Secret)
+	if err != nil {
+		return fmt.Errorf("shamir: invalid test vector secret: %w", err)
+	}
+	seed, err := hex.DecodeString(decoded.Seed)
+	if err != nil {
+		return fmt.Errorf("shamir: invalid test vector seed: %w", err)
+	}
+	expected := make([]Share, len(decoded.Expected))
+	for i, share := range decoded.Expected {
+		encoded, err := hex.DecodeString(share.Encoded)
+		if err != nil {
+			return fmt.Errorf("shamir: invalid test vector share: %w", err)
+		}
+		if err := expected[i].UnmarshalBinary(encoded); err != nil {
+			return err
+		}
+		if expected[i].Index != share.Index || hex.EncodeToString(expected[i].Payload) != share.Payload {
+			return errors.New("shamir: the encoding of a test vector share does not match its payload")
+		}
+	}
+
+	*v = TestVector{
+		Description: decoded.Description,
+		Version:     decoded.Version,
+		Secret:      secret,
+		Seed:        seed,
+		Shares:      decoded.Shares,
+		Threshold:   decoded.Threshold,
+		Polynomial:  decoded.Polynomial,
+		Padded:      decoded.Padded,
+		Expected:    expected,
+	}
+	return nil
+}
+
+// options returns the split options of the vector.
+func (v TestVector) options() []SplitOption {
+	var options []SplitOption
+	if v.Polynomial != 0 {
+		options = append(options, WithPolynomial(v.Polynomial))
+	}
+	if v.Padded {
+		options = append(options, WithPadding())
+	}
+	return options
+}
+
+// split deals the shares of the vector, drawing the randomness from its seed.
+func (v TestVector) split(field *galois.Field256) ([]Share, error) {
+	stream := sha3.NewSHAKE256()
+	stream.Write([]byte(vectorDomain))
+	stream.Write(v.Seed)
+	return dealFromStream(field, v.Secret, v.Shares, v.Threshold, v.Padded, v.Polynomial, stream)
+}
+
+// uniform draws an integer uniformly in [0, n), 1 <= n <= 256, reading one byte at a time from r and rejecting the
+// bytes that would bias the draw.
+func uniform(r io.Reader, n int) (int, error) {
+	limit := 256 - 256%n
+	var b [1]byte
+	for {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return 0, err
+		}
+		if int(b[0]) < limit {
+			return int(b[0]) % n, nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package shamir
+
+import (
+	"errors"
+)
+
+// Participant represents a participant in a weighted Shamir scheme.
+// The weight of a participant is the number of shares dealt to them: a participant of weight 2
+// counts as two participants of weight 1 when recovering the secret.
+type Participant struct {
+	Name   string
+	Weight uint8
+}
+
+// WeightedShare is the collection of shares dealt to a single participant of a weighted scheme.
+type WeightedShare struct {
+	Participant Participant
+	Shares      []Share
+}
+
+// SplitWeighted splits a secret among weighted participants, such that the sum of the weights of
+// the participants combining their shares must be at least the threshold in order to recover the secret.
+//
+// Under the hood, a regular (k,n) Shamir scheme is used where n is the total weight of the participants,
+// and each participant receives as many shares as their weight. As we operate in GF(2^8), the total
+// weight cannot exceed 255.
+func SplitWeighted(secret []byte, participants []Participant, threshold uint8) ([]WeightedShare, error) {
+	var total int
+	for _, participant := range participants {
+		if participant.Weight == 0 {
+			return nil, errors.New("shamir: the weight of a participant must be at least 1")
+		}
+		total += int(participant.Weight)
+	}
+	if total > 255 {
+		return nil, errors.New("shamir: the total weight of the participants cannot be greater than 255")
+	}
+
+	shares, err := Split(secret, uint8(total), threshold)
+	if err != nil {
+		return nil, err
+	}
+	weighted := make([]WeightedShare, len(participants))
+	var offset int
+	for i, participant := range participants {
+		weighted[i] = WeightedShare{
+			Participant: participant,
+			Shares:      shares[offset : offset+int(participant.Weight)],
+		}
+		offset += int(participant.Weight)
+	}
+	return weighted, nil
+}
+
+// RecoverWeighted recovers a secret split with SplitWeighted.
+// The shares of all the provided participants are combined, so that each participant contributes
+// to the recovery as much as their weight.
+func RecoverWeighted(shares []WeightedShare) ([]byte, error) {
+	var combined []Share
+	for _, weighted := range shares {
+		combined = append(combined, weighted.Shares...)
+	}
+	return Recover(combined)
+}
@@ -0,0 +1,40 @@
+package shamir
+
+import "runtime"
+
+// The secrets and the intermediate values derived from them (the coefficients of the polynomials, the padded
+// secrets and the secrets of the groups) are overwritten with zeros as soon as they are no longer needed, to
+// reduce the window during which they linger in memory. This is a best effort only: the garbage collector may
+// have moved or copied the buffers, and the secrets may have been swapped to disk.
+//
+// The secret passed to Split and the shares passed to Recover belong to the caller and are left untouched,
+// unless the operation is run with WithWipe or WithRecoveryWipe, in which case they are wiped on return, whether
+// the operation succeeds or not.
+
+// Zeroize overwrites b with zeros. Unlike a loop writing to a buffer which is never read again, it is not
+// optimized away by the compiler.
+func Zeroize(b []byte) {
+	clear(b)
+	runtime.KeepAlive(b)
+}
+
+// zeroizeAll overwrites every buffer with zeros.
+func zeroizeAll(buffers [][]byte) {
+	for _, b := range buffers {
+		Zeroize(b)
+	}
+}
+
+// WithWipe wipes the secret once it is split, or once splitting fails, so that the caller does not have to.
+func WithWipe() SplitOption {
+	return func(c *splitConfig) {
+		c.wipe = true
+	}
+}
+
+// WithRecoveryWipe wipes the payloads of the shares once the secret is recovered, or once the recovery fails.
+func WithRecoveryWipe() RecoverOption {
+	return func(c *recoverConfig) {
+		c.wipe = true
+	}
+}
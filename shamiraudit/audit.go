@@ -0,0 +1,254 @@
+package shamiraudit
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package records audit events, so that compliance teams can reconstruct who did what during the key
+// ceremonies: splits, shares verified, recoveries approved, and recoveries attempted, succeeded or failed, with the
+// identity of the participant when it is known. Events never hold secrets nor share payloads.
+//
+// Events are written to a log/slog handler, so that they can be sent wherever the logs of the application go. An
+// event is a record whose message is the type of the event and whose attributes are grouped under "audit". Every
+// event holds a sequence number and the SHA-256 hash of the previous event, so that removed or reordered events
+// are detected, and is signed with Ed25519 when the logger has a signing key (see WithSigningKey). The signed
+// payload is the JSON encoding of Record. Logs written with slog.JSONHandler can be read back with ParseJSON, and
+// checked with Verify.
+
+// Event types.
+const (
+	SplitCreated      = "split.created"
+	ShareVerified     = "share.verified"
+	RecoveryAttempted = "recovery.attempted"
+	RecoverySucceeded = "recovery.succeeded"
+	RecoveryFailed    = "recovery.failed"
+	RecoveryApproved  = "recovery.approved"
+)
+
+// Group is the name of the group of the attributes of the events.
+const Group = "audit"
+
+// Event is an audit event.
+type Event struct {
+	// Type is the type of the event, e.g. SplitCreated.
+	Type string `json:"type"`
+	// Time is the time of the event. It defaults to the time the event is logged.
+	Time    time.Time       `json:"time"`
+	SplitID *shamir.SplitID `json:"split,omitempty"`
+	// Threshold is the threshold of the split, if known.
+	Threshold uint8 `json:"threshold,omitempty"`
+	// Shares lists the indexes of the shares concerned by the event.
+	Shares []int `json:"shares,omitempty"`
+	// Actor identifies the participant, e.g. the common name of their client certificate, if known.
+	Actor string `json:"actor,omitempty"`
+	// Source identifies the component reporting the event. It defaults to the source of the logger.
+	Source string `json:"source,omitempty"`
+	// Error describes the failure of the operation, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Indexes returns the indexes of shares, for Event.Shares.
+func Indexes(shares []shamir.Share) []int {
+	indexes := make([]int, len(shares))
+	for i, share := range shares {
+		indexes[i] = int(share.Index)
+	}
+	return indexes
+}
+
+// Record is a logged event, along with its position in the log.
+type Record struct {
+	Event
+	// Seq is the sequence number of the event, from 1.
+	Seq uint64 `json:"seq"`
+	// Prev is the hex encoded SHA-256 hash of the payload of the previous event, empty for the first event.
+	Prev string `json:"prev,omitempty"`
+	// Signature is the Ed25519 signature of the payload, if the logger has a signing key.
+	Signature []byte `json:"-"`
+}
+
+// Payload returns the signed payload of the record: its JSON encoding, without its signature.
+func (r Record) Payload() []byte {
+	r.Time = r.Time.UTC()
+	data, err := json.Marshal(r)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// Hash returns the hex encoded SHA-256 hash of the payload, the Prev of the next record.
+func (r Record) Hash() string {
+	sum := sha256.Sum256(r.Payload())
+	return hex.EncodeToString(sum[:])
+}
+
+// Option configures a logger.
+type Option func(*Logger)
+
+// WithSigningKey signs every event with key.
+func WithSigningKey(key ed25519.PrivateKey) Option {
+	return func(l *Logger) {
+		l.key = key
+	}
+}
+
+// WithSource sets the default source of the events, e.g. the name of the service.
+func WithSource(source string) Option {
+	return func(l *Logger) {
+		l.source = source
+	}
+}
+
+// WithPrevious continues the log after a record, e.g. the last record of the log file appended to.
+func WithPrevious(last Record) Option {
+	return func(l *Logger) {
+		l.seq = last.Seq
+		l.prev = last.Hash()
+	}
+}
+
+// Logger writes audit events to a slog handler. It is safe for concurrent use, and a nil logger discards the
+// events.
+type Logger struct {
+	handler slog.Handler
+	key     ed25519.PrivateKey
+	source  string
+	mu      sync.Mutex
+	seq     uint64
+	prev    string
+}
+
+// New returns a logger writing to handler, e.g. slog.NewJSONHandler(file, nil).
+func New(handler slog.Handler, options ...Option) *Logger {
+	l := &Logger{handler: handler}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// Log logs an event.
+func (l *Logger) Log(ctx context.Context, event Event) error {
+	if l == nil {
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if event.Source == "" {
+		event.Source = l.source
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	record := Record{Event: event, Seq: l.seq + 1, Prev: l.prev}
+	attrs := []any{
+		slog.String("type", event.Type),
+		slog.String("time", event.Time.UTC().Format(time.RFC3339Nano)),
+	}
+	if event.SplitID != nil {
+		attrs = append(attrs, slog.String("split", event.SplitID.String()))
+	}
+	if event.Threshold != 0 {
+		attrs = append(attrs, slog.Int("threshold", int(event.Threshold)))
+	}
+	if len(event.Shares) > 0 {
+		attrs = append(attrs, slog.Any("shares", event.Shares))
+	}
+	for _, attr := range []struct{ key, value string }{
+		{"actor", event.Actor}, {"source", event.Source}, {"error", event.Error}, {"prev", record.Prev},
+	} {
+		if attr.value != "" {
+			attrs = append(attrs, slog.String(attr.key, attr.value))
+		}
+	}
+	attrs = append(attrs, slog.Uint64("seq", record.Seq))
+	if l.key != nil {
+		record.Signature = ed25519.Sign(l.key, record.Payload())
+		attrs = append(attrs, slog.String("sig", base64.StdEncoding.EncodeToString(record.Signature)))
+	}
+
+	level := slog.LevelInfo
+	if event.Error != "" {
+		level = slog.LevelWarn
+	}
+	r := slog.NewRecord(event.Time, level, event.Type, 0)
+	r.AddAttrs(slog.Group(Group, attrs...))
+	if err := l.handler.Handle(ctx, r); err != nil {
+		return fmt.Errorf("shamiraudit: %w", err)
+	}
+	l.seq, l.prev = record.Seq, record.Hash()
+	return nil
+}
+
+// jsonRecord is the JSON encoding of an event by slog.JSONHandler.
+type jsonRecord struct {
+	Audit *struct {
+		Record
+		Time      string `json:"time"`
+		Signature []byte `json:"sig"`
+	} `json:"audit"`
+}
+
+// ParseJSON reads the records of a log written by slog.JSONHandler, one JSON object per line. The lines which are
+// not audit events are skipped.
+func ParseJSON(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var j jsonRecord
+		if err := json.Unmarshal(scanner.Bytes(), &j); err != nil || j.Audit == nil {
+			continue
+		}
+		record := j.Audit.Record
+		var err error
+		if record.Time, err = time.Parse(time.RFC3339Nano, j.Audit.Time); err != nil {
+			return nil, fmt.Errorf("shamiraudit: line %d: %w", line, err)
+		}
+		record.Signature = j.Audit.Signature
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("shamiraudit: %w", err)
+	}
+	return records, nil
+}
+
+// Verify checks that the records follow each other, and that they are signed with key if not nil. The first
+// record may follow records which were rotated out of the log.
+func Verify(records []Record, key ed25519.PublicKey) error {
+	for i, record := range records {
+		if i > 0 && (record.Seq != records[i-1].Seq+1 || record.Prev != records[i-1].Hash()) {
+			return fmt.Errorf("shamiraudit: record %d does not follow record %d", record.Seq, records[i-1].Seq)
+		}
+		if key == nil {
+			continue
+		}
+		if record.Signature == nil {
+			return fmt.Errorf("shamiraudit: record %d is not signed", record.Seq)
+		}
+		if !ed25519.Verify(key, record.Payload(), record.Signature) {
+			return fmt.Errorf("shamiraudit: the signature of record %d is invalid", record.Seq)
+		}
+	}
+	if len(records) > 0 && records[0].Seq != 1 && records[0].Prev == "" {
+		return errors.New("shamiraudit: the first record does not follow any record")
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package shamirgrpc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shamiraudit"
+	"github.com/etiennebch/shamir-sss/sharepb"
+)
+
+// Server implements the gRPC service of sharepb (see service.proto), so that secrets can be split and recovered by
+// a central service rather than by every binary linking the library. Secrets are streamed in chunks and shares one
+// at a time. Every operation is authorized by Config.Authorize once its parameters are known: after the first
+// request for Split, and once every share is received for Recover.
+//
+// The shares received are checked to belong to the same split before being combined. The server does not log the secrets or the shares; requests can be logged by gRPC interceptors, and the
+// splits and recoveries by an audit logger (see Config.Audit).
+
+// DefaultMaxSecretSize is the maximum length of the secrets, and of the payloads of the shares, when
+// Config.MaxSecretSize is 0.
+const DefaultMaxSecretSize = 16 << 20
+
+// chunkSize is the length of the chunks of the recovered secrets.
+const chunkSize = 64 << 10
+
+const (
+	MethodSplit   = "Split"
+	MethodRecover = "Recover"
+)
+
+// Request describes an operation to authorize.
+type Request struct {
+	// Method is MethodSplit or MethodRecover.
+	Method string
+	// Shares is the number of shares to deal, or the number of shares to combine.
+	Shares uint8
+	// Threshold is the threshold of the split, or 0 if the shares to combine do not record it.
+	Threshold uint8
+	// SplitID identifies the split whose shares are combined, and is nil for Split.
+	SplitID *shamir.SplitID
+}
+
+// Config configures a Server.
+type Config struct {
+	// Authorize returns an error if the client of the context may not perform the operation. Errors are reported
+	// to the client with the PermissionDenied code, unless they hold a gRPC status. If nil, every operation is
+	// allowed, and clients should be authenticated by the transport (see PeerCommonName).
+	Authorize func(ctx context.Context, request Request) error
+	// MaxSecretSize is the maximum length of the secrets, and of the payloads of the shares. It defaults to
+	// DefaultMaxSecretSize.
+	MaxSecretSize int
+	// DealerKey, if set, requires the shares to recover to be signed by the dealer (see shamir.WithDealerKey).
+	DealerKey ed25519.PublicKey
+	// Audit, if set, logs the splits and the recoveries authorized, whose actor is the common name of the client
+	// certificate if any.
+	Audit *shamiraudit.Logger
+}
+
+// Server is the gRPC service splitting and recovering secrets.
+type Server struct {
+	sharepb.UnimplementedShamirServiceServer
+	config Config
+}
+
+// New creates a Server.
+func New(config Config) *Server {
+	if config.MaxSecretSize <= 0 {
+		config.MaxSecretSize = DefaultMaxSecretSize
+	}
+	return &Server{config: config}
+}
+
+// Register registers the service on a gRPC server.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	sharepb.RegisterShamirServiceServer(registrar, s)
+}
+
+// PeerCommonName returns the common name of the verified TLS client certificate of the peer of a context, to
+// identify clients in Config.Authorize.
+func PeerCommonName(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", errors.New("shamirgrpc: no peer")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 {
+		return "", errors.New("shamirgrpc: a verified client certificate is required")
+	}
+	name := info.State.VerifiedChains[0][0].Subject.CommonName
+	if name == "" {
+		return "", errors.New("shamirgrpc: the client certificate has no common name")
+	}
+	return name, nil
+}
+
+// Split implements sharepb.ShamirServiceServer.
+func (s *Server) Split(stream sharepb.ShamirService_SplitServer) error {
+	request, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "shamirgrpc: no secret to split")
+	}
+	if err != nil {
+		return err
+	}
+	n, threshold := request.GetShares(), request.GetThreshold()
+	if n > 255 || threshold < 2 || threshold > n {
+		return status.Error(codes.InvalidArgument,
+			"shamirgrpc: the threshold must be at least 2 and at most the number of shares, at most 255")
+	}
+	if err := s.authorize(stream.Context(), Request{Method: MethodSplit, Shares: uint8(n),
+		Threshold: uint8(threshold)}); err != nil {
+		return err
+	}
+	var options []shamir.SplitOption
+	if request.GetPadded() {
+		options = append(options, shamir.WithPadding())
+	}
+
+	var secret []byte
+	defer func() { clear(secret) }()
+	for {
+		if len(secret)+len(request.GetSecret()) > s.config.MaxSecretSize {
+			return status.Errorf(codes.ResourceExhausted, "shamirgrpc: the secret is longer than %d bytes",
+				s.config.MaxSecretSize)
+		}
+		secret = appendSecret(secret, request.GetSecret())
+		clear(request.GetSecret())
+		if request, err = stream.Recv(); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	if len(secret) == 0 {
+		return status.Error(codes.InvalidArgument, "shamirgrpc: the secret is empty")
+	}
+
+	shares, err := shamir.SplitContext(stream.Context(), secret, uint8(n), uint8(threshold), options...)
+	if err != nil {
+		return operationError(stream.Context(), err)
+	}
+	defer func() {
+		for _, share := range shares {
+			clear(share.Payload)
+		}
+	}()
+	for _, share := range shares {
+		message := sharepb.FromShare(share)
+		err := stream.Send(&sharepb.SplitResponse{Share: message})
+		clear(message.Payload)
+		if err != nil {
+			return err
+		}
+	}
+	s.audit(stream.Context(), shamiraudit.Event{Type: shamiraudit.SplitCreated, SplitID: &shares[0].SplitID,
+		Threshold: uint8(threshold), Shares: shamiraudit.Indexes(shares)}, nil)
+	return nil
+}
+
+// Recover implements sharepb.ShamirServiceServer.
+func (s *Server) Recover(stream sharepb.ShamirService_RecoverServer) error {
+	var shares []shamir.Share
+	defer func() {
+		for _, share := range shares {
+			clear(share.Payload)
+		}
+	}()
+	for {
+		request, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(shares) == 255 {
+			return status.Error(codes.InvalidArgument, "shamirgrpc: more than 255 shares")
+		}
+		if len(request.GetShare().GetPayload()) > s.config.MaxSecretSize {
+			return status.Errorf(codes.ResourceExhausted, "shamirgrpc: the share is longer than %d bytes",
+				s.config.MaxSecretSize)
+		}
+		share, err := request.GetShare().ToShare()
+		clear(request.GetShare().GetPayload())
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "shamirgrpc: share %d: %v", len(shares)+1, err)
+		}
+		shares = append(shares, share)
+	}
+	if len(shares) == 0 {
+		return status.Error(codes.InvalidArgument, "shamirgrpc: no shares to combine")
+	}
+	if err := s.authorize(stream.Context(), Request{Method: MethodRecover, Shares: uint8(len(shares)),
+		Threshold: shares[0].Threshold, SplitID: &shares[0].SplitID}); err != nil {
+		return err
+	}
+
+	event := shamiraudit.Event{Type: shamiraudit.RecoveryAttempted, SplitID: &shares[0].SplitID,
+		Threshold: shares[0].Threshold, Shares: shamiraudit.Indexes(shares)}
+	s.audit(stream.Context(), event, nil)
+	secret, err := s.recover(stream.Context(), shares)
+	event.Type = shamiraudit.RecoverySucceeded
+	if err != nil {
+		event.Type = shamiraudit.RecoveryFailed
+	}
+	s.audit(stream.Context(), event, err)
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	for start := 0; start < len(secret); start += chunkSize {
+		end := min(start+chunkSize, len(secret))
+		if err := stream.Send(&sharepb.RecoverResponse{Secret: secret[start:end]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recover validates the shares, and recovers the secret.
+func (s *Server) recover(ctx context.Context, shares []shamir.Share) ([]byte, error) {
+	first := shares[0]
+	if len(shares) < 2 || len(shares) < int(first.Threshold) {
+		return nil, status.Errorf(codes.FailedPrecondition, "shamirgrpc: %d shares are not enough to recover the secret",
+			len(shares))
+	}
+	for i, share := range shares {
+		if share.SplitID != first.SplitID || share.Threshold != first.Threshold {
+			return nil, status.Error(codes.InvalidArgument, "shamirgrpc: the shares belong to different splits")
+		}
+		if share.Padded != first.Padded {
+			return nil, status.Error(codes.InvalidArgument, "shamirgrpc: the shares do not match")
+		}
+		if s.config.DealerKey != nil {
+			if err := shamir.Verify(share, s.config.DealerKey); err != nil {
+				return nil, status.Errorf(codes.PermissionDenied, "shamirgrpc: share %d: %v", i+1, err)
+			}
+		}
+	}
+
+	secret, err := shamir.RecoverContext(ctx, shares)
+	if err != nil {
+		return nil, operationError(ctx, err)
+	}
+	return secret, nil
+}
+
+// operationError converts the error of a split or a recovery to a gRPC status, reporting the cancellation of the
+// call or its deadline if it stopped the operation.
+func operationError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// appendSecret appends a chunk to a secret, clearing the former buffer of the secret when it is reallocated.
+func appendSecret(secret, chunk []byte) []byte {
+	if len(secret)+len(chunk) <= cap(secret) {
+		return append(secret, chunk...)
+	}
+	grown := make([]byte, len(secret), max(2*cap(secret), len(secret)+len(chunk)))
+	copy(grown, secret)
+	clear(secret)
+	return append(grown, chunk...)
+}
+
+// authorize authorizes an operation.
+func (s *Server) authorize(ctx context.Context, request Request) error {
+	if s.config.Authorize == nil {
+		return nil
+	}
+	err := s.config.Authorize(ctx, request)
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return status.Error(codes.PermissionDenied, err.Error())
+}
+
+// audit logs an event, along with the error of the operation if not nil.
+func (s *Server) audit(ctx context.Context, event shamiraudit.Event, err error) {
+	if s.config.Audit == nil {
+		return
+	}
+	event.Actor, _ = PeerCommonName(ctx)
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.config.Audit.Log(ctx, event)
+}
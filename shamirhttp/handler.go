@@ -0,0 +1,338 @@
+package shamirhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shamiraudit"
+)
+
+// Handler serves a JSON API splitting and recovering secrets, to be embedded in existing services (mount it with
+// http.StripPrefix to serve it under a prefix). Byte strings are base64 encoded, and shares use the JSON encoding
+// of the shamir package:
+//
+// 	POST /v1/split    {"secret": "...", "shares": 5, "threshold": 3, "padded": true}
+// 	                  -> {"splitId": "...", "threshold": 3, "shares": [{"index": 42, ...}, ...]}
+// 	POST /v1/recover  {"shares": [{"index": 42, ...}, ...]}
+// 	                  -> {"secret": "..."}
+//
+// Errors are reported as {"error": "..."} along with a 4xx status. The handler does not authenticate the clients,
+// which is left to the middleware of the service, but limits the rate of their requests (see WithRateLimit) and
+// reports every request to the audit hooks (see WithAudit), without the secrets or the shares. The shares are
+// checked to belong to the same split before being combined.
+
+// DefaultMaxRequestSize is the maximum size of the body of a request, unless set by WithMaxRequestSize.
+const DefaultMaxRequestSize = 4 << 20
+
+const (
+	OperationSplit   = "split"
+	OperationRecover = "recover"
+)
+
+// Event describes a request for the audit hooks. It never holds the secret or the shares.
+type Event struct {
+	Time time.Time
+	// Operation is OperationSplit or OperationRecover.
+	Operation string
+	// Client identifies the client, see WithClientKey.
+	Client string
+	// SplitID identifies the split, once known.
+	SplitID *shamir.SplitID
+	// Shares is the number of shares dealt or combined.
+	Shares int
+	// Threshold is the threshold of the split, or 0 if unknown.
+	Threshold uint8
+	// Status is the HTTP status of the response.
+	Status int
+	// Err is the error reported to the client, if any.
+	Err error
+}
+
+// Option configures the handler.
+type Option func(*config)
+
+type config struct {
+	maxRequestSize int64
+	limiter        *limiter
+	clientKey      func(r *http.Request) string
+	audit          []func(ctx context.Context, event Event)
+}
+
+// WithMaxRequestSize limits the size of the body of the requests, DefaultMaxRequestSize by default.
+func WithMaxRequestSize(size int64) Option {
+	return func(c *config) {
+		c.maxRequestSize = size
+	}
+}
+
+// WithRateLimit limits every client (see WithClientKey) to rate requests per second on average, with bursts of
+// up to burst requests. Requests exceeding the limit are rejected with the 429 status.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(c *config) {
+		c.limiter = newLimiter(rate, burst)
+	}
+}
+
+// WithClientKey identifies the clients, for the rate limits and the audit hooks. The clients are identified by
+// their IP address by default, while services authenticating their clients should identify them by their
+// authenticated identity.
+func WithClientKey(key func(r *http.Request) string) Option {
+	return func(c *config) {
+		c.clientKey = key
+	}
+}
+
+// WithAudit calls hook after every request, once the response is written. Hooks are called in the order they
+// were added.
+func WithAudit(hook func(ctx context.Context, event Event)) Option {
+	return func(c *config) {
+		c.audit = append(c.audit, hook)
+	}
+}
+
+// WithAuditLogger reports every request to logger, as a split.created, recovery.succeeded or recovery.failed
+// event whose actor is the client. Failed splits are reported as split.created events holding the error.
+func WithAuditLogger(logger *shamiraudit.Logger) Option {
+	return WithAudit(func(ctx context.Context, event Event) {
+		e := shamiraudit.Event{
+			Type:      shamiraudit.SplitCreated,
+			Time:      event.Time,
+			SplitID:   event.SplitID,
+			Threshold: event.Threshold,
+			Actor:     event.Client,
+		}
+		if event.Operation == OperationRecover {
+			e.Type = shamiraudit.RecoverySucceeded
+			if event.Err != nil {
+				e.Type = shamiraudit.RecoveryFailed
+			}
+		}
+		if event.Err != nil {
+			e.Error = event.Err.Error()
+		}
+		logger.Log(ctx, e)
+	})
+}
+
+// Handler returns the handler of the API.
+func Handler(options ...Option) http.Handler {
+	c := config{maxRequestSize: DefaultMaxRequestSize, clientKey: remoteIP}
+	for _, option := range options {
+		option(&c)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/split", c.handle(OperationSplit, c.split))
+	mux.HandleFunc("POST /v1/recover", c.handle(OperationRecover, c.recover))
+	return mux
+}
+
+type splitRequest struct {
+	Secret    []byte `json:"secret"`
+	Shares    int    `json:"shares"`
+	Threshold int    `json:"threshold"`
+	Padded    bool   `json:"padded,omitempty"`
+}
+
+type splitResponse struct {
+	SplitID   shamir.SplitID `json:"splitId"`
+	Threshold uint8          `json:"threshold"`
+	Shares    []shamir.Share `json:"shares"`
+}
+
+type recoverRequest struct {
+	Shares []shamir.Share `json:"shares"`
+}
+
+type recoverResponse struct {
+	Secret []byte `json:"secret"`
+}
+
+// requestError is an error reported to the client with an HTTP status.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// badRequest returns an error reported with the 400 status.
+func badRequest(format string, args ...any) error {
+	return &requestError{http.StatusBadRequest, fmt.Errorf("shamirhttp: "+format, args...)}
+}
+
+// handle decodes the body of a request, runs an operation, writes its response and reports it to the audit hooks.
+func (c *config) handle(operation string, run func(r *http.Request, event *Event) (clearer, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		event := Event{Time: time.Now(), Operation: operation, Client: c.clientKey(r)}
+		defer func() {
+			for _, hook := range c.audit {
+				hook(r.Context(), event)
+			}
+		}()
+
+		var response clearer
+		var err error
+		if c.limiter != nil && !c.limiter.allow(event.Client, event.Time) {
+			err = &requestError{http.StatusTooManyRequests, errors.New("shamirhttp: too many requests")}
+		} else {
+			r.Body = http.MaxBytesReader(w, r.Body, c.maxRequestSize)
+			response, err = run(r, &event)
+		}
+		if err != nil {
+			event.Status, event.Err = http.StatusInternalServerError, err
+			var requestErr *requestError
+			if errors.As(err, &requestErr) {
+				event.Status = requestErr.status
+			}
+			writeJSON(w, event.Status, struct {
+				Error string `json:"error"`
+			}{err.Error()})
+			return
+		}
+		event.Status = http.StatusOK
+		writeJSON(w, event.Status, response)
+		response.clear()
+	}
+}
+
+// clearer is implemented by the responses, to clear the secret or the shares once written.
+type clearer interface {
+	clear()
+}
+
+func (r splitResponse) clear() {
+	for _, share := range r.Shares {
+		clear(share.Payload)
+	}
+}
+
+func (r recoverResponse) clear() {
+	clear(r.Secret)
+}
+
+// decode decodes the JSON body of a request.
+func decode(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &requestError{http.StatusRequestEntityTooLarge, errors.New("shamirhttp: the request is too large")}
+		}
+		return badRequest("invalid request: %v", err)
+	}
+	return nil
+}
+
+// split splits a secret.
+func (c *config) split(r *http.Request, event *Event) (clearer, error) {
+	var request splitRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	defer clear(request.Secret)
+	if request.Shares > 255 || request.Threshold < 2 || request.Threshold > request.Shares {
+		return nil, badRequest("the threshold must be at least 2 and at most the number of shares, at most 255")
+	}
+	if len(request.Secret) == 0 {
+		return nil, badRequest("the secret is empty")
+	}
+	var options []shamir.SplitOption
+	if request.Padded {
+		options = append(options, shamir.WithPadding())
+	}
+	shares, err := shamir.SplitContext(r.Context(), request.Secret, uint8(request.Shares), uint8(request.Threshold),
+		options...)
+	if err != nil {
+		return nil, operationError(r.Context(), err)
+	}
+	event.SplitID, event.Shares, event.Threshold = &shares[0].SplitID, len(shares), shares[0].Threshold
+	return splitResponse{SplitID: shares[0].SplitID, Threshold: shares[0].Threshold, Shares: shares}, nil
+}
+
+// recover recovers a secret.
+func (c *config) recover(r *http.Request, event *Event) (clearer, error) {
+	var request recoverRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	shares := request.Shares
+	defer func() {
+		for _, share := range shares {
+			clear(share.Payload)
+		}
+	}()
+	if len(shares) == 0 {
+		return nil, badRequest("no shares to combine")
+	}
+	event.SplitID, event.Shares, event.Threshold = &shares[0].SplitID, len(shares), shares[0].Threshold
+	if err := validate(shares); err != nil {
+		return nil, err
+	}
+
+	secret, err := shamir.RecoverContext(r.Context(), shares)
+	if err != nil {
+		return nil, operationError(r.Context(), err)
+	}
+	return recoverResponse{Secret: secret}, nil
+}
+
+// operationError converts the error of a split or a recovery to a request error, reporting the cancellation of the
+// request or its deadline with the 503 status if it stopped the operation.
+func operationError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return &requestError{http.StatusServiceUnavailable, fmt.Errorf("shamirhttp: %w", ctx.Err())}
+	}
+	return &requestError{http.StatusBadRequest, err}
+}
+
+// validate checks that the shares belong to the same split, which shamir.Recover does not check.
+func validate(shares []shamir.Share) error {
+	first := shares[0]
+	if len(shares) < 2 || len(shares) < int(first.Threshold) {
+		return &requestError{http.StatusUnprocessableEntity,
+			fmt.Errorf("shamirhttp: %d shares are not enough to recover the secret", len(shares))}
+	}
+	if len(shares) > 255 {
+		return badRequest("more than 255 shares")
+	}
+	for i, share := range shares {
+		if len(share.Payload) == 0 {
+			return badRequest("share %d is empty", i+1)
+		}
+		if share.SplitID != first.SplitID || share.Threshold != first.Threshold {
+			return badRequest("the shares belong to different splits")
+		}
+		if share.Padded != first.Padded {
+			return badRequest("the shares do not match")
+		}
+	}
+	return nil
+}
+
+// remoteIP identifies clients by their IP address.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
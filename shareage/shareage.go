@@ -0,0 +1,153 @@
+package shareage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"filippo.io/age/plugin"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package wraps shares in age encrypted files (https://age-encryption.org), so that custodians can rely on
+// the age ecosystem to hold their share: X25519 keys, scrypt passphrases, or plugins such as age-plugin-yubikey.
+//
+// The plaintext of an age file is the binary encoding of a share (see shamir.Share.MarshalBinary). Files can be
+// decrypted with the age command line tool, and the share parsed with shamir.Share.UnmarshalBinary.
+
+// FileExtension is the extension of the files written by WriteFiles.
+const FileExtension = ".age"
+
+// Encrypt encrypts a share to the recipients, e.g. the recipients returned by ParseRecipient or
+// age.NewScryptRecipient. If armored is true, the file is ASCII-armored so that it can be printed or emailed.
+func Encrypt(share shamir.Share, armored bool, recipients ...age.Recipient) ([]byte, error) {
+	plaintext, err := share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	var dst io.Writer = &b
+	var armorWriter io.WriteCloser
+	if armored {
+		armorWriter = armor.NewWriter(&b)
+		dst = armorWriter
+	}
+	w, err := age.Encrypt(dst, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if armorWriter != nil {
+		if err := armorWriter.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// Decrypt decrypts a share encrypted with Encrypt, armored or not, using one of the identities.
+func Decrypt(data []byte, identities ...age.Identity) (shamir.Share, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(src)
+	}
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	var share shamir.Share
+	if err := share.UnmarshalBinary(plaintext); err != nil {
+		return shamir.Share{}, err
+	}
+	return share, nil
+}
+
+// WriteFiles encrypts every share to the recipients of its custodian, and writes it in dir as
+// share-<index>.age. It returns the paths of the files.
+func WriteFiles(dir string, shares []shamir.Share, recipients [][]age.Recipient) ([]string, error) {
+	if len(recipients) != len(shares) {
+		return nil, errors.New("shareage: every share must have its own recipients")
+	}
+	paths := make([]string, len(shares))
+	for i, share := range shares {
+		data, err := Encrypt(share, false, recipients[i]...)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", share.Index, err)
+		}
+		paths[i] = filepath.Join(dir, fmt.Sprintf("share-%d%s", share.Index, FileExtension))
+		if err := os.WriteFile(paths[i], data, 0600); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// RecoverFiles decrypts the shares held by the files with the identities of their custodians, and recovers
+// the secret. Every file is decrypted with the first identity able to open it.
+func RecoverFiles(paths []string, identities ...age.Identity) ([]byte, error) {
+	shares := make([]shamir.Share, len(paths))
+	for i, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if shares[i], err = Decrypt(data, identities...); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return shamir.Recover(shares)
+}
+
+// ParseRecipient parses an age recipient: a native recipient ("age1..."), a post-quantum hybrid recipient
+// ("age1pq1...") or a plugin recipient ("age1yubikey1..."). Plugins interact with the user through the terminal.
+func ParseRecipient(s string) (age.Recipient, error) {
+	if recipient, err := age.ParseX25519Recipient(s); err == nil {
+		return recipient, nil
+	}
+	switch {
+	case strings.HasPrefix(s, "age1pq1"):
+		return age.ParseHybridRecipient(s)
+	case strings.HasPrefix(s, "age1"):
+		return plugin.NewRecipient(s, terminalUI())
+	default:
+		return nil, errors.New("shareage: unknown age recipient")
+	}
+}
+
+// ParseIdentity parses an age identity: a native identity ("AGE-SECRET-KEY-1..."), a post-quantum hybrid
+// identity ("AGE-SECRET-KEY-PQ-1...") or a plugin identity ("AGE-PLUGIN-YUBIKEY-1...").
+func ParseIdentity(s string) (age.Identity, error) {
+	switch {
+	case strings.HasPrefix(s, "AGE-SECRET-KEY-PQ-1"):
+		return age.ParseHybridIdentity(s)
+	case strings.HasPrefix(s, "AGE-PLUGIN-"):
+		return plugin.NewIdentity(s, terminalUI())
+	default:
+		return age.ParseX25519Identity(s)
+	}
+}
+
+// terminalUI returns the user interface of plugins, writing messages to the standard error.
+func terminalUI() *plugin.ClientUI {
+	printf := func(format string, v ...any) {
+		fmt.Fprintf(os.Stderr, "shareage: "+format+"\n", v...)
+	}
+	return plugin.NewTerminalUI(printf, printf)
+}
@@ -0,0 +1,70 @@
+package shareaws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+
+	"github.com/etiennebch/shamir-sss/sharewrap"
+)
+
+// This package wraps shares with AWS KMS keys (see sharewrap), so that shares stored in the cloud are protected
+// by the IAM policies of the keys, and every unwrapping is recorded by CloudTrail. The data keys are encrypted
+// with the symmetric KMS key of the custodian (a key ID, ARN or alias), and the split identifier and the index of
+// the share are passed as KMS encryption context (under the shamir:split and shamir:index keys), so that they
+// appear in CloudTrail and can be used in the conditions of key policies.
+
+// Type is the type of the wrapper, recorded in the wrapped shares.
+const Type = "aws-kms"
+
+// KMS is the part of the KMS API used by this package, implemented by *kms.Client.
+type KMS interface {
+	Encrypt(ctx context.Context, params *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+var _ sharewrap.Wrapper = (*Wrapper)(nil)
+
+// Wrapper wraps data keys with an AWS KMS key. It implements sharewrap.Wrapper.
+type Wrapper struct {
+	client KMS
+	keyID  string
+}
+
+// New returns a wrapper using the KMS key keyID, which may be empty if the wrapper is only used to unwrap shares.
+func New(client KMS, keyID string) *Wrapper {
+	return &Wrapper{client: client, keyID: keyID}
+}
+
+// Type implements sharewrap.Wrapper.
+func (w *Wrapper) Type() string {
+	return Type
+}
+
+// WrapKey implements sharewrap.Wrapper. The identifier of the key is its ARN.
+func (w *Wrapper) WrapKey(ctx context.Context, key []byte, context map[string]string) (string, []byte, error) {
+	output, err := w.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:             aws.String(w.keyID),
+		Plaintext:         key,
+		EncryptionContext: context,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("shareaws: %w", err)
+	}
+	return aws.ToString(output.KeyId), output.CiphertextBlob, nil
+}
+
+// UnwrapKey implements sharewrap.Wrapper.
+func (w *Wrapper) UnwrapKey(ctx context.Context, keyID string, wrapped []byte, context map[string]string) ([]byte, error) {
+	output, err := w.client.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob:    wrapped,
+		KeyId:             aws.String(keyID),
+		EncryptionContext: context,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("shareaws: %w", err)
+	}
+	return output.Plaintext, nil
+}
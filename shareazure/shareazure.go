@@ -0,0 +1,184 @@
+package shareazure
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+
+	"github.com/etiennebch/shamir-sss/sharewrap"
+)
+
+// This package wraps shares with Azure Key Vault keys (see sharewrap), so that shares stored in the cloud are
+// protected by the access policies of the keys, and every unwrapping is recorded by the Key Vault logs. The data
+// keys are wrapped with the key of the custodian through the wrapkey and unwrapkey operations of the REST API of
+// Key Vault, authenticated with an azidentity credential. Key Vault does not authenticate any context along with
+// the wrapped keys, which are only bound to their share by sharewrap.
+//
+// Keys are identified by their URL, https://VAULT.vault.azure.net/keys/NAME[/VERSION], and the wrapped shares record
+// the version of the key which wrapped them. A wrapper only sends requests to the vaults of its DNS suffix, so
+// that a tampered wrapped share cannot send the access token of the wrapper to another host.
+
+// Type is the type of the wrapper, recorded in the wrapped shares.
+const Type = "azure-keyvault"
+
+const (
+	apiVersion = "7.4"
+	moduleName = "shareazure"
+	version    = "v1.0.0"
+)
+
+// keyPath matches the paths of the keys, and captures their name and version.
+var keyPath = regexp.MustCompile(`^/keys/([0-9A-Za-z-]+)(?:/([0-9A-Za-z]+))?/?$`)
+
+// Options configures a wrapper.
+type Options struct {
+	// Algorithm is the key wrapping algorithm, RSA-OAEP-256 by default. Managed HSM AES keys use A256KW.
+	Algorithm string
+	// DNSSuffix is the DNS suffix of the vaults, vault.azure.net by default (managedhsm.azure.net for Managed
+	// HSM, vault.azure.cn or vault.usgovcloudapi.net for the national clouds).
+	DNSSuffix string
+	// ClientOptions configures the HTTP pipeline.
+	ClientOptions policy.ClientOptions
+}
+
+var _ sharewrap.Wrapper = (*Wrapper)(nil)
+
+// Wrapper wraps data keys with an Azure Key Vault key. It implements sharewrap.Wrapper.
+type Wrapper struct {
+	pipeline  runtime.Pipeline
+	key       string
+	algorithm string
+	suffix    string
+}
+
+// New returns a wrapper using the key of the URL key, which may be empty if the wrapper is only used to unwrap
+// shares. credential is typically returned by azidentity.NewDefaultAzureCredential. options may be nil.
+func New(credential azcore.TokenCredential, key string, options *Options) (*Wrapper, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	w := &Wrapper{algorithm: options.Algorithm, suffix: options.DNSSuffix}
+	if w.algorithm == "" {
+		w.algorithm = "RSA-OAEP-256"
+	}
+	if w.suffix == "" {
+		w.suffix = "vault.azure.net"
+	}
+	if key != "" {
+		if _, err := w.operationURL(key, "wrapkey"); err != nil {
+			return nil, err
+		}
+		w.key = key
+	}
+	scope := "https://" + w.suffix + "/.default"
+	w.pipeline = runtime.NewPipeline(moduleName, version, runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(credential, []string{scope}, nil)},
+	}, &options.ClientOptions)
+	return w, nil
+}
+
+// Type implements sharewrap.Wrapper.
+func (w *Wrapper) Type() string {
+	return Type
+}
+
+type operationRequest struct {
+	Algorithm string `json:"alg"`
+	Value     string `json:"value"`
+}
+
+type operationResponse struct {
+	KeyID string `json:"kid"`
+	Value string `json:"value"`
+}
+
+// WrapKey implements sharewrap.Wrapper. The identifier of the key is its URL, with its version.
+func (w *Wrapper) WrapKey(ctx context.Context, key []byte, _ map[string]string) (string, []byte, error) {
+	if w.key == "" {
+		return "", nil, errors.New("shareazure: no key to wrap with")
+	}
+	response, err := w.call(ctx, w.key, "wrapkey", key)
+	if err != nil {
+		return "", nil, err
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(response.Value)
+	if err != nil {
+		return "", nil, fmt.Errorf("shareazure: invalid response: %w", err)
+	}
+	if _, err := w.operationURL(response.KeyID, "unwrapkey"); err != nil {
+		return "", nil, err
+	}
+	return response.KeyID, wrapped, nil
+}
+
+// UnwrapKey implements sharewrap.Wrapper.
+func (w *Wrapper) UnwrapKey(ctx context.Context, keyID string, wrapped []byte, _ map[string]string) ([]byte, error) {
+	response, err := w.call(ctx, keyID, "unwrapkey", wrapped)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.RawURLEncoding.DecodeString(response.Value)
+	if err != nil {
+		return nil, fmt.Errorf("shareazure: invalid response: %w", err)
+	}
+	return key, nil
+}
+
+// operationURL returns the URL of an operation of a key, checking that the key belongs to a vault of the DNS
+// suffix of the wrapper.
+func (w *Wrapper) operationURL(key, operation string) (string, error) {
+	u, err := url.Parse(key)
+	if err != nil {
+		return "", fmt.Errorf("shareazure: invalid key URL: %w", err)
+	}
+	match := keyPath.FindStringSubmatch(u.Path)
+	if u.Scheme != "https" || !strings.HasSuffix(u.Hostname(), "."+w.suffix) || match == nil ||
+		u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("shareazure: %q is not the URL of a key of a vault of %s", key, w.suffix)
+	}
+	// an empty version selects the latest version of the key
+	return fmt.Sprintf("https://%s/keys/%s/%s/%s", u.Host, match[1], match[2], operation), nil
+}
+
+// call calls an operation of a key.
+func (w *Wrapper) call(ctx context.Context, key, operation string, value []byte) (operationResponse, error) {
+	endpoint, err := w.operationURL(key, operation)
+	if err != nil {
+		return operationResponse{}, err
+	}
+	request, err := runtime.NewRequest(ctx, http.MethodPost, endpoint)
+	if err != nil {
+		return operationResponse{}, err
+	}
+	query := request.Raw().URL.Query()
+	query.Set("api-version", apiVersion)
+	request.Raw().URL.RawQuery = query.Encode()
+	err = runtime.MarshalAsJSON(request, operationRequest{
+		Algorithm: w.algorithm,
+		Value:     base64.RawURLEncoding.EncodeToString(value),
+	})
+	if err != nil {
+		return operationResponse{}, err
+	}
+	response, err := w.pipeline.Do(request)
+	if err != nil {
+		return operationResponse{}, fmt.Errorf("shareazure: %w", err)
+	}
+	if !runtime.HasStatusCode(response, http.StatusOK) {
+		return operationResponse{}, fmt.Errorf("shareazure: %w", runtime.NewResponseError(response))
+	}
+	var result operationResponse
+	if err := runtime.UnmarshalAsJSON(response, &result); err != nil {
+		return operationResponse{}, fmt.Errorf("shareazure: invalid response: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,243 @@
+package sharecbor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package encodes shares using CBOR (RFC 8949), a compact binary format suitable for constrained devices.
+// Shares are encoded as a map with integer keys, using the core deterministic encoding so that a share always
+// has a single valid encoding:
+//
+// 	1: format version (uint)
+// 	2: share index (uint)
+// 	3: threshold (uint)
+// 	4: split identifier (bstr, 16 bytes)
+// 	5: creation time (tag 1, seconds since the Unix epoch), omitted if unknown
+// 	6: label (tstr), omitted if empty
+// 	7: payload (bstr)
+// 	8: signature of the dealer (bstr, see shamir.Sign), omitted if the share is not signed
+// 	9: whether the secret was padded (bool, see shamir.WithPadding), omitted if false
+// 	10: reduction polynomial of GF(2^8) (uint, see shamir.WithPolynomial), omitted for the AES polynomial
+// 	11: release policy (map, see shamir.WithReleasePolicy), omitted if the share has none:
+// 	    1: time before which the share must not be used (tag 1), omitted if unset
+// 	    2: identities of the approvers (array of tstr), omitted if empty
+// 	12: role (tstr, see shamir.WithRoles), omitted if empty
+//
+// Shares can also be wrapped in a COSE_Sign1 structure (RFC 9052) signed by the dealer using Ed25519,
+// so that custodians can verify that their share was not tampered with.
+
+// cborShare is the CBOR representation of a share.
+type cborShare struct {
+	Version    uint8       `cbor:"1,keyasint"`
+	Index      uint8       `cbor:"2,keyasint"`
+	Threshold  uint8       `cbor:"3,keyasint"`
+	SplitID    []byte      `cbor:"4,keyasint"`
+	CreatedAt  *time.Time  `cbor:"5,keyasint,omitempty"`
+	Label      string      `cbor:"6,keyasint,omitempty"`
+	Payload    []byte      `cbor:"7,keyasint"`
+	Signature  []byte      `cbor:"8,keyasint,omitempty"`
+	Padded     bool        `cbor:"9,keyasint,omitempty"`
+	Polynomial uint16      `cbor:"10,keyasint,omitempty"`
+	Policy     *cborPolicy `cbor:"11,keyasint,omitempty"`
+	Role       string      `cbor:"12,keyasint,omitempty"`
+}
+
+// cborPolicy is the CBOR representation of the release policy of a share.
+type cborPolicy struct {
+	NotBefore *time.Time `cbor:"1,keyasint,omitempty"`
+	Approvers []string   `cbor:"2,keyasint,omitempty"`
+}
+
+// optionalTime returns nil for the zero time, which would otherwise be encoded as null rather than omitted.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+var (
+	encMode = func() cbor.EncMode {
+		options := cbor.CoreDetEncOptions()
+		options.Time = cbor.TimeUnix
+		options.TimeTag = cbor.EncTagRequired
+		mode, err := options.EncMode()
+		if err != nil {
+			panic(err)
+		}
+		return mode
+	}()
+	decMode = func() cbor.DecMode {
+		mode, err := cbor.DecOptions{DupMapKey: cbor.DupMapKeyEnforcedAPF}.DecMode()
+		if err != nil {
+			panic(err)
+		}
+		return mode
+	}()
+)
+
+// Marshal encodes a share using deterministic CBOR.
+func Marshal(share shamir.Share) ([]byte, error) {
+	encoded := cborShare{
+		Version:    shamir.Version,
+		Index:      share.Index,
+		Threshold:  share.Threshold,
+		SplitID:    share.SplitID[:],
+		CreatedAt:  optionalTime(share.CreatedAt),
+		Label:      share.Label,
+		Payload:    share.Payload,
+		Signature:  share.Signature,
+		Padded:     share.Padded,
+		Polynomial: share.Polynomial,
+		Role:       share.Role,
+	}
+	if !share.Policy.IsZero() {
+		encoded.Policy = &cborPolicy{NotBefore: optionalTime(share.Policy.NotBefore), Approvers: share.Policy.Approvers}
+	}
+	return encMode.Marshal(encoded)
+}
+
+// Unmarshal decodes a share encoded with Marshal.
+func Unmarshal(data []byte) (shamir.Share, error) {
+	var decoded cborShare
+	if err := decMode.Unmarshal(data, &decoded); err != nil {
+		return shamir.Share{}, err
+	}
+	if decoded.Version != shamir.Version {
+		return shamir.Share{}, shamir.ErrUnsupportedVersion
+	}
+	if len(decoded.SplitID) != len(shamir.SplitID{}) || len(decoded.Payload) == 0 {
+		return shamir.Share{}, shamir.ErrInvalidFormat
+	}
+
+	share := shamir.Share{
+		Index:      decoded.Index,
+		Threshold:  decoded.Threshold,
+		Payload:    decoded.Payload,
+		Label:      decoded.Label,
+		Signature:  decoded.Signature,
+		Padded:     decoded.Padded,
+		Polynomial: decoded.Polynomial,
+		Role:       decoded.Role,
+	}
+	copy(share.SplitID[:], decoded.SplitID)
+	if decoded.CreatedAt != nil {
+		share.CreatedAt = decoded.CreatedAt.UTC()
+	}
+	if decoded.Policy != nil {
+		share.Policy.Approvers = decoded.Policy.Approvers
+		if decoded.Policy.NotBefore != nil {
+			share.Policy.NotBefore = decoded.Policy.NotBefore.UTC()
+		}
+	}
+	return share, nil
+}
+
+const (
+	// coseSign1Tag is the CBOR tag of a COSE_Sign1 structure
+	coseSign1Tag = 18
+	// headerAlgorithm and headerKeyID are the labels of the COSE header parameters
+	headerAlgorithm = 1
+	headerKeyID     = 4
+	// algorithmEdDSA is the COSE identifier of the EdDSA signature algorithm
+	algorithmEdDSA = -8
+)
+
+// ErrInvalidSignature is returned when the signature of a signed share cannot be verified.
+var ErrInvalidSignature = errors.New("sharecbor: invalid signature")
+
+// coseSign1 is the COSE_Sign1 structure: [protected, unprotected, payload, signature].
+type coseSign1 struct {
+	_           struct{} `cbor:",toarray"`
+	Protected   []byte
+	Unprotected map[int]interface{}
+	Payload     []byte
+	Signature   []byte
+}
+
+// Sign encodes the share with Marshal and wraps it in a COSE_Sign1 structure signed with the dealer's key.
+// The key identifier is optional, and is carried unprotected so that custodians can pick the right
+// verification key.
+func Sign(share shamir.Share, key ed25519.PrivateKey, keyID []byte) ([]byte, error) {
+	payload, err := Marshal(share)
+	if err != nil {
+		return nil, err
+	}
+	protected, err := encMode.Marshal(map[int]int{headerAlgorithm: algorithmEdDSA})
+	if err != nil {
+		return nil, err
+	}
+	toBeSigned, err := sigStructure(protected, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	message := coseSign1{
+		Protected:   protected,
+		Unprotected: map[int]interface{}{},
+		Payload:     payload,
+		Signature:   ed25519.Sign(key, toBeSigned),
+	}
+	if len(keyID) > 0 {
+		message.Unprotected[headerKeyID] = keyID
+	}
+	return encMode.Marshal(cbor.Tag{Number: coseSign1Tag, Content: message})
+}
+
+// Verify checks the signature of a share signed with Sign and returns the share.
+func Verify(data []byte, key ed25519.PublicKey) (shamir.Share, error) {
+	var tag cbor.RawTag
+	if err := decMode.Unmarshal(data, &tag); err != nil {
+		return shamir.Share{}, err
+	}
+	if tag.Number != coseSign1Tag {
+		return shamir.Share{}, errors.New("sharecbor: not a COSE_Sign1 structure")
+	}
+	var message coseSign1
+	if err := decMode.Unmarshal(tag.Content, &message); err != nil {
+		return shamir.Share{}, err
+	}
+
+	var headers map[int]int
+	if err := decMode.Unmarshal(message.Protected, &headers); err != nil {
+		return shamir.Share{}, err
+	}
+	if headers[headerAlgorithm] != algorithmEdDSA {
+		return shamir.Share{}, errors.New("sharecbor: unsupported signature algorithm")
+	}
+
+	toBeSigned, err := sigStructure(message.Protected, message.Payload)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	if !ed25519.Verify(key, toBeSigned, message.Signature) {
+		return shamir.Share{}, ErrInvalidSignature
+	}
+	return Unmarshal(message.Payload)
+}
+
+// KeyID returns the key identifier of a share signed with Sign, without verifying the signature.
+func KeyID(data []byte) ([]byte, error) {
+	var tag cbor.RawTag
+	if err := decMode.Unmarshal(data, &tag); err != nil {
+		return nil, err
+	}
+	var message coseSign1
+	if err := decMode.Unmarshal(tag.Content, &message); err != nil {
+		return nil, err
+	}
+	keyID, _ := message.Unprotected[headerKeyID].([]byte)
+	return bytes.Clone(keyID), nil
+}
+
+// sigStructure builds the Sig_structure signed by COSE_Sign1, with no external data.
+func sigStructure(protected, payload []byte) ([]byte, error) {
+	return encMode.Marshal([]interface{}{"Signature1", protected, []byte{}, payload})
+}
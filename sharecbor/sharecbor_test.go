@@ -0,0 +1,66 @@
+package sharecbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+func TestMarshalGolden(t *testing.T) {
+	share := shamir.Share{
+		Index:     1,
+		Threshold: 2,
+		SplitID:   shamir.SplitID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		Payload:   []byte{0xaa, 0xbb},
+	}
+	policy := shamir.Policy{Approvers: []string{"a"}}
+	for _, test := range []struct {
+		name   string
+		modify func(*shamir.Share)
+		want   string
+	}{
+		{"minimal", func(*shamir.Share) {}, "a5" + "0101" + "0201" + "0302" + "0450000102030405060708090a0b0c0d0e0f" +
+			"0742aabb"},
+		{"created", func(s *shamir.Share) { s.CreatedAt = time.Unix(1700000000, 0).UTC() }, "a6" + "0101" + "0201" +
+			"0302" + "0450000102030405060708090a0b0c0d0e0f" + "05c11a6553f100" + "0742aabb"},
+		// the policy has no time before which the share must not be used: key 1 is omitted
+		{"policy", func(s *shamir.Share) { s.Policy = policy }, "a6" + "0101" + "0201" + "0302" +
+			"0450000102030405060708090a0b0c0d0e0f" + "0742aabb" + "0ba1028161" + "61"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			share := share
+			test.modify(&share)
+			data, err := Marshal(share)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(data); got != test.want {
+				t.Errorf("Marshal() = %s, want %s", got, test.want)
+			}
+			decoded, err := Unmarshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, share) {
+				t.Errorf("Unmarshal() = %#v, want %#v", decoded, share)
+			}
+		})
+	}
+}
+
+func TestUnmarshalNullTime(t *testing.T) {
+	// encodings of earlier versions held null for a share without a creation time
+	data, _ := hex.DecodeString("a6" + "0101" + "0201" + "0302" + "0450000102030405060708090a0b0c0d0e0f" + "05f6" +
+		"0742aabb")
+	share, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !share.CreatedAt.IsZero() || !bytes.Equal(share.Payload, []byte{0xaa, 0xbb}) {
+		t.Errorf("Unmarshal() = %#v", share)
+	}
+}
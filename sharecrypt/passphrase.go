@@ -0,0 +1,171 @@
+package sharecrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package encrypts shares, so that a stolen paper or file share is useless to an attacker, and so that
+// shares can be distributed over untrusted channels (see SplitEncrypted).
+//
+// A share can be wrapped with a key derived from the passphrase of its holder using Argon2id, and encrypted
+// using XChaCha20-Poly1305. A wrapped share is encoded as:
+//
+// 	offset  size  field
+// 	0       4     magic bytes "SHMP"
+// 	4       1     format version
+// 	5       4     Argon2id time parameter (iterations)
+// 	9       4     Argon2id memory parameter, in KiB
+// 	13      1     Argon2id parallelism
+// 	14      16    salt
+// 	30      24    nonce
+// 	54      -     encrypted binary encoding of the share (see shamir.Share.MarshalBinary), and tag
+//
+// The header (the first 54 bytes) is authenticated along with the share.
+
+const (
+	passphraseVersion      = 1
+	passphraseHeaderLength = 54
+	saltSize               = 16
+	keySize                = chacha20poly1305.KeySize
+	// maxMemory and maxTime bound the cost of the key derivation when unwrapping a share,
+	// so that a crafted share cannot exhaust the resources of the holder
+	maxMemory = 4 << 20
+	maxTime   = 64
+)
+
+var passphraseMagic = []byte("SHMP")
+
+// ErrWrongPassphrase is returned when a wrapped share cannot be decrypted, because the passphrase is wrong or
+// the share was altered.
+var ErrWrongPassphrase = errors.New("sharecrypt: wrong passphrase or altered share")
+
+// Params are the Argon2id parameters used to derive keys from passphrases.
+type Params struct {
+	// Time is the number of passes over the memory.
+	Time uint32
+	// Memory is the size of the memory, in KiB.
+	Memory uint32
+	// Threads is the number of threads used.
+	Threads uint8
+}
+
+// DefaultParams are the recommended Argon2id parameters (RFC 9106, second recommended option).
+var DefaultParams = Params{Time: 3, Memory: 64 << 10, Threads: 4}
+
+// Wrap encrypts a share with a passphrase, using DefaultParams.
+func Wrap(share shamir.Share, passphrase string) ([]byte, error) {
+	return WrapWithParams(share, passphrase, DefaultParams)
+}
+
+// WrapWithParams encrypts a share with a passphrase, using the provided Argon2id parameters.
+func WrapWithParams(share shamir.Share, passphrase string, params Params) ([]byte, error) {
+	if params.Time < 1 || params.Time > maxTime || params.Memory < 8*uint32(params.Threads) ||
+		params.Memory > maxMemory || params.Threads < 1 {
+		return nil, errors.New("sharecrypt: invalid Argon2id parameters")
+	}
+	plaintext, err := share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, passphraseHeaderLength)
+	copy(header, passphraseMagic)
+	header[4] = passphraseVersion
+	binary.BigEndian.PutUint32(header[5:9], params.Time)
+	binary.BigEndian.PutUint32(header[9:13], params.Memory)
+	header[13] = params.Threads
+	if _, err := rand.Read(header[14:passphraseHeaderLength]); err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(deriveKey(passphrase, header[14:30], params))
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, header[30:passphraseHeaderLength], plaintext, header), nil
+}
+
+// Unwrap decrypts a share wrapped with Wrap.
+func Unwrap(data []byte, passphrase string) (shamir.Share, error) {
+	if len(data) < passphraseHeaderLength+chacha20poly1305.Overhead || !bytes.Equal(data[:4], passphraseMagic) {
+		return shamir.Share{}, shamir.ErrInvalidFormat
+	}
+	if data[4] != passphraseVersion {
+		return shamir.Share{}, shamir.ErrUnsupportedVersion
+	}
+	params := Params{
+		Time:    binary.BigEndian.Uint32(data[5:9]),
+		Memory:  binary.BigEndian.Uint32(data[9:13]),
+		Threads: data[13],
+	}
+	if params.Time < 1 || params.Time > maxTime || params.Memory > maxMemory || params.Threads < 1 {
+		return shamir.Share{}, errors.New("sharecrypt: unsupported Argon2id parameters")
+	}
+
+	aead, err := chacha20poly1305.NewX(deriveKey(passphrase, data[14:30], params))
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	header := data[:passphraseHeaderLength]
+	plaintext, err := aead.Open(nil, header[30:], data[passphraseHeaderLength:], header)
+	if err != nil {
+		return shamir.Share{}, ErrWrongPassphrase
+	}
+	var share shamir.Share
+	if err := share.UnmarshalBinary(plaintext); err != nil {
+		return shamir.Share{}, err
+	}
+	return share, nil
+}
+
+// SplitWithPassphrases splits a secret into one share per passphrase, such that threshold shares are required to
+// recover it, and wraps every share with the passphrase of its holder.
+func SplitWithPassphrases(secret []byte, threshold uint8, passphrases []string) ([][]byte, error) {
+	if len(passphrases) > 255 {
+		return nil, errors.New("sharecrypt: the secret cannot be split between more than 255 holders")
+	}
+	shares, err := shamir.Split(secret, uint8(len(passphrases)), threshold)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := make([][]byte, len(shares))
+	for i, share := range shares {
+		var err error
+		if wrapped[i], err = Wrap(share, passphrases[i]); err != nil {
+			return nil, err
+		}
+	}
+	return wrapped, nil
+}
+
+// PassphraseFunc returns the passphrase of the i-th wrapped share (starting from 0), e.g. by prompting its holder.
+type PassphraseFunc func(i int) (string, error)
+
+// Recover unwraps the shares using the passphrases of their holders, and recovers the secret.
+func Recover(wrapped [][]byte, passphrase PassphraseFunc) ([]byte, error) {
+	shares := make([]shamir.Share, len(wrapped))
+	for i, data := range wrapped {
+		p, err := passphrase(i)
+		if err != nil {
+			return nil, err
+		}
+		if shares[i], err = Unwrap(data, p); err != nil {
+			return nil, fmt.Errorf("share %d: %w", i+1, err)
+		}
+	}
+	return shamir.Recover(shares)
+}
+
+// deriveKey derives the encryption key of a share from a passphrase.
+func deriveKey(passphrase string, salt []byte, params Params) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, keySize)
+}
@@ -0,0 +1,169 @@
+package sharecrypt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// Shares can also be encrypted to the X25519 public key of their recipient, so that the dealer can distribute
+// them over untrusted channels. Following age, a key is derived with HKDF-SHA256 from the X25519 shared secret
+// between an ephemeral key and the key of the recipient, and used to encrypt the share with ChaCha20-Poly1305.
+// An encrypted share is encoded as:
+//
+// 	offset  size  field
+// 	0       4     magic bytes "SHMX"
+// 	4       1     format version
+// 	5       32    ephemeral X25519 public key
+// 	37      -     encrypted binary encoding of the share, and tag
+//
+// The header (the first 37 bytes) is authenticated along with the share.
+
+const (
+	recipientVersion      = 1
+	recipientHeaderLength = 37
+	recipientKeySize      = 32
+	recipientInfo         = "shamir-sss X25519 share"
+)
+
+var recipientMagic = []byte("SHMX")
+
+// ErrDecryption is returned when an encrypted share cannot be decrypted, because it was encrypted to another
+// recipient or was altered.
+var ErrDecryption = errors.New("sharecrypt: the share cannot be decrypted with this key")
+
+// RecipientPublicKey is the X25519 public key of the recipient of a share.
+type RecipientPublicKey [recipientKeySize]byte
+
+// RecipientPrivateKey is the X25519 private key of the recipient of a share.
+type RecipientPrivateKey [recipientKeySize]byte
+
+// GenerateRecipientKey generates a new X25519 private key for a recipient.
+func GenerateRecipientKey() (RecipientPrivateKey, error) {
+	var key RecipientPrivateKey
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return key, err
+	}
+	copy(key[:], private.Bytes())
+	return key, nil
+}
+
+// Public returns the public key of the recipient.
+func (k RecipientPrivateKey) Public() (RecipientPublicKey, error) {
+	var public RecipientPublicKey
+	private, err := ecdh.X25519().NewPrivateKey(k[:])
+	if err != nil {
+		return public, err
+	}
+	copy(public[:], private.PublicKey().Bytes())
+	return public, nil
+}
+
+// SplitEncrypted splits a secret into one share per recipient, such that threshold shares are required to
+// recover it, and encrypts every share to the key of its recipient.
+func SplitEncrypted(secret []byte, recipients []RecipientPublicKey, threshold uint8) ([][]byte, error) {
+	if len(recipients) > 255 {
+		return nil, errors.New("sharecrypt: the secret cannot be split between more than 255 recipients")
+	}
+	shares, err := shamir.Split(secret, uint8(len(recipients)), threshold)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := make([][]byte, len(shares))
+	for i, share := range shares {
+		var err error
+		if encrypted[i], err = Encrypt(share, recipients[i]); err != nil {
+			return nil, err
+		}
+	}
+	return encrypted, nil
+}
+
+// Encrypt encrypts a share to the key of its recipient.
+func Encrypt(share shamir.Share, recipient RecipientPublicKey) ([]byte, error) {
+	plaintext, err := share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	public, err := ecdh.X25519().NewPublicKey(recipient[:])
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(public)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, recipientHeaderLength, recipientHeaderLength+len(plaintext)+chacha20poly1305.Overhead)
+	copy(header, recipientMagic)
+	header[4] = recipientVersion
+	copy(header[5:], ephemeral.PublicKey().Bytes())
+
+	aead, err := recipientAEAD(shared, header[5:], recipient[:])
+	if err != nil {
+		return nil, err
+	}
+	// the key is only used once, so the nonce can be zero
+	return aead.Seal(header, make([]byte, chacha20poly1305.NonceSize), plaintext, header), nil
+}
+
+// Decrypt decrypts a share encrypted to the recipient.
+func Decrypt(data []byte, key RecipientPrivateKey) (shamir.Share, error) {
+	if len(data) < recipientHeaderLength+chacha20poly1305.Overhead || !bytes.Equal(data[:4], recipientMagic) {
+		return shamir.Share{}, shamir.ErrInvalidFormat
+	}
+	if data[4] != recipientVersion {
+		return shamir.Share{}, shamir.ErrUnsupportedVersion
+	}
+	private, err := ecdh.X25519().NewPrivateKey(key[:])
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(data[5:recipientHeaderLength])
+	if err != nil {
+		return shamir.Share{}, shamir.ErrInvalidFormat
+	}
+	shared, err := private.ECDH(ephemeral)
+	if err != nil {
+		return shamir.Share{}, ErrDecryption
+	}
+
+	aead, err := recipientAEAD(shared, data[5:recipientHeaderLength], private.PublicKey().Bytes())
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	header := data[:recipientHeaderLength]
+	plaintext, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), data[recipientHeaderLength:], header)
+	if err != nil {
+		return shamir.Share{}, ErrDecryption
+	}
+	var share shamir.Share
+	if err := share.UnmarshalBinary(plaintext); err != nil {
+		return shamir.Share{}, err
+	}
+	return share, nil
+}
+
+// recipientAEAD derives the key encrypting a share from the X25519 shared secret, salted with the ephemeral and
+// recipient public keys as in age.
+func recipientAEAD(shared, ephemeral, recipient []byte) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	key, err := hkdf.Key(sha256.New, shared, salt, recipientInfo, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
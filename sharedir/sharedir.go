@@ -0,0 +1,234 @@
+package sharedir
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package splits whole directories, e.g. secret stores or certificate authorities.
+//
+// The directory is archived using tar, optionally compressed using gzip, and encrypted using shamir.SealLarge:
+// only the encryption key is split. SplitDir writes the encrypted archive and the binary encoding of every share
+// (see shamir.Share.MarshalBinary) to separate files, and RecoverDir restores the directory tree, including the
+// permissions of the files and the symbolic links.
+
+const (
+	// CiphertextFile is the name of the encrypted archive written by SplitDir.
+	CiphertextFile = "archive.enc"
+	// ShareFilePattern is the pattern of the name of the share files written by SplitDir.
+	ShareFilePattern = "share-%d.bin"
+)
+
+// SplitDir archives and encrypts the src directory, and splits the encryption key into n shares such that
+// threshold shares are required to restore it. The encrypted archive and the shares are written to the dst
+// directory, and the paths of the share files are returned: the shares should then be handed to their custodians.
+func SplitDir(src, dst string, n, threshold uint8, compress bool) ([]string, error) {
+	var archive bytes.Buffer
+	if err := Archive(&archive, src, compress); err != nil {
+		return nil, err
+	}
+	ciphertext, shares, err := shamir.SealLarge(archive.Bytes(), n, threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dst, CiphertextFile), ciphertext, 0600); err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(shares))
+	for i, share := range shares {
+		data, err := share.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		paths[i] = filepath.Join(dst, fmt.Sprintf(ShareFilePattern, share.Index))
+		if err := os.WriteFile(paths[i], data, 0600); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// RecoverDir decrypts the archive written by SplitDir using the share files, and restores the directory tree
+// into the dst directory.
+func RecoverDir(ciphertextPath string, sharePaths []string, dst string) error {
+	ciphertext, err := os.ReadFile(ciphertextPath)
+	if err != nil {
+		return err
+	}
+	shares := make([]shamir.Share, len(sharePaths))
+	for i, path := range sharePaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := shares[i].UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	archive, err := shamir.OpenLarge(ciphertext, shares)
+	if err != nil {
+		return err
+	}
+	return Extract(bytes.NewReader(archive), dst)
+}
+
+// Archive writes the tar archive of a directory to w, compressed using gzip if compress is true.
+// Regular files, directories and symbolic links are archived, along with their permissions.
+func Archive(w io.Writer, dir string, compress bool) error {
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil || name == "." {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		switch {
+		case info.Mode().IsRegular(), info.IsDir():
+		case info.Mode()&fs.ModeSymlink != 0:
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("sharedir: %s: unsupported file type", path)
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		// the owners are not restored, do not leak them
+		header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if gz != nil {
+		return gz.Close()
+	}
+	return nil
+}
+
+// Extract restores a directory tree archived with Archive into dir, detecting compression.
+// Entries cannot be written outside of dir, even through symbolic links.
+func Extract(r io.Reader, dir string) error {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	// the permissions of directories are restored last, so that read-only directories can be filled
+	type directory struct {
+		name string
+		mode fs.FileMode
+	}
+	var directories []directory
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(header.Name)
+		mode := fs.FileMode(header.Mode).Perm()
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := root.MkdirAll(name, 0700); err != nil {
+				return err
+			}
+			directories = append(directories, directory{name, mode})
+		case tar.TypeReg:
+			file, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(file, tr)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+			if err := root.Chmod(name, mode); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := root.Symlink(header.Linkname, name); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("sharedir: %s: unsupported entry type", header.Name)
+		}
+	}
+
+	for i := len(directories) - 1; i >= 0; i-- {
+		if err := root.Chmod(directories[i].name, directories[i].mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package sharegcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/sharewrap"
+)
+
+// This package wraps shares with Google Cloud KMS keys (see sharewrap), so that shares stored in the cloud are
+// protected by the IAM policies of the keys, and every unwrapping is recorded by Cloud Audit Logs. The data keys
+// are encrypted with the symmetric key of the custodian through the REST API of Cloud KMS, the context being
+// authenticated as additional data (see sharewrap.EncodeContext). The integrity of the requests and responses is
+// checked with CRC32C checksums, as recommended by Google.
+//
+// The package does not depend on the Google Cloud client libraries: requests are sent with an HTTP client adding
+// the OAuth2 credentials, such as the client returned by google.DefaultClient of golang.org/x/oauth2/google with
+// the https://www.googleapis.com/auth/cloudkms scope.
+
+// Type is the type of the wrapper, recorded in the wrapped shares.
+const Type = "gcp-kms"
+
+// DefaultEndpoint is the endpoint of the REST API of Cloud KMS.
+const DefaultEndpoint = "https://cloudkms.googleapis.com/v1/"
+
+// keyName matches the resource names of crypto keys, and captures the name of the key of a key version.
+var keyName = regexp.MustCompile(`^(projects/[^/]+/locations/[^/]+/keyRings/[^/]+/cryptoKeys/[^/]+)(/cryptoKeyVersions/[^/]+)?$`)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+var _ sharewrap.Wrapper = (*Wrapper)(nil)
+
+// Wrapper wraps data keys with a Cloud KMS key. It implements sharewrap.Wrapper.
+type Wrapper struct {
+	client   *http.Client
+	key      string
+	endpoint string
+}
+
+// New returns a wrapper using the key named
+// projects/PROJECT/locations/LOCATION/keyRings/RING/cryptoKeys/KEY (or one of its versions), which may be empty if
+// the wrapper is only used to unwrap shares. client must add the OAuth2 credentials to the requests.
+func New(client *http.Client, key string) (*Wrapper, error) {
+	if key != "" && !keyName.MatchString(key) {
+		return nil, fmt.Errorf("sharegcp: invalid key name %q", key)
+	}
+	return &Wrapper{client: client, key: key, endpoint: DefaultEndpoint}, nil
+}
+
+// WithEndpoint returns a copy of the wrapper sending its requests to another endpoint, e.g. a regional or
+// private endpoint.
+func (w *Wrapper) WithEndpoint(endpoint string) *Wrapper {
+	c := *w
+	c.endpoint = strings.TrimSuffix(endpoint, "/") + "/"
+	return &c
+}
+
+// Type implements sharewrap.Wrapper.
+func (w *Wrapper) Type() string {
+	return Type
+}
+
+// checksum is the JSON encoding of the CRC32C checksums of the API, an int64 encoded as a string.
+type checksum string
+
+func newChecksum(data []byte) checksum {
+	return checksum(strconv.FormatUint(uint64(crc32.Checksum(data, castagnoli)), 10))
+}
+
+type encryptRequest struct {
+	Plaintext                         []byte   `json:"plaintext"`
+	AdditionalAuthenticatedData       []byte   `json:"additionalAuthenticatedData"`
+	PlaintextCRC32C                   checksum `json:"plaintextCrc32c"`
+	AdditionalAuthenticatedDataCRC32C checksum `json:"additionalAuthenticatedDataCrc32c"`
+}
+
+type encryptResponse struct {
+	Name                                      string   `json:"name"`
+	Ciphertext                                []byte   `json:"ciphertext"`
+	CiphertextCRC32C                          checksum `json:"ciphertextCrc32c"`
+	VerifiedPlaintextCRC32C                   bool     `json:"verifiedPlaintextCrc32c"`
+	VerifiedAdditionalAuthenticatedDataCRC32C bool     `json:"verifiedAdditionalAuthenticatedDataCrc32c"`
+}
+
+type decryptRequest struct {
+	Ciphertext                        []byte   `json:"ciphertext"`
+	AdditionalAuthenticatedData       []byte   `json:"additionalAuthenticatedData"`
+	CiphertextCRC32C                  checksum `json:"ciphertextCrc32c"`
+	AdditionalAuthenticatedDataCRC32C checksum `json:"additionalAuthenticatedDataCrc32c"`
+}
+
+type decryptResponse struct {
+	Plaintext       []byte   `json:"plaintext"`
+	PlaintextCRC32C checksum `json:"plaintextCrc32c"`
+}
+
+// WrapKey implements sharewrap.Wrapper. The identifier of the key is its resource name, without the version.
+func (w *Wrapper) WrapKey(ctx context.Context, key []byte, context map[string]string) (string, []byte, error) {
+	if w.key == "" {
+		return "", nil, errors.New("sharegcp: no key to wrap with")
+	}
+	aad := sharewrap.EncodeContext(context)
+	var response encryptResponse
+	err := w.call(ctx, w.key+":encrypt", encryptRequest{
+		Plaintext:                         key,
+		AdditionalAuthenticatedData:       aad,
+		PlaintextCRC32C:                   newChecksum(key),
+		AdditionalAuthenticatedDataCRC32C: newChecksum(aad),
+	}, &response)
+	if err != nil {
+		return "", nil, err
+	}
+	if !response.VerifiedPlaintextCRC32C || !response.VerifiedAdditionalAuthenticatedDataCRC32C ||
+		response.CiphertextCRC32C != newChecksum(response.Ciphertext) {
+		return "", nil, errors.New("sharegcp: the request or the response was corrupted")
+	}
+	// data keys are decrypted with the key rather than with the version which encrypted them
+	return keyName.FindStringSubmatch(w.key)[1], response.Ciphertext, nil
+}
+
+// UnwrapKey implements sharewrap.Wrapper.
+func (w *Wrapper) UnwrapKey(ctx context.Context, keyID string, wrapped []byte, context map[string]string) ([]byte, error) {
+	match := keyName.FindStringSubmatch(keyID)
+	if match == nil {
+		return nil, fmt.Errorf("sharegcp: invalid key name %q", keyID)
+	}
+	aad := sharewrap.EncodeContext(context)
+	var response decryptResponse
+	err := w.call(ctx, match[1]+":decrypt", decryptRequest{
+		Ciphertext:                        wrapped,
+		AdditionalAuthenticatedData:       aad,
+		CiphertextCRC32C:                  newChecksum(wrapped),
+		AdditionalAuthenticatedDataCRC32C: newChecksum(aad),
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response.PlaintextCRC32C != newChecksum(response.Plaintext) {
+		clear(response.Plaintext)
+		return nil, errors.New("sharegcp: the response was corrupted")
+	}
+	return response.Plaintext, nil
+}
+
+// call calls a method of the API.
+func (w *Wrapper) call(ctx context.Context, method string, request, response any) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	defer clear(body)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, w.endpoint+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(r)
+	if err != nil {
+		return fmt.Errorf("sharegcp: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("sharegcp: %w", err)
+	}
+	defer clear(data)
+	if resp.StatusCode != http.StatusOK {
+		var apiError struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiError) == nil && apiError.Error.Message != "" {
+			return fmt.Errorf("sharegcp: %s: %s", resp.Status, apiError.Error.Message)
+		}
+		return fmt.Errorf("sharegcp: %s", resp.Status)
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("sharegcp: invalid response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/sharek8s"
+)
+
+// This controller is an example of the sharek8s package. It watches the Secrets of a namespace labeled
+// shamir-sss.io/split=true, splits each of them into one share per target (a namespace of a kubeconfig context,
+// e.g. another cluster), then empties the data of the Secret and records the split identifier in its
+// shamir-sss.io/split-id annotation, so that the watched cluster no longer holds the credential:
+//
+// 	controller --namespace payments --threshold 2 --targets eu/shares,us/shares,ap/shares
+//
+// The Secret is restored from the targets with --recover:
+//
+// 	controller --namespace payments --targets ... --recover 3bd9d9d0-5c0e-4667-a842-54efae534ebd
+//
+// The watched cluster is reached with the in-cluster configuration unless --kubeconfig is set, and the targets
+// with the contexts of the kubeconfig. The controller must be allowed to watch and update the Secrets of the
+// namespace, and to create, get and delete the Secrets of the targets.
+
+const (
+	labelSplit         = "shamir-sss.io/split"
+	annotationSplitID  = "shamir-sss.io/split-id"
+	defaultResync      = 10 * time.Minute
+	reconcileTimeout   = time.Minute
+	targetSeparator    = ","
+	namespaceSeparator = "/"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("controller: ")
+	kubeconfig := flag.String("kubeconfig", "", "kubeconfig of the watched cluster and of the targets")
+	namespace := flag.String("namespace", "default", "namespace of the Secrets to split")
+	targets := flag.String("targets", "", "comma-separated targets of the shares, as CONTEXT/NAMESPACE")
+	threshold := flag.Uint("threshold", 2, "number of targets required to restore a Secret")
+	recoverID := flag.String("recover", "", "split identifier of the Secret to restore, instead of watching")
+	flag.Parse()
+	if *targets == "" || *threshold > 255 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config, err := restConfig(*kubeconfig, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	stores, err := targetStores(*kubeconfig, *targets)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *recoverID != "" {
+		id, err := shamir.ParseSplitID(*recoverID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := restore(ctx, client, *namespace, id, stores); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	c := &controller{client: client, stores: stores, threshold: uint8(*threshold)}
+	factory := informers.NewSharedInformerFactoryWithOptions(client, defaultResync,
+		informers.WithNamespace(*namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = labelSplit + "=true"
+		}))
+	informer := factory.Core().V1().Secrets().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.reconcile,
+		UpdateFunc: func(_, secret any) { c.reconcile(secret) },
+	})
+	factory.Start(ctx.Done())
+	<-ctx.Done()
+	factory.Shutdown()
+}
+
+// controller splits the Secrets of the watched namespace. The event handlers of an informer are called
+// sequentially, so it needs no locking.
+type controller struct {
+	client    kubernetes.Interface
+	stores    []sharek8s.Store
+	threshold uint8
+}
+
+// reconcile splits a Secret which has not been split yet.
+func (c *controller) reconcile(object any) {
+	secret, ok := object.(*corev1.Secret)
+	if !ok || secret.Annotations[annotationSplitID] != "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	defer cancel()
+	id, err := sharek8s.Distribute(ctx, secret, c.threshold, c.stores)
+	if err != nil {
+		log.Printf("%s/%s: %v", secret.Namespace, secret.Name, err)
+		return
+	}
+
+	// the informer cache must not be modified
+	split := secret.DeepCopy()
+	if split.Annotations == nil {
+		split.Annotations = map[string]string{}
+	}
+	split.Annotations[annotationSplitID] = id.String()
+	split.Data = nil
+	if _, err := c.client.CoreV1().Secrets(split.Namespace).Update(ctx, split, metav1.UpdateOptions{}); err != nil {
+		// the shares are removed, so that the Secret is split again on the next event
+		for _, store := range c.stores {
+			store.Delete(ctx, id)
+		}
+		log.Printf("%s/%s: %v", secret.Namespace, secret.Name, err)
+		return
+	}
+	log.Printf("%s/%s: split %s into %d targets", secret.Namespace, secret.Name, id, len(c.stores))
+}
+
+// restore restores the data of a split Secret from the targets.
+func restore(ctx context.Context, client kubernetes.Interface, namespace string, id shamir.SplitID, stores []sharek8s.Store) error {
+	recovered, err := sharek8s.Collect(ctx, id, stores)
+	if err != nil {
+		return err
+	}
+	if recovered.Namespace != namespace {
+		return fmt.Errorf("the Secret belongs to the %s namespace", recovered.Namespace)
+	}
+	secrets := client.CoreV1().Secrets(namespace)
+	secret, err := secrets.Get(ctx, recovered.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if secret.Annotations[annotationSplitID] != id.String() {
+		return errors.New("the Secret was modified since it was split")
+	}
+	// the label is removed, so that the restored Secret is not split again
+	delete(secret.Labels, labelSplit)
+	delete(secret.Annotations, annotationSplitID)
+	secret.Type = recovered.Type
+	secret.Data = recovered.Data
+	if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	log.Printf("%s/%s: restored from split %s", namespace, recovered.Name, id)
+	return nil
+}
+
+// targetStores returns the stores of the targets.
+func targetStores(kubeconfig, targets string) ([]sharek8s.Store, error) {
+	var stores []sharek8s.Store
+	for target := range strings.SplitSeq(targets, targetSeparator) {
+		kubeContext, namespace, ok := strings.Cut(strings.TrimSpace(target), namespaceSeparator)
+		if !ok || kubeContext == "" || namespace == "" {
+			return nil, fmt.Errorf("invalid target %q, expected CONTEXT/NAMESPACE", target)
+		}
+		config, err := restConfig(kubeconfig, kubeContext)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", target, err)
+		}
+		client, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("target %s: %w", target, err)
+		}
+		stores = append(stores, sharek8s.NewSecretStore(client, namespace))
+	}
+	return stores, nil
+}
+
+// restConfig returns the configuration of a context of the kubeconfig (KUBECONFIG or ~/.kube/config if empty), or
+// the in-cluster configuration if both are empty.
+func restConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	if kubeconfig == "" && kubeContext == "" {
+		return rest.InClusterConfig()
+	}
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+}
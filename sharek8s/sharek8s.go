@@ -0,0 +1,162 @@
+package sharek8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package splits Kubernetes Secrets, so that no single cluster holds a whole credential: the data of a Secret
+// is split into shares, every share is written to its own store, typically a Secret in another namespace or
+// cluster (see SecretStore), and the Secret is reassembled from any threshold of the stores. Other stores, such
+// as the wrapped shares of sharewrap, can be used by implementing Store. The controller directory holds a small
+// controller built on these functions.
+//
+// The split payload is the JSON encoding of the namespace, name, type and data of the Secret, padded (see
+// shamir.WithPadding) so that the shares do not reveal the length of the data. The label of every share is the
+// namespace and name of the split Secret.
+
+// ErrNotEnoughShares is returned when fewer shares than the threshold can be collected.
+var ErrNotEnoughShares = errors.New("sharek8s: not enough shares to recover the Secret")
+
+// Store holds the shares of split Secrets, one share per split.
+type Store interface {
+	// Put stores a share. It fails if the store already holds a share of the split.
+	Put(ctx context.Context, share shamir.Share) error
+	// Get returns the share of a split, or an error wrapping ErrNotFound.
+	Get(ctx context.Context, id shamir.SplitID) (shamir.Share, error)
+	// Delete deletes the share of a split.
+	Delete(ctx context.Context, id shamir.SplitID) error
+}
+
+// payload is the part of a Secret which is split.
+type payload struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Type      corev1.SecretType `json:"type,omitempty"`
+	Data      map[string][]byte `json:"data"`
+}
+
+// SplitSecret splits the data of a Secret into n shares such that threshold shares are required to recover it.
+// The StringData of the Secret is ignored, as it is only set on writes.
+func SplitSecret(secret *corev1.Secret, n, threshold uint8) ([]shamir.Share, error) {
+	if threshold < 2 || threshold > n {
+		return nil, errors.New("sharek8s: the threshold must be at least 2 and at most the number of shares")
+	}
+	data, err := json.Marshal(payload{
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+		Type:      secret.Type,
+		Data:      secret.Data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer clear(data)
+	shares, err := shamir.Split(data, n, threshold, shamir.WithPadding())
+	if err != nil {
+		return nil, err
+	}
+	for i := range shares {
+		shares[i].Label = secret.Namespace + "/" + secret.Name
+	}
+	return shares, nil
+}
+
+// RecoverSecret reassembles a Secret split with SplitSecret. The shares must belong to the same split, and there
+// must be at least as many as the threshold.
+func RecoverSecret(shares []shamir.Share) (*corev1.Secret, error) {
+	if err := checkShares(shares); err != nil {
+		return nil, err
+	}
+	data, err := shamir.Recover(shares)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(data)
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("sharek8s: invalid Secret: %w", err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: p.Namespace, Name: p.Name},
+		Type:       p.Type,
+		Data:       p.Data,
+	}, nil
+}
+
+// Distribute splits a Secret into one share per store such that threshold shares are required to recover it, and
+// writes every share to its store. If a share cannot be written, the shares already written are deleted. It
+// returns the split identifier, to be passed to Collect.
+func Distribute(ctx context.Context, secret *corev1.Secret, threshold uint8, stores []Store) (shamir.SplitID, error) {
+	if len(stores) > 255 {
+		return shamir.SplitID{}, errors.New("sharek8s: a Secret cannot be split into more than 255 stores")
+	}
+	shares, err := SplitSecret(secret, uint8(len(stores)), threshold)
+	if err != nil {
+		return shamir.SplitID{}, err
+	}
+	defer func() {
+		for _, share := range shares {
+			clear(share.Payload)
+		}
+	}()
+	for i, share := range shares {
+		if err := stores[i].Put(ctx, share); err != nil {
+			for _, store := range stores[:i] {
+				store.Delete(ctx, share.SplitID)
+			}
+			return shamir.SplitID{}, fmt.Errorf("store %d: %w", i+1, err)
+		}
+	}
+	return shares[0].SplitID, nil
+}
+
+// Collect reads the shares of a split from the stores, in order, until the threshold is reached, and reassembles
+// the Secret. Stores which cannot be read are skipped, so that the Secret can be recovered while some clusters are
+// unavailable; their errors are returned along with ErrNotEnoughShares if the threshold is not reached.
+func Collect(ctx context.Context, id shamir.SplitID, stores []Store) (*corev1.Secret, error) {
+	var shares []shamir.Share
+	defer func() {
+		for _, share := range shares {
+			clear(share.Payload)
+		}
+	}()
+	var errs []error
+	for i, store := range stores {
+		share, err := store.Get(ctx, id)
+		if err == nil && share.SplitID != id {
+			err = errors.New("sharek8s: the share belongs to another split")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("store %d: %w", i+1, err))
+			continue
+		}
+		shares = append(shares, share)
+		if int(shares[0].Threshold) <= len(shares) {
+			return RecoverSecret(shares)
+		}
+	}
+	return nil, errors.Join(append([]error{ErrNotEnoughShares}, errs...)...)
+}
+
+// checkShares checks that the shares belong to the same split and reach its threshold, which shamir.Recover does
+// not check.
+func checkShares(shares []shamir.Share) error {
+	if len(shares) == 0 || len(shares) < int(shares[0].Threshold) || len(shares) < 2 {
+		return ErrNotEnoughShares
+	}
+	first := shares[0]
+	for _, share := range shares {
+		if share.SplitID != first.SplitID || share.Threshold != first.Threshold || share.Padded != first.Padded {
+			return fmt.Errorf("sharek8s: share %d does not match share %d", share.Index, first.Index)
+		}
+	}
+	return nil
+}
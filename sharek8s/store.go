@@ -0,0 +1,106 @@
+package sharek8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// A SecretStore writes every share to an immutable Secret of its namespace, named shamir-SPLIT and of type
+// SecretType. The binary encoding of the share is held by the DataKey key, and the Secrets are labeled with the
+// split identifier and annotated with the index of the share and the Secret it was split from, so that they can
+// be listed with a label selector. Using one store per cluster spreads the credential over the clusters.
+
+// Labels, annotations and keys of the Secrets holding shares.
+const (
+	SecretType       corev1.SecretType = "shamir-sss.io/share"
+	LabelSplitID                       = "shamir-sss.io/split-id"
+	AnnotationIndex                    = "shamir-sss.io/index"
+	AnnotationSource                   = "shamir-sss.io/source"
+	DataKey                            = "share"
+)
+
+// ErrNotFound is returned when a store holds no share of the split.
+var ErrNotFound = errors.New("sharek8s: no share of this split in the store")
+
+var _ Store = (*SecretStore)(nil)
+
+// SecretStore stores the shares as Secrets of a namespace. It implements Store.
+type SecretStore struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// NewSecretStore returns a store writing to the namespace of the cluster of client. The service account of the
+// client must be allowed to create, get and delete Secrets in the namespace.
+func NewSecretStore(client kubernetes.Interface, namespace string) *SecretStore {
+	return &SecretStore{client: client, namespace: namespace}
+}
+
+// SecretName returns the name of the Secret holding the share of a split.
+func SecretName(id shamir.SplitID) string {
+	return "shamir-" + id.String()
+}
+
+// Put implements Store.
+func (s *SecretStore) Put(ctx context.Context, share shamir.Share) error {
+	data, err := share.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	immutable := true
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SecretName(share.SplitID),
+			Namespace: s.namespace,
+			Labels:    map[string]string{LabelSplitID: share.SplitID.String()},
+			Annotations: map[string]string{
+				AnnotationIndex:  strconv.Itoa(int(share.Index)),
+				AnnotationSource: share.Label,
+			},
+		},
+		Type:      SecretType,
+		Immutable: &immutable,
+		Data:      map[string][]byte{DataKey: data},
+	}
+	if _, err := s.client.CoreV1().Secrets(s.namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("sharek8s: %w", err)
+	}
+	return nil
+}
+
+// Get implements Store.
+func (s *SecretStore) Get(ctx context.Context, id shamir.SplitID) (shamir.Share, error) {
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, SecretName(id), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return shamir.Share{}, ErrNotFound
+	}
+	if err != nil {
+		return shamir.Share{}, fmt.Errorf("sharek8s: %w", err)
+	}
+	var share shamir.Share
+	if err := share.UnmarshalBinary(secret.Data[DataKey]); err != nil {
+		return shamir.Share{}, fmt.Errorf("sharek8s: Secret %s/%s: %w", s.namespace, secret.Name, err)
+	}
+	return share, nil
+}
+
+// Delete implements Store.
+func (s *SecretStore) Delete(ctx context.Context, id shamir.SplitID) error {
+	err := s.client.CoreV1().Secrets(s.namespace).Delete(ctx, SecretName(id), metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("sharek8s: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package sharekey
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// Ethereum accounts are split as their 32-byte secp256k1 private key, whether it is provided raw or in a
+// keystore file (version 3 of the Web3 Secret Storage format), which is decrypted with its passphrase. On
+// recovery, a new keystore is encrypted with scrypt using the parameters of geth, and the address of the key can
+// be checked against the address of the account which was split.
+
+const (
+	ethereumKeySize = 32
+	keystoreVersion = 3
+	scryptN         = 1 << 18
+	scryptR         = 8
+	scryptP         = 1
+	keystoreKeySize = 32
+)
+
+// ErrAddressMismatch is returned when a recovered key does not match the expected address.
+var ErrAddressMismatch = errors.New("sharekey: the recovered key does not match the address of the account")
+
+// keystore is the JSON encoding of a version 3 keystore.
+type keystore struct {
+	Address string         `json:"address"`
+	Crypto  keystoreCrypto `json:"crypto"`
+	ID      string         `json:"id"`
+	Version int            `json:"version"`
+}
+
+type keystoreCrypto struct {
+	Cipher       string `json:"cipher"`
+	CipherText   string `json:"ciphertext"`
+	CipherParams struct {
+		IV string `json:"iv"`
+	} `json:"cipherparams"`
+	KDF       string          `json:"kdf"`
+	KDFParams json.RawMessage `json:"kdfparams"`
+	MAC       string          `json:"mac"`
+}
+
+type scryptParams struct {
+	DKLen int    `json:"dklen"`
+	N     int    `json:"n"`
+	P     int    `json:"p"`
+	R     int    `json:"r"`
+	Salt  string `json:"salt"`
+}
+
+type pbkdf2Params struct {
+	C     int    `json:"c"`
+	DKLen int    `json:"dklen"`
+	PRF   string `json:"prf"`
+	Salt  string `json:"salt"`
+}
+
+// SplitEthereumKey splits a raw Ethereum private key into n shares such that threshold shares are required to
+// recover it.
+func SplitEthereumKey(key []byte, n, threshold uint8) ([]shamir.Share, error) {
+	if _, err := EthereumAddress(key); err != nil {
+		return nil, err
+	}
+	return shamir.Split(key, n, threshold)
+}
+
+// SplitEthereumKeystore decrypts an Ethereum keystore file with its passphrase, and splits the private key into
+// n shares such that threshold shares are required to recover it.
+func SplitEthereumKeystore(data, passphrase []byte, n, threshold uint8) ([]shamir.Share, error) {
+	key, err := decryptKeystore(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := SplitEthereumKey(key, n, threshold)
+	clear(key)
+	return shares, err
+}
+
+// RecoverEthereumKey recovers a raw Ethereum private key. If address is not empty, the address of the key must
+// match it, otherwise ErrAddressMismatch is returned.
+func RecoverEthereumKey(shares []shamir.Share, address string) ([]byte, error) {
+	key, err := shamir.Recover(shares)
+	if err != nil {
+		return nil, err
+	}
+	recovered, err := EthereumAddress(key)
+	if err != nil {
+		return nil, err
+	}
+	if address != "" && !strings.EqualFold(strings.TrimPrefix(address, "0x"), strings.TrimPrefix(recovered, "0x")) {
+		return nil, ErrAddressMismatch
+	}
+	return key, nil
+}
+
+// RecoverEthereumKeystore recovers an Ethereum private key and encrypts it in a new keystore file with the
+// passphrase. If address is not empty, the address of the key must match it, otherwise ErrAddressMismatch is
+// returned.
+func RecoverEthereumKeystore(shares []shamir.Share, passphrase []byte, address string) ([]byte, error) {
+	key, err := RecoverEthereumKey(shares, address)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encryptKeystore(key, passphrase)
+	clear(key)
+	return data, err
+}
+
+// EthereumAddress returns the address of an Ethereum private key, with the checksum of EIP-55.
+func EthereumAddress(key []byte) (string, error) {
+	var scalar secp256k1.ModNScalar
+	if len(key) != ethereumKeySize || scalar.SetByteSlice(key) || scalar.IsZero() {
+		return "", errors.New("sharekey: invalid Ethereum private key")
+	}
+	public := secp256k1.NewPrivateKey(&scalar).PubKey().SerializeUncompressed()
+	address := hex.EncodeToString(keccak256(public[1:])[12:])
+
+	// EIP-55: a letter is upper case if the corresponding nibble of the hash of the address is at least 8
+	hash := hex.EncodeToString(keccak256([]byte(address)))
+	checksummed := []byte(address)
+	for i, c := range checksummed {
+		if c >= 'a' && hash[i] >= '8' {
+			checksummed[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(checksummed), nil
+}
+
+// decryptKeystore decrypts the private key of a keystore.
+func decryptKeystore(data, passphrase []byte) ([]byte, error) {
+	var k keystore
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	if k.Version != keystoreVersion {
+		return nil, fmt.Errorf("sharekey: unsupported keystore version %d", k.Version)
+	}
+	if k.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("sharekey: unsupported keystore cipher %q", k.Crypto.Cipher)
+	}
+	ciphertext, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(k.Crypto.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, errors.New("sharekey: invalid keystore IV")
+	}
+	mac, err := hex.DecodeString(k.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+
+	var derived []byte
+	switch k.Crypto.KDF {
+	case "scrypt":
+		var params scryptParams
+		if err := json.Unmarshal(k.Crypto.KDFParams, &params); err != nil {
+			return nil, err
+		}
+		salt, err := hex.DecodeString(params.Salt)
+		if err != nil {
+			return nil, err
+		}
+		if params.DKLen < keystoreKeySize {
+			return nil, errors.New("sharekey: invalid keystore key length")
+		}
+		if derived, err = scrypt.Key(passphrase, salt, params.N, params.R, params.P, params.DKLen); err != nil {
+			return nil, err
+		}
+	case "pbkdf2":
+		var params pbkdf2Params
+		if err := json.Unmarshal(k.Crypto.KDFParams, &params); err != nil {
+			return nil, err
+		}
+		if params.PRF != "hmac-sha256" {
+			return nil, fmt.Errorf("sharekey: unsupported keystore PRF %q", params.PRF)
+		}
+		salt, err := hex.DecodeString(params.Salt)
+		if err != nil {
+			return nil, err
+		}
+		if params.DKLen < keystoreKeySize {
+			return nil, errors.New("sharekey: invalid keystore key length")
+		}
+		if derived, err = pbkdf2.Key(sha256.New, string(passphrase), salt, params.C, params.DKLen); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("sharekey: unsupported keystore KDF %q", k.Crypto.KDF)
+	}
+
+	if subtle.ConstantTimeCompare(keccak256(derived[16:32], ciphertext), mac) != 1 {
+		return nil, ErrIncorrectPassphrase
+	}
+	key, err := aesCTR(derived[:16], iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	address, err := EthereumAddress(key)
+	if err != nil {
+		return nil, err
+	}
+	if k.Address != "" && !strings.EqualFold(strings.TrimPrefix(k.Address, "0x"), address[2:]) {
+		return nil, ErrAddressMismatch
+	}
+	return key, nil
+}
+
+// encryptKeystore encrypts a private key in a new keystore.
+func encryptKeystore(key, passphrase []byte) ([]byte, error) {
+	random := make([]byte, 32+aes.BlockSize+16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	salt, iv, id := random[:32], random[32:32+aes.BlockSize], random[32+aes.BlockSize:]
+	derived, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keystoreKeySize)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := aesCTR(derived[:16], iv, key)
+	if err != nil {
+		return nil, err
+	}
+	params, err := json.Marshal(scryptParams{
+		DKLen: keystoreKeySize,
+		N:     scryptN,
+		P:     scryptP,
+		R:     scryptR,
+		Salt:  hex.EncodeToString(salt),
+	})
+	if err != nil {
+		return nil, err
+	}
+	address, err := EthereumAddress(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// the identifier is a random (version 4) UUID
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	k := keystore{
+		Address: strings.ToLower(address[2:]),
+		ID:      fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16]),
+		Version: keystoreVersion,
+	}
+	k.Crypto = keystoreCrypto{
+		Cipher:     "aes-128-ctr",
+		CipherText: hex.EncodeToString(ciphertext),
+		KDF:        "scrypt",
+		KDFParams:  params,
+		MAC:        hex.EncodeToString(keccak256(derived[16:32], ciphertext)),
+	}
+	k.Crypto.CipherParams.IV = hex.EncodeToString(iv)
+	return json.Marshal(k)
+}
+
+// aesCTR encrypts or decrypts data with AES in counter mode.
+func aesCTR(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(out, data)
+	return out, nil
+}
+
+// keccak256 computes the Keccak-256 hash used by Ethereum, which predates the SHA-3 padding.
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,71 @@
+package sharekey
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// SplitPGPKey splits an ASCII armored OpenPGP private key into n shares such that threshold shares are required
+// to recover it with RecoverPGPKey. The passphrase of a protected key must be provided, and is ignored if the key
+// is not protected. The encrypted key is returned along with the shares.
+func SplitPGPKey(armored, passphrase []byte, n, threshold uint8) ([]byte, []shamir.Share, error) {
+	entities, err := readPGPKey(armored)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entity := range entities {
+		if !pgpKeyEncrypted(entity) {
+			continue
+		}
+		if passphrase == nil {
+			return nil, nil, ErrPassphraseRequired
+		}
+		if err := entity.DecryptPrivateKeys(passphrase); err != nil {
+			return nil, nil, ErrIncorrectPassphrase
+		}
+	}
+	return shamir.SealLarge(armored, n, threshold)
+}
+
+// RecoverPGPKey recovers an ASCII armored OpenPGP private key split by SplitPGPKey.
+func RecoverPGPKey(ciphertext []byte, shares []shamir.Share) ([]byte, error) {
+	armored, err := shamir.OpenLarge(ciphertext, shares)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := readPGPKey(armored); err != nil {
+		return nil, err
+	}
+	return armored, nil
+}
+
+// readPGPKey parses an armored key ring and checks that its keys are private keys.
+func readPGPKey(armored []byte) (openpgp.EntityList, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armored))
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range entities {
+		if entity.PrivateKey == nil {
+			return nil, errors.New("sharekey: the OpenPGP key is not a private key")
+		}
+	}
+	return entities, nil
+}
+
+// pgpKeyEncrypted returns true if the primary key or a subkey of the entity is protected by a passphrase.
+func pgpKeyEncrypted(entity *openpgp.Entity) bool {
+	if entity.PrivateKey.Encrypted {
+		return true
+	}
+	for _, subkey := range entity.Subkeys {
+		if subkey.PrivateKey != nil && subkey.PrivateKey.Encrypted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package sharekey
+
+import (
+	"crypto/x509"
+	"errors"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package splits private key files, so that the shell pipelines which read a key, split it and reassemble
+// it are not needed. The key files are split as they are using shamir.SealLarge: the file is encrypted with a
+// fresh key and the encryption key is split, and the recovered file is identical to the original one.
+//
+// The keys are parsed before they are split, and after they are recovered, so that an invalid file is detected
+// before its shares are distributed. A key protected by a passphrase remains protected, and the passphrase is
+// required to split it, so that a key whose passphrase was forgotten is not escrowed.
+
+// ErrPassphraseRequired is returned when a key is protected by a passphrase which was not provided.
+var ErrPassphraseRequired = errors.New("sharekey: the key is protected by a passphrase")
+
+// ErrIncorrectPassphrase is returned when the passphrase of a key is incorrect.
+var ErrIncorrectPassphrase = errors.New("sharekey: the passphrase of the key is incorrect")
+
+// SplitSSHKey splits an SSH private key file, in the OpenSSH or PEM format, into n shares such that threshold
+// shares are required to recover it with RecoverSSHKey. The passphrase of a protected key must be provided, and
+// is ignored if the key is not protected. The encrypted key is returned along with the shares.
+func SplitSSHKey(key, passphrase []byte, n, threshold uint8) ([]byte, []shamir.Share, error) {
+	_, err := ssh.ParseRawPrivateKey(key)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == nil {
+			return nil, nil, ErrPassphraseRequired
+		}
+		if _, err = ssh.ParseRawPrivateKeyWithPassphrase(key, passphrase); errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, nil, ErrIncorrectPassphrase
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return shamir.SealLarge(key, n, threshold)
+}
+
+// RecoverSSHKey recovers an SSH private key file split by SplitSSHKey.
+func RecoverSSHKey(ciphertext []byte, shares []shamir.Share) ([]byte, error) {
+	key, err := shamir.OpenLarge(ciphertext, shares)
+	if err != nil {
+		return nil, err
+	}
+	var missing *ssh.PassphraseMissingError
+	if _, err := ssh.ParseRawPrivateKey(key); err != nil && !errors.As(err, &missing) {
+		return nil, err
+	}
+	return key, nil
+}
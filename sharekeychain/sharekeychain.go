@@ -0,0 +1,90 @@
+package sharekeychain
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package keeps the share of a custodian in the credential store of their operating system, so that it is
+// protected by their login session rather than left in a file: the Keychain on macOS (through /usr/bin/security),
+// the Credential Manager on Windows (encrypted with DPAPI), and the Secret Service on Linux and the BSDs (e.g.
+// GNOME Keyring or KWallet, over D-Bus).
+//
+// Shares are stored under the Service service, with their split identifier as account, so that a custodian holds
+// at most one share of a split. The secret is the base64 encoding of the binary encoding of the share. The
+// Credential Manager limits secrets to 2560 bytes, which holds the shares of secrets up to about 1800 bytes.
+
+// Service is the service the shares are stored under.
+const Service = "shamir-sss"
+
+var (
+	// ErrNotFound is returned when the credential store holds no share of the split.
+	ErrNotFound = errors.New("sharekeychain: no share of this split in the credential store")
+	// ErrExists is returned when storing a share of a split whose share is already stored.
+	ErrExists = errors.New("sharekeychain: a share of this split is already in the credential store")
+)
+
+// Store stores a share in the credential store. The share of the split must not already be stored: it must be
+// deleted first.
+func Store(share shamir.Share) error {
+	account := share.SplitID.String()
+	if _, err := keyring.Get(Service, account); err == nil {
+		return ErrExists
+	} else if !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("sharekeychain: %w", err)
+	}
+	data, err := share.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	defer clear(data)
+	if err := keyring.Set(Service, account, base64.StdEncoding.EncodeToString(data)); err != nil {
+		if errors.Is(err, keyring.ErrSetDataTooBig) {
+			return errors.New("sharekeychain: the share is too long for the credential store")
+		}
+		return fmt.Errorf("sharekeychain: %w", err)
+	}
+	return nil
+}
+
+// Load loads the share of a split from the credential store.
+func Load(id shamir.SplitID) (shamir.Share, error) {
+	encoded, err := keyring.Get(Service, id.String())
+	if errors.Is(err, keyring.ErrNotFound) {
+		return shamir.Share{}, ErrNotFound
+	}
+	if err != nil {
+		return shamir.Share{}, fmt.Errorf("sharekeychain: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return shamir.Share{}, fmt.Errorf("sharekeychain: invalid share: %w", err)
+	}
+	defer clear(data)
+	var share shamir.Share
+	if err := share.UnmarshalBinary(data); err != nil {
+		return shamir.Share{}, err
+	}
+	if share.SplitID != id {
+		clear(share.Payload)
+		return shamir.Share{}, errors.New("sharekeychain: the stored share belongs to another split")
+	}
+	return share, nil
+}
+
+// Delete deletes the share of a split from the credential store.
+func Delete(id shamir.SplitID) error {
+	err := keyring.Delete(Service, id.String())
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("sharekeychain: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package sharepb
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative share.proto
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative service.proto
+
+// FromShare converts a share to its protobuf representation.
+func FromShare(share shamir.Share) *Share {
+	message := &Share{
+		Version:    uint32(shamir.Version),
+		Index:      uint32(share.Index),
+		Threshold:  uint32(share.Threshold),
+		SplitId:    append([]byte{}, share.SplitID[:]...),
+		Label:      share.Label,
+		Payload:    append([]byte{}, share.Payload...),
+		Signature:  append([]byte(nil), share.Signature...),
+		Padded:     share.Padded,
+		Polynomial: uint32(share.Polynomial),
+		Role:       share.Role,
+	}
+	if !share.CreatedAt.IsZero() {
+		message.CreatedAt = timestamppb.New(share.CreatedAt)
+	}
+	if !share.Policy.IsZero() {
+		message.Policy = &Policy{Approvers: append([]string(nil), share.Policy.Approvers...)}
+		if !share.Policy.NotBefore.IsZero() {
+			message.Policy.NotBefore = timestamppb.New(share.Policy.NotBefore)
+		}
+	}
+	return message
+}
+
+// ToShare converts the protobuf representation of a share back to a share, validating its fields.
+func (x *Share) ToShare() (shamir.Share, error) {
+	if x.GetVersion() != uint32(shamir.Version) {
+		return shamir.Share{}, shamir.ErrUnsupportedVersion
+	}
+	if x.GetIndex() > 255 || x.GetThreshold() > 255 || x.GetPolynomial() > 0xffff || len(x.GetSplitId()) != len(shamir.SplitID{}) ||
+		len(x.GetPayload()) == 0 {
+		return shamir.Share{}, shamir.ErrInvalidFormat
+	}
+
+	share := shamir.Share{
+		Index:      uint8(x.GetIndex()),
+		Threshold:  uint8(x.GetThreshold()),
+		Label:      x.GetLabel(),
+		Payload:    append([]byte{}, x.GetPayload()...),
+		Signature:  append([]byte(nil), x.GetSignature()...),
+		Padded:     x.GetPadded(),
+		Polynomial: uint16(x.GetPolynomial()),
+		Role:       x.GetRole(),
+	}
+	copy(share.SplitID[:], x.GetSplitId())
+	if x.GetCreatedAt() != nil {
+		if err := x.GetCreatedAt().CheckValid(); err != nil {
+			return shamir.Share{}, err
+		}
+		share.CreatedAt = x.GetCreatedAt().AsTime()
+	}
+	if policy := x.GetPolicy(); policy != nil {
+		share.Policy.Approvers = append([]string(nil), policy.GetApprovers()...)
+		if policy.GetNotBefore() != nil {
+			if err := policy.GetNotBefore().CheckValid(); err != nil {
+				return shamir.Share{}, err
+			}
+			share.Policy.NotBefore = policy.GetNotBefore().AsTime()
+		}
+	}
+	return share, nil
+}
@@ -0,0 +1,164 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: service.proto
+
+// gRPC service splitting and recovering secrets, implemented by github.com/etiennebch/shamir-sss/shamirgrpc.
+// The Go bindings are generated with:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative service.proto
+
+package sharepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ShamirService_Split_FullMethodName   = "/shamir.v1.ShamirService/Split"
+	ShamirService_Recover_FullMethodName = "/shamir.v1.ShamirService/Recover"
+)
+
+// ShamirServiceClient is the client API for ShamirService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ShamirService splits and recovers secrets. Secrets are streamed in chunks and shares one at a time, so that
+// large secrets do not exceed the maximum size of the messages.
+type ShamirServiceClient interface {
+	// Split splits the secret streamed by the client, and streams back the shares once the client closes its stream.
+	Split(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SplitRequest, SplitResponse], error)
+	// Recover recovers the secret from the shares streamed by the client, and streams it back in chunks once the
+	// client closes its stream.
+	Recover(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RecoverRequest, RecoverResponse], error)
+}
+
+type shamirServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShamirServiceClient(cc grpc.ClientConnInterface) ShamirServiceClient {
+	return &shamirServiceClient{cc}
+}
+
+func (c *shamirServiceClient) Split(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SplitRequest, SplitResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ShamirService_ServiceDesc.Streams[0], ShamirService_Split_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SplitRequest, SplitResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShamirService_SplitClient = grpc.BidiStreamingClient[SplitRequest, SplitResponse]
+
+func (c *shamirServiceClient) Recover(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RecoverRequest, RecoverResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ShamirService_ServiceDesc.Streams[1], ShamirService_Recover_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RecoverRequest, RecoverResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShamirService_RecoverClient = grpc.BidiStreamingClient[RecoverRequest, RecoverResponse]
+
+// ShamirServiceServer is the server API for ShamirService service.
+// All implementations must embed UnimplementedShamirServiceServer
+// for forward compatibility.
+//
+// ShamirService splits and recovers secrets. Secrets are streamed in chunks and shares one at a time, so that
+// large secrets do not exceed the maximum size of the messages.
+type ShamirServiceServer interface {
+	// Split splits the secret streamed by the client, and streams back the shares once the client closes its stream.
+	Split(grpc.BidiStreamingServer[SplitRequest, SplitResponse]) error
+	// Recover recovers the secret from the shares streamed by the client, and streams it back in chunks once the
+	// client closes its stream.
+	Recover(grpc.BidiStreamingServer[RecoverRequest, RecoverResponse]) error
+	mustEmbedUnimplementedShamirServiceServer()
+}
+
+// UnimplementedShamirServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedShamirServiceServer struct{}
+
+func (UnimplementedShamirServiceServer) Split(grpc.BidiStreamingServer[SplitRequest, SplitResponse]) error {
+	return status.Error(codes.Unimplemented, "method Split not implemented")
+}
+func (UnimplementedShamirServiceServer) Recover(grpc.BidiStreamingServer[RecoverRequest, RecoverResponse]) error {
+	return status.Error(codes.Unimplemented, "method Recover not implemented")
+}
+func (UnimplementedShamirServiceServer) mustEmbedUnimplementedShamirServiceServer() {}
+func (UnimplementedShamirServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeShamirServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShamirServiceServer will
+// result in compilation errors.
+type UnsafeShamirServiceServer interface {
+	mustEmbedUnimplementedShamirServiceServer()
+}
+
+func RegisterShamirServiceServer(s grpc.ServiceRegistrar, srv ShamirServiceServer) {
+	// If the following call panics, it indicates UnimplementedShamirServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ShamirService_ServiceDesc, srv)
+}
+
+func _ShamirService_Split_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShamirServiceServer).Split(&grpc.GenericServerStream[SplitRequest, SplitResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShamirService_SplitServer = grpc.BidiStreamingServer[SplitRequest, SplitResponse]
+
+func _ShamirService_Recover_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShamirServiceServer).Recover(&grpc.GenericServerStream[RecoverRequest, RecoverResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ShamirService_RecoverServer = grpc.BidiStreamingServer[RecoverRequest, RecoverResponse]
+
+// ShamirService_ServiceDesc is the grpc.ServiceDesc for ShamirService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ShamirService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "shamir.v1.ShamirService",
+	HandlerType: (*ShamirServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Split",
+			Handler:       _ShamirService_Split_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Recover",
+			Handler:       _ShamirService_Recover_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "service.proto",
+}
@@ -0,0 +1,308 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: share.proto
+
+// Canonical wire representation of the shares dealt by github.com/etiennebch/shamir-sss.
+// The Go bindings are generated with:
+//
+//   protoc --go_out=. --go_opt=paths=source_relative share.proto
+
+package sharepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Share is the share of a secret dealt to a single participant.
+type Share struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// version of the share format, see shamir.Version.
+	Version uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// index is the coordinate x[i] used to evaluate the polynomials for the participant (1 to 255).
+	Index uint32 `protobuf:"varint,2,opt,name=index,proto3" json:"index,omitempty"`
+	// threshold is the number of shares required to recover the secret, or 0 if unknown.
+	Threshold uint32 `protobuf:"varint,3,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	// split_id identifies the shares dealt together (16 bytes UUID).
+	SplitId []byte `protobuf:"bytes,4,opt,name=split_id,json=splitId,proto3" json:"split_id,omitempty"`
+	// created_at is the time the share was dealt, unset if unknown.
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// label is a free-form description of the share.
+	Label string `protobuf:"bytes,6,opt,name=label,proto3" json:"label,omitempty"`
+	// payload holds the values of the polynomials at x[i], one for every byte of the secret.
+	Payload []byte `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`
+	// signature is the Ed25519 signature of the share by the dealer, empty if the share is not signed.
+	Signature []byte `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	// padded is true if the secret was padded before being split, see shamir.WithPadding.
+	Padded bool `protobuf:"varint,9,opt,name=padded,proto3" json:"padded,omitempty"`
+	// polynomial is the reduction polynomial of GF(2^8), 0 for the AES polynomial, see shamir.WithPolynomial.
+	Polynomial uint32 `protobuf:"varint,10,opt,name=polynomial,proto3" json:"polynomial,omitempty"`
+	// policy is the release policy of the share, unset if none, see shamir.WithReleasePolicy.
+	Policy *Policy `protobuf:"bytes,11,opt,name=policy,proto3" json:"policy,omitempty"`
+	// role is the role of the share, e.g. the team of its custodian, empty if none, see shamir.WithRoles.
+	Role          string `protobuf:"bytes,12,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Share) Reset() {
+	*x = Share{}
+	mi := &file_share_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Share) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Share) ProtoMessage() {}
+
+func (x *Share) ProtoReflect() protoreflect.Message {
+	mi := &file_share_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Share.ProtoReflect.Descriptor instead.
+func (*Share) Descriptor() ([]byte, []int) {
+	return file_share_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Share) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Share) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Share) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *Share) GetSplitId() []byte {
+	if x != nil {
+		return x.SplitId
+	}
+	return nil
+}
+
+func (x *Share) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Share) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Share) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Share) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Share) GetPadded() bool {
+	if x != nil {
+		return x.Padded
+	}
+	return false
+}
+
+func (x *Share) GetPolynomial() uint32 {
+	if x != nil {
+		return x.Polynomial
+	}
+	return 0
+}
+
+func (x *Share) GetPolicy() *Policy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+func (x *Share) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+// Policy is the release policy of a share, enforced by recovery coordinators, see shamir.Policy.
+type Policy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// not_before is the time before which the share must not be used, unset if none.
+	NotBefore *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	// approvers lists the identities whose approval is required to use the share.
+	Approvers     []string `protobuf:"bytes,2,rep,name=approvers,proto3" json:"approvers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Policy) Reset() {
+	*x = Policy{}
+	mi := &file_share_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Policy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Policy) ProtoMessage() {}
+
+func (x *Policy) ProtoReflect() protoreflect.Message {
+	mi := &file_share_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Policy.ProtoReflect.Descriptor instead.
+func (*Policy) Descriptor() ([]byte, []int) {
+	return file_share_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Policy) GetNotBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NotBefore
+	}
+	return nil
+}
+
+func (x *Policy) GetApprovers() []string {
+	if x != nil {
+		return x.Approvers
+	}
+	return nil
+}
+
+var File_share_proto protoreflect.FileDescriptor
+
+const file_share_proto_rawDesc = "" +
+	"\n" +
+	"\vshare.proto\x12\tshamir.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf0\x02\n" +
+	"\x05Share\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\rR\aversion\x12\x14\n" +
+	"\x05index\x18\x02 \x01(\rR\x05index\x12\x1c\n" +
+	"\tthreshold\x18\x03 \x01(\rR\tthreshold\x12\x19\n" +
+	"\bsplit_id\x18\x04 \x01(\fR\asplitId\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x14\n" +
+	"\x05label\x18\x06 \x01(\tR\x05label\x12\x18\n" +
+	"\apayload\x18\a \x01(\fR\apayload\x12\x1c\n" +
+	"\tsignature\x18\b \x01(\fR\tsignature\x12\x16\n" +
+	"\x06padded\x18\t \x01(\bR\x06padded\x12\x1e\n" +
+	"\n" +
+	"polynomial\x18\n" +
+	" \x01(\rR\n" +
+	"polynomial\x12)\n" +
+	"\x06policy\x18\v \x01(\v2\x11.shamir.v1.PolicyR\x06policy\x12\x12\n" +
+	"\x04role\x18\f \x01(\tR\x04role\"a\n" +
+	"\x06Policy\x129\n" +
+	"\n" +
+	"not_before\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tnotBefore\x12\x1c\n" +
+	"\tapprovers\x18\x02 \x03(\tR\tapproversB*Z(github.com/etiennebch/shamir-sss/sharepbb\x06proto3"
+
+var (
+	file_share_proto_rawDescOnce sync.Once
+	file_share_proto_rawDescData []byte
+)
+
+func file_share_proto_rawDescGZIP() []byte {
+	file_share_proto_rawDescOnce.Do(func() {
+		file_share_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_share_proto_rawDesc), len(file_share_proto_rawDesc)))
+	})
+	return file_share_proto_rawDescData
+}
+
+var file_share_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_share_proto_goTypes = []any{
+	(*Share)(nil),                 // 0: shamir.v1.Share
+	(*Policy)(nil),                // 1: shamir.v1.Policy
+	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
+}
+var file_share_proto_depIdxs = []int32{
+	2, // 0: shamir.v1.Share.created_at:type_name -> google.protobuf.Timestamp
+	1, // 1: shamir.v1.Share.policy:type_name -> shamir.v1.Policy
+	2, // 2: shamir.v1.Policy.not_before:type_name -> google.protobuf.Timestamp
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_share_proto_init() }
+func file_share_proto_init() {
+	if File_share_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_share_proto_rawDesc), len(file_share_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_share_proto_goTypes,
+		DependencyIndexes: file_share_proto_depIdxs,
+		MessageInfos:      file_share_proto_msgTypes,
+	}.Build()
+	File_share_proto = out.File
+	file_share_proto_goTypes = nil
+	file_share_proto_depIdxs = nil
+}
@@ -0,0 +1,199 @@
+package sharepdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shareqr"
+)
+
+// This package generates printable PDF certificates, one per participant, so that key ceremonies can be run
+// on paper. A certificate holds:
+//
+// 	- the split parameters: split identifier, share index, threshold, number of shares, creation time and label
+// 	- the fingerprint of the share set, which participants can compare to check their shares belong together,
+// 	  and the fingerprint of the share (see shamir.SetFingerprint and Share.Fingerprint)
+// 	- the share as QR codes (see the shareqr package), as words (see shamir.EncodeMnemonic) and in hexadecimal
+// 	  (see Share.MarshalBinary), so that it can be recovered from whichever representation survived
+// 	- fields to be filled in by hand to keep track of the custody of the share
+
+const (
+	pageMargin = 20.0
+	// qrSize is the printed size of QR codes, in millimeters
+	qrSize       = 55.0
+	lineHeight   = 5.0
+	wordColumns  = 4
+	hexGroupSize = 4
+)
+
+// custodyFields are the fields of the custody section, filled in by hand.
+var custodyFields = []string{"Custodian", "Signature", "Date received", "Storage location", "Witness"}
+
+// Generate generates the certificates of all the shares of a split, in the order of the shares.
+func Generate(shares []shamir.Share) ([][]byte, error) {
+	if _, err := shamir.SetFingerprint(shares); err != nil {
+		return nil, err
+	}
+	certificates := make([][]byte, len(shares))
+	for i, share := range shares {
+		var b bytes.Buffer
+		if err := Write(&b, share, len(shares)); err != nil {
+			return nil, fmt.Errorf("share %d: %w", share.Index, err)
+		}
+		certificates[i] = b.Bytes()
+	}
+	return certificates, nil
+}
+
+// Write writes the certificate of a share, one of the n shares of a split.
+func Write(w io.Writer, share shamir.Share, n int) error {
+	fingerprint, err := shamir.SetFingerprint([]shamir.Share{share})
+	if err != nil {
+		return err
+	}
+	codes, err := shareqr.PNG(share, 512)
+	if err != nil {
+		return err
+	}
+	words, err := shamir.EncodeMnemonic(share)
+	if err != nil {
+		return err
+	}
+	binary, err := share.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle(fmt.Sprintf("Share %d of split %s", share.Index, share.SplitID), true)
+	pdf.SetCreator("github.com/etiennebch/shamir-sss", true)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	width := pageWidth - 2*pageMargin
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(width, 10, "Secret share certificate", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	summary := fmt.Sprintf("Any %d of the %d shares of this split recover the secret. Keep this document private.",
+		share.Threshold, n)
+	pdf.CellFormat(width, lineHeight, summary, "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	section(pdf, width, "Split parameters")
+	parameters := [][2]string{
+		{"Split ID", share.SplitID.String()},
+		{"Share index", fmt.Sprint(share.Index)},
+		{"Threshold", fmt.Sprintf("%d of %d", share.Threshold, n)},
+		{"Set fingerprint", fmt.Sprintf("%s (%s)", fingerprint, fingerprint.Words())},
+		{"Fingerprint", share.Fingerprint().String()},
+	}
+	if !share.CreatedAt.IsZero() {
+		parameters = append(parameters, [2]string{"Created at", share.CreatedAt.UTC().Format(time.RFC3339)})
+	}
+	if share.Label != "" {
+		parameters = append(parameters, [2]string{"Label", translate(share.Label)})
+	}
+	top := pdf.GetY()
+	for _, parameter := range parameters {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(35, lineHeight, parameter[0], "", 0, "", false, 0, "")
+		pdf.SetFont("Courier", "", 10)
+		pdf.CellFormat(width-35-qrSize, lineHeight, parameter[1], "", 1, "", false, 0, "")
+	}
+	if len(codes) == 1 {
+		// a single QR code fits next to the parameters, which keeps the certificate on a single page
+		pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(codes[0]))
+		pdf.ImageOptions("qr", pageMargin+width-qrSize, top, qrSize, qrSize, false,
+			fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		pdf.SetY(max(pdf.GetY(), top+qrSize))
+	} else {
+		pdf.Ln(4)
+		qrSection(pdf, width, pageHeight, codes)
+	}
+	pdf.Ln(4)
+
+	section(pdf, width, "Words")
+	pdf.SetFont("Courier", "", 10)
+	columnWidth := width / wordColumns
+	list := strings.Fields(words)
+	for i, word := range list {
+		ln := 0
+		if i%wordColumns == wordColumns-1 || i == len(list)-1 {
+			ln = 1
+		}
+		pdf.CellFormat(columnWidth, lineHeight, fmt.Sprintf("%2d. %s", i+1, word), "", ln, "", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	section(pdf, width, "Hexadecimal")
+	pdf.SetFont("Courier", "", 9)
+	pdf.MultiCell(width, 5, groupHex(binary), "", "", false)
+	pdf.Ln(4)
+
+	section(pdf, width, "Custody")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, field := range custodyFields {
+		if pdf.GetY()+2*lineHeight > pageHeight-pageMargin {
+			pdf.AddPage()
+		}
+		pdf.CellFormat(40, 2*lineHeight, field, "", 0, "", false, 0, "")
+		y := pdf.GetY() + 2*lineHeight - 1
+		pdf.Line(pageMargin+40, y, pageMargin+width, y)
+		pdf.Ln(2 * lineHeight)
+	}
+
+	return pdf.Output(w)
+}
+
+// qrSection writes the QR codes of a multi-part sequence, in rows.
+func qrSection(pdf *fpdf.Fpdf, width, pageHeight float64, codes [][]byte) {
+	section(pdf, width, "QR codes")
+	perRow := int(width / (qrSize + 5))
+	for i, code := range codes {
+		if i%perRow == 0 {
+			if i > 0 {
+				pdf.Ln(qrSize + lineHeight)
+			}
+			if pdf.GetY()+qrSize+lineHeight > pageHeight-pageMargin {
+				pdf.AddPage()
+			}
+		}
+		x, y := pageMargin+float64(i%perRow)*(qrSize+5), pdf.GetY()
+		name := fmt.Sprintf("qr%d", i)
+		pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(code))
+		pdf.ImageOptions(name, x, y, qrSize, qrSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		pdf.SetXY(x, y+qrSize)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(qrSize, 4, fmt.Sprintf("Part %d of %d", i+1, len(codes)), "", 0, "C", false, 0, "")
+		pdf.SetXY(pageMargin, y)
+	}
+	pdf.Ln(qrSize + lineHeight)
+}
+
+// section writes the title of a section of the certificate.
+func section(pdf *fpdf.Fpdf, width float64, title string) {
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(width, 8, title, "B", 1, "", false, 0, "")
+	pdf.Ln(2)
+}
+
+// groupHex encodes data in hexadecimal, in groups of hexGroupSize characters so that it is easier to read.
+func groupHex(data []byte) string {
+	encoded := hex.EncodeToString(data)
+	var groups []string
+	for len(encoded) > hexGroupSize {
+		groups = append(groups, encoded[:hexGroupSize])
+		encoded = encoded[hexGroupSize:]
+	}
+	return strings.Join(append(groups, encoded), " ")
+}
@@ -0,0 +1,124 @@
+package sharepkcs11
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/etiennebch/shamir-sss/sharewrap"
+)
+
+// The wrapped shares are stored as data objects (CKO_DATA) of the token: private, persistent objects labeled by
+// the caller, whose CKA_APPLICATION is Application and whose CKA_VALUE is the JSON encoding of the wrapped share.
+// Only users logged in the token can read them.
+
+// Store stores a wrapped share on the token under label, which must not be used by another share.
+func (t *Token) Store(label string, wrapped sharewrap.WrappedShare) error {
+	value, err := json.Marshal(wrapped)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	handles, err := t.find(shareTemplate(label))
+	if err != nil {
+		return err
+	}
+	if len(handles) > 0 {
+		return fmt.Errorf("%w: %q", ErrExists, label)
+	}
+	_, err = t.ctx.CreateObject(t.session, append(shareTemplate(label),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, value),
+	))
+	if err != nil {
+		return fmt.Errorf("sharepkcs11: %w", err)
+	}
+	return nil
+}
+
+// Load loads the wrapped share stored on the token under label.
+func (t *Token) Load(label string) (sharewrap.WrappedShare, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	handle, err := t.findShare(label)
+	if err != nil {
+		return sharewrap.WrappedShare{}, err
+	}
+	attributes, err := t.ctx.GetAttributeValue(t.session, handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+	})
+	if err != nil {
+		return sharewrap.WrappedShare{}, fmt.Errorf("sharepkcs11: %w", err)
+	}
+	var wrapped sharewrap.WrappedShare
+	if err := json.Unmarshal(attributes[0].Value, &wrapped); err != nil {
+		return sharewrap.WrappedShare{}, fmt.Errorf("sharepkcs11: invalid share %q: %w", label, err)
+	}
+	return wrapped, nil
+}
+
+// List returns the sorted labels of the wrapped shares stored on the token.
+func (t *Token) List() ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	handles, err := t.find([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+		pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, Application),
+	})
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]string, 0, len(handles))
+	for _, handle := range handles {
+		attributes, err := t.ctx.GetAttributeValue(t.session, handle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("sharepkcs11: %w", err)
+		}
+		labels = append(labels, string(attributes[0].Value))
+	}
+	slices.Sort(labels)
+	return labels, nil
+}
+
+// Delete destroys the wrapped share stored on the token under label.
+func (t *Token) Delete(label string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	handle, err := t.findShare(label)
+	if err != nil {
+		return err
+	}
+	if err := t.ctx.DestroyObject(t.session, handle); err != nil {
+		return fmt.Errorf("sharepkcs11: %w", err)
+	}
+	return nil
+}
+
+// findShare returns the handle of the data object of the share labeled label. The caller must hold the lock.
+func (t *Token) findShare(label string) (pkcs11.ObjectHandle, error) {
+	handles, err := t.find(shareTemplate(label))
+	switch {
+	case err != nil:
+		return 0, err
+	case len(handles) == 0:
+		return 0, fmt.Errorf("%w: share %q", ErrNotFound, label)
+	case len(handles) > 1:
+		return 0, fmt.Errorf("sharepkcs11: several shares are labeled %q", label)
+	}
+	return handles[0], nil
+}
+
+// shareTemplate returns the template matching the data object of the share labeled label.
+func shareTemplate(label string) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+		pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, Application),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+}
@@ -0,0 +1,250 @@
+package sharepkcs11
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+
+	"github.com/etiennebch/shamir-sss/sharewrap"
+)
+
+// This package backs the dealing and the custody of shares with a PKCS#11 token, e.g. an HSM, for regulated users
+// who must show that the randomness of the dealer and the shares at rest are protected by certified hardware:
+//
+//   - Token.Reader draws random bytes from the random number generator of the token, to be passed to
+//     shamir.WithRandom so that the coefficients of the polynomials come from the token.
+//   - Token.Wrapper wraps shares with an AES key of the token (see sharewrap), using CKM_AES_GCM with the context
+//     as additional data (see sharewrap.EncodeContext). The key never leaves the token.
+//   - Token.Store and Token.Load keep the wrapped shares as private data objects of the token.
+//
+// The package uses cgo to load the PKCS#11 module of the vendor, e.g. /usr/lib/softhsm/libsofthsm2.so. A token
+// opens a single session, which its methods serialize.
+
+// Type is the type of the wrapper, recorded in the wrapped shares.
+const Type = "pkcs11"
+
+// Application is the CKA_APPLICATION attribute of the data objects holding the wrapped shares.
+const Application = "shamir-sss"
+
+const (
+	ivSize  = 12
+	tagBits = 128
+	// maxRandom is the number of random bytes requested at once, which some tokens limit.
+	maxRandom = 1024
+)
+
+var (
+	// ErrNotFound is returned when a key or a share is not on the token.
+	ErrNotFound = errors.New("sharepkcs11: object not found")
+	// ErrExists is returned when storing a share under the label of another share.
+	ErrExists = errors.New("sharepkcs11: an object with this label already exists")
+)
+
+// Token is a session with a PKCS#11 token, logged in as the user. It is safe for concurrent use.
+type Token struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+}
+
+// Open loads the PKCS#11 module (the path of its library), and logs in the token labeled label with pin. The
+// token must be closed once done.
+func Open(module, label, pin string) (*Token, error) {
+	ctx := pkcs11.New(module)
+	if ctx == nil {
+		return nil, fmt.Errorf("sharepkcs11: cannot load %s", module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("sharepkcs11: %w", err)
+	}
+	t, err := open(ctx, label, pin)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return t, nil
+}
+
+// open opens a session with the token labeled label.
+func open(ctx *pkcs11.Ctx, label, pin string) (*Token, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return nil, fmt.Errorf("sharepkcs11: %w", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil || strings.TrimRight(info.Label, " \x00") != label {
+			continue
+		}
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			return nil, fmt.Errorf("sharepkcs11: %w", err)
+		}
+		if err := ctx.Login(session, pkcs11.CKU_USER, pin); err != nil {
+			ctx.CloseSession(session)
+			return nil, fmt.Errorf("sharepkcs11: %w", err)
+		}
+		return &Token{ctx: ctx, session: session}, nil
+	}
+	return nil, fmt.Errorf("sharepkcs11: no token labeled %q", label)
+}
+
+// Close logs out and unloads the module.
+func (t *Token) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ctx.Logout(t.session)
+	err := t.ctx.CloseSession(t.session)
+	t.ctx.Finalize()
+	t.ctx.Destroy()
+	return err
+}
+
+// Reader returns a reader of the random number generator of the token, e.g. to be passed to shamir.WithRandom.
+// It is safe for concurrent use.
+func (t *Token) Reader() io.Reader {
+	return reader{t}
+}
+
+type reader struct {
+	t *Token
+}
+
+func (r reader) Read(p []byte) (int, error) {
+	r.t.mu.Lock()
+	defer r.t.mu.Unlock()
+	return r.t.read(p)
+}
+
+// read fills p with random bytes of the token. The caller must hold the lock.
+func (t *Token) read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		random, err := t.ctx.GenerateRandom(t.session, min(len(p)-n, maxRandom))
+		if err != nil {
+			return n, fmt.Errorf("sharepkcs11: %w", err)
+		}
+		n += copy(p[n:], random)
+	}
+	return n, nil
+}
+
+// find returns the handles of the objects matching a template. The caller must hold the lock.
+func (t *Token) find(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if err := t.ctx.FindObjectsInit(t.session, template); err != nil {
+		return nil, fmt.Errorf("sharepkcs11: %w", err)
+	}
+	defer t.ctx.FindObjectsFinal(t.session)
+	var handles []pkcs11.ObjectHandle
+	for {
+		found, _, err := t.ctx.FindObjects(t.session, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sharepkcs11: %w", err)
+		}
+		if len(found) == 0 {
+			return handles, nil
+		}
+		handles = append(handles, found...)
+	}
+}
+
+// findKey returns the handle of the AES key labeled label. The caller must hold the lock.
+func (t *Token) findKey(label string) (pkcs11.ObjectHandle, error) {
+	handles, err := t.find([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_AES),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	})
+	switch {
+	case err != nil:
+		return 0, err
+	case len(handles) == 0:
+		return 0, fmt.Errorf("%w: key %q", ErrNotFound, label)
+	case len(handles) > 1:
+		return 0, fmt.Errorf("sharepkcs11: several keys are labeled %q", label)
+	}
+	return handles[0], nil
+}
+
+var _ sharewrap.Wrapper = (*Wrapper)(nil)
+
+// Wrapper wraps data keys with an AES key of a token. It implements sharewrap.Wrapper.
+type Wrapper struct {
+	token *Token
+	key   string
+}
+
+// Wrapper returns a wrapper using the AES key labeled key, which must allow CKA_ENCRYPT and CKA_DECRYPT. key may
+// be empty if the wrapper is only used to unwrap shares.
+func (t *Token) Wrapper(key string) *Wrapper {
+	return &Wrapper{token: t, key: key}
+}
+
+// Type implements sharewrap.Wrapper.
+func (w *Wrapper) Type() string {
+	return Type
+}
+
+// WrapKey implements sharewrap.Wrapper. The identifier of the key is its label. The wrapped key is the IV followed
+// by the ciphertext and the tag.
+func (w *Wrapper) WrapKey(_ context.Context, key []byte, context map[string]string) (string, []byte, error) {
+	if w.key == "" {
+		return "", nil, errors.New("sharepkcs11: no key to wrap with")
+	}
+	t := w.token
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	handle, err := t.findKey(w.key)
+	if err != nil {
+		return "", nil, err
+	}
+	iv := make([]byte, ivSize)
+	if _, err := t.read(iv); err != nil {
+		return "", nil, err
+	}
+	params := pkcs11.NewGCMParams(iv, sharewrap.EncodeContext(context), tagBits)
+	defer params.Free()
+	if err := t.ctx.EncryptInit(t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}, handle); err != nil {
+		return "", nil, fmt.Errorf("sharepkcs11: %w", err)
+	}
+	ciphertext, err := t.ctx.Encrypt(t.session, key)
+	if err != nil {
+		return "", nil, fmt.Errorf("sharepkcs11: %w", err)
+	}
+	// some tokens ignore the IV they are given and generate their own
+	if actual := params.IV(); len(actual) == ivSize {
+		iv = actual
+	}
+	return w.key, append(iv, ciphertext...), nil
+}
+
+// UnwrapKey implements sharewrap.Wrapper.
+func (w *Wrapper) UnwrapKey(_ context.Context, keyID string, wrapped []byte, context map[string]string) ([]byte, error) {
+	if len(wrapped) < ivSize+tagBits/8 {
+		return nil, errors.New("sharepkcs11: the wrapped key is too short")
+	}
+	t := w.token
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	handle, err := t.findKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	params := pkcs11.NewGCMParams(wrapped[:ivSize], sharewrap.EncodeContext(context), tagBits)
+	defer params.Free()
+	if err := t.ctx.DecryptInit(t.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_AES_GCM, params)}, handle); err != nil {
+		return nil, fmt.Errorf("sharepkcs11: %w", err)
+	}
+	key, err := t.ctx.Decrypt(t.session, wrapped[ivSize:])
+	if err != nil {
+		return nil, fmt.Errorf("sharepkcs11: the key cannot be unwrapped: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,181 @@
+package shareqr
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	_ "image/jpeg" // register the JPEG format for scans
+	_ "image/png"  // register the PNG format
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	multiqrcode "github.com/makiuchi-d/gozxing/multi/qrcode"
+	"github.com/makiuchi-d/gozxing/qrcode"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// DecodeImage locates and decodes the QR codes of an image, and returns their contents.
+// A single image may hold several QR codes, e.g. a scanned page holding a multi-part sequence.
+func DecodeImage(img image.Image) ([]string, error) {
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil, err
+	}
+	hints := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_TRY_HARDER: true}
+
+	results, err := multiqrcode.NewQRCodeMultiReader().DecodeMultiple(bitmap, hints)
+	if err != nil || len(results) == 0 {
+		// the multiple reader misses some codes the single reader finds, e.g. codes filling the whole image
+		result, err := qrcode.NewQRCodeReader().Decode(bitmap, hints)
+		if err != nil {
+			return nil, fmt.Errorf("shareqr: no QR code found: %w", err)
+		}
+		results = []*gozxing.Result{result}
+	}
+
+	contents := make([]string, len(results))
+	for i, result := range results {
+		contents[i] = result.GetText()
+	}
+	return contents, nil
+}
+
+// ReadImage decodes the QR codes of a PNG or JPEG image.
+func ReadImage(r io.Reader) ([]string, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeImage(img)
+}
+
+// ReadDir decodes the shares held by the PNG and JPEG images of a directory, e.g. a directory of scans.
+// Images without QR codes are skipped.
+func ReadDir(dir string) ([]shamir.Share, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".png", ".jpg", ".jpeg":
+		default:
+			continue
+		}
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		found, err := ReadImage(file)
+		file.Close()
+		if err != nil {
+			continue
+		}
+		contents = append(contents, found...)
+	}
+	return Assemble(contents)
+}
+
+// RecoverDir decodes the shares held by the images of a directory with ReadDir, and recovers the secret.
+func RecoverDir(dir string) ([]byte, error) {
+	shares, err := ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return shamir.Recover(shares)
+}
+
+// Assemble parses the contents of QR codes produced by Encode, in any order, and returns the shares.
+// The parts of multi-part sequences are joined together, and duplicated QR codes are ignored.
+func Assemble(contents []string) ([]shamir.Share, error) {
+	type sequence struct {
+		parts []string
+		found int
+	}
+
+	var uris []string
+	sequences := make(map[uint32]*sequence)
+	var checksums []uint32
+	seen := make(map[string]bool)
+	for _, content := range contents {
+		if seen[content] {
+			continue
+		}
+		seen[content] = true
+		if !strings.HasPrefix(content, partPrefix) {
+			uris = append(uris, content)
+			continue
+		}
+
+		part, parts, checksum, data, err := parsePart(content)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := sequences[checksum]
+		if !ok {
+			s = &sequence{parts: make([]string, parts)}
+			sequences[checksum] = s
+			checksums = append(checksums, checksum)
+		}
+		if len(s.parts) != parts {
+			return nil, errors.New("shareqr: inconsistent multi-part sequence")
+		}
+		if s.parts[part-1] == "" {
+			s.found++
+		}
+		s.parts[part-1] = data
+	}
+
+	sort.Slice(checksums, func(i, j int) bool { return checksums[i] < checksums[j] })
+	for _, checksum := range checksums {
+		s := sequences[checksum]
+		if s.found != len(s.parts) {
+			return nil, fmt.Errorf("shareqr: %d of %d parts missing in sequence %08x",
+				len(s.parts)-s.found, len(s.parts), checksum)
+		}
+		uri := strings.Join(s.parts, "")
+		if crc32.ChecksumIEEE([]byte(uri)) != checksum {
+			return nil, fmt.Errorf("shareqr: invalid checksum for sequence %08x", checksum)
+		}
+		uris = append(uris, uri)
+	}
+
+	shares := make([]shamir.Share, len(uris))
+	for i, uri := range uris {
+		share, err := shamir.ParseURI(uri)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = share
+	}
+	return shares, nil
+}
+
+// parsePart parses the content of a QR code holding a part of a share.
+func parsePart(content string) (part, parts int, checksum uint32, data string, err error) {
+	header, data, ok := strings.Cut(strings.TrimPrefix(content, partPrefix), ":")
+	fields := strings.Split(header, "/")
+	if !ok || len(fields) != 3 {
+		return 0, 0, 0, "", errors.New("shareqr: invalid part header")
+	}
+	if part, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, 0, "", errors.New("shareqr: invalid part number")
+	}
+	if parts, err = strconv.Atoi(fields[1]); err != nil || part < 1 || part > parts || parts > maxParts {
+		return 0, 0, 0, "", errors.New("shareqr: invalid part number")
+	}
+	sum, err := strconv.ParseUint(fields[2], 16, 32)
+	if err != nil {
+		return 0, 0, 0, "", errors.New("shareqr: invalid part checksum")
+	}
+	return part, parts, uint32(sum), data, nil
+}
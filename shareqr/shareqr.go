@@ -0,0 +1,123 @@
+package shareqr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/skip2/go-qrcode"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package renders shares as QR codes, for printing and air-gapped transfer.
+//
+// A QR code holds the URI of a share (see shamir.FormatURI). The highest error correction level for which the
+// URI fits in a QR code of at most MaxVersion is used, so that small shares survive damaged or dirty prints.
+// When the URI does not fit at all, it is split into a sequence of QR codes, each holding a part of the URI:
+//
+// 	shamir-part:<part>/<parts>/<checksum>:<data>
+//
+// where part starts from 1 and checksum is the CRC-32 (IEEE) of the whole URI, in hexadecimal, which ties the
+// parts of a sequence together.
+//
+// Scanned QR codes are decoded from PNG or JPEG images, and the parts of multi-part sequences are joined together
+// regardless of their order.
+
+// MaxVersion is the largest QR code version (97x97 modules) produced, which remains easy to scan when printed.
+const MaxVersion = 20
+
+// capacity is the number of bytes held by a QR code of version MaxVersion at the medium error correction level.
+const capacity = 666
+
+// maxParts is the largest number of parts of a multi-part sequence.
+const maxParts = 255
+
+// partPrefix is the prefix of the content of the QR codes holding a part of a share.
+const partPrefix = "shamir-part:"
+
+// levels are the error correction levels, from the highest to the lowest.
+var levels = []qrcode.RecoveryLevel{qrcode.Highest, qrcode.High, qrcode.Medium, qrcode.Low}
+
+// Encode encodes a share as one QR code, or a sequence of QR codes for large shares.
+func Encode(share shamir.Share) ([]*qrcode.QRCode, error) {
+	content := shamir.FormatURI(share)
+	for _, level := range levels {
+		if code, err := qrcode.New(content, level); err == nil && code.VersionNumber <= MaxVersion {
+			return []*qrcode.QRCode{code}, nil
+		}
+	}
+
+	// split the URI into the smallest number of parts fitting at the medium level
+	checksum := crc32.ChecksumIEEE([]byte(content))
+	for parts := max(2, len(content)/capacity); parts <= len(content); parts++ {
+		length := (len(content) + parts - 1) / parts
+		codes, ok := encodeParts(content, length, parts, checksum)
+		if ok {
+			return codes, nil
+		}
+	}
+	return nil, errors.New("shareqr: the share is too large to be encoded as QR codes")
+}
+
+// encodeParts encodes the content split into parts of length bytes, and reports whether every part fits.
+func encodeParts(content string, length, parts int, checksum uint32) ([]*qrcode.QRCode, bool) {
+	codes := make([]*qrcode.QRCode, 0, parts)
+	for part := 0; part*length < len(content); part++ {
+		end := min((part+1)*length, len(content))
+		data := fmt.Sprintf("%s%d/%d/%08x:%s", partPrefix, part+1, parts, checksum, content[part*length:end])
+		code, err := qrcode.New(data, qrcode.Medium)
+		if err != nil || code.VersionNumber > MaxVersion {
+			return nil, false
+		}
+		codes = append(codes, code)
+	}
+	return codes, len(codes) == parts
+}
+
+// PNG renders a share as PNG images of size x size pixels, one per QR code.
+func PNG(share shamir.Share, size int) ([][]byte, error) {
+	codes, err := Encode(share)
+	if err != nil {
+		return nil, err
+	}
+	images := make([][]byte, len(codes))
+	for i, code := range codes {
+		if images[i], err = code.PNG(size); err != nil {
+			return nil, err
+		}
+	}
+	return images, nil
+}
+
+// SVG renders a share as SVG images of size x size pixels, one per QR code.
+func SVG(share shamir.Share, size int) ([][]byte, error) {
+	codes, err := Encode(share)
+	if err != nil {
+		return nil, err
+	}
+	images := make([][]byte, len(codes))
+	for i, code := range codes {
+		images[i] = RenderSVG(code, size)
+	}
+	return images, nil
+}
+
+// RenderSVG renders a QR code as an SVG image of size x size pixels. Dark modules are drawn as a single path.
+func RenderSVG(code *qrcode.QRCode, size int) []byte {
+	bitmap := code.Bitmap()
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, len(bitmap), len(bitmap))
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, len(bitmap), len(bitmap))
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.Bytes()
+}
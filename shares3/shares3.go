@@ -0,0 +1,151 @@
+package shares3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/sharestore"
+)
+
+// This package stores shares in the buckets of Amazon S3 or of S3-compatible object stores, such as MinIO or Ceph
+// (see sharestore). Every share is an object named after its key (see sharestore.Key), under an optional prefix.
+// Shares are written with a conditional request (If-None-Match: *), so that an existing share is never replaced,
+// and can be encrypted at rest with a KMS key of the bucket.
+//
+// S3-compatible stores are used by configuring the client, e.g. with the BaseEndpoint and UsePathStyle options
+// of s3.Options.
+
+// maxShareSize bounds the size of the objects read, as a share holds at most a few megabytes in practice.
+const maxShareSize = 64 << 20
+
+// S3 is the part of the S3 API used by this package, implemented by *s3.Client.
+type S3 interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	s3.ListObjectsV2APIClient
+}
+
+var _ sharestore.ShareStore = (*Store)(nil)
+
+// Store stores the shares in a bucket. It implements sharestore.ShareStore.
+type Store struct {
+	client S3
+	bucket string
+	prefix string
+	kmsKey string
+}
+
+// New returns a store writing to the bucket, under prefix if not empty (e.g. "shares/").
+func New(client S3, bucket, prefix string) *Store {
+	return &Store{client: client, bucket: bucket, prefix: prefix}
+}
+
+// WithKMSKey returns a copy of the store encrypting the shares at rest with the AWS KMS key keyID (SSE-KMS).
+func (s *Store) WithKMSKey(keyID string) *Store {
+	c := *s
+	c.kmsKey = keyID
+	return &c
+}
+
+// Put implements sharestore.ShareStore.
+func (s *Store) Put(ctx context.Context, share shamir.Share) error {
+	data, err := share.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.key(share.SplitID, share.Index)),
+		Body:        bytes.NewReader(data),
+		IfNoneMatch: aws.String("*"),
+		ContentType: aws.String("application/octet-stream"),
+	}
+	if s.kmsKey != "" {
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(s.kmsKey)
+	}
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		var apiError smithy.APIError
+		if errors.As(err, &apiError) && apiError.ErrorCode() == "PreconditionFailed" {
+			return sharestore.ErrExists
+		}
+		return fmt.Errorf("shares3: %w", err)
+	}
+	return nil
+}
+
+// Get implements sharestore.ShareStore.
+func (s *Store) Get(ctx context.Context, id shamir.SplitID, index uint8) (shamir.Share, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(id, index)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return shamir.Share{}, sharestore.ErrNotFound
+		}
+		return shamir.Share{}, fmt.Errorf("shares3: %w", err)
+	}
+	defer output.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(output.Body, maxShareSize))
+	if err != nil {
+		return shamir.Share{}, fmt.Errorf("shares3: %w", err)
+	}
+	defer clear(data)
+	return sharestore.Decode(data, id, index)
+}
+
+// List implements sharestore.ShareStore.
+func (s *Store) List(ctx context.Context, id shamir.SplitID) ([]uint8, error) {
+	prefix := s.prefix + id.String() + "/"
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	var indexes []uint8
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("shares3: %w", err)
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(aws.ToString(object.Key), prefix)
+			if index, ok := sharestore.ParseKey(name); ok && path.Base(name) == name {
+				indexes = append(indexes, index)
+			}
+		}
+	}
+	slices.Sort(indexes)
+	return indexes, nil
+}
+
+// Delete implements sharestore.ShareStore. In a versioned bucket, the former versions of the share are kept.
+func (s *Store) Delete(ctx context.Context, id shamir.SplitID, index uint8) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(id, index)),
+	})
+	if err != nil {
+		return fmt.Errorf("shares3: %w", err)
+	}
+	return nil
+}
+
+// key returns the key of the object of a share.
+func (s *Store) key(id shamir.SplitID, index uint8) string {
+	return s.prefix + sharestore.Key(id, index)
+}
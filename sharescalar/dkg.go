@@ -0,0 +1,466 @@
+package sharescalar
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"sort"
+
+	"filippo.io/edwards25519"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// A distributed key generation (DKG) lets n participants generate a key jointly, such that every participant
+// ends with a share of the private key and nobody, not even a dealer, ever knows the key. This is Pedersen's
+// protocol over edwards25519, in which every participant acts as the dealer of a Feldman verifiable secret
+// sharing (VSS) of a random secret, and the key is the sum of the secrets of the qualified dealers:
+//
+// 	1. Every participant calls Deal, broadcasts its Commitment and sends every other participant its
+// 	   DealerShare over a private channel. The commitment holds the coefficients of the polynomial of the
+// 	   dealer multiplied by the base point, and a proof of knowledge of its secret.
+// 	2. Every participant passes the commitments to ReceiveCommitment and the shares it received to ReceiveShare,
+// 	   which checks them against the commitment of their dealer. Invalid shares, and the shares which were
+// 	   not received (see MissingShares), result in a Complaint against the dealer, which must be broadcast.
+// 	3. Every participant passes the complaints to ReceiveComplaint. The dealers answer the complaints against
+// 	   them with Justify, revealing the disputed share, and the justifications are broadcast and passed to
+// 	   ReceiveJustification.
+// 	4. Every participant calls Finalize. A dealer is disqualified if its commitment is invalid, if it fails to
+// 	   answer a complaint with a valid share, or if it receives threshold complaints or more.
+//
+// The protocol is transport agnostic: the messages are plain structs which can be encoded in JSON, and the
+// caller is responsible for the broadcast and private channels. The broadcast channel must be reliable, i.e.
+// all the participants must receive the same commitments, complaints and justifications, otherwise they may
+// not agree on the set of qualified dealers. The dealer shares must be encrypted and authenticated, e.g. using
+// the sharecrypt package.
+
+const dkgProofDomain = "shamir-sss dkg proof of knowledge\x00"
+
+// ErrDisqualified is returned by Finalize when the participant itself was disqualified.
+var ErrDisqualified = errors.New("sharescalar: the participant was disqualified")
+
+// Commitment is the message broadcast by a dealer in the first round of the DKG.
+type Commitment struct {
+	// Dealer is the index of the dealer.
+	Dealer uint8 `json:"dealer"`
+	// Coefficients are the encoded points a[k]*B, where a[k] is the coefficient of degree k of the polynomial
+	// of the dealer and B the base point of edwards25519.
+	Coefficients [][]byte `json:"coefficients"`
+	// Proof is a Schnorr proof of knowledge of a[0], so that a dealer cannot pick its contribution to the
+	// public key as a function of the contributions of the others.
+	Proof []byte `json:"proof"`
+}
+
+// DealerShare is the message sent privately by a dealer to every other participant in the first round of the DKG.
+type DealerShare struct {
+	// Dealer is the index of the dealer.
+	Dealer uint8 `json:"dealer"`
+	// Recipient is the index of the recipient.
+	Recipient uint8 `json:"recipient"`
+	// Value is the encoded value of the polynomial of the dealer at the index of the recipient.
+	Value []byte `json:"value"`
+}
+
+// Complaint is broadcast by the recipient of an invalid or missing share.
+type Complaint struct {
+	// Accuser is the index of the recipient of the share.
+	Accuser uint8 `json:"accuser"`
+	// Dealer is the index of the dealer of the share.
+	Dealer uint8 `json:"dealer"`
+}
+
+// Justification is broadcast by a dealer in answer to a complaint, revealing the disputed share.
+type Justification struct {
+	// Dealer is the index of the dealer.
+	Dealer uint8 `json:"dealer"`
+	// Accuser is the index of the participant which complained.
+	Accuser uint8 `json:"accuser"`
+	// Value is the encoded value of the polynomial of the dealer at the index of the accuser.
+	Value []byte `json:"value"`
+}
+
+// KeyShare is the outcome of the DKG for a participant.
+type KeyShare struct {
+	// Index is the index of the participant.
+	Index uint8
+	// Threshold is the number of shares required to use the key.
+	Threshold uint8
+	// Secret is the share of the private key of the participant.
+	Secret *edwards25519.Scalar
+	// PublicKey is the public key of the group, i.e. the private key multiplied by the base point.
+	PublicKey *edwards25519.Point
+	// VerificationShares are the shares of the private key of all the participants multiplied by the base
+	// point, by index, which let the group verify the contribution of every participant.
+	VerificationShares map[uint8]*edwards25519.Point
+	// Qualified are the indexes of the dealers which contributed to the key.
+	Qualified []uint8
+}
+
+// Participant holds the state of a participant of the DKG. A Participant is not safe for concurrent use.
+type Participant struct {
+	context    []byte
+	index      uint8
+	n          uint8
+	threshold  uint8
+	polynomial galois.Poly[*edwards25519.Scalar]
+
+	commitments    map[uint8][]*edwards25519.Point
+	shares         map[uint8]*edwards25519.Scalar
+	complaints     map[uint8]map[uint8]bool
+	disqualified   map[uint8]bool
+	justifications map[uint8]map[uint8]bool
+}
+
+// NewParticipant returns the participant with the provided index of a DKG among n participants, such that
+// threshold shares are required to use the key. The context identifies the DKG, e.g. a random session
+// identifier agreed upon by the participants, and binds the proofs of knowledge to it.
+func NewParticipant(context []byte, index, n, threshold uint8) (*Participant, error) {
+	if threshold > n {
+		return nil, errors.New("sharescalar: the threshold cannot be greater than the number of participants")
+	}
+	if threshold < minThreshold {
+		return nil, errors.New("sharescalar: the threshold must be at least 2")
+	}
+	if index == 0 || index > n {
+		return nil, errors.New("sharescalar: the index of a participant must be between 1 and n")
+	}
+	return &Participant{
+		context:        append([]byte{}, context...),
+		index:          index,
+		n:              n,
+		threshold:      threshold,
+		commitments:    make(map[uint8][]*edwards25519.Point),
+		shares:         make(map[uint8]*edwards25519.Scalar),
+		complaints:     make(map[uint8]map[uint8]bool),
+		disqualified:   make(map[uint8]bool),
+		justifications: make(map[uint8]map[uint8]bool),
+	}, nil
+}
+
+// Deal generates the random polynomial of the participant and returns the commitment to broadcast, and the
+// shares to send privately to the other participants.
+func (p *Participant) Deal() (Commitment, []DealerShare, error) {
+	if p.polynomial.Coefficients != nil {
+		return Commitment{}, nil, errors.New("sharescalar: the participant has already dealt")
+	}
+	field := Edwards25519{}
+	secret, err := field.Random(rand.Reader)
+	if err != nil {
+		return Commitment{}, nil, err
+	}
+	polynomial, err := galois.RandomPoly[*edwards25519.Scalar](field, secret, int(p.threshold)-1, rand.Reader)
+	if err != nil {
+		return Commitment{}, nil, err
+	}
+
+	points := make([]*edwards25519.Point, len(polynomial.Coefficients))
+	coefficients := make([][]byte, len(points))
+	for k, coefficient := range polynomial.Coefficients {
+		points[k] = new(edwards25519.Point).ScalarBaseMult(coefficient)
+		coefficients[k] = points[k].Bytes()
+	}
+	proof, err := p.prove(secret, points[0])
+	if err != nil {
+		return Commitment{}, nil, err
+	}
+
+	shares := make([]DealerShare, 0, p.n-1)
+	for i := 1; i <= int(p.n); i++ {
+		recipient := uint8(i)
+		value := polynomial.Eval(element[*edwards25519.Scalar](field, recipient))
+		if recipient == p.index {
+			p.shares[recipient] = value
+			continue
+		}
+		shares = append(shares, DealerShare{Dealer: p.index, Recipient: recipient, Value: value.Bytes()})
+	}
+	p.polynomial = polynomial
+	p.commitments[p.index] = points
+	return Commitment{Dealer: p.index, Coefficients: coefficients, Proof: proof}, shares, nil
+}
+
+// ReceiveCommitment records the commitment broadcast by another dealer. An invalid commitment disqualifies
+// its dealer, and the error is returned.
+func (p *Participant) ReceiveCommitment(c Commitment) error {
+	if c.Dealer == 0 || c.Dealer > p.n || c.Dealer == p.index {
+		return errors.New("sharescalar: the commitment has an invalid dealer")
+	}
+	if _, ok := p.commitments[c.Dealer]; ok || p.disqualified[c.Dealer] {
+		return errors.New("sharescalar: the commitment of the dealer was already received")
+	}
+	points, err := p.verifyCommitment(c)
+	if err != nil {
+		p.disqualified[c.Dealer] = true
+		return err
+	}
+	p.commitments[c.Dealer] = points
+	return nil
+}
+
+// ReceiveShare checks the share sent privately by a dealer against its commitment, which must have been
+// received first. If the share is invalid, it returns the complaint to broadcast, otherwise it returns nil.
+func (p *Participant) ReceiveShare(s DealerShare) (*Complaint, error) {
+	if s.Recipient != p.index {
+		return nil, errors.New("sharescalar: the share is addressed to another participant")
+	}
+	points, ok := p.commitments[s.Dealer]
+	if !ok || s.Dealer == p.index {
+		return nil, errors.New("sharescalar: the commitment of the dealer of the share was not received")
+	}
+	if _, ok := p.shares[s.Dealer]; ok {
+		return nil, errors.New("sharescalar: the share of the dealer was already received")
+	}
+	value, err := verifyShare(points, p.index, s.Value)
+	if err != nil {
+		return p.complain(s.Dealer), nil
+	}
+	p.shares[s.Dealer] = value
+	return nil, nil
+}
+
+// MissingShares returns the complaints to broadcast against the dealers whose commitment was received but
+// whose share was not. It must be called once all the shares have been received, or have timed out.
+func (p *Participant) MissingShares() []Complaint {
+	var complaints []Complaint
+	for _, dealer := range sortedKeys(p.commitments) {
+		if _, ok := p.shares[dealer]; !ok && !p.complaints[dealer][p.index] {
+			complaints = append(complaints, *p.complain(dealer))
+		}
+	}
+	return complaints
+}
+
+// ReceiveComplaint records a complaint broadcast by another participant.
+func (p *Participant) ReceiveComplaint(c Complaint) error {
+	if c.Accuser == 0 || c.Accuser > p.n || c.Dealer == 0 || c.Dealer > p.n || c.Accuser == c.Dealer {
+		return errors.New("sharescalar: the complaint has an invalid accuser or dealer")
+	}
+	if c.Accuser == p.index {
+		return errors.New("sharescalar: the complaints of the participant are recorded when they are made")
+	}
+	p.record(c)
+	return nil
+}
+
+// Justify returns the justification to broadcast in answer to a complaint against the participant, and records
+// that the complaint was answered.
+func (p *Participant) Justify(c Complaint) (Justification, error) {
+	if c.Dealer != p.index || p.polynomial.Coefficients == nil {
+		return Justification{}, errors.New("sharescalar: the complaint is not against the participant")
+	}
+	if c.Accuser == 0 || c.Accuser > p.n || c.Accuser == p.index {
+		return Justification{}, errors.New("sharescalar: the complaint has an invalid accuser")
+	}
+	value := p.polynomial.Eval(element[*edwards25519.Scalar](Edwards25519{}, c.Accuser))
+	p.record(c)
+	p.answer(c.Dealer, c.Accuser)
+	return Justification{Dealer: p.index, Accuser: c.Accuser, Value: value.Bytes()}, nil
+}
+
+// ReceiveJustification checks a justification broadcast by a dealer. If the justification answers a
+// complaint made by the participant, the revealed share replaces the disputed one. An invalid justification
+// disqualifies its dealer, and the error is returned.
+func (p *Participant) ReceiveJustification(j Justification) error {
+	if !p.complaints[j.Dealer][j.Accuser] {
+		return errors.New("sharescalar: the justification does not answer a complaint")
+	}
+	if p.justifications[j.Dealer][j.Accuser] {
+		return errors.New("sharescalar: the complaint was already answered")
+	}
+	points, ok := p.commitments[j.Dealer]
+	if !ok {
+		return errors.New("sharescalar: the commitment of the dealer of the justification was not received")
+	}
+	value, err := verifyShare(points, j.Accuser, j.Value)
+	if err != nil {
+		p.disqualified[j.Dealer] = true
+		return err
+	}
+	p.answer(j.Dealer, j.Accuser)
+	if j.Accuser == p.index {
+		p.shares[j.Dealer] = value
+	}
+	return nil
+}
+
+// Finalize ends the DKG, once all the complaints and justifications have been received, and returns the share
+// of the key of the participant. The key is the sum of the secrets of the qualified dealers, and at least
+// threshold dealers must qualify, so that the key is random if fewer than threshold participants are dishonest.
+func (p *Participant) Finalize() (*KeyShare, error) {
+	if p.polynomial.Coefficients == nil {
+		return nil, errors.New("sharescalar: the participant has not dealt")
+	}
+	var qualified []uint8
+	for _, dealer := range sortedKeys(p.commitments) {
+		if !p.qualifies(dealer) {
+			continue
+		}
+		qualified = append(qualified, dealer)
+	}
+	if !p.qualifies(p.index) {
+		return nil, ErrDisqualified
+	}
+	if len(qualified) < int(p.threshold) {
+		return nil, errors.New("sharescalar: fewer than threshold dealers qualified")
+	}
+
+	field := Edwards25519{}
+	secret := field.Zero()
+	for _, dealer := range qualified {
+		share, ok := p.shares[dealer]
+		if !ok {
+			return nil, errors.New("sharescalar: the share of a qualified dealer is missing")
+		}
+		secret = field.Add(secret, share)
+	}
+
+	// the commitments of the qualified dealers add up to the commitment of the polynomial of the key
+	combined := make([]*edwards25519.Point, p.threshold)
+	for k := range combined {
+		combined[k] = edwards25519.NewIdentityPoint()
+		for _, dealer := range qualified {
+			combined[k].Add(combined[k], p.commitments[dealer][k])
+		}
+	}
+	verification := make(map[uint8]*edwards25519.Point, p.n)
+	for i := 1; i <= int(p.n); i++ {
+		verification[uint8(i)] = evaluateCommitment(combined, uint8(i))
+	}
+	return &KeyShare{
+		Index:              p.index,
+		Threshold:          p.threshold,
+		Secret:             secret,
+		PublicKey:          combined[0],
+		VerificationShares: verification,
+		Qualified:          qualified,
+	}, nil
+}
+
+// qualifies returns true if the dealer was not disqualified, answered all the complaints against it and
+// received fewer than threshold complaints.
+func (p *Participant) qualifies(dealer uint8) bool {
+	if p.disqualified[dealer] || len(p.complaints[dealer]) >= int(p.threshold) {
+		return false
+	}
+	for accuser := range p.complaints[dealer] {
+		if !p.justifications[dealer][accuser] {
+			return false
+		}
+	}
+	return true
+}
+
+// complain records a complaint of the participant against a dealer and returns it.
+func (p *Participant) complain(dealer uint8) *Complaint {
+	c := Complaint{Accuser: p.index, Dealer: dealer}
+	p.record(c)
+	return &c
+}
+
+// record records a complaint.
+func (p *Participant) record(c Complaint) {
+	if p.complaints[c.Dealer] == nil {
+		p.complaints[c.Dealer] = make(map[uint8]bool)
+	}
+	p.complaints[c.Dealer][c.Accuser] = true
+}
+
+// answer records that the complaint of accuser against dealer was answered.
+func (p *Participant) answer(dealer, accuser uint8) {
+	if p.justifications[dealer] == nil {
+		p.justifications[dealer] = make(map[uint8]bool)
+	}
+	p.justifications[dealer][accuser] = true
+}
+
+// verifyCommitment decodes the points of a commitment and checks its proof of knowledge.
+func (p *Participant) verifyCommitment(c Commitment) ([]*edwards25519.Point, error) {
+	if len(c.Coefficients) != int(p.threshold) {
+		return nil, errors.New("sharescalar: the commitment has an invalid number of coefficients")
+	}
+	points := make([]*edwards25519.Point, len(c.Coefficients))
+	for k, coefficient := range c.Coefficients {
+		point, err := new(edwards25519.Point).SetBytes(coefficient)
+		if err != nil {
+			return nil, errors.New("sharescalar: the commitment has an invalid coefficient")
+		}
+		points[k] = point
+	}
+
+	// the proof is (R, z) such that z*B = R + c*A[0], where c is the challenge
+	if len(c.Proof) != 64 {
+		return nil, errors.New("sharescalar: the proof of knowledge of the commitment is invalid")
+	}
+	r, err := new(edwards25519.Point).SetBytes(c.Proof[:32])
+	if err != nil {
+		return nil, errors.New("sharescalar: the proof of knowledge of the commitment is invalid")
+	}
+	z, err := edwards25519.NewScalar().SetCanonicalBytes(c.Proof[32:])
+	if err != nil {
+		return nil, errors.New("sharescalar: the proof of knowledge of the commitment is invalid")
+	}
+	challenge := p.challenge(c.Dealer, points[0], r)
+	expected := new(edwards25519.Point).Add(r, new(edwards25519.Point).ScalarMult(challenge, points[0]))
+	if new(edwards25519.Point).ScalarBaseMult(z).Equal(expected) != 1 {
+		return nil, errors.New("sharescalar: the proof of knowledge of the commitment is invalid")
+	}
+	return points, nil
+}
+
+// prove returns a Schnorr proof of knowledge of the secret of the participant.
+func (p *Participant) prove(secret *edwards25519.Scalar, public *edwards25519.Point) ([]byte, error) {
+	nonce, err := Edwards25519{}.Random(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	r := new(edwards25519.Point).ScalarBaseMult(nonce)
+	z := edwards25519.NewScalar().MultiplyAdd(p.challenge(p.index, public, r), secret, nonce)
+	return append(r.Bytes(), z.Bytes()...), nil
+}
+
+// challenge computes the challenge of the proof of knowledge of a dealer.
+func (p *Participant) challenge(dealer uint8, public, r *edwards25519.Point) *edwards25519.Scalar {
+	h := sha512.New()
+	h.Write([]byte(dkgProofDomain))
+	h.Write(p.context)
+	h.Write([]byte{dealer})
+	h.Write(public.Bytes())
+	h.Write(r.Bytes())
+	challenge, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	return challenge
+}
+
+// verifyShare decodes the share of the participant with the provided index and checks it against the
+// commitment of its dealer, i.e. that value*B is the commitment evaluated at index.
+func verifyShare(points []*edwards25519.Point, index uint8, encoded []byte) (*edwards25519.Scalar, error) {
+	value, err := edwards25519.NewScalar().SetCanonicalBytes(encoded)
+	if err != nil {
+		return nil, errors.New("sharescalar: the share is not a valid scalar")
+	}
+	if new(edwards25519.Point).ScalarBaseMult(value).Equal(evaluateCommitment(points, index)) != 1 {
+		return nil, errors.New("sharescalar: the share does not match the commitment of its dealer")
+	}
+	return value, nil
+}
+
+// evaluateCommitment computes the sum of points[k] * index^k using Horner's algorithm, i.e. the value of the
+// committed polynomial at index multiplied by the base point.
+func evaluateCommitment(points []*edwards25519.Point, index uint8) *edwards25519.Point {
+	x := element[*edwards25519.Scalar](Edwards25519{}, index)
+	value := new(edwards25519.Point).Set(points[len(points)-1])
+	for k := len(points) - 2; k >= 0; k-- {
+		value.ScalarMult(x, value)
+		value.Add(value, points[k])
+	}
+	return value
+}
+
+// sortedKeys returns the keys of a map in increasing order.
+func sortedKeys[V any](m map[uint8]V) []uint8 {
+	keys := make([]uint8, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
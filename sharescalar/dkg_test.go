@@ -0,0 +1,148 @@
+package sharescalar
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+// TestVerifyShareVector checks the Feldman commitment to the polynomial of the FROST(Ed25519, SHA-512) test
+// vector of RFC 9591 against the shares of the vector: its coefficient of degree 0 is the public key of the
+// vector.
+func TestVerifyShareVector(t *testing.T) {
+	suite := FROSTEd25519{}
+	publicKey, _ := testFROSTKey(t, suite, frostEd25519Key)
+	coefficient, err := suite.decodeScalar(decodeHex(t, frostEd25519Key.coefficient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := []*edwards25519.Point{publicKey, PublicKey(suite, coefficient)}
+	for i, share := range frostEd25519Key.shares {
+		if _, err := verifyShare(points, uint8(i+1), decodeHex(t, share)); err != nil {
+			t.Errorf("share %d: verifyShare() = %v", i+1, err)
+		}
+		if _, err := verifyShare(points, uint8(i+2), decodeHex(t, share)); err == nil {
+			t.Errorf("share %d: verifyShare() accepted the share at another index", i+1)
+		}
+	}
+}
+
+// runDKG runs the DKG among n honest participants, tampering with the share sent by dealer 1 to participant 2
+// so that it is disputed and justified, and returns the key shares.
+func runDKG(t *testing.T, n, threshold uint8) []*KeyShare {
+	t.Helper()
+	participants := make([]*Participant, n)
+	commitments := make([]Commitment, n)
+	var shares []DealerShare
+	for i := range participants {
+		var err error
+		if participants[i], err = NewParticipant([]byte("session"), uint8(i+1), n, threshold); err != nil {
+			t.Fatal(err)
+		}
+		var own []DealerShare
+		if commitments[i], own, err = participants[i].Deal(); err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, own...)
+	}
+	for _, p := range participants {
+		for _, c := range commitments {
+			if c.Dealer != p.index {
+				if err := p.ReceiveCommitment(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	var complaints []Complaint
+	for _, s := range shares {
+		if s.Dealer == 1 && s.Recipient == 2 {
+			s.Value = bytes.Repeat([]byte{1}, 32)
+		}
+		complaint, err := participants[s.Recipient-1].ReceiveShare(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if complaint != nil {
+			complaints = append(complaints, *complaint)
+		}
+	}
+	if len(complaints) != 1 {
+		t.Fatalf("%d complaints, want 1", len(complaints))
+	}
+	for _, c := range complaints {
+		for _, p := range participants {
+			if p.index != c.Accuser {
+				if err := p.ReceiveComplaint(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		justification, err := participants[c.Dealer-1].Justify(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range participants {
+			if p.index != c.Dealer {
+				if err := p.ReceiveJustification(justification); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+	}
+	keys := make([]*KeyShare, n)
+	for i, p := range participants {
+		var err error
+		if keys[i], err = p.Finalize(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return keys
+}
+
+// TestDKGFROSTEd25519 checks that a key generated by the DKG signs with FROST, and that the signature verifies
+// with crypto/ed25519 under the public key of the group.
+func TestDKGFROSTEd25519(t *testing.T) {
+	keys := runDKG(t, 4, 3)
+	for _, key := range keys {
+		if key.PublicKey.Equal(keys[0].PublicKey) != 1 || len(key.Qualified) != 4 {
+			t.Fatalf("participant %d: public key %x, qualified %v", key.Index, key.PublicKey.Bytes(), key.Qualified)
+		}
+		if PublicKey(FROSTEd25519{}, key.Secret).Equal(keys[0].VerificationShares[key.Index]) != 1 {
+			t.Errorf("participant %d: the verification share does not match the secret", key.Index)
+		}
+	}
+
+	suite := FROSTEd25519{}
+	signers := []*KeyShare{keys[3], keys[0], keys[2]}
+	message := []byte("test")
+	nonces := make([]*SigningNonces[*edwards25519.Scalar], len(signers))
+	commitments := make([]NonceCommitment, len(signers))
+	for i, key := range signers {
+		var err error
+		share := Share[*edwards25519.Scalar]{Index: key.Index, Value: key.Secret}
+		if nonces[i], commitments[i], err = Commit(suite, share); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signatureShares := make([]SignatureShare, len(signers))
+	for i, key := range signers {
+		var err error
+		share := Share[*edwards25519.Scalar]{Index: key.Index, Value: key.Secret}
+		if signatureShares[i], err = SignShare(suite, nonces[i], share, key.PublicKey, message,
+			commitments); err != nil {
+			t.Fatal(err)
+		}
+	}
+	signature, err := Aggregate(suite, keys[0].PublicKey, message, commitments, signatureShares,
+		keys[0].VerificationShares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(keys[0].PublicKey.Bytes(), message, signature) {
+		t.Errorf("the signature %s does not verify with crypto/ed25519", hex.EncodeToString(signature))
+	}
+}
@@ -0,0 +1,218 @@
+package sharescalar
+
+import (
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Threshold ElGamal lets threshold holders of the shares of a private key decrypt a message encrypted to the
+// public key of the group, without reconstructing the key. A message is encrypted as in ECIES: the shared point
+// is r*8*Y, where r is an ephemeral scalar and Y the public key of the group, and a key is derived from it using
+// HKDF-SHA256 in order to encrypt the message with ChaCha20-Poly1305. The ciphertext is encoded as:
+//
+// 	offset  size  field
+// 	0       32    ephemeral point R = r*B
+// 	32      -     encrypted message, and tag
+//
+// The holder of the share s[i] computes the partial decryption s[i]*8*R along with a proof that it used its
+// share, and threshold partial decryptions are combined using Lagrange's interpolation in the exponent into the
+// shared point. The ephemeral point is multiplied by the cofactor, so that a crafted point cannot reveal
+// information about the shares.
+
+const (
+	elgamalInfo        = "shamir-sss threshold ElGamal"
+	elgamalProofDomain = "shamir-sss partial decryption proof\x00"
+	pointSize          = 32
+)
+
+// ErrThresholdDecryption is returned when a ciphertext cannot be decrypted, because it was encrypted to another
+// key, was altered, or the partial decryptions are invalid.
+var ErrThresholdDecryption = errors.New("sharescalar: the ciphertext cannot be decrypted")
+
+// PartialDecryption is the contribution of the holder of a share to the decryption of a ciphertext.
+type PartialDecryption struct {
+	// Index is the index of the share.
+	Index uint8 `json:"index"`
+	// Value is the encoded point s[i]*8*R.
+	Value []byte `json:"value"`
+	// Proof is a Chaum-Pedersen proof that the discrete logarithm of Value in base 8*R is the discrete logarithm
+	// of the verification share of the holder in base B.
+	Proof []byte `json:"proof"`
+}
+
+// Encrypt encrypts a message to the public key of a group, e.g. KeyShare.PublicKey.
+func Encrypt(publicKey *edwards25519.Point, plaintext []byte) ([]byte, error) {
+	r, err := Edwards25519{}.Random(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral := new(edwards25519.Point).ScalarBaseMult(r)
+	shared := new(edwards25519.Point).ScalarMult(r, new(edwards25519.Point).MultByCofactor(publicKey))
+
+	header := ephemeral.Bytes()
+	aead, err := elgamalAEAD(shared, header, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	// the key is only used once, so the nonce can be zero
+	return aead.Seal(header, make([]byte, chacha20poly1305.NonceSize), plaintext, header), nil
+}
+
+// PartialDecrypt computes the partial decryption of a ciphertext by the holder of a share.
+func PartialDecrypt(share Share[*edwards25519.Scalar], ciphertext []byte) (PartialDecryption, error) {
+	point, err := ephemeralPoint(ciphertext)
+	if err != nil {
+		return PartialDecryption{}, err
+	}
+	value := new(edwards25519.Point).ScalarMult(share.Value, point)
+	public := new(edwards25519.Point).ScalarBaseMult(share.Value)
+
+	// Chaum-Pedersen proof (c, z): k is random, and z = k + c*s[i] where c is the hash of k*B and k*8*R
+	k, err := Edwards25519{}.Random(rand.Reader)
+	if err != nil {
+		return PartialDecryption{}, err
+	}
+	c := decryptionChallenge(public, point, value,
+		new(edwards25519.Point).ScalarBaseMult(k), new(edwards25519.Point).ScalarMult(k, point))
+	z := edwards25519.NewScalar().MultiplyAdd(c, share.Value, k)
+	return PartialDecryption{
+		Index: share.Index,
+		Value: value.Bytes(),
+		Proof: append(c.Bytes(), z.Bytes()...),
+	}, nil
+}
+
+// VerifyPartialDecryption checks the proof of a partial decryption of a ciphertext against the verification
+// share of its holder, e.g. KeyShare.VerificationShares[partial.Index].
+func VerifyPartialDecryption(verificationShare *edwards25519.Point, ciphertext []byte,
+	partial PartialDecryption) error {
+	point, err := ephemeralPoint(ciphertext)
+	if err != nil {
+		return err
+	}
+	if _, err := verifyPartialDecryption(verificationShare, point, partial); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CombineDecryption combines threshold partial decryptions of a ciphertext and returns the message. The partial
+// decryptions are verified against verificationShares, which holds the verification shares of the holders by
+// index, and an invalid partial decryption results in an error.
+func CombineDecryption(ciphertext []byte, partials []PartialDecryption,
+	verificationShares map[uint8]*edwards25519.Point) ([]byte, error) {
+	point, err := ephemeralPoint(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	indexes := make([]uint8, len(partials))
+	for i, partial := range partials {
+		indexes[i] = partial.Index
+	}
+	coefficients, err := LagrangeCoefficients[*edwards25519.Scalar](Edwards25519{}, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := edwards25519.NewIdentityPoint()
+	for i, partial := range partials {
+		public, ok := verificationShares[partial.Index]
+		if !ok {
+			return nil, errors.New("sharescalar: the verification share of a holder is missing")
+		}
+		value, err := verifyPartialDecryption(public, point, partial)
+		if err != nil {
+			return nil, err
+		}
+		shared.Add(shared, new(edwards25519.Point).ScalarMult(coefficients[i], value))
+	}
+
+	// the public key of the group is interpolated from the verification shares of the holders alike
+	publicKey := edwards25519.NewIdentityPoint()
+	for i, index := range indexes {
+		publicKey.Add(publicKey, new(edwards25519.Point).ScalarMult(coefficients[i], verificationShares[index]))
+	}
+	header := ciphertext[:pointSize]
+	aead, err := elgamalAEAD(shared, header, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), ciphertext[pointSize:], header)
+	if err != nil {
+		return nil, ErrThresholdDecryption
+	}
+	return plaintext, nil
+}
+
+// ephemeralPoint decodes the ephemeral point of a ciphertext and returns it multiplied by the cofactor.
+func ephemeralPoint(ciphertext []byte) (*edwards25519.Point, error) {
+	if len(ciphertext) < pointSize+chacha20poly1305.Overhead {
+		return nil, ErrThresholdDecryption
+	}
+	point, err := new(edwards25519.Point).SetBytes(ciphertext[:pointSize])
+	if err != nil {
+		return nil, ErrThresholdDecryption
+	}
+	point.MultByCofactor(point)
+	if point.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, ErrThresholdDecryption
+	}
+	return point, nil
+}
+
+// verifyPartialDecryption checks the proof of a partial decryption and returns its decoded value.
+func verifyPartialDecryption(public, point *edwards25519.Point, partial PartialDecryption) (*edwards25519.Point,
+	error) {
+	invalid := errors.New("sharescalar: the partial decryption is invalid")
+	value, err := new(edwards25519.Point).SetBytes(partial.Value)
+	if err != nil || len(partial.Proof) != 64 {
+		return nil, invalid
+	}
+	c, err := edwards25519.NewScalar().SetCanonicalBytes(partial.Proof[:32])
+	if err != nil {
+		return nil, invalid
+	}
+	z, err := edwards25519.NewScalar().SetCanonicalBytes(partial.Proof[32:])
+	if err != nil {
+		return nil, invalid
+	}
+	// k*B = z*B - c*Y[i] and k*8*R = z*8*R - c*D[i]
+	minusC := edwards25519.NewScalar().Negate(c)
+	a := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(minusC, public, z)
+	b := new(edwards25519.Point).ScalarMult(z, point)
+	b.Add(b, new(edwards25519.Point).ScalarMult(minusC, value))
+	if decryptionChallenge(public, point, value, a, b).Equal(c) != 1 {
+		return nil, invalid
+	}
+	return value, nil
+}
+
+// decryptionChallenge computes the challenge of the proof of a partial decryption.
+func decryptionChallenge(public, point, value, a, b *edwards25519.Point) *edwards25519.Scalar {
+	h := sha512.New()
+	h.Write([]byte(elgamalProofDomain))
+	for _, p := range []*edwards25519.Point{public, point, value, a, b} {
+		h.Write(p.Bytes())
+	}
+	c, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	return c
+}
+
+// elgamalAEAD derives the key encrypting a message from the shared point, salted with the ephemeral point and
+// the public key of the group.
+func elgamalAEAD(shared *edwards25519.Point, ephemeral []byte, publicKey *edwards25519.Point) (cipher.AEAD,
+	error) {
+	salt := append(append([]byte{}, ephemeral...), publicKey.Bytes()...)
+	key, err := hkdf.Key(sha256.New, shared.Bytes(), salt, elgamalInfo, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
@@ -0,0 +1,62 @@
+package sharescalar
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+// elgamalCiphertext is the message "attack at dawn" encrypted to the public key of the FROST(Ed25519, SHA-512)
+// test vector of RFC 9591, whose shares decrypt it.
+const elgamalCiphertext = "d800bbc8beae6387311e6662c9236dfa89df55e6c305f225c2474d6c42d1da9d" +
+	"7eb06a2f4b830daa91903024b19a163a6d8f5ba36f8afe058edb593925d9"
+
+func TestCombineDecryptionVector(t *testing.T) {
+	_, shares := testFROSTKey(t, FROSTEd25519{}, frostEd25519Key)
+	verificationShares := make(map[uint8]*edwards25519.Point)
+	for _, share := range shares {
+		verificationShares[share.Index] = PublicKey(FROSTEd25519{}, share.Value)
+	}
+	ciphertext := decodeHex(t, elgamalCiphertext)
+	for _, holders := range [][]Share[*edwards25519.Scalar]{shares[:2], {shares[2], shares[0]}, shares} {
+		partials := make([]PartialDecryption, len(holders))
+		for i, share := range holders {
+			var err error
+			if partials[i], err = PartialDecrypt(share, ciphertext); err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyPartialDecryption(verificationShares[share.Index], ciphertext, partials[i]); err != nil {
+				t.Errorf("VerifyPartialDecryption() = %v", err)
+			}
+		}
+		plaintext, err := CombineDecryption(ciphertext, partials, verificationShares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(plaintext, []byte("attack at dawn")) {
+			t.Errorf("CombineDecryption() = %q, want %q", plaintext, "attack at dawn")
+		}
+	}
+
+	// a partial decryption computed with another share fails its proof
+	forged, err := PartialDecrypt(Share[*edwards25519.Scalar]{Index: 1, Value: shares[1].Value}, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPartialDecryption(verificationShares[1], ciphertext, forged); err == nil {
+		t.Error("VerifyPartialDecryption() accepted a partial decryption computed with another share")
+	}
+	altered := bytes.Clone(ciphertext)
+	altered[len(altered)-1] ^= 1
+	partials := make([]PartialDecryption, 2)
+	for i, share := range shares[:2] {
+		if partials[i], err = PartialDecrypt(share, altered); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := CombineDecryption(altered, partials, verificationShares); !errors.Is(err, ErrThresholdDecryption) {
+		t.Errorf("CombineDecryption() error = %v, want %v", err, ErrThresholdDecryption)
+	}
+}
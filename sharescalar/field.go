@@ -0,0 +1,197 @@
+package sharescalar
+
+import (
+	"io"
+
+	"filippo.io/edwards25519"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// The scalar fields below implement galois.Field using constant-time arithmetic: the scalars are secret, e.g.
+// private keys or shares of private keys, and must not leak through timing side channels. Only the checks for
+// division by zero branch on the values, which are the differences of public coordinates in Shamir's scheme.
+
+var (
+	_ galois.Field[*edwards25519.Scalar]  = Edwards25519{}
+	_ galois.Field[*secp256k1.ModNScalar] = Secp256k1{}
+)
+
+// Edwards25519 is the scalar field of the edwards25519 and ristretto255 groups, i.e. the integers modulo the
+// prime 2^252 + 27742317777372353535851937790883648493. Elements are encoded using 32 bytes in little-endian
+// order, as in Ed25519.
+type Edwards25519 struct{}
+
+// Zero returns the scalar 0.
+func (Edwards25519) Zero() *edwards25519.Scalar {
+	return edwards25519.NewScalar()
+}
+
+// One returns the scalar 1.
+func (Edwards25519) One() *edwards25519.Scalar {
+	one := [32]byte{1}
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(one[:])
+	return s
+}
+
+// Add computes the addition a+b.
+func (Edwards25519) Add(a, b *edwards25519.Scalar) *edwards25519.Scalar {
+	return edwards25519.NewScalar().Add(a, b)
+}
+
+// Subtract computes the substraction a-b.
+func (Edwards25519) Subtract(a, b *edwards25519.Scalar) *edwards25519.Scalar {
+	return edwards25519.NewScalar().Subtract(a, b)
+}
+
+// Multiply computes the multiplication a*b.
+func (Edwards25519) Multiply(a, b *edwards25519.Scalar) *edwards25519.Scalar {
+	return edwards25519.NewScalar().Multiply(a, b)
+}
+
+// Divide computes the division a/b. It panics with galois.ErrDivisionByZero if b is 0.
+func (f Edwards25519) Divide(a, b *edwards25519.Scalar) *edwards25519.Scalar {
+	return f.Multiply(a, f.Inverse(b))
+}
+
+// Inverse computes the multiplicative inverse of a. It panics with galois.ErrDivisionByZero if a is 0.
+func (f Edwards25519) Inverse(a *edwards25519.Scalar) *edwards25519.Scalar {
+	if f.Equal(a, f.Zero()) {
+		panic(galois.ErrDivisionByZero)
+	}
+	return edwards25519.NewScalar().Invert(a)
+}
+
+// Equal returns true if a and b are the same scalar.
+func (Edwards25519) Equal(a, b *edwards25519.Scalar) bool {
+	return a.Equal(b) == 1
+}
+
+// ElementSize returns the length in bytes of the encoding of a scalar, i.e. 32.
+func (Edwards25519) ElementSize() int {
+	return 32
+}
+
+// PutElement encodes a into dst[0:32].
+func (Edwards25519) PutElement(dst []byte, a *edwards25519.Scalar) {
+	copy(dst[:32], a.Bytes())
+}
+
+// Element decodes the scalar encoded in src[0:32]. Non-canonical encodings are reduced.
+func (Edwards25519) Element(src []byte) *edwards25519.Scalar {
+	if s, err := edwards25519.NewScalar().SetCanonicalBytes(src[:32]); err == nil {
+		return s
+	}
+	var wide [64]byte
+	copy(wide[:], src[:32])
+	s, _ := edwards25519.NewScalar().SetUniformBytes(wide[:])
+	return s
+}
+
+// Random returns a uniformly random scalar, reading randomness from r.
+func (Edwards25519) Random(r io.Reader) (*edwards25519.Scalar, error) {
+	var wide [64]byte
+	if _, err := io.ReadFull(r, wide[:]); err != nil {
+		return nil, err
+	}
+	return edwards25519.NewScalar().SetUniformBytes(wide[:])
+}
+
+// Secp256k1 is the scalar field of the secp256k1 group, i.e. the integers modulo its order
+// (see galois.Secp256k1Order). Elements are encoded using 32 bytes in big-endian order, as in BIP-340.
+type Secp256k1 struct{}
+
+// secp256k1OrderMinus2 is the order of the secp256k1 group minus 2, the exponent of the inverse.
+var secp256k1OrderMinus2 = [32]byte{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe,
+	0xba, 0xae, 0xdc, 0xe6, 0xaf, 0x48, 0xa0, 0x3b, 0xbf, 0xd2, 0x5e, 0x8c, 0xd0, 0x36, 0x41, 0x3f,
+}
+
+// Zero returns the scalar 0.
+func (Secp256k1) Zero() *secp256k1.ModNScalar {
+	return new(secp256k1.ModNScalar)
+}
+
+// One returns the scalar 1.
+func (Secp256k1) One() *secp256k1.ModNScalar {
+	return new(secp256k1.ModNScalar).SetInt(1)
+}
+
+// Add computes the addition a+b.
+func (Secp256k1) Add(a, b *secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	return new(secp256k1.ModNScalar).Add2(a, b)
+}
+
+// Subtract computes the substraction a-b.
+func (Secp256k1) Subtract(a, b *secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	return new(secp256k1.ModNScalar).NegateVal(b).Add(a)
+}
+
+// Multiply computes the multiplication a*b.
+func (Secp256k1) Multiply(a, b *secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	return new(secp256k1.ModNScalar).Mul2(a, b)
+}
+
+// Divide computes the division a/b. It panics with galois.ErrDivisionByZero if b is 0.
+func (f Secp256k1) Divide(a, b *secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	return f.Multiply(a, f.Inverse(b))
+}
+
+// Inverse computes the multiplicative inverse of a. It panics with galois.ErrDivisionByZero if a is 0.
+//
+// The inverse is computed in constant time as a^(n-2), since the inversion provided by the secp256k1 package
+// does not run in constant time.
+func (Secp256k1) Inverse(a *secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	if a.IsZero() {
+		panic(galois.ErrDivisionByZero)
+	}
+	inverse := new(secp256k1.ModNScalar).SetInt(1)
+	for _, b := range secp256k1OrderMinus2 {
+		for bit := 7; bit >= 0; bit-- {
+			inverse.Square()
+			// the exponent is public, branching on its bits does not leak a
+			if b>>bit&1 == 1 {
+				inverse.Mul(a)
+			}
+		}
+	}
+	return inverse
+}
+
+// Equal returns true if a and b are the same scalar.
+func (Secp256k1) Equal(a, b *secp256k1.ModNScalar) bool {
+	return a.Equals(b)
+}
+
+// ElementSize returns the length in bytes of the encoding of a scalar, i.e. 32.
+func (Secp256k1) ElementSize() int {
+	return 32
+}
+
+// PutElement encodes a into dst[0:32].
+func (Secp256k1) PutElement(dst []byte, a *secp256k1.ModNScalar) {
+	a.PutBytesUnchecked(dst[:32])
+}
+
+// Element decodes the scalar encoded in src[0:32]. Non-canonical encodings are reduced.
+func (Secp256k1) Element(src []byte) *secp256k1.ModNScalar {
+	s := new(secp256k1.ModNScalar)
+	s.SetByteSlice(src[:32])
+	return s
+}
+
+// Random returns a uniformly random scalar, reading randomness from r.
+func (Secp256k1) Random(r io.Reader) (*secp256k1.ModNScalar, error) {
+	var b [32]byte
+	s := new(secp256k1.ModNScalar)
+	for {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		// reject the values greater than the order, so that the scalar is uniform
+		if !s.SetByteSlice(b[:]) {
+			return s, nil
+		}
+	}
+}
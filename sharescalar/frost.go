@@ -0,0 +1,311 @@
+package sharescalar
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// FROST (RFC 9591) lets threshold holders of the shares of a signing key, dealt by SplitScalar or generated by
+// the DKG, produce a Schnorr signature without reconstructing the key. The signature is a standard Ed25519
+// (see FROSTEd25519) or BIP-340 (see FROSTSecp256k1) signature, which verifies under the public key of the
+// group. A signature takes two rounds among the signers, and a coordinator which can be one of them:
+//
+// 	1. Every signer calls Commit, keeps the secret nonces and sends the NonceCommitment to the coordinator.
+// 	2. The coordinator picks the message and at least threshold commitments, and sends them to the signers.
+// 	   Every signer calls SignShare and sends the SignatureShare to the coordinator.
+// 	3. The coordinator calls Aggregate, which returns the signature.
+//
+// Nonces must never be used twice: a signer which signs two messages with the same nonces reveals its share.
+// SignShare erases the nonces, and a signer must not persist them.
+
+// Ciphersuite is a FROST ciphersuite, i.e. a prime order group with scalars S and points P, and its hash
+// functions. It is implemented by FROSTEd25519 and FROSTSecp256k1.
+type Ciphersuite[S, P any] interface {
+	// field returns the scalar field of the group.
+	field() galois.Field[S]
+	// baseMult computes s*B, where B is the base point of the group.
+	baseMult(s S) P
+	// mult computes s*p.
+	mult(s S, p P) P
+	// add computes p+q.
+	add(p, q P) P
+	// identity returns the identity element of the group.
+	identity() P
+	// encodePoint and decodePoint encode the points which are not the identity.
+	encodePoint(p P) []byte
+	decodePoint(data []byte) (P, error)
+	// decodeScalar decodes a canonical scalar.
+	decodeScalar(data []byte) (S, error)
+	// hashToScalar and hash are the hash functions of the ciphersuite, tag being one of "rho", "nonce", "msg"
+	// and "com" (H1, H3, H4 and H5 in RFC 9591).
+	hashToScalar(tag string, data ...[]byte) S
+	hash(tag string, data ...[]byte) []byte
+	// challenge computes the challenge of the signature (H2 in RFC 9591).
+	challenge(r, publicKey P, message []byte) S
+	// negated returns true if the signature scheme uses the opposite of p, i.e. if the y coordinate of p is
+	// odd with BIP-340.
+	negated(p P) bool
+	// signature encodes the signature (r, z).
+	signature(r P, z S) []byte
+}
+
+// SigningNonces are the secret nonces of a signer for a single signature.
+type SigningNonces[S any] struct {
+	hiding     S
+	binding    S
+	commitment NonceCommitment
+	used       bool
+}
+
+// NonceCommitment is the message sent by a signer to the coordinator in the first round of FROST.
+type NonceCommitment struct {
+	// Signer is the index of the share of the signer.
+	Signer uint8 `json:"signer"`
+	// Hiding and Binding are the encoded commitments to the nonces of the signer.
+	Hiding  []byte `json:"hiding"`
+	Binding []byte `json:"binding"`
+}
+
+// SignatureShare is the message sent by a signer to the coordinator in the second round of FROST.
+type SignatureShare struct {
+	// Signer is the index of the share of the signer.
+	Signer uint8 `json:"signer"`
+	// Value is the encoded share of the signature.
+	Value []byte `json:"value"`
+}
+
+// PublicKey returns the public key of a secret scalar, i.e. the secret multiplied by the base point of the
+// group. The dealer of a key split using SplitScalar computes the public key of the group from the key, and the
+// verification shares checked by Aggregate from the shares.
+func PublicKey[S, P any](suite Ciphersuite[S, P], secret S) P {
+	return suite.baseMult(secret)
+}
+
+// Commit generates the nonces of the holder of a share for a signature, and returns the commitment to send to
+// the coordinator.
+func Commit[S, P any](suite Ciphersuite[S, P], share Share[S]) (*SigningNonces[S], NonceCommitment, error) {
+	hiding, err := generateNonce(suite, share.Value)
+	if err != nil {
+		return nil, NonceCommitment{}, err
+	}
+	binding, err := generateNonce(suite, share.Value)
+	if err != nil {
+		return nil, NonceCommitment{}, err
+	}
+	commitment := NonceCommitment{
+		Signer:  share.Index,
+		Hiding:  suite.encodePoint(suite.baseMult(hiding)),
+		Binding: suite.encodePoint(suite.baseMult(binding)),
+	}
+	return &SigningNonces[S]{hiding: hiding, binding: binding, commitment: commitment}, commitment, nil
+}
+
+// SignShare computes the share of the signature of the message by the holder of a share, given the commitments
+// of all the signers selected by the coordinator. The nonces are erased and cannot be used again.
+func SignShare[S, P any](suite Ciphersuite[S, P], nonces *SigningNonces[S], share Share[S], publicKey P,
+	message []byte, commitments []NonceCommitment) (SignatureShare, error) {
+	if nonces.used {
+		return SignatureShare{}, errors.New("sharescalar: the nonces have already been used")
+	}
+	field := suite.field()
+	session, err := newSigningSession(suite, publicKey, message, commitments)
+	if err != nil {
+		return SignatureShare{}, err
+	}
+	position := session.position(share.Index)
+	if position < 0 {
+		return SignatureShare{}, errors.New("sharescalar: the commitment of the signer is missing")
+	}
+	own := session.commitments[position]
+	if !bytes.Equal(own.Hiding, nonces.commitment.Hiding) || !bytes.Equal(own.Binding, nonces.commitment.Binding) {
+		return SignatureShare{}, errors.New("sharescalar: the commitment of the signer does not match its nonces")
+	}
+	nonces.used = true
+
+	// z = d + e*rho + lambda*s*c, where the nonces and the share are negated as required by the signature scheme
+	nonce := field.Add(nonces.hiding, field.Multiply(nonces.binding, session.bindingFactors[position]))
+	secret := share.Value
+	if session.negatedCommitment {
+		nonce = field.Subtract(field.Zero(), nonce)
+	}
+	if session.negatedKey {
+		secret = field.Subtract(field.Zero(), secret)
+	}
+	z := field.Add(nonce, field.Multiply(field.Multiply(session.coefficients[position], secret), session.challenge))
+	nonces.hiding, nonces.binding = field.Zero(), field.Zero()
+
+	value := make([]byte, field.ElementSize())
+	field.PutElement(value, z)
+	return SignatureShare{Signer: share.Index, Value: value}, nil
+}
+
+// Aggregate combines the shares of the signature of the message into the signature, given the commitments sent
+// to the signers. If verificationShares holds the public keys of the shares of the signers (see PublicKey and
+// KeyShare.VerificationShares), the shares of the signature are verified and an invalid share is reported in
+// the error, otherwise an invalid share results in an invalid signature.
+func Aggregate[S, P any](suite Ciphersuite[S, P], publicKey P, message []byte, commitments []NonceCommitment,
+	shares []SignatureShare, verificationShares map[uint8]P) ([]byte, error) {
+	field := suite.field()
+	session, err := newSigningSession(suite, publicKey, message, commitments)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) != len(session.commitments) {
+		return nil, errors.New("sharescalar: the number of signature shares does not match the number of commitments")
+	}
+
+	z := field.Zero()
+	seen := make(map[uint8]bool, len(shares))
+	for _, share := range shares {
+		position := session.position(share.Signer)
+		if position < 0 || seen[share.Signer] {
+			return nil, errors.New("sharescalar: a signature share does not match the commitments")
+		}
+		seen[share.Signer] = true
+		value, err := suite.decodeScalar(share.Value)
+		if err != nil {
+			return nil, err
+		}
+		if verificationShares != nil {
+			if err := session.verify(position, value, verificationShares); err != nil {
+				return nil, err
+			}
+		}
+		z = field.Add(z, value)
+	}
+	return suite.signature(session.commitment, z), nil
+}
+
+// signingSession holds the values derived from the commitments of the signers, which the signers and the
+// coordinator compute alike.
+type signingSession[S, P any] struct {
+	suite             Ciphersuite[S, P]
+	commitments       []NonceCommitment
+	hiding            []P
+	binding           []P
+	bindingFactors    []S
+	coefficients      []S
+	commitment        P
+	challenge         S
+	negatedCommitment bool
+	negatedKey        bool
+}
+
+// newSigningSession computes the binding factors of the signers, the commitment of the group, and the challenge.
+func newSigningSession[S, P any](suite Ciphersuite[S, P], publicKey P, message []byte,
+	commitments []NonceCommitment) (*signingSession[S, P], error) {
+	field := suite.field()
+	sorted := append([]NonceCommitment{}, commitments...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Signer < sorted[j].Signer })
+
+	s := &signingSession[S, P]{
+		suite:          suite,
+		commitments:    sorted,
+		hiding:         make([]P, len(sorted)),
+		binding:        make([]P, len(sorted)),
+		bindingFactors: make([]S, len(sorted)),
+	}
+	indexes := make([]uint8, len(sorted))
+	var encoded []byte
+	for i, commitment := range sorted {
+		indexes[i] = commitment.Signer
+		hiding, err := suite.decodePoint(commitment.Hiding)
+		if err != nil {
+			return nil, err
+		}
+		binding, err := suite.decodePoint(commitment.Binding)
+		if err != nil {
+			return nil, err
+		}
+		s.hiding[i], s.binding[i] = hiding, binding
+		encoded = append(encoded, encodeScalar(field, element(field, commitment.Signer))...)
+		encoded = append(encoded, commitment.Hiding...)
+		encoded = append(encoded, commitment.Binding...)
+	}
+	coefficients, err := LagrangeCoefficients(field, indexes)
+	if err != nil {
+		return nil, err
+	}
+	s.coefficients = coefficients
+
+	prefix := append(suite.encodePoint(publicKey), suite.hash("msg", message)...)
+	prefix = append(prefix, suite.hash("com", encoded)...)
+	commitment := suite.identity()
+	for i, signer := range indexes {
+		s.bindingFactors[i] = suite.hashToScalar("rho", prefix, encodeScalar(field, element(field, signer)))
+		commitment = suite.add(commitment, suite.add(s.hiding[i], suite.mult(s.bindingFactors[i], s.binding[i])))
+	}
+	s.commitment = commitment
+	s.challenge = suite.challenge(commitment, publicKey, message)
+	s.negatedCommitment = suite.negated(commitment)
+	s.negatedKey = suite.negated(publicKey)
+	return s, nil
+}
+
+// position returns the position of the commitment of a signer, or -1.
+func (s *signingSession[S, P]) position(signer uint8) int {
+	for i, commitment := range s.commitments {
+		if commitment.Signer == signer {
+			return i
+		}
+	}
+	return -1
+}
+
+// verify checks the share of the signature of the signer at the provided position, i.e. that
+// z*B = D + rho*E + lambda*c*Y, where Y is the verification share of the signer.
+func (s *signingSession[S, P]) verify(position int, z S, verificationShares map[uint8]P) error {
+	suite, field := s.suite, s.suite.field()
+	signer := s.commitments[position].Signer
+	public, ok := verificationShares[signer]
+	if !ok {
+		return errors.New("sharescalar: the verification share of a signer is missing")
+	}
+	minusOne := field.Subtract(field.Zero(), field.One())
+	commitment := suite.add(s.hiding[position], suite.mult(s.bindingFactors[position], s.binding[position]))
+	if s.negatedCommitment {
+		commitment = suite.mult(minusOne, commitment)
+	}
+	factor := field.Multiply(s.coefficients[position], s.challenge)
+	if s.negatedKey {
+		factor = field.Multiply(minusOne, factor)
+	}
+	expected := suite.add(commitment, suite.mult(factor, public))
+	if !bytes.Equal(suite.encodePoint(suite.baseMult(z)), suite.encodePoint(expected)) {
+		return &InvalidShareError{Signer: signer}
+	}
+	return nil
+}
+
+// InvalidShareError is returned by Aggregate when the share of the signature of a signer is invalid.
+type InvalidShareError struct {
+	// Signer is the index of the share of the signer.
+	Signer uint8
+}
+
+func (e *InvalidShareError) Error() string {
+	return fmt.Sprintf("sharescalar: the signature share of signer %d is invalid", e.Signer)
+}
+
+// generateNonce returns a nonce derived from fresh randomness and the secret, so that a weak random number
+// generator alone does not reveal the nonce.
+func generateNonce[S, P any](suite Ciphersuite[S, P], secret S) (S, error) {
+	var random [32]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		var zero S
+		return zero, err
+	}
+	return suite.hashToScalar("nonce", random[:], encodeScalar(suite.field(), secret)), nil
+}
+
+// encodeScalar returns the encoding of a scalar.
+func encodeScalar[S any](field galois.Field[S], s S) []byte {
+	encoded := make([]byte, field.ElementSize())
+	field.PutElement(encoded, s)
+	return encoded
+}
@@ -0,0 +1,98 @@
+package sharescalar
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// FROSTEd25519 is the FROST(Ed25519, SHA-512) ciphersuite of RFC 9591, which produces Ed25519 signatures.
+type FROSTEd25519 struct{}
+
+var _ Ciphersuite[*edwards25519.Scalar, *edwards25519.Point] = FROSTEd25519{}
+
+const frostEd25519Context = "FROST-ED25519-SHA512-v1"
+
+// Ed25519Scalar returns the secret scalar of an Ed25519 private key, which can be split using SplitScalar in
+// order to sign with FROSTEd25519. Its public key is the public key of the private key.
+func Ed25519Scalar(key ed25519.PrivateKey) *edwards25519.Scalar {
+	h := sha512.Sum512(key.Seed())
+	s, _ := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	return s
+}
+
+func (FROSTEd25519) field() galois.Field[*edwards25519.Scalar] {
+	return Edwards25519{}
+}
+
+func (FROSTEd25519) baseMult(s *edwards25519.Scalar) *edwards25519.Point {
+	return new(edwards25519.Point).ScalarBaseMult(s)
+}
+
+func (FROSTEd25519) mult(s *edwards25519.Scalar, p *edwards25519.Point) *edwards25519.Point {
+	return new(edwards25519.Point).ScalarMult(s, p)
+}
+
+func (FROSTEd25519) add(p, q *edwards25519.Point) *edwards25519.Point {
+	return new(edwards25519.Point).Add(p, q)
+}
+
+func (FROSTEd25519) identity() *edwards25519.Point {
+	return edwards25519.NewIdentityPoint()
+}
+
+func (FROSTEd25519) encodePoint(p *edwards25519.Point) []byte {
+	return p.Bytes()
+}
+
+func (FROSTEd25519) decodePoint(data []byte) (*edwards25519.Point, error) {
+	p, err := new(edwards25519.Point).SetBytes(data)
+	if err != nil || p.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return nil, errors.New("sharescalar: invalid edwards25519 point")
+	}
+	return p, nil
+}
+
+func (FROSTEd25519) decodeScalar(data []byte) (*edwards25519.Scalar, error) {
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(data)
+	if err != nil {
+		return nil, errors.New("sharescalar: invalid edwards25519 scalar")
+	}
+	return s, nil
+}
+
+func (FROSTEd25519) hashToScalar(tag string, data ...[]byte) *edwards25519.Scalar {
+	s, _ := edwards25519.NewScalar().SetUniformBytes(FROSTEd25519{}.hash(tag, data...))
+	return s
+}
+
+func (FROSTEd25519) hash(tag string, data ...[]byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(frostEd25519Context + tag))
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// challenge is the challenge of Ed25519, SHA-512(R || A || M) reduced modulo the order of the group.
+func (FROSTEd25519) challenge(r, publicKey *edwards25519.Point, message []byte) *edwards25519.Scalar {
+	h := sha512.New()
+	h.Write(r.Bytes())
+	h.Write(publicKey.Bytes())
+	h.Write(message)
+	c, _ := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	return c
+}
+
+func (FROSTEd25519) negated(*edwards25519.Point) bool {
+	return false
+}
+
+func (FROSTEd25519) signature(r *edwards25519.Point, z *edwards25519.Scalar) []byte {
+	return append(r.Bytes(), z.Bytes()...)
+}
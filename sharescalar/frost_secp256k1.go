@@ -0,0 +1,164 @@
+package sharescalar
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// FROSTSecp256k1 is the FROST(secp256k1, SHA-256) ciphersuite of RFC 9591 adapted to produce BIP-340 signatures,
+// as used by Taproot: the challenge is the BIP-340 challenge, and the nonces and the shares are negated when
+// the y coordinate of the commitment of the group or of the public key is odd. The public key of a signature
+// is the x coordinate of the public key of the group (see BIP340PublicKey).
+//
+// The scalar multiplications of the secp256k1 package do not run in constant time, so that the timing of
+// Commit may reveal information about the nonces to an attacker measuring it precisely.
+type FROSTSecp256k1 struct{}
+
+var _ Ciphersuite[*secp256k1.ModNScalar, *secp256k1.JacobianPoint] = FROSTSecp256k1{}
+
+const frostSecp256k1Context = "FROST-secp256k1-SHA256-TR-v1"
+
+// twoTo256 is 2^256 modulo the order of the secp256k1 group, used to reduce 48-byte hashes.
+var twoTo256 = func() *secp256k1.ModNScalar {
+	var s secp256k1.ModNScalar
+	s.SetByteSlice([]byte{0x01, 0x45, 0x51, 0x23, 0x19, 0x50, 0xb7, 0x5f, 0xc4, 0x40, 0x2d, 0xa1, 0x73, 0x2f, 0xc9, 0xbe, 0xbf})
+	return &s
+}()
+
+// BIP340PublicKey returns the x-only public key of BIP-340 under which the signatures of FROSTSecp256k1 verify.
+func BIP340PublicKey(publicKey *secp256k1.JacobianPoint) []byte {
+	p := *publicKey
+	p.ToAffine()
+	return p.X.Bytes()[:]
+}
+
+func (FROSTSecp256k1) field() galois.Field[*secp256k1.ModNScalar] {
+	return Secp256k1{}
+}
+
+func (FROSTSecp256k1) baseMult(s *secp256k1.ModNScalar) *secp256k1.JacobianPoint {
+	var p secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(s, &p)
+	return &p
+}
+
+func (FROSTSecp256k1) mult(s *secp256k1.ModNScalar, p *secp256k1.JacobianPoint) *secp256k1.JacobianPoint {
+	var result secp256k1.JacobianPoint
+	secp256k1.ScalarMultNonConst(s, p, &result)
+	return &result
+}
+
+func (FROSTSecp256k1) add(p, q *secp256k1.JacobianPoint) *secp256k1.JacobianPoint {
+	var result secp256k1.JacobianPoint
+	secp256k1.AddNonConst(p, q, &result)
+	return &result
+}
+
+func (FROSTSecp256k1) identity() *secp256k1.JacobianPoint {
+	return new(secp256k1.JacobianPoint)
+}
+
+// encodePoint returns the compressed encoding of the point, or 33 zero bytes for the identity.
+func (FROSTSecp256k1) encodePoint(p *secp256k1.JacobianPoint) []byte {
+	affine := *p
+	affine.ToAffine()
+	if (affine.X.IsZero() && affine.Y.IsZero()) || affine.Z.IsZero() {
+		return make([]byte, 33)
+	}
+	return secp256k1.NewPublicKey(&affine.X, &affine.Y).SerializeCompressed()
+}
+
+func (FROSTSecp256k1) decodePoint(data []byte) (*secp256k1.JacobianPoint, error) {
+	if len(data) != 33 {
+		return nil, errors.New("sharescalar: invalid secp256k1 point")
+	}
+	key, err := secp256k1.ParsePubKey(data)
+	if err != nil {
+		return nil, errors.New("sharescalar: invalid secp256k1 point")
+	}
+	var p secp256k1.JacobianPoint
+	key.AsJacobian(&p)
+	return &p, nil
+}
+
+func (FROSTSecp256k1) decodeScalar(data []byte) (*secp256k1.ModNScalar, error) {
+	var s secp256k1.ModNScalar
+	if len(data) != 32 || s.SetByteSlice(data) {
+		return nil, errors.New("sharescalar: invalid secp256k1 scalar")
+	}
+	return &s, nil
+}
+
+// hashToScalar implements hash_to_field of RFC 9380 using expand_message_xmd with SHA-256, as in RFC 9591.
+func (FROSTSecp256k1) hashToScalar(tag string, data ...[]byte) *secp256k1.ModNScalar {
+	const length = 48
+	dst := append([]byte(frostSecp256k1Context+tag), byte(len(frostSecp256k1Context+tag)))
+
+	h := sha256.New()
+	h.Write(make([]byte, sha256.BlockSize))
+	for _, d := range data {
+		h.Write(d)
+	}
+	h.Write([]byte{0, length, 0})
+	h.Write(dst)
+	b0 := h.Sum(nil)
+
+	uniform := make([]byte, 0, 2*sha256.Size)
+	previous := make([]byte, sha256.Size)
+	for i := byte(1); len(uniform) < length; i++ {
+		h.Reset()
+		for j := range previous {
+			previous[j] ^= b0[j]
+		}
+		h.Write(previous)
+		h.Write([]byte{i})
+		h.Write(dst)
+		previous = h.Sum(nil)
+		uniform = append(uniform, previous...)
+	}
+
+	// the 48 bytes are the big-endian integer hi*2^256 + lo, reduced modulo the order in constant time
+	var hi, lo secp256k1.ModNScalar
+	hi.SetByteSlice(uniform[:length-32])
+	lo.SetByteSlice(uniform[length-32 : length])
+	return hi.Mul(twoTo256).Add(&lo)
+}
+
+func (FROSTSecp256k1) hash(tag string, data ...[]byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(frostSecp256k1Context + tag))
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// challenge is the challenge of BIP-340, the tagged hash of the x coordinates of R and of the public key, and
+// of the message, reduced modulo the order of the group.
+func (FROSTSecp256k1) challenge(r, publicKey *secp256k1.JacobianPoint, message []byte) *secp256k1.ModNScalar {
+	tag := sha256.Sum256([]byte("BIP0340/challenge"))
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	h.Write(BIP340PublicKey(r))
+	h.Write(BIP340PublicKey(publicKey))
+	h.Write(message)
+	var c secp256k1.ModNScalar
+	c.SetByteSlice(h.Sum(nil))
+	return &c
+}
+
+func (FROSTSecp256k1) negated(p *secp256k1.JacobianPoint) bool {
+	affine := *p
+	affine.ToAffine()
+	return affine.Y.IsOdd()
+}
+
+func (FROSTSecp256k1) signature(r *secp256k1.JacobianPoint, z *secp256k1.ModNScalar) []byte {
+	encoded := z.Bytes()
+	return append(BIP340PublicKey(r), encoded[:]...)
+}
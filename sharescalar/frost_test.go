@@ -0,0 +1,280 @@
+package sharescalar
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// frostKeyVector is the key generation of the test vectors of RFC 9591: the key is split with a trusted dealer
+// among 3 participants with a threshold of 2, using the polynomial secret + coefficient*x.
+type frostKeyVector struct {
+	secret      string
+	coefficient string
+	publicKey   string
+	shares      []string
+}
+
+var (
+	frostEd25519Key = frostKeyVector{
+		secret:      "7b1c33d3f5291d85de664833beb1ad469f7fb6025a0ec78b3a790c6e13a98304",
+		coefficient: "178199860edd8c62f5212ee91eff1295d0d670ab4ed4506866bae57e7030b204",
+		publicKey:   "15d21ccd7ee42959562fc8aa63224c8851fb3ec85a3faf66040d380fb9738673",
+		shares: []string{
+			"929dcc590407aae7d388761cddb0c0db6f5627aea8e217f4a033f2ec83d93509",
+			"a91e66e012e4364ac9aaa405fcafd370402d9859f7b6685c07eed76bf409e80d",
+			"d3cb090a075eb154e82fdb4b3cb507f110040905468bb9c46da8bdea643a9a02",
+		},
+	}
+	frostSecp256k1Key = frostKeyVector{
+		secret:      "0d004150d27c3bf2a42f312683d35fac7394b1e9e318249c1bfe7f0795a83114",
+		coefficient: "fbf85eadae3058ea14f19148bb72b45e4399c0b16028acaf0395c9b03c823579",
+		publicKey:   "02f37c34b66ced1fb51c34a90bdae006901f10625cc06c4f64663b0eae87d87b4f",
+		shares: []string{
+			"08f89ffe80ac94dcb920c26f3f46140bfc7f95b493f8310f5fc1ea2b01f4254c",
+			"04f0feac2edcedc6ce1253b7fab8c86b856a797f44d83d82a385554e6e401984",
+			"00e95d59dd0d46b0e303e500b62b7ccb0e555d49f5b849f5e748c071da8c0dbc",
+		},
+	}
+)
+
+// testFROSTKey checks the shares and the public key of a key vector, and returns the public key and the shares.
+func testFROSTKey[S, P any](t *testing.T, suite Ciphersuite[S, P], v frostKeyVector) (P, []Share[S]) {
+	t.Helper()
+	field := suite.field()
+	secret, err := suite.decodeScalar(decodeHex(t, v.secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coefficient, err := suite.decodeScalar(decodeHex(t, v.coefficient))
+	if err != nil {
+		t.Fatal(err)
+	}
+	polynomial := galois.NewPoly(field, secret, coefficient)
+	shares := make([]Share[S], len(v.shares))
+	for i := range shares {
+		shares[i] = Share[S]{Index: uint8(i + 1), Value: polynomial.Eval(element(field, uint8(i+1)))}
+		if got := encodeHex(field, shares[i].Value); got != v.shares[i] {
+			t.Errorf("share %d = %s, want %s", i+1, got, v.shares[i])
+		}
+	}
+	publicKey := PublicKey(suite, secret)
+	if got := hex.EncodeToString(suite.encodePoint(publicKey)); got != v.publicKey {
+		t.Errorf("PublicKey() = %s, want %s", got, v.publicKey)
+	}
+	return publicKey, shares
+}
+
+// frostNonces derives the nonces of a signer from the randomness of a test vector, as generateNonce does.
+func frostNonces[S, P any](t *testing.T, suite Ciphersuite[S, P], share Share[S], hidingRandomness,
+	bindingRandomness string) *SigningNonces[S] {
+	t.Helper()
+	secret := encodeScalar(suite.field(), share.Value)
+	hiding := suite.hashToScalar("nonce", decodeHex(t, hidingRandomness), secret)
+	binding := suite.hashToScalar("nonce", decodeHex(t, bindingRandomness), secret)
+	return &SigningNonces[S]{hiding: hiding, binding: binding, commitment: NonceCommitment{
+		Signer:  share.Index,
+		Hiding:  suite.encodePoint(suite.baseMult(hiding)),
+		Binding: suite.encodePoint(suite.baseMult(binding)),
+	}}
+}
+
+// TestFROSTEd25519Vector follows the FROST(Ed25519, SHA-512) test vector of RFC 9591, signed by participants 1
+// and 3.
+func TestFROSTEd25519Vector(t *testing.T) {
+	suite := FROSTEd25519{}
+	publicKey, shares := testFROSTKey(t, suite, frostEd25519Key)
+	message := decodeHex(t, "74657374")
+	signers := []struct {
+		share                               Share[*edwards25519.Scalar]
+		hidingRandomness, bindingRandomness string
+		hiding, binding                     string
+		signatureShare                      string
+	}{
+		{
+			share:             shares[0],
+			hidingRandomness:  "0fd2e39e111cdc266f6c0f4d0fd45c947761f1f5d3cb583dfcb9bbaf8d4c9fec",
+			bindingRandomness: "69cd85f631d5f7f2721ed5e40519b1366f340a87c2f6856363dbdcda348a7501",
+			hiding:            "b5aa8ab305882a6fc69cbee9327e5a45e54c08af61ae77cb8207be3d2ce13de3",
+			binding:           "67e98ab55aa310c3120418e5050c9cf76cf387cb20ac9e4b6fdb6f82a469f932",
+			signatureShare:    "001719ab5a53ee1a12095cd088fd149702c0720ce5fd2f29dbecf24b7281b603",
+		},
+		{
+			share:             shares[2],
+			hidingRandomness:  "86d64a260059e495d0fb4fcc17ea3da7452391baa494d4b00321098ed2a0062f",
+			bindingRandomness: "13e6b25afb2eba51716a9a7d44130c0dbae0004a9ef8d7b5550c8a0e07c61775",
+			hiding:            "cfbdb165bd8aad6eb79deb8d287bcc0ab6658ae57fdcc98ed12c0669e90aec91",
+			binding:           "7487bc41a6e712eea2f2af24681b58b1cf1da278ea11fe4e8b78398965f13552",
+			signatureShare:    "bd86125de990acc5e1f13781d8e32c03a9bbd4c53539bbc106058bfd14326007",
+		},
+	}
+	nonces := make([]*SigningNonces[*edwards25519.Scalar], len(signers))
+	commitments := make([]NonceCommitment, len(signers))
+	for i, signer := range signers {
+		nonces[i] = frostNonces(t, suite, signer.share, signer.hidingRandomness, signer.bindingRandomness)
+		commitments[i] = nonces[i].commitment
+		if got := hex.EncodeToString(commitments[i].Hiding); got != signer.hiding {
+			t.Errorf("signer %d: hiding commitment = %s, want %s", signer.share.Index, got, signer.hiding)
+		}
+		if got := hex.EncodeToString(commitments[i].Binding); got != signer.binding {
+			t.Errorf("signer %d: binding commitment = %s, want %s", signer.share.Index, got, signer.binding)
+		}
+	}
+	signatureShares := make([]SignatureShare, len(signers))
+	verificationShares := make(map[uint8]*edwards25519.Point)
+	for i, signer := range signers {
+		var err error
+		signatureShares[i], err = SignShare(suite, nonces[i], signer.share, publicKey, message, commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(signatureShares[i].Value); got != signer.signatureShare {
+			t.Errorf("signer %d: signature share = %s, want %s", signer.share.Index, got, signer.signatureShare)
+		}
+		verificationShares[signer.share.Index] = PublicKey(suite, signer.share.Value)
+	}
+
+	signature, err := Aggregate(suite, publicKey, message, commitments, signatureShares, verificationShares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "36282629c383bb820a88b71cae937d41f2f2adfcc3d02e55507e2fb9e2dd3cbe" +
+		"bd9d2b0844e49ae0f3fa935161e1419aab7b47d21a37ebeae1f17d4987b3160b"
+	if got := hex.EncodeToString(signature); got != want {
+		t.Errorf("Aggregate() = %s, want %s", got, want)
+	}
+	if !ed25519.Verify(suite.encodePoint(publicKey), message, signature) {
+		t.Error("the signature does not verify with crypto/ed25519")
+	}
+}
+
+// TestEd25519Scalar checks Ed25519Scalar against the first test vector of RFC 8032.
+func TestEd25519Scalar(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(decodeHex(t, "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"))
+	public := PublicKey(FROSTEd25519{}, Ed25519Scalar(key))
+	want := "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	if got := hex.EncodeToString(public.Bytes()); got != want {
+		t.Errorf("Ed25519Scalar() public key = %s, want %s", got, want)
+	}
+}
+
+// TestFROSTSecp256k1BIP340 checks that the signatures of the key of the FROST(secp256k1, SHA-256) test vector of
+// RFC 9591 verify under BIP-340. The ciphersuite departs from RFC 9591 to produce BIP-340 signatures, so that only
+// the key generation of the vector applies.
+func TestFROSTSecp256k1BIP340(t *testing.T) {
+	suite := FROSTSecp256k1{}
+	publicKey, shares := testFROSTKey(t, suite, frostSecp256k1Key)
+	message := []byte("test")
+	for _, signers := range [][]Share[*secp256k1.ModNScalar]{shares[:2], {shares[2], shares[0]}} {
+		nonces := make([]*SigningNonces[*secp256k1.ModNScalar], len(signers))
+		commitments := make([]NonceCommitment, len(signers))
+		for i, share := range signers {
+			var err error
+			if nonces[i], commitments[i], err = Commit(suite, share); err != nil {
+				t.Fatal(err)
+			}
+		}
+		signatureShares := make([]SignatureShare, len(signers))
+		verificationShares := make(map[uint8]*secp256k1.JacobianPoint)
+		for i, share := range signers {
+			var err error
+			signatureShares[i], err = SignShare(suite, nonces[i], share, publicKey, message, commitments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verificationShares[share.Index] = PublicKey(suite, share.Value)
+		}
+		signature, err := Aggregate(suite, publicKey, message, commitments, signatureShares, verificationShares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verifyBIP340(BIP340PublicKey(publicKey), message, signature) {
+			t.Errorf("the signature %x does not verify under BIP-340", signature)
+		}
+	}
+}
+
+// TestVerifyBIP340 checks verifyBIP340 against the test vectors of BIP-340.
+func TestVerifyBIP340(t *testing.T) {
+	tests := []struct {
+		publicKey, message, signature string
+		valid                         bool
+	}{
+		{
+			"f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"e907831f80848d1069a5371b402410364bdf1c5f8307b0084c55f1ce2dca8215" +
+				"25f66a4a85ea8b71e482a74f382d2ce5ebeee8fdb2172f477df4900d310536c0",
+			true,
+		},
+		{
+			"dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659",
+			"243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89",
+			"6896bd60eeae296db48a229ff71dfe071bde413e6d43f917dc8dcf8c78de3341" +
+				"8906d11ac976abccb20b091292bff4ea897efcb639ea871cfa95f6de339e4b0a",
+			true,
+		},
+		{
+			"dd308afec5777e13121fa72b9cc1b7cc0139715309b086c960e18fd969774eb8",
+			"7e2d58d8b3bcdf1abadec7829054f90dda9805aab56c77333024b9d0a508b75c",
+			"5831aaeed7b44bb74e5eab94ba9d4294c49bcf2a60728d8b4c200f50dd313c1b" +
+				"ab745879a5ad954a72c45a91c3a51d3c7adea98d82f8481e0e1e03674a6f3fb7",
+			true,
+		},
+		{
+			// the public key is not on the curve
+			"eefdea4cdb677750a420fee807eacf21eb9898ae79b9768766e4faa04a2d4a34",
+			"243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c89",
+			"6cff5c3ba86c69ea4b7376f31a9bcb4f74c1976089b2d9963da2e5543e177769" +
+				"69e89b4c5564d00349106b8497785dd7d1d713a8ae82b32fa79d5f7fc407d39b",
+			false,
+		},
+	}
+	for i, tt := range tests {
+		if got := verifyBIP340(decodeHex(t, tt.publicKey), decodeHex(t, tt.message),
+			decodeHex(t, tt.signature)); got != tt.valid {
+			t.Errorf("vector %d: verifyBIP340() = %t, want %t", i, got, tt.valid)
+		}
+	}
+}
+
+// verifyBIP340 verifies a BIP-340 signature of a message under an x-only public key.
+func verifyBIP340(publicKey, message, signature []byte) bool {
+	key, err := secp256k1.ParsePubKey(append([]byte{0x02}, publicKey...))
+	if err != nil || len(signature) != 64 {
+		return false
+	}
+	var r secp256k1.FieldVal
+	var s secp256k1.ModNScalar
+	if r.SetByteSlice(signature[:32]) || s.SetByteSlice(signature[32:]) {
+		return false
+	}
+	tag := sha256.Sum256([]byte("BIP0340/challenge"))
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	h.Write(signature[:32])
+	h.Write(publicKey)
+	h.Write(message)
+	var e secp256k1.ModNScalar
+	e.SetByteSlice(h.Sum(nil))
+
+	// R = s*G - e*P
+	var p, sG, eP, point secp256k1.JacobianPoint
+	key.AsJacobian(&p)
+	secp256k1.ScalarBaseMultNonConst(&s, &sG)
+	secp256k1.ScalarMultNonConst(e.Negate(), &p, &eP)
+	secp256k1.AddNonConst(&sG, &eP, &point)
+	if (point.X.IsZero() && point.Y.IsZero()) || point.Z.IsZero() {
+		return false
+	}
+	point.ToAffine()
+	return !point.Y.IsOdd() && bytes.Equal(point.X.Bytes()[:], signature[:32])
+}
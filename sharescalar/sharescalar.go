@@ -0,0 +1,111 @@
+package sharescalar
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// This package splits scalars, i.e. elements of the scalar field of an elliptic curve group, rather than byte
+// strings. Sharing a private key as a scalar rather than as bytes is the building block of threshold
+// cryptography: the holders of the shares can compute with them, e.g. to sign with FROST or to run a
+// distributed key generation, without ever reconstructing the key.
+//
+// SplitScalar and RecoverScalar are generic over the field, and the package provides the scalar fields of
+// edwards25519 (Ed25519, ristretto255) and secp256k1. Participant i receives the point (i, f(i)) of a random
+// polynomial f such that f(0) is the secret, as in shamir.SplitPrime, so that shares are compatible with the
+// usual threshold signature schemes.
+
+// minThreshold is the minimum number of shares required to recover a secret, as in the shamir package.
+const minThreshold = 2
+
+// Share is the share of a scalar dealt to a single participant, i.e. the point (Index, Value) of the polynomial.
+type Share[S any] struct {
+	// Index is the coordinate of the participant, from 1 to the number of shares.
+	Index uint8
+	// Value is the value of the polynomial at Index.
+	Value S
+}
+
+// SplitScalar splits a secret scalar into n shares, such that threshold shares are required to recover it.
+// The participants are assigned the indexes 1 to n.
+func SplitScalar[S any](field galois.Field[S], secret S, n, threshold uint8) ([]Share[S], error) {
+	return splitScalar(field, secret, n, threshold, rand.Reader)
+}
+
+// splitScalar implements SplitScalar, reading the coefficients of the polynomial from r.
+func splitScalar[S any](field galois.Field[S], secret S, n, threshold uint8, r io.Reader) ([]Share[S], error) {
+	if threshold > n {
+		return nil, errors.New("sharescalar: the threshold cannot be greater than the number of shares")
+	}
+	if threshold < minThreshold {
+		return nil, errors.New("sharescalar: the threshold must be at least 2")
+	}
+
+	polynomial, err := galois.RandomPoly(field, secret, int(threshold)-1, r)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]Share[S], n)
+	for i := range shares {
+		index := uint8(i + 1)
+		shares[i] = Share[S]{Index: index, Value: polynomial.Eval(element(field, index))}
+	}
+	return shares, nil
+}
+
+// RecoverScalar combines shares dealt by SplitScalar using Lagrange's interpolation in order to reconstruct
+// the secret. At least threshold shares must be provided, otherwise an unrelated scalar is returned.
+func RecoverScalar[S any](field galois.Field[S], shares []Share[S]) (S, error) {
+	indexes := make([]uint8, len(shares))
+	for i, share := range shares {
+		indexes[i] = share.Index
+	}
+	coefficients, err := LagrangeCoefficients(field, indexes)
+	if err != nil {
+		var zero S
+		return zero, err
+	}
+
+	secret := field.Zero()
+	for i, share := range shares {
+		secret = field.Add(secret, field.Multiply(coefficients[i], share.Value))
+	}
+	return secret, nil
+}
+
+// LagrangeCoefficients returns the Lagrange coefficients of the participants with the given indexes, i.e. the
+// values at 0 of the Lagrange basis polynomials, such that the secret is the sum of the shares multiplied by
+// their coefficient. Threshold signature schemes use them to combine the contributions of the signers.
+func LagrangeCoefficients[S any](field galois.Field[S], indexes []uint8) ([]S, error) {
+	if len(indexes) < minThreshold {
+		return nil, errors.New("sharescalar: the number of shares provided is below the minimum threshold")
+	}
+	x := make([]S, len(indexes))
+	for i, index := range indexes {
+		if index == 0 {
+			return nil, errors.New("sharescalar: the index of a share cannot be 0")
+		}
+		for _, other := range indexes[:i] {
+			if index == other {
+				return nil, errors.New("sharescalar: all shares must have distinct indexes")
+			}
+		}
+		x[i] = element(field, index)
+	}
+	return galois.LagrangeBasis(field, x, field.Zero()), nil
+}
+
+// element returns the scalar i, i.e. 1 added i times, which does not depend on the encoding of the field.
+func element[S any](field galois.Field[S], i uint8) S {
+	e := field.Zero()
+	for bit := 7; bit >= 0; bit-- {
+		e = field.Add(e, e)
+		if i>>bit&1 == 1 {
+			e = field.Add(e, field.One())
+		}
+	}
+	return e
+}
@@ -0,0 +1,254 @@
+package sharescalar
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/etiennebch/shamir-sss/galois"
+)
+
+// vector is a known sharing of a scalar: the coefficients of the polynomial are read from randomness, and the
+// shares are its values at 1, 2, ..., all encoded as by the field.
+type vector struct {
+	name       string
+	secret     string
+	randomness string
+	threshold  uint8
+	shares     []string
+}
+
+// edwards25519Vectors are sharings in the scalar field of edwards25519, whose scalars are encoded in little-endian
+// order and drawn from 64 bytes of randomness.
+var edwards25519Vectors = []vector{
+	{
+		name:       "linear",
+		secret:     "0500000000000000000000000000000000000000000000000000000000000000",
+		randomness: "07000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		threshold:  2,
+		shares: []string{
+			"0c00000000000000000000000000000000000000000000000000000000000000",
+			"1300000000000000000000000000000000000000000000000000000000000000",
+			"1a00000000000000000000000000000000000000000000000000000000000000",
+		},
+	},
+	{
+		name:       "quadratic",
+		secret:     "0100000000000000000000000000000000000000000000000000000000000000",
+		randomness: "0200000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		threshold:  3,
+		shares: []string{
+			"0600000000000000000000000000000000000000000000000000000000000000",
+			"1100000000000000000000000000000000000000000000000000000000000000",
+			"2200000000000000000000000000000000000000000000000000000000000000",
+			"3900000000000000000000000000000000000000000000000000000000000000",
+		},
+	},
+	{
+		// the secret is the order minus 1, so that the shares wrap around the order
+		name:       "wrapping",
+		secret:     "ecd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010",
+		randomness: "01000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		threshold:  2,
+		shares: []string{
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"0100000000000000000000000000000000000000000000000000000000000000",
+			"0200000000000000000000000000000000000000000000000000000000000000",
+		},
+	},
+	{
+		name:       "random",
+		secret:     "1828ffa8e755e409a73c24c7a7c16b8b587036e18649206904a95f84f15b9704",
+		randomness: "e3e9431852a8cded04a85c1df87bd2148babda0ba5646aae8b067679d95435134348e70c260aee7be7dae4823c3bddefb57201754c7ec8262281f73f42123ad892d41cf268228cef0c39822dba2c7462397f84056e02e3395059f9787922b9052f12671a607bd4c010f47be69d7890041d09997383c8ddd84775384004f3aeab14a0988855a4ad7088d5380f17b8300883d565557fbc6a73dd255f3b7139da2b7763c4b4eeda7beeee151b0cbc2403069877a8fdc3c111d3930dfb30a1f12227",
+		threshold:  4,
+		shares: []string{
+			"7fce37e3d88db4da5b13c0852cf1f5a0bff9061a3a924832e130ebbfe557f202",
+			"9d5c02fb0a7cf58b316151b8d8d644e640d9e30ddf1f83801e4cdcf2cbdcd703",
+			"6bbec9ac10258111bad742802923a7042d6edd16257093815c200a33a36b7a0e",
+			"08380dfb47c70cafdaee0fb9de92ad7bd417048fbb003d633bd34b966a850c0a",
+			"5a892dff5dca84b0fbf41a2754d08509883568d0514f43535b8a783221abc00d",
+			"80f6a9bbb06c9e590262dfa54998c02d98261a3597d9697f5c6b671dc65dc900",
+		},
+	},
+}
+
+// secp256k1Vectors are sharings in the scalar field of secp256k1, whose scalars are encoded in big-endian order
+// and drawn from 32 bytes of randomness.
+var secp256k1Vectors = []vector{
+	{
+		name:       "linear",
+		secret:     "0000000000000000000000000000000000000000000000000000000000000005",
+		randomness: "0000000000000000000000000000000000000000000000000000000000000007",
+		threshold:  2,
+		shares: []string{
+			"000000000000000000000000000000000000000000000000000000000000000c",
+			"0000000000000000000000000000000000000000000000000000000000000013",
+			"000000000000000000000000000000000000000000000000000000000000001a",
+		},
+	},
+	{
+		name:       "quadratic",
+		secret:     "0000000000000000000000000000000000000000000000000000000000000001",
+		randomness: "00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000003",
+		threshold:  3,
+		shares: []string{
+			"0000000000000000000000000000000000000000000000000000000000000006",
+			"0000000000000000000000000000000000000000000000000000000000000011",
+			"0000000000000000000000000000000000000000000000000000000000000022",
+			"0000000000000000000000000000000000000000000000000000000000000039",
+		},
+	},
+	{
+		// the secret is the order minus 1, so that the shares wrap around the order
+		name:       "wrapping",
+		secret:     "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+		randomness: "0000000000000000000000000000000000000000000000000000000000000001",
+		threshold:  2,
+		shares: []string{
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"0000000000000000000000000000000000000000000000000000000000000001",
+			"0000000000000000000000000000000000000000000000000000000000000002",
+		},
+	},
+	{
+		name:       "random",
+		secret:     "ec814d476d7306dc1366fabd68cb7329bb5a8df3833783b0a2e62e1f6cd5d25a",
+		randomness: "c34e4864fb4a56387d1160a5aaab369ab45b95867602bf848a45f77a9ca11cee97317a544213e5b0097958664859a691c43bbb1faa63439b0270053ebfab32ca112bcee7f04669ff3808982b8ed2062cd54150c782ceb072d5e5a2402e7a0bbe",
+		threshold:  4,
+		shares: []string{
+			"582cdee89b17acc3d1fa4bf4eaa2568593d57593c7daf6cb85dd0fff572fab4e",
+			"59423ea1ee8a9a06f3b3defe5618ac1339a0db39c2d47391b1436b510785ef09",
+			"56c845e309724aa0c8c744df041a98e0f195c6abd5b37c7868a8af08c47ea2be",
+			"b7c5ce1b8d753a8ca1680e9c4d9441fbbb3c1c9711503430af6ea8a7a4f60ce1",
+			"e341b0bb1c39e5c5cdc9cd3b8b71cc71db6ce4c1d73a1d2fc924c721ef9232a5",
+			"4042c7315766c8479e2011c2169f5d51970126f28900b9eaf95a796beaf9193d",
+		},
+	},
+}
+
+func TestSplitScalarVectors(t *testing.T) {
+	t.Run("edwards25519", func(t *testing.T) {
+		testVectors(t, Edwards25519{}, edwards25519Vectors)
+	})
+	t.Run("secp256k1", func(t *testing.T) {
+		testVectors(t, Secp256k1{}, secp256k1Vectors)
+	})
+}
+
+// testVectors splits the secret of each vector with its randomness, checks the shares against the vector, and
+// recovers the secret from the first and the last threshold shares.
+func testVectors[S any](t *testing.T, field galois.Field[S], vectors []vector) {
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			secret := field.Element(decodeHex(t, v.secret))
+			shares, err := splitScalar(field, secret, uint8(len(v.shares)), v.threshold,
+				bytes.NewReader(decodeHex(t, v.randomness)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, share := range shares {
+				if share.Index != uint8(i+1) {
+					t.Errorf("share %d: index = %d", i+1, share.Index)
+				}
+				if got := encodeHex(field, share.Value); got != v.shares[i] {
+					t.Errorf("share %d = %s, want %s", i+1, got, v.shares[i])
+				}
+			}
+			for _, subset := range [][]Share[S]{shares[:v.threshold], shares[len(shares)-int(v.threshold):]} {
+				recovered, err := RecoverScalar(field, subset)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := encodeHex(field, recovered); got != v.secret {
+					t.Errorf("RecoverScalar() = %s, want %s", got, v.secret)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitScalarErrors(t *testing.T) {
+	field := Secp256k1{}
+	if _, err := SplitScalar(field, field.One(), 3, 4); err == nil {
+		t.Error("SplitScalar() accepted a threshold greater than the number of shares")
+	}
+	if _, err := SplitScalar(field, field.One(), 3, 1); err == nil {
+		t.Error("SplitScalar() accepted a threshold of 1")
+	}
+	if _, err := splitScalar(field, field.One(), 3, 2, bytes.NewReader(nil)); err == nil {
+		t.Error("splitScalar() succeeded without randomness")
+	}
+}
+
+func TestLagrangeCoefficients(t *testing.T) {
+	t.Run("edwards25519", func(t *testing.T) {
+		testLagrangeCoefficients(t, Edwards25519{})
+	})
+	t.Run("secp256k1", func(t *testing.T) {
+		testLagrangeCoefficients(t, Secp256k1{})
+	})
+}
+
+// testLagrangeCoefficients checks the coefficients of known sets of indexes, and that the coefficients of any set
+// sum to 1, i.e. interpolate the constant polynomial 1.
+func testLagrangeCoefficients[S any](t *testing.T, field galois.Field[S]) {
+	minus := func(i uint8) S { return field.Subtract(field.Zero(), element(field, i)) }
+	tests := []struct {
+		indexes []uint8
+		want    []S
+	}{
+		{[]uint8{1, 2}, []S{element(field, 2), minus(1)}},
+		{[]uint8{1, 2, 3}, []S{element(field, 3), minus(3), element(field, 1)}},
+		{[]uint8{3, 1, 2}, []S{element(field, 1), element(field, 3), minus(3)}},
+		{[]uint8{2, 4}, []S{element(field, 2), minus(1)}},
+	}
+	for _, tt := range tests {
+		coefficients, err := LagrangeCoefficients(field, tt.indexes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range tt.want {
+			if !field.Equal(coefficients[i], want) {
+				t.Errorf("LagrangeCoefficients(%v)[%d] = %s, want %s", tt.indexes, i,
+					encodeHex(field, coefficients[i]), encodeHex(field, want))
+			}
+		}
+	}
+
+	for _, indexes := range [][]uint8{{5, 17}, {1, 7, 200, 255}, {9, 3, 27, 81, 243}} {
+		coefficients, err := LagrangeCoefficients(field, indexes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum := field.Zero()
+		for _, coefficient := range coefficients {
+			sum = field.Add(sum, coefficient)
+		}
+		if !field.Equal(sum, field.One()) {
+			t.Errorf("the coefficients of %v sum to %s, want 1", indexes, encodeHex(field, sum))
+		}
+	}
+
+	for _, indexes := range [][]uint8{nil, {1}, {0, 1}, {1, 2, 1}} {
+		if _, err := LagrangeCoefficients(field, indexes); err == nil {
+			t.Errorf("LagrangeCoefficients(%v) succeeded", indexes)
+		}
+	}
+}
+
+// decodeHex decodes a scalar or randomness of a vector.
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// encodeHex encodes a scalar in hexadecimal.
+func encodeHex[S any](field galois.Field[S], a S) string {
+	b := make([]byte, field.ElementSize())
+	field.PutElement(b, a)
+	return hex.EncodeToString(b)
+}
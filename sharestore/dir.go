@@ -0,0 +1,105 @@
+package sharestore
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// A Dir stores every share in a file of a local directory named after its key (see Key), e.g. a mounted volume
+// or a directory synchronized by other means. The directories and files are only accessible to their owner. A
+// share is written to a temporary file which is then linked to its name, so that a share is never partially
+// written nor replaced.
+
+var _ ShareStore = Dir("")
+
+// Dir stores the shares in a local directory. It implements ShareStore.
+type Dir string
+
+// Put implements ShareStore.
+func (d Dir) Put(_ context.Context, share shamir.Share) error {
+	data, err := share.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	path := d.path(share.SplitID, share.Index)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".share-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Link(tmp.Name(), path); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return ErrExists
+		}
+		return err
+	}
+	return nil
+}
+
+// Get implements ShareStore.
+func (d Dir) Get(_ context.Context, id shamir.SplitID, index uint8) (shamir.Share, error) {
+	data, err := os.ReadFile(d.path(id, index))
+	if errors.Is(err, fs.ErrNotExist) {
+		return shamir.Share{}, ErrNotFound
+	}
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	defer clear(data)
+	return Decode(data, id, index)
+}
+
+// List implements ShareStore.
+func (d Dir) List(_ context.Context, id shamir.SplitID) ([]uint8, error) {
+	entries, err := os.ReadDir(filepath.Join(string(d), id.String()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var indexes []uint8
+	for _, entry := range entries {
+		if index, ok := ParseKey(entry.Name()); ok && entry.Type().IsRegular() {
+			indexes = append(indexes, index)
+		}
+	}
+	slices.Sort(indexes)
+	return indexes, nil
+}
+
+// Delete implements ShareStore. The directory of the split is removed with its last share.
+func (d Dir) Delete(_ context.Context, id shamir.SplitID, index uint8) error {
+	path := d.path(id, index)
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// fails if the directory still holds shares
+	os.Remove(filepath.Dir(path))
+	return nil
+}
+
+// path returns the path of the file of a share.
+func (d Dir) path(id shamir.SplitID, index uint8) string {
+	return filepath.Join(string(d), filepath.FromSlash(Key(id, index)))
+}
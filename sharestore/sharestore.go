@@ -0,0 +1,97 @@
+package sharestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package defines the ShareStore interface, so that ceremony tooling persists and retrieves shares the same
+// way whatever the backend: a local directory (Dir), or an S3-compatible object store (see the shares3 package).
+// Shares are identified by their split identifier and index, and stored as their binary encoding (see
+// shamir.Share.MarshalBinary) under the key returned by Key. Stores do not protect the confidentiality of the
+// shares: shares kept in shared backends should be wrapped (see sharewrap) or encrypted (see sharecrypt) first.
+
+var (
+	// ErrNotFound is returned when a store holds no such share.
+	ErrNotFound = errors.New("sharestore: share not found")
+	// ErrExists is returned when storing a share which is already stored.
+	ErrExists = errors.New("sharestore: share already stored")
+)
+
+// ShareStore stores shares by split identifier and index.
+type ShareStore interface {
+	// Put stores a share, or returns ErrExists if a share of the split with the same index is already stored.
+	Put(ctx context.Context, share shamir.Share) error
+	// Get returns the share of the split with the index, or ErrNotFound.
+	Get(ctx context.Context, id shamir.SplitID, index uint8) (shamir.Share, error)
+	// List returns the sorted indexes of the stored shares of the split.
+	List(ctx context.Context, id shamir.SplitID) ([]uint8, error)
+	// Delete deletes the share of the split with the index. Deleting a missing share is not an error.
+	Delete(ctx context.Context, id shamir.SplitID, index uint8) error
+}
+
+// Key returns the key of a share in a store, SPLIT/share-INDEX.bin.
+func Key(id shamir.SplitID, index uint8) string {
+	return id.String() + "/share-" + strconv.Itoa(int(index)) + ".bin"
+}
+
+// ParseKey parses the index of a share from the name of its key, share-INDEX.bin.
+func ParseKey(name string) (uint8, bool) {
+	digits, ok := strings.CutPrefix(name, "share-")
+	if !ok {
+		return 0, false
+	}
+	if digits, ok = strings.CutSuffix(digits, ".bin"); !ok {
+		return 0, false
+	}
+	index, err := strconv.ParseUint(digits, 10, 8)
+	if err != nil || strconv.Itoa(int(index)) != digits {
+		return 0, false
+	}
+	return uint8(index), true
+}
+
+// PutAll stores the shares of a split.
+func PutAll(ctx context.Context, store ShareStore, shares []shamir.Share) error {
+	for _, share := range shares {
+		if err := store.Put(ctx, share); err != nil {
+			return fmt.Errorf("share %d: %w", share.Index, err)
+		}
+	}
+	return nil
+}
+
+// GetAll returns the stored shares of a split.
+func GetAll(ctx context.Context, store ShareStore, id shamir.SplitID) ([]shamir.Share, error) {
+	indexes, err := store.List(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]shamir.Share, len(indexes))
+	for i, index := range indexes {
+		if shares[i], err = store.Get(ctx, id, index); err != nil {
+			return nil, fmt.Errorf("share %d: %w", index, err)
+		}
+	}
+	return shares, nil
+}
+
+// Decode decodes a share read from a store, checking that it is the share of the split with the index, so that a
+// share moved to another key of the backend is not accepted.
+func Decode(data []byte, id shamir.SplitID, index uint8) (shamir.Share, error) {
+	var share shamir.Share
+	if err := share.UnmarshalBinary(data); err != nil {
+		return shamir.Share{}, err
+	}
+	if share.SplitID != id || share.Index != index {
+		clear(share.Payload)
+		return shamir.Share{}, fmt.Errorf("sharestore: the share stored as %s is share %d of split %s",
+			Key(id, index), share.Index, share.SplitID)
+	}
+	return share, nil
+}
@@ -0,0 +1,109 @@
+package sharevault
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/etiennebch/shamir-sss/sharewrap"
+)
+
+// This package wraps shares with keys of the transit secrets engine of HashiCorp Vault (see sharewrap), for teams
+// centralizing their key operations in Vault but wanting the secret to be recovered outside of it. The data keys
+// are encrypted by the transit engine, and the context is authenticated as associated data (see
+// sharewrap.EncodeContext), which requires an AEAD key type such as aes256-gcm96 (the default) or
+// chacha20-poly1305. Every unwrapping is recorded by the audit devices of Vault, and governed by its policies on
+// the transit/decrypt/NAME paths.
+//
+// Keys are identified by their mount and name, such as transit/alice. A wrapper only uses the keys of its mount,
+// so that a tampered wrapped share cannot make it write to other paths.
+
+// Type is the type of the wrapper, recorded in the wrapped shares.
+const Type = "vault-transit"
+
+// DefaultMount is the default mount path of the transit engine.
+const DefaultMount = "transit"
+
+// name matches the names of the transit keys and the mount paths.
+var name = regexp.MustCompile(`^[0-9A-Za-z_.-]+(/[0-9A-Za-z_.-]+)*$`)
+
+var _ sharewrap.Wrapper = (*Wrapper)(nil)
+
+// Wrapper wraps data keys with a key of the transit engine. It implements sharewrap.Wrapper.
+type Wrapper struct {
+	client *api.Client
+	mount  string
+	key    string
+}
+
+// New returns a wrapper using the key of the transit engine mounted at mount (DefaultMount if empty). key may be
+// empty if the wrapper is only used to unwrap shares. client must be authenticated, e.g. with a token allowed to
+// use transit/encrypt/KEY to wrap and transit/decrypt/KEY to unwrap.
+func New(client *api.Client, mount, key string) (*Wrapper, error) {
+	if mount == "" {
+		mount = DefaultMount
+	}
+	mount = strings.Trim(mount, "/")
+	if !name.MatchString(mount) || (key != "" && (!name.MatchString(key) || strings.Contains(key, "/"))) {
+		return nil, errors.New("sharevault: invalid mount or key name")
+	}
+	return &Wrapper{client: client, mount: mount, key: key}, nil
+}
+
+// Type implements sharewrap.Wrapper.
+func (w *Wrapper) Type() string {
+	return Type
+}
+
+// WrapKey implements sharewrap.Wrapper. The identifier of the key is its mount and name, e.g. transit/alice.
+func (w *Wrapper) WrapKey(ctx context.Context, key []byte, context map[string]string) (string, []byte, error) {
+	if w.key == "" {
+		return "", nil, errors.New("sharevault: no key to wrap with")
+	}
+	secret, err := w.client.Logical().WriteWithContext(ctx, w.mount+"/encrypt/"+w.key, map[string]any{
+		"plaintext":       base64.StdEncoding.EncodeToString(key),
+		"associated_data": base64.StdEncoding.EncodeToString(sharewrap.EncodeContext(context)),
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("sharevault: %w", err)
+	}
+	ciphertext, ok := field(secret, "ciphertext")
+	if !ok {
+		return "", nil, errors.New("sharevault: invalid response")
+	}
+	return w.mount + "/" + w.key, []byte(ciphertext), nil
+}
+
+// UnwrapKey implements sharewrap.Wrapper.
+func (w *Wrapper) UnwrapKey(ctx context.Context, keyID string, wrapped []byte, context map[string]string) ([]byte, error) {
+	key, ok := strings.CutPrefix(keyID, w.mount+"/")
+	if !ok || !name.MatchString(key) || strings.Contains(key, "/") {
+		return nil, fmt.Errorf("sharevault: %q is not a key of the %s mount", keyID, w.mount)
+	}
+	secret, err := w.client.Logical().WriteWithContext(ctx, w.mount+"/decrypt/"+key, map[string]any{
+		"ciphertext":      string(wrapped),
+		"associated_data": base64.StdEncoding.EncodeToString(sharewrap.EncodeContext(context)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sharevault: %w", err)
+	}
+	plaintext, ok := field(secret, "plaintext")
+	if !ok {
+		return nil, errors.New("sharevault: invalid response")
+	}
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+// field returns a string field of the data of a response.
+func field(secret *api.Secret, key string) (string, bool) {
+	if secret == nil {
+		return "", false
+	}
+	value, ok := secret.Data[key].(string)
+	return value, ok && value != ""
+}
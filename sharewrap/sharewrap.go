@@ -0,0 +1,197 @@
+package sharewrap
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package wraps shares with keys held by key management services, so that shares stored in the cloud are
+// protected by the access policies of the keys and every unwrapping is audited by the service. Organizations can
+// spread the shares over several providers, one wrapped share per cloud, for jurisdictional or provider
+// diversity. The services are behind the Wrapper interface, implemented by the shareaws (AWS KMS), sharegcp
+// (Google Cloud KMS), shareazure (Azure Key Vault) and sharevault (HashiCorp Vault transit) packages.
+//
+// Shares are wrapped with envelope encryption: a random AES-256 data key encrypts the binary encoding of the share
+// with AES-256-GCM, and the data key is encrypted (wrapped) by the service, so that shares of any length can be
+// wrapped whatever the limits of the service. The split identifier and the index of the share are authenticated
+// along with the share, and passed to the service as context (see Context) to be bound to the data key and
+// recorded in the audit logs when the service supports it.
+
+// Context keys.
+const (
+	ContextSplit = "shamir:split"
+	ContextIndex = "shamir:index"
+)
+
+// ErrMismatch is returned when an unwrapped share does not match its envelope.
+var ErrMismatch = errors.New("sharewrap: the share does not match its envelope")
+
+// Wrapper wraps data keys with a key held by a key management service.
+type Wrapper interface {
+	// Type identifies the service, and is recorded in the wrapped shares (e.g. "aws-kms").
+	Type() string
+	// WrapKey encrypts a data key with the key of the wrapper, binding it to the context when the service
+	// supports it. It returns the identifier of the key used, which is passed back to UnwrapKey.
+	WrapKey(ctx context.Context, key []byte, context map[string]string) (keyID string, wrapped []byte, err error)
+	// UnwrapKey decrypts a data key wrapped by WrapKey with the key keyID, which may not be the key of the wrapper:
+	// a wrapper can unwrap the keys wrapped by any key of its service the caller is allowed to use.
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte, context map[string]string) ([]byte, error)
+}
+
+// WrappedShare is a wrapped share. It is encoded in JSON for storage.
+type WrappedShare struct {
+	// Wrapper is the type of the wrapper which wrapped the data key.
+	Wrapper string `json:"wrapper"`
+	// KeyID identifies the key the data key is wrapped with.
+	KeyID   string         `json:"keyId"`
+	Index   uint8          `json:"index"`
+	SplitID shamir.SplitID `json:"splitId"`
+	// EncryptedKey is the wrapped data key.
+	EncryptedKey []byte `json:"encryptedKey"`
+	// Ciphertext is the binary encoding of the share encrypted with the data key, preceded by the nonce.
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Wrap wraps a share.
+func Wrap(ctx context.Context, wrapper Wrapper, share shamir.Share) (WrappedShare, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return WrappedShare{}, err
+	}
+	defer clear(key)
+	aead, err := newAEAD(key)
+	if err != nil {
+		return WrappedShare{}, err
+	}
+	plaintext, err := share.MarshalBinary()
+	if err != nil {
+		return WrappedShare{}, err
+	}
+	defer clear(plaintext)
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return WrappedShare{}, err
+	}
+
+	keyID, wrapped, err := wrapper.WrapKey(ctx, key, Context(share.SplitID, share.Index))
+	if err != nil {
+		return WrappedShare{}, err
+	}
+	return WrappedShare{
+		Wrapper:      wrapper.Type(),
+		KeyID:        keyID,
+		Index:        share.Index,
+		SplitID:      share.SplitID,
+		EncryptedKey: wrapped,
+		Ciphertext:   aead.Seal(nonce, nonce, plaintext, additionalData(share.SplitID, share.Index)),
+	}, nil
+}
+
+// Unwrap unwraps a share with the first wrapper of its type.
+func Unwrap(ctx context.Context, wrapped WrappedShare, wrappers ...Wrapper) (shamir.Share, error) {
+	i := slices.IndexFunc(wrappers, func(w Wrapper) bool { return w.Type() == wrapped.Wrapper })
+	if i < 0 {
+		return shamir.Share{}, fmt.Errorf("sharewrap: no %s wrapper", wrapped.Wrapper)
+	}
+	key, err := wrappers[i].UnwrapKey(ctx, wrapped.KeyID, wrapped.EncryptedKey, Context(wrapped.SplitID, wrapped.Index))
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	defer clear(key)
+	aead, err := newAEAD(key)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	if len(wrapped.Ciphertext) < aead.NonceSize() {
+		return shamir.Share{}, errors.New("sharewrap: the ciphertext is too short")
+	}
+	nonce, ciphertext := wrapped.Ciphertext[:aead.NonceSize()], wrapped.Ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(wrapped.SplitID, wrapped.Index))
+	if err != nil {
+		return shamir.Share{}, errors.New("sharewrap: the share cannot be decrypted")
+	}
+	defer clear(plaintext)
+	var share shamir.Share
+	if err := share.UnmarshalBinary(plaintext); err != nil {
+		return shamir.Share{}, err
+	}
+	if share.SplitID != wrapped.SplitID || share.Index != wrapped.Index {
+		clear(share.Payload)
+		return shamir.Share{}, ErrMismatch
+	}
+	return share, nil
+}
+
+// WrapShares wraps every share with the wrapper of its custodian, e.g. with keys of different providers.
+func WrapShares(ctx context.Context, shares []shamir.Share, wrappers []Wrapper) ([]WrappedShare, error) {
+	if len(wrappers) != len(shares) {
+		return nil, errors.New("sharewrap: every share must have its own wrapper")
+	}
+	wrapped := make([]WrappedShare, len(shares))
+	for i, share := range shares {
+		var err error
+		if wrapped[i], err = Wrap(ctx, wrappers[i], share); err != nil {
+			return nil, fmt.Errorf("share %d: %w", share.Index, err)
+		}
+	}
+	return wrapped, nil
+}
+
+// Recover unwraps the shares with the wrappers of their types, and recovers the secret.
+func Recover(ctx context.Context, wrapped []WrappedShare, wrappers ...Wrapper) ([]byte, error) {
+	shares := make([]shamir.Share, len(wrapped))
+	defer func() {
+		for _, share := range shares {
+			clear(share.Payload)
+		}
+	}()
+	for i, w := range wrapped {
+		var err error
+		if shares[i], err = Unwrap(ctx, w, wrappers...); err != nil {
+			return nil, fmt.Errorf("share %d: %w", w.Index, err)
+		}
+	}
+	return shamir.Recover(shares)
+}
+
+// Context returns the context of the data key of a share.
+func Context(id shamir.SplitID, index uint8) map[string]string {
+	return map[string]string{
+		ContextSplit: id.String(),
+		ContextIndex: strconv.Itoa(int(index)),
+	}
+}
+
+// EncodeContext encodes a context as bytes, for the services authenticating additional data rather than
+// key-value pairs: every pair is encoded as key=value followed by a newline, sorted by key.
+func EncodeContext(context map[string]string) []byte {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(context)) {
+		b.WriteString(key + "=" + context[key] + "\n")
+	}
+	return []byte(b.String())
+}
+
+// additionalData returns the data authenticated along with a share.
+func additionalData(id shamir.SplitID, index uint8) []byte {
+	return append(id[:], index)
+}
+
+// newAEAD returns AES-256-GCM keyed with a data key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("sharewrap: invalid data key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
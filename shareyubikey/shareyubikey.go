@@ -0,0 +1,221 @@
+package shareyubikey
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hpke"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/go-piv/piv-go/v2/piv"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package encrypts shares to a key resident on the YubiKey of their custodian, through its PIV application,
+// so that a share can only be decrypted with the YubiKey plugged in and, for the keys generated with a touch
+// policy (see GenerateKey), physically touched. Custodians already using age can instead encrypt their share to
+// age-plugin-yubikey with the shareage package.
+//
+// Shares are encrypted with HPKE (RFC 9180) to the P-256, P-384 or X25519 key of a PIV slot, the decapsulation
+// being performed by the YubiKey. An encrypted share is encoded as:
+//
+// 	offset  size  field
+// 	0       4     magic bytes "SHMY"
+// 	4       1     format version
+// 	5       1     PIV slot of the key (e.g. 0x9d)
+// 	6       -     HPKE encapsulated key, encrypted binary encoding of the share, and tag
+//
+// The header (the first 6 bytes) is authenticated along with the share. The dealer should check that the keys
+// were generated on YubiKeys with a touch policy, with VerifyAttestation, before encrypting shares to them.
+
+const (
+	version      = 1
+	headerLength = 6
+	info         = "shamir-sss YubiKey share"
+)
+
+var magic = []byte("SHMY")
+
+// ErrDecryption is returned when an encrypted share cannot be decrypted, because it was encrypted to another key
+// or was altered.
+var ErrDecryption = errors.New("shareyubikey: the share cannot be decrypted with this YubiKey")
+
+// ErrNoTouch is returned by VerifyAttestation when the key can be used without touching the YubiKey.
+var ErrNoTouch = errors.New("shareyubikey: the key does not require a touch")
+
+// GenerateKey generates a P-256 key in a slot of the YubiKey, usually piv.SlotKeyManagement or a retired key
+// management slot, which requires the PIN once per session and a touch for every decryption. managementKey is the
+// PIV management key of the YubiKey (piv.DefaultManagementKey unless changed). It returns the public key to
+// encrypt the shares to.
+func GenerateKey(yk *piv.YubiKey, managementKey []byte, slot piv.Slot) (*ecdh.PublicKey, error) {
+	public, err := yk.GenerateKey(managementKey, slot, piv.Key{
+		Algorithm:   piv.AlgorithmEC256,
+		PINPolicy:   piv.PINPolicyOnce,
+		TouchPolicy: piv.TouchPolicyAlways,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("shareyubikey: %w", err)
+	}
+	return PublicKey(public)
+}
+
+// PublicKey returns the key to encrypt the shares to from the public key of a slot, e.g. the public key of the
+// certificate of the slot.
+func PublicKey(public crypto.PublicKey) (*ecdh.PublicKey, error) {
+	switch public := public.(type) {
+	case *ecdsa.PublicKey:
+		return public.ECDH()
+	case *ecdh.PublicKey:
+		if public.Curve() == ecdh.X25519() {
+			return public, nil
+		}
+	}
+	return nil, fmt.Errorf("shareyubikey: unsupported public key %T", public)
+}
+
+// VerifyAttestation verifies that the key of a slot certificate was generated on a YubiKey, using the attestation
+// certificate of the YubiKey (see piv.YubiKey.AttestationCertificate and piv.YubiKey.Attest), and that it requires
+// a touch to be used. It returns the key and the slot to encrypt the shares to.
+func VerifyAttestation(attestation, slotCertificate *x509.Certificate) (*ecdh.PublicKey, piv.Slot, error) {
+	a, err := piv.Verify(attestation, slotCertificate)
+	if err != nil {
+		return nil, piv.Slot{}, fmt.Errorf("shareyubikey: %w", err)
+	}
+	if a.TouchPolicy != piv.TouchPolicyAlways && a.TouchPolicy != piv.TouchPolicyCached {
+		return nil, piv.Slot{}, ErrNoTouch
+	}
+	public, err := PublicKey(slotCertificate.PublicKey)
+	if err != nil {
+		return nil, piv.Slot{}, err
+	}
+	return public, a.Slot, nil
+}
+
+// Encrypt encrypts a share to the key of a slot of the YubiKey of its custodian.
+func Encrypt(share shamir.Share, public *ecdh.PublicKey, slot piv.Slot) ([]byte, error) {
+	if _, ok := findSlot(slot.Key); !ok {
+		return nil, fmt.Errorf("shareyubikey: unsupported slot %s", slot)
+	}
+	plaintext, err := share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+	pk, err := hpke.NewDHKEMPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("shareyubikey: %w", err)
+	}
+	enc, sender, err := hpke.NewSender(pk, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), []byte(info))
+	if err != nil {
+		return nil, err
+	}
+	header := append(append([]byte{}, magic...), version, byte(slot.Key))
+	ciphertext, err := sender.Seal(header, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(header, enc...), ciphertext...), nil
+}
+
+// Decrypt decrypts a share with the key of the YubiKey. auth provides the PIN of the YubiKey, if the key requires
+// it. The YubiKey blinks until it is touched, if the key requires a touch: the caller should prompt the custodian
+// beforehand.
+func Decrypt(yk *piv.YubiKey, data []byte, auth piv.KeyAuth) (shamir.Share, error) {
+	if len(data) < headerLength || !bytes.Equal(data[:4], magic) {
+		return shamir.Share{}, errors.New("shareyubikey: not an encrypted share")
+	}
+	if data[4] != version {
+		return shamir.Share{}, fmt.Errorf("shareyubikey: unsupported version %d", data[4])
+	}
+	slot, ok := findSlot(uint32(data[5]))
+	if !ok {
+		return shamir.Share{}, fmt.Errorf("shareyubikey: unsupported slot %x", data[5])
+	}
+	public, err := slotPublicKey(yk, slot)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	private, err := yk.PrivateKey(slot, public, auth)
+	if err != nil {
+		return shamir.Share{}, fmt.Errorf("shareyubikey: %w", err)
+	}
+	exchanger, ok := private.(ecdher)
+	if !ok {
+		return shamir.Share{}, fmt.Errorf("shareyubikey: the key of slot %s does not support ECDH", slot)
+	}
+	key, err := PublicKey(public)
+	if err != nil {
+		return shamir.Share{}, err
+	}
+	sk, err := hpke.NewDHKEMPrivateKey(keyExchanger{exchanger, key})
+	if err != nil {
+		return shamir.Share{}, fmt.Errorf("shareyubikey: %w", err)
+	}
+
+	header, rest := data[:headerLength], data[headerLength:]
+	encSize := len(key.Bytes())
+	if len(rest) < encSize {
+		return shamir.Share{}, ErrDecryption
+	}
+	recipient, err := hpke.NewRecipient(rest[:encSize], sk, hpke.HKDFSHA256(), hpke.ChaCha20Poly1305(), []byte(info))
+	if err != nil {
+		// the YubiKey refused the decapsulation, e.g. because it was not touched in time
+		return shamir.Share{}, fmt.Errorf("shareyubikey: %w", err)
+	}
+	plaintext, err := recipient.Open(header, rest[encSize:])
+	if err != nil {
+		return shamir.Share{}, ErrDecryption
+	}
+	defer clear(plaintext)
+	var share shamir.Share
+	if err := share.UnmarshalBinary(plaintext); err != nil {
+		return shamir.Share{}, err
+	}
+	return share, nil
+}
+
+// slotPublicKey returns the public key of a slot, from the metadata of the key (YubiKey 5.3 and later) or else
+// from the certificate of the slot.
+func slotPublicKey(yk *piv.YubiKey, slot piv.Slot) (crypto.PublicKey, error) {
+	if info, err := yk.KeyInfo(slot); err == nil && info.PublicKey != nil {
+		return info.PublicKey, nil
+	}
+	cert, err := yk.Certificate(slot)
+	if err != nil {
+		return nil, fmt.Errorf("shareyubikey: no key in slot %s: %w", slot, err)
+	}
+	return cert.PublicKey, nil
+}
+
+// findSlot returns the PIV slot identified by key.
+func findSlot(key uint32) (piv.Slot, bool) {
+	for _, slot := range []piv.Slot{piv.SlotAuthentication, piv.SlotSignature, piv.SlotKeyManagement, piv.SlotCardAuthentication} {
+		if slot.Key == key {
+			return slot, true
+		}
+	}
+	return piv.RetiredKeyManagementSlot(key)
+}
+
+// ecdher is implemented by the private keys of the YubiKey supporting key agreements.
+type ecdher interface {
+	ECDH(peer *ecdh.PublicKey) ([]byte, error)
+}
+
+// keyExchanger adapts a private key of the YubiKey to ecdh.KeyExchanger, for HPKE.
+type keyExchanger struct {
+	ecdher
+	public *ecdh.PublicKey
+}
+
+func (k keyExchanger) PublicKey() *ecdh.PublicKey {
+	return k.public
+}
+
+func (k keyExchanger) Curve() ecdh.Curve {
+	return k.public.Curve()
+}
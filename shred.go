@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/rand"
+	"io"
+	"os"
+)
+
+// Once a secret is split, the file holding it can be shredded: its content is overwritten with random bytes, then
+// with zeros, synced to the disk, and the file is removed. This is a best effort: solid state drives remap the
+// blocks written to, and copy-on-write or journaling filesystems (e.g. btrfs, ZFS, APFS) write the new content
+// elsewhere, so that the former content of the file may survive on the disk. Backups and snapshots are not
+// affected either. Secrets should rather be kept on encrypted or memory-backed filesystems in the first place.
+
+// shredChunkSize is the size of the writes overwriting a file.
+const shredChunkSize = 64 << 10
+
+// shredFile overwrites the content of the file at path, and removes it.
+func shredFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	for _, source := range []io.Reader{rand.Reader, zeros{}} {
+		if err := overwrite(f, info.Size(), source); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// overwrite writes size bytes read from source at the start of the file, and syncs the file.
+func overwrite(f *os.File, size int64, source io.Reader) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.CopyBuffer(f, io.LimitReader(source, size), make([]byte, shredChunkSize)); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// zeros is a reader of zeros.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,63 @@
+package slip39
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// The master secret is encrypted with a 4-round Feistel network, using PBKDF2-HMAC-SHA256 as the round function.
+// The salt depends on the identifier of the split, unless the split is extendable in which case the same
+// encrypted master secret can be shared several times with different identifiers.
+
+// encrypt encrypts the master secret using the passphrase.
+func encrypt(secret []byte, passphrase string, iterationExponent uint8, identifier uint16, extendable bool) []byte {
+	left := secret[:len(secret)/2]
+	right := secret[len(secret)/2:]
+	salt := cipherSalt(identifier, extendable)
+	for i := 0; i < roundCount; i++ {
+		left, right = right, xor(left, roundFunction(byte(i), passphrase, iterationExponent, salt, right))
+	}
+	return append(append([]byte{}, right...), left...)
+}
+
+// decrypt decrypts the encrypted master secret using the passphrase.
+func decrypt(encrypted []byte, passphrase string, iterationExponent uint8, identifier uint16, extendable bool) []byte {
+	left := encrypted[:len(encrypted)/2]
+	right := encrypted[len(encrypted)/2:]
+	salt := cipherSalt(identifier, extendable)
+	for i := roundCount - 1; i >= 0; i-- {
+		left, right = right, xor(left, roundFunction(byte(i), passphrase, iterationExponent, salt, right))
+	}
+	return append(append([]byte{}, right...), left...)
+}
+
+// roundFunction computes the Feistel round function F(i, R) = PBKDF2(i || passphrase, salt || R).
+func roundFunction(i byte, passphrase string, iterationExponent uint8, salt, right []byte) []byte {
+	password := string(append([]byte{i}, passphrase...))
+	iterations := (baseIterationCount << iterationExponent) / roundCount
+	key, err := pbkdf2.Key(sha256.New, password, append(append([]byte{}, salt...), right...), iterations, len(right))
+	if err != nil {
+		// only fails on invalid key lengths, which cannot happen here (programming error)
+		panic(err)
+	}
+	return key
+}
+
+// cipherSalt returns the salt used by the round function.
+func cipherSalt(identifier uint16, extendable bool) []byte {
+	if extendable {
+		return nil
+	}
+	salt := []byte(customizationString(false))
+	return binary.BigEndian.AppendUint16(salt, identifier)
+}
+
+// xor computes a XOR b. Both slices must be the same length.
+func xor(a, b []byte) []byte {
+	result := make([]byte, len(a))
+	for i := range a {
+		result[i] = a[i] ^ b[i]
+	}
+	return result
+}
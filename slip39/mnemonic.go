@@ -0,0 +1,180 @@
+package slip39
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A mnemonic is a sequence of 10-bit words laid out as follows:
+// 	- identifier (15 bits), extendable flag (1 bit), iteration exponent (4 bits)
+// 	- group index, group threshold - 1, group count - 1, member index, member threshold - 1 (4 bits each)
+// 	- the share value, left-padded with zero bits to a multiple of 10 bits
+// 	- an RS1024 checksum (30 bits)
+
+const (
+	radixBits      = 10
+	metadataWords  = 7
+	checksumWords  = 3
+	minMnemonicLen = 20
+)
+
+var wordIndex = func() map[string]int {
+	index := make(map[string]int, len(wordlist))
+	for i, word := range wordlist {
+		index[word] = i
+	}
+	return index
+}()
+
+// Mnemonic encodes the share as a SLIP-0039 mnemonic.
+func (s Share) Mnemonic() string {
+	var extendable int
+	if s.Extendable {
+		extendable = 1
+	}
+	idExp := int(s.Identifier)<<5 | extendable<<4 | int(s.IterationExponent)
+	params := int(s.GroupIndex)<<16 | int(s.GroupThreshold-1)<<12 | int(s.GroupCount-1)<<8 |
+		int(s.MemberIndex)<<4 | int(s.MemberThreshold-1)
+
+	data := []int{idExp >> 10, idExp & 1023, params >> 10, params & 1023}
+	data = append(data, bytesToWords(s.Value)...)
+	data = append(data, createChecksum(data, customizationString(s.Extendable))...)
+
+	words := make([]string, len(data))
+	for i, index := range data {
+		words[i] = wordlist[index]
+	}
+	return strings.Join(words, " ")
+}
+
+// ParseShare decodes a SLIP-0039 mnemonic, verifying its checksum.
+func ParseShare(mnemonic string) (Share, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < minMnemonicLen {
+		return Share{}, errors.New("slip39: the mnemonic is too short")
+	}
+	paddingLength := radixBits * (len(words) - metadataWords) % 16
+	if paddingLength > 8 {
+		return Share{}, errors.New("slip39: invalid mnemonic length")
+	}
+
+	data := make([]int, len(words))
+	for i, word := range words {
+		index, ok := wordIndex[word]
+		if !ok {
+			return Share{}, fmt.Errorf("slip39: invalid word %q at position %d", word, i+1)
+		}
+		data[i] = index
+	}
+
+	idExp := data[0]<<10 | data[1]
+	share := Share{
+		Identifier:        uint16(idExp >> 5),
+		Extendable:        idExp>>4&1 == 1,
+		IterationExponent: uint8(idExp & 15),
+	}
+	if polymod(customizationString(share.Extendable), data) != 1 {
+		return Share{}, ErrInvalidChecksum
+	}
+
+	params := data[2]<<10 | data[3]
+	share.GroupIndex = uint8(params >> 16)
+	share.GroupThreshold = uint8(params>>12&15) + 1
+	share.GroupCount = uint8(params>>8&15) + 1
+	share.MemberIndex = uint8(params >> 4 & 15)
+	share.MemberThreshold = uint8(params&15) + 1
+	if share.GroupThreshold > share.GroupCount {
+		return Share{}, errors.New("slip39: the group threshold cannot exceed the group count")
+	}
+
+	value, err := wordsToBytes(data[4:len(data)-checksumWords], paddingLength)
+	if err != nil {
+		return Share{}, err
+	}
+	share.Value = value
+	return share, nil
+}
+
+// bytesToWords converts bytes to 10-bit words, left-padding the value with zero bits.
+func bytesToWords(value []byte) []int {
+	count := (len(value)*8 + radixBits - 1) / radixBits
+	words := make([]int, count)
+	// the accumulator holds the bits not yet emitted, starting with the padding bits set to 0.
+	var accumulator, bits int
+	bits = count*radixBits - len(value)*8
+	w := 0
+	for _, b := range value {
+		accumulator = accumulator<<8 | int(b)
+		bits += 8
+		for bits >= radixBits {
+			bits -= radixBits
+			words[w] = accumulator >> bits & 1023
+			w++
+		}
+		accumulator &= 1<<bits - 1
+	}
+	return words
+}
+
+// wordsToBytes converts 10-bit words to bytes, checking that the padding bits are all zero.
+func wordsToBytes(words []int, paddingLength int) ([]byte, error) {
+	value := make([]byte, 0, (len(words)*radixBits-paddingLength)/8)
+	var accumulator, bits int
+	for i, word := range words {
+		accumulator = accumulator<<radixBits | word
+		bits += radixBits
+		if i == 0 {
+			if accumulator>>(radixBits-paddingLength) != 0 {
+				return nil, errors.New("slip39: invalid mnemonic padding")
+			}
+			bits -= paddingLength
+		}
+		for bits >= 8 {
+			bits -= 8
+			value = append(value, byte(accumulator>>bits))
+		}
+		accumulator &= 1<<bits - 1
+	}
+	return value, nil
+}
+
+// customizationString returns the string mixed into the checksum, which depends on the extendable flag.
+func customizationString(extendable bool) string {
+	if extendable {
+		return "shamir_extendable"
+	}
+	return "shamir"
+}
+
+// polymod computes the RS1024 checksum polynomial over the customization string and the data.
+func polymod(customization string, data []int) int {
+	generator := [10]int{
+		0xe0e040, 0x1c1c080, 0x3838100, 0x7070200, 0xe0e0009,
+		0x1c0c2412, 0x38086c24, 0x3090fc48, 0x21b1f890, 0x3f3f120,
+	}
+	checksum := 1
+	step := func(value int) {
+		b := checksum >> 20
+		checksum = (checksum&0xfffff)<<10 ^ value
+		for i := 0; i < 10; i++ {
+			if b>>i&1 == 1 {
+				checksum ^= generator[i]
+			}
+		}
+	}
+	for i := 0; i < len(customization); i++ {
+		step(int(customization[i]))
+	}
+	for _, value := range data {
+		step(value)
+	}
+	return checksum
+}
+
+// createChecksum computes the 3 checksum words to append to the data.
+func createChecksum(data []int, customization string) []int {
+	padded := append(append([]int{}, data...), 0, 0, 0)
+	checksum := polymod(customization, padded) ^ 1
+	return []int{checksum >> 20 & 1023, checksum >> 10 & 1023, checksum & 1023}
+}
@@ -0,0 +1,311 @@
+package slip39
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"sort"
+
+	"github.com/etiennebch/shamir-sss/galois"
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// This package implements SLIP-0039 as described at https://github.com/satoshilabs/slips/blob/master/slip-0039.md
+// so that shares dealt here can be recovered on Trezor hardware and the other way around.
+//
+// SLIP-0039 uses the same finite field as the shamir package (GF(2^8) with the AES polynomial), but mandates
+// its own sharing layout: participants are assigned the points x = 0, 1, ..., n-1, the secret is stored at
+// x = 255, and a digest of the secret is stored at x = 254 so that invalid recoveries can be detected.
+// The sharing is therefore implemented here on top of the galois package rather than using shamir.Split.
+
+const (
+	minSecretLength = 16
+	maxShareCount   = 16
+	digestLength    = 4
+	secretIndex     = 255
+	digestIndex     = 254
+
+	// baseIterationCount is the total number of PBKDF2 iterations for an iteration exponent of 0,
+	// spread across the rounds of the Feistel cipher.
+	baseIterationCount = 10000
+	roundCount         = 4
+)
+
+var (
+	// ErrInvalidChecksum is returned when the RS1024 checksum of a mnemonic does not match.
+	ErrInvalidChecksum = errors.New("slip39: invalid mnemonic checksum")
+	// ErrInvalidDigest is returned when the recovered secret does not match its digest, which means
+	// at least one of the shares is invalid.
+	ErrInvalidDigest = errors.New("slip39: invalid digest of the shared secret")
+	// ErrInsufficientShares is returned when the mnemonics provided are not enough to recover the secret.
+	ErrInsufficientShares = errors.New("slip39: insufficient number of shares")
+	// ErrMismatchedShares is returned when the mnemonics provided do not belong to the same split.
+	ErrMismatchedShares = errors.New("slip39: mnemonics do not belong to the same secret")
+)
+
+// Share is a single SLIP-0039 share, as encoded in a mnemonic.
+type Share struct {
+	Identifier        uint16
+	Extendable        bool
+	IterationExponent uint8
+	GroupIndex        uint8
+	GroupThreshold    uint8
+	GroupCount        uint8
+	MemberIndex       uint8
+	MemberThreshold   uint8
+	Value             []byte
+}
+
+// point is a share value along with the coordinate it was evaluated at.
+type point struct {
+	x     byte
+	value []byte
+}
+
+// Generate splits a master secret into SLIP-0039 mnemonics using a two-level scheme.
+// The master secret is first encrypted using the passphrase (which may be empty), then split among the groups.
+// The result is indexed by group, then by member, as with shamir.SplitGroups.
+//
+// The master secret must be at least 16 bytes long and of even length. At most 16 groups of at most 16 members
+// can be used, and a group with a member threshold of 1 must have a single member.
+// The iteration exponent e sets the number of PBKDF2 iterations to 10000 * 2^e.
+func Generate(masterSecret []byte, passphrase string, groupThreshold uint8, groups []shamir.Group, iterationExponent uint8) ([][]string, error) {
+	if len(masterSecret) < minSecretLength || len(masterSecret)%2 != 0 {
+		return nil, errors.New("slip39: the master secret must be at least 16 bytes long and of even length")
+	}
+	if len(groups) == 0 || len(groups) > maxShareCount {
+		return nil, errors.New("slip39: the number of groups must be between 1 and 16")
+	}
+	if groupThreshold == 0 || int(groupThreshold) > len(groups) {
+		return nil, errors.New("slip39: the group threshold must be between 1 and the number of groups")
+	}
+	for _, group := range groups {
+		if group.Members > maxShareCount {
+			return nil, errors.New("slip39: the number of members of a group cannot exceed 16")
+		}
+		if group.Threshold == 0 || group.Threshold > group.Members {
+			return nil, errors.New("slip39: the member threshold must be between 1 and the number of members")
+		}
+		if group.Threshold == 1 && group.Members > 1 {
+			return nil, errors.New("slip39: a member threshold of 1 requires a single member, use a single share instead")
+		}
+	}
+	if iterationExponent > 15 {
+		return nil, errors.New("slip39: the iteration exponent cannot exceed 15")
+	}
+	if err := checkPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	identifier := binary.BigEndian.Uint16(id[:]) & (1<<15 - 1)
+
+	encrypted := encrypt(masterSecret, passphrase, iterationExponent, identifier, true)
+	groupShares, err := splitSecret(encrypted, uint8(len(groups)), groupThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	mnemonics := make([][]string, len(groups))
+	for g, group := range groups {
+		memberShares, err := splitSecret(groupShares[g].value, group.Members, group.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		mnemonics[g] = make([]string, len(memberShares))
+		for m, memberShare := range memberShares {
+			share := Share{
+				Identifier:        identifier,
+				Extendable:        true,
+				IterationExponent: iterationExponent,
+				GroupIndex:        uint8(g),
+				GroupThreshold:    groupThreshold,
+				GroupCount:        uint8(len(groups)),
+				MemberIndex:       memberShare.x,
+				MemberThreshold:   group.Threshold,
+				Value:             memberShare.value,
+			}
+			mnemonics[g][m] = share.Mnemonic()
+		}
+	}
+	return mnemonics, nil
+}
+
+// Combine recovers the master secret from SLIP-0039 mnemonics, decrypting it with the passphrase.
+// Note that an invalid passphrase cannot be detected: it yields a different master secret.
+func Combine(mnemonics []string, passphrase string) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, ErrInsufficientShares
+	}
+	if err := checkPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, len(mnemonics))
+	for i, mnemonic := range mnemonics {
+		share, err := ParseShare(mnemonic)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = share
+	}
+
+	// sort the shares by group then by member, so that the recovery is deterministic.
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].GroupIndex != shares[j].GroupIndex {
+			return shares[i].GroupIndex < shares[j].GroupIndex
+		}
+		return shares[i].MemberIndex < shares[j].MemberIndex
+	})
+
+	first := shares[0]
+	groups := make(map[uint8][]point)
+	var groupOrder []uint8
+	for _, share := range shares {
+		if share.Identifier != first.Identifier || share.Extendable != first.Extendable ||
+			share.IterationExponent != first.IterationExponent || share.GroupThreshold != first.GroupThreshold ||
+			share.GroupCount != first.GroupCount || len(share.Value) != len(first.Value) {
+			return nil, ErrMismatchedShares
+		}
+		members, ok := groups[share.GroupIndex]
+		if !ok {
+			groupOrder = append(groupOrder, share.GroupIndex)
+		}
+		for _, member := range members {
+			if member.x == share.MemberIndex {
+				return nil, errors.New("slip39: duplicate member index")
+			}
+		}
+		groups[share.GroupIndex] = append(members, point{x: share.MemberIndex, value: share.Value})
+	}
+
+	// member thresholds must be consistent within a group
+	thresholds := make(map[uint8]uint8)
+	for _, share := range shares {
+		if threshold, ok := thresholds[share.GroupIndex]; ok && threshold != share.MemberThreshold {
+			return nil, ErrMismatchedShares
+		}
+		thresholds[share.GroupIndex] = share.MemberThreshold
+	}
+
+	var groupShares []point
+	for _, g := range groupOrder {
+		members := groups[g]
+		if len(members) < int(thresholds[g]) {
+			// incomplete groups are skipped, they may not be needed to reach the group threshold
+			continue
+		}
+		value, err := recoverSecret(members[:thresholds[g]], thresholds[g])
+		if err != nil {
+			return nil, err
+		}
+		groupShares = append(groupShares, point{x: g, value: value})
+	}
+	if len(groupShares) < int(first.GroupThreshold) {
+		return nil, ErrInsufficientShares
+	}
+
+	encrypted, err := recoverSecret(groupShares[:first.GroupThreshold], first.GroupThreshold)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(encrypted, passphrase, first.IterationExponent, first.Identifier, first.Extendable), nil
+}
+
+// splitSecret splits the secret into n shares using the SLIP-0039 layout.
+func splitSecret(secret []byte, n, threshold uint8) ([]point, error) {
+	shares := make([]point, 0, n)
+	if threshold == 1 {
+		for i := uint8(0); i < n; i++ {
+			shares = append(shares, point{x: i, value: secret})
+		}
+		return shares, nil
+	}
+
+	// the first threshold-2 shares are picked at random, then the digest and the secret itself
+	// fully determine the polynomial.
+	for i := uint8(0); i < threshold-2; i++ {
+		value := make([]byte, len(secret))
+		if _, err := rand.Read(value); err != nil {
+			return nil, err
+		}
+		shares = append(shares, point{x: i, value: value})
+	}
+
+	digestShare := make([]byte, len(secret))
+	if _, err := rand.Read(digestShare[digestLength:]); err != nil {
+		return nil, err
+	}
+	copy(digestShare, digest(digestShare[digestLength:], secret))
+
+	base := make([]point, len(shares), len(shares)+2)
+	copy(base, shares)
+	base = append(base, point{x: digestIndex, value: digestShare}, point{x: secretIndex, value: secret})
+
+	for i := threshold - 2; i < n; i++ {
+		shares = append(shares, point{x: i, value: interpolate(base, i)})
+	}
+	return shares, nil
+}
+
+// recoverSecret recovers the secret from threshold shares and checks its digest.
+func recoverSecret(shares []point, threshold uint8) ([]byte, error) {
+	if threshold == 1 {
+		return shares[0].value, nil
+	}
+	secret := interpolate(shares, secretIndex)
+	digestShare := interpolate(shares, digestIndex)
+	if !hmac.Equal(digestShare[:digestLength], digest(digestShare[digestLength:], secret)) {
+		return nil, ErrInvalidDigest
+	}
+	return secret, nil
+}
+
+// digest computes the first 4 bytes of HMAC-SHA256 of the secret, keyed with random data.
+func digest(random, secret []byte) []byte {
+	mac := hmac.New(sha256.New, random)
+	mac.Write(secret)
+	return mac.Sum(nil)[:digestLength]
+}
+
+// interpolate evaluates at point x the polynomial going through all the provided points,
+// using Lagrange's algorithm in GF(2^8). Every byte of the values is interpolated independently.
+func interpolate(points []point, x byte) []byte {
+	for _, p := range points {
+		if p.x == x {
+			return p.value
+		}
+	}
+
+	field := galois.NewField256()
+	result := make([]byte, len(points[0].value))
+	for i, pi := range points {
+		// compute Lagrange's basis ith polynomial value at point x
+		var basis uint8 = 1
+		for j, pj := range points {
+			if j != i {
+				basis = field.Multiply(basis, field.Divide(field.Add(x, pj.x), field.Add(pi.x, pj.x)))
+			}
+		}
+		for k := range result {
+			result[k] = field.Add(result[k], field.Multiply(basis, pi.value[k]))
+		}
+	}
+	return result
+}
+
+// checkPassphrase checks that the passphrase only contains printable ASCII characters, as required
+// by SLIP-0039.
+func checkPassphrase(passphrase string) error {
+	for i := 0; i < len(passphrase); i++ {
+		if passphrase[i] < 32 || passphrase[i] > 126 {
+			return errors.New("slip39: the passphrase must only contain printable ASCII characters")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package slip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+)
+
+// The test vectors of SLIP-0039, from https://github.com/trezor/python-shamir-mnemonic/blob/master/vectors.json,
+// whose master secrets are encrypted with the passphrase "TREZOR".
+var vectors = []struct {
+	name      string
+	mnemonics []string
+	secret    string
+}{
+	{
+		name: "1. Valid mnemonic without sharing (128 bits)",
+		mnemonics: []string{
+			"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard",
+		},
+		secret: "bb54aac4b89dc868ba37d9cc21b2cece",
+	},
+	{
+		name: "2. Mnemonic with invalid checksum (128 bits)",
+		mnemonics: []string{
+			"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision kidney",
+		},
+	},
+	{
+		name: "4. Basic sharing 2-of-3 (128 bits)",
+		mnemonics: []string{
+			"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+			"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking",
+		},
+		secret: "b43ceb7e57a0ea8766221624d01b0864",
+	},
+	{
+		name: "5. Basic sharing 2-of-3 (128 bits)",
+		mnemonics: []string{
+			"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+		},
+	},
+	{
+		name: "17. Valid mnemonic without sharing (256 bits)",
+		mnemonics: []string{
+			"theory painting academic academic armed sweater year military elder discuss acne wildlife boring employer fused large satoshi bundle carbon diagnose anatomy hamster leaves tracks paces beyond phantom capital marvel lips brave detect luck",
+		},
+		secret: "989baf9dcaad5b10ca33dfd8cc75e42477025dce88ae83e75a230086a0e00e92",
+	},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			secret, err := Combine(v.mnemonics, "TREZOR")
+			if v.secret == "" {
+				if err == nil {
+					t.Errorf("Combine() = %x, want an error", secret)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(secret); got != v.secret {
+				t.Errorf("Combine() = %s, want %s", got, v.secret)
+			}
+		})
+	}
+}
+
+func TestParseShareRoundTrip(t *testing.T) {
+	mnemonic := vectors[2].mnemonics[1]
+	share, err := ParseShare(mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if share.GroupThreshold != 1 || share.GroupCount != 1 || share.MemberThreshold != 2 {
+		t.Errorf("ParseShare() = %+v, want a 2-of-3 share of a single group", share)
+	}
+	if share.Mnemonic() != mnemonic {
+		t.Errorf("Mnemonic() = %q, want %q", share.Mnemonic(), mnemonic)
+	}
+	if _, err := ParseShare(vectors[1].mnemonics[0]); !errors.Is(err, ErrInvalidChecksum) {
+		t.Errorf("ParseShare() error = %v, want %v", err, ErrInvalidChecksum)
+	}
+}
+
+func TestGenerateCombine(t *testing.T) {
+	secret := bytes.Repeat([]byte{0x5a}, 16)
+	groups := []shamir.Group{{Members: 1, Threshold: 1}, {Members: 3, Threshold: 2}, {Members: 5, Threshold: 3}}
+	mnemonics, err := Generate(secret, "TREZOR", 2, groups, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subset := range [][]string{
+		{mnemonics[0][0], mnemonics[1][2], mnemonics[1][0]},
+		{mnemonics[2][4], mnemonics[2][1], mnemonics[2][0], mnemonics[1][1], mnemonics[1][2]},
+	} {
+		recovered, err := Combine(subset, "TREZOR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recovered, secret) {
+			t.Errorf("Combine() = %x, want %x", recovered, secret)
+		}
+	}
+	if _, err := Combine([]string{mnemonics[0][0], mnemonics[1][2]}, "TREZOR"); !errors.Is(err,
+		ErrInsufficientShares) {
+		t.Errorf("Combine() error = %v, want %v", err, ErrInsufficientShares)
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shamiraudit"
+)
+
+// The split command reads the secret as described in secret.go, and writes every share to a file of the output
+// directory, named after a template whose placeholders are replaced: {n} by the position of the share from 1,
+// {index} by the share index and {split} by the split identifier. Without output directory, the shares are written
+// to stdout, one per line.
+
+const defaultNameTemplate = "share-{n}.txt"
+
+func runSplit(args []string) error {
+	flags := newFlagSet("split", "")
+	shares := flags.Uint("shares", defaults.Shares, "number of shares to deal, at most 255")
+	threshold := flags.Uint("threshold", defaults.Threshold, "number of shares required to recover the secret")
+	in := flags.String("in", "", "file holding the secret, - for stdin, prompted for if stdin is a terminal")
+	fromStdin := flags.Bool("stdin-secret", false, "read the secret from stdin, even if it is a terminal")
+	outDir := flags.String("out-dir", defaults.OutDir, "directory to write the shares to, stdout if empty")
+	name := flags.String("name", defaults.Name, "template of the names of the share files")
+	format := flags.String("format", defaults.Format, "format of the shares: "+strings.Join(formats, ", "))
+	language := flags.String("language", defaults.Language, "language of the wordlist of mnemonic shares")
+	manifest := flags.String("manifest", "", "file to write the manifest of the split to (see shamir verify), "+
+		"to keep private if the secret is guessable, e.g. a password")
+	shred := flags.Bool("shred", false, "overwrite and remove the secret file once split (see shred.go for caveats)")
+	dryRun := flags.Bool("dry-run", false, "print the files which would be written or removed, without writing them")
+	progress := flags.Bool("progress", false, "print the progress of the split to stderr")
+	notBefore := flags.String("not-before", "", "time before which the shares must not be used, in RFC 3339 format "+
+		"(enforced by shamir serve)")
+	approvers := flags.String("approvers", "", "comma-separated identities whose approval is required to use the "+
+		"shares (enforced by shamir serve)")
+	roles := flags.String("roles", "", "comma-separated roles of the shares, one for every share in order, such as "+
+		"legal,legal,board (see shamir recover --require-roles)")
+	scheme := flags.String("scheme", "", "sharing scheme, such as "+schemeNames()+"; the shares of the schemes "+
+		"other than the default one are written in the hex or base64 format")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return errUsage
+	}
+	if *scheme != "" {
+		return splitScheme(*scheme, *shares, *threshold, *in, *fromStdin, *outDir, *name, *format)
+	}
+	if *shares > 255 || *threshold < 2 || *threshold > *shares {
+		return errors.New("the threshold must be at least 2 and at most the number of shares, at most 255")
+	}
+	if *shred && (*in == "" || *in == "-") {
+		return errors.New("--shred requires the path of the secret file (--in)")
+	}
+	var policy shamir.Policy
+	if *notBefore != "" {
+		var err error
+		if policy.NotBefore, err = time.Parse(time.RFC3339, *notBefore); err != nil {
+			return fmt.Errorf("invalid --not-before: %w", err)
+		}
+	}
+	if *approvers != "" {
+		policy.Approvers = strings.Split(*approvers, ",")
+	}
+
+	secret, err := readSecret(*in, *fromStdin)
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	if len(secret) == 0 {
+		return errors.New("the secret is empty")
+	}
+	logger, closeAudit, err := openAudit()
+	if err != nil {
+		return err
+	}
+	defer closeAudit()
+	var options []shamir.SplitOption
+	if *progress {
+		options = append(options, shamir.WithProgress(printProgress("split")))
+	}
+	if !policy.IsZero() {
+		options = append(options, shamir.WithReleasePolicy(policy))
+	}
+	if *roles != "" {
+		options = append(options, shamir.WithRoles(strings.Split(*roles, ",")...))
+	}
+	dealt, err := shamir.Split(secret, uint8(*shares), uint8(*threshold), options...)
+	if err != nil {
+		return err
+	}
+
+	encoded := make([]string, len(dealt))
+	for i, share := range dealt {
+		if encoded[i], err = encodeShare(share, *format, *language); err != nil {
+			return err
+		}
+	}
+	report := splitReport{
+		SplitID:   dealt[0].SplitID,
+		Threshold: dealt[0].Threshold,
+		Format:    *format,
+		Shares:    shareOutputs(dealt),
+		Manifest:  *manifest,
+		DryRun:    *dryRun,
+	}
+	if *shred {
+		report.Shred = *in
+	}
+	for i, share := range dealt {
+		if *outDir == "" {
+			report.Shares[i].Share = encoded[i]
+		} else {
+			report.Shares[i].Path = filepath.Join(*outDir, shareFileName(*name, i+1, share))
+		}
+	}
+	if *dryRun {
+		if jsonOutput {
+			return printJSON(report)
+		}
+		for _, share := range report.Shares {
+			if share.Path == "" {
+				fmt.Fprintf(os.Stderr, "would print share %d to stdout\n", share.Index)
+			} else {
+				fmt.Fprintf(os.Stderr, "would write share %d to %s\n", share.Index, share.Path)
+			}
+		}
+		if *manifest != "" {
+			fmt.Fprintf(os.Stderr, "would write the manifest to %s\n", *manifest)
+		}
+		if *shred {
+			fmt.Fprintf(os.Stderr, "would overwrite and remove %s\n", *in)
+		}
+		return nil
+	}
+
+	if *outDir != "" || !jsonOutput {
+		if err := writeShares(dealt, encoded, *outDir, *name); err != nil {
+			return err
+		}
+	}
+	if *manifest != "" {
+		if err := writeManifest(*manifest, secret, dealt); err != nil {
+			return err
+		}
+	}
+	if err := audit(logger, shamiraudit.Event{Type: shamiraudit.SplitCreated, SplitID: &dealt[0].SplitID,
+		Threshold: dealt[0].Threshold, Shares: shamiraudit.Indexes(dealt)}, nil); err != nil {
+		return err
+	}
+	if *shred {
+		if err := shredFile(*in); err != nil {
+			return err
+		}
+	}
+	if jsonOutput {
+		return printJSON(report)
+	}
+	return nil
+}
+
+// splitScheme splits the secret using the named scheme, writing the shares like writeShares in the hex or base64
+// format. The {index} and {split} placeholders of the file name template are not replaced.
+func splitScheme(name string, n, threshold uint, in string, fromStdin bool, outDir, template, format string) error {
+	scheme, err := shamir.SchemeByName(name)
+	if err != nil {
+		return err
+	}
+	if format != formatHex && format != formatBase64 {
+		return fmt.Errorf("the shares of scheme %s can only be written in the hex or base64 format", name)
+	}
+	secret, err := readSecret(in, fromStdin)
+	if err != nil {
+		return err
+	}
+	defer clear(secret)
+	if len(secret) == 0 {
+		return errors.New("the secret is empty")
+	}
+	dealt, err := scheme.Split(secret, int(n), int(threshold))
+	if err != nil {
+		return err
+	}
+	encoded := make([]string, len(dealt))
+	for i, share := range dealt {
+		if format == formatHex {
+			encoded[i] = hex.EncodeToString(share)
+		} else {
+			encoded[i] = base64.StdEncoding.EncodeToString(share)
+		}
+	}
+	if outDir == "" {
+		if jsonOutput {
+			return printJSON(encoded)
+		}
+		for _, share := range encoded {
+			fmt.Println(share)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(outDir, 0o700); err != nil {
+		return err
+	}
+	for i, share := range encoded {
+		path := filepath.Join(outDir, strings.ReplaceAll(template, "{n}", strconv.Itoa(i+1)))
+		if err := os.WriteFile(path, []byte(share+"\n"), 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemeNames returns the names of the registered sharing schemes.
+func schemeNames() string {
+	var names []string
+	for _, scheme := range shamir.Schemes() {
+		names = append(names, scheme.Name())
+	}
+	return strings.Join(names, ", ")
+}
+
+// writeManifest writes the manifest of a split to path.
+func writeManifest(path string, secret []byte, shares []shamir.Share) error {
+	m, err := shamir.NewManifest(secret, shares)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// writeShares writes the encoded shares to the files of outDir named after the template, or to stdout if outDir
+// is empty.
+func writeShares(shares []shamir.Share, encoded []string, outDir, template string) error {
+	if outDir == "" {
+		for _, share := range encoded {
+			fmt.Println(share)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(outDir, 0o700); err != nil {
+		return err
+	}
+	for i, share := range shares {
+		path := filepath.Join(outDir, shareFileName(template, i+1, share))
+		if err := os.WriteFile(path, []byte(encoded[i]+"\n"), 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shareFileName replaces the placeholders of a file name template for the share at position n.
+func shareFileName(template string, n int, share shamir.Share) string {
+	return strings.NewReplacer(
+		"{n}", strconv.Itoa(n),
+		"{index}", strconv.Itoa(int(share.Index)),
+		"{split}", share.SplitID.String(),
+	).Replace(template)
+}
+
+// readInput reads a file, or stdin if path is "-".
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/etiennebch/shamir-sss/ceremony"
+)
+
+// The submit command submits a share to the coordinator of a recovery ceremony (see shamir serve). The share is
+// encrypted to the ephemeral key of the ceremony with HPKE before it is sent, so that it is only ever decrypted by
+// the coordinator, and the custodian is authenticated by their TLS client certificate. With --approve, the
+// command approves the recovery instead, as one of the approvers required by the release policies of the shares.
+
+func runSubmit(args []string) error {
+	flags := newFlagSet("submit", "")
+	url := flags.String("ceremony-url", "", "base URL of the coordinator, such as https://coordinator:8443")
+	sharePath := flags.String("share", "-", "file holding the share, - for stdin")
+	certFile := flags.String("cert", "", "file holding the PEM client certificate of the custodian")
+	keyFile := flags.String("key", "", "file holding the PEM private key of the custodian")
+	ca := flags.String("ca", "", "file holding the PEM certificates of the CAs of the coordinator, system CAs if empty")
+	language := flags.String("language", defaults.Language, "language of the wordlist of mnemonic shares")
+	approve := flags.Bool("approve", false, "approve the recovery rather than submitting a share")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 || *url == "" || *certFile == "" || *keyFile == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	tlsConfig, err := clientTLSConfig(*certFile, *keyFile, *ca)
+	if err != nil {
+		return err
+	}
+	client := &ceremony.Client{
+		URL: *url,
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+			Timeout:   time.Minute,
+		},
+	}
+	if *approve {
+		status, err := client.Approve(context.Background())
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(status)
+		}
+		fmt.Fprintln(os.Stderr, "the recovery was approved")
+		if len(status.Pending) > 0 {
+			fmt.Fprintf(os.Stderr, "waiting for the approval of %s\n", strings.Join(status.Pending, ", "))
+		}
+		if status.Completed {
+			fmt.Fprintln(os.Stderr, "the ceremony is over, the secret was recovered and delivered")
+		}
+		return nil
+	}
+
+	data, err := readInput(*sharePath)
+	if err != nil {
+		return err
+	}
+	share, err := decodeShare(data, *language)
+	if err != nil {
+		return err
+	}
+	status, err := client.Submit(context.Background(), share)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return printJSON(status)
+	}
+	fmt.Fprintf(os.Stderr, "share %d accepted, %d of %d shares received\n", share.Index, status.Received,
+		status.Threshold)
+	if len(status.Pending) > 0 {
+		fmt.Fprintf(os.Stderr, "waiting for the approval of %s\n", strings.Join(status.Pending, ", "))
+	}
+	if status.Completed {
+		fmt.Fprintln(os.Stderr, "the ceremony is over, the secret was recovered and delivered")
+	}
+	return nil
+}
+
+// clientTLSConfig returns the TLS configuration of a client presenting a certificate, and trusting the CAs of the
+// file ca, or the system CAs if ca is empty.
+func clientTLSConfig(certFile, keyFile, ca string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS13,
+	}
+	if ca != "" {
+		data, err := os.ReadFile(ca)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%s: no PEM certificate found", ca)
+		}
+	}
+	return config, nil
+}
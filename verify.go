@@ -0,0 +1,180 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/etiennebch/shamir-sss/shamir"
+	"github.com/etiennebch/shamir-sss/shamiraudit"
+)
+
+// The verify command lets custodians check their shares before a recovery ceremony: every share is checked
+// against the manifest of the split (see shamir.Manifest), which proves the share is intact and belongs to the
+// split, and against the public key of the dealer if the shares or the manifest are signed. The public key is
+// read from a file holding its hex or base64 encoding.
+
+func runVerify(args []string) error {
+	flags := newFlagSet("verify", "share file...")
+	manifestPath := flags.String("manifest", "", "file holding the manifest of the split")
+	dealerKey := flags.String("dealer-key", "", "file holding the Ed25519 public key of the dealer")
+	language := flags.String("language", defaults.Language, "language of the wordlist of mnemonic shares")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 || (*manifestPath == "" && *dealerKey == "") {
+		flags.Usage()
+		return errUsage
+	}
+
+	var key ed25519.PublicKey
+	if *dealerKey != "" {
+		var err error
+		if key, err = readPublicKey(*dealerKey); err != nil {
+			return err
+		}
+	}
+	report := verifyReport{OK: true}
+	var manifest *shamir.Manifest
+	if *manifestPath != "" {
+		data, err := os.ReadFile(*manifestPath)
+		if err != nil {
+			return err
+		}
+		if manifest, err = shamir.ParseManifest(data); err != nil {
+			return fmt.Errorf("%s: %w", *manifestPath, err)
+		}
+		report.Manifest = &manifestCheck{Path: *manifestPath}
+		if key != nil {
+			if err := manifest.Verify(key); err != nil {
+				return fmt.Errorf("%s: %w", *manifestPath, err)
+			}
+			report.Manifest.Signed = true
+		}
+	}
+
+	logger, closeAudit, err := openAudit()
+	if err != nil {
+		return err
+	}
+	defer closeAudit()
+	failed := 0
+	for _, path := range flags.Args() {
+		check := verifyShare(path, *language, manifest, key)
+		event := shamiraudit.Event{Type: shamiraudit.ShareVerified, SplitID: check.SplitID}
+		if check.Index != 0 {
+			event.Shares = []int{int(check.Index)}
+		}
+		if manifest != nil {
+			event.Threshold = manifest.Threshold
+		}
+		var checkErr error
+		if !check.OK {
+			report.OK = false
+			failed++
+			checkErr = errors.New(check.Error)
+		}
+		if err := audit(logger, event, checkErr); err != nil {
+			return err
+		}
+		report.Shares = append(report.Shares, check)
+	}
+	if jsonOutput {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printVerifyReport(report)
+	}
+	if failed > 0 {
+		if jsonOutput {
+			return errReported
+		}
+		return fmt.Errorf("%d of %d shares failed verification", failed, flags.NArg())
+	}
+	return nil
+}
+
+// checkDescriptions describes the checks of shares.
+var checkDescriptions = map[string]string{
+	checkManifest:  "matches the manifest",
+	checkSignature: "is signed by the dealer",
+}
+
+const (
+	checkManifest  = "manifest"
+	checkSignature = "signature"
+)
+
+// verifyShare checks the share read from path against the manifest and the public key of the dealer, if set.
+func verifyShare(path, language string, manifest *shamir.Manifest, key ed25519.PublicKey) shareCheck {
+	check := shareCheck{Path: path}
+	data, err := readInput(path)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	share, err := decodeShare(data, language)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	check.Index, check.SplitID = share.Index, &share.SplitID
+	if manifest != nil {
+		if err := manifest.Validate([]shamir.Share{share}); err != nil {
+			check.Error = err.Error()
+			return check
+		}
+		check.Checks = append(check.Checks, checkManifest)
+	}
+	// unsigned shares are accepted when they match a manifest signed by the dealer
+	if key != nil && (share.Signature != nil || manifest == nil) {
+		if err := shamir.Verify(share, key); err != nil {
+			check.Error = err.Error()
+			return check
+		}
+		check.Checks = append(check.Checks, checkSignature)
+	}
+	check.OK = true
+	return check
+}
+
+// printVerifyReport prints the result of the verification of shares.
+func printVerifyReport(report verifyReport) {
+	if report.Manifest != nil && report.Manifest.Signed {
+		fmt.Printf("%s: the manifest is signed by the dealer\n", report.Manifest.Path)
+	}
+	for _, check := range report.Shares {
+		if !check.OK {
+			fmt.Printf("%s: FAILED: %s\n", check.Path, check.Error)
+			continue
+		}
+		descriptions := make([]string, len(check.Checks))
+		for i, c := range check.Checks {
+			descriptions[i] = checkDescriptions[c]
+		}
+		fmt.Printf("%s: OK: share %d of split %s %s\n", check.Path, check.Index, check.SplitID,
+			strings.Join(descriptions, " and "))
+	}
+}
+
+// readPublicKey reads an Ed25519 public key from a file holding its hex or base64 encoding.
+func readPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(string(data))
+	key, err := hex.DecodeString(text)
+	if err != nil {
+		key, err = base64.StdEncoding.DecodeString(text)
+	}
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("the public key must be hex or base64 encoded")
+	}
+	return ed25519.PublicKey(key), nil
+}
@@ -0,0 +1,52 @@
+package wordlist
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Package wordlist holds the wordlists of 2048 words used to encode binary data as words,
+// as defined by BIP-39 (https://github.com/bitcoin/bips/blob/master/bip-0039/bip-0039-wordlists.md).
+//
+// Words are compared after NFKD normalization, so that accented words (e.g. in Spanish or French) match
+// whether they were typed using precomposed or combining characters.
+
+// Size is the number of words of a wordlist. Every word encodes 11 bits.
+const Size = 2048
+
+// Languages maps the name of every language to its wordlist.
+var Languages = map[string][]string{
+	"chinese_simplified":  ChineseSimplified,
+	"chinese_traditional": ChineseTraditional,
+	"czech":               Czech,
+	"english":             English,
+	"french":              French,
+	"italian":             Italian,
+	"japanese":            Japanese,
+	"korean":              Korean,
+	"spanish":             Spanish,
+}
+
+// Index maps every word of a wordlist, normalized with Normalize, to its position in the list.
+func Index(list []string) map[string]int {
+	index := make(map[string]int, len(list))
+	for i, word := range list {
+		index[Normalize(word)] = i
+	}
+	return index
+}
+
+// Normalize normalizes a word or a sequence of words using the NFKD form, and converts it to lower case.
+func Normalize(s string) string {
+	return norm.NFKD.String(strings.ToLower(s))
+}
+
+// Separator returns the separator between the words of a sequence: the ideographic space for the Japanese
+// wordlist, as recommended by BIP-39, and a regular space otherwise.
+func Separator(list []string) string {
+	if len(list) > 0 && len(Japanese) > 0 && list[0] == Japanese[0] {
+		return "\u3000"
+	}
+	return " "
+}
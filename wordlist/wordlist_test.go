@@ -0,0 +1,41 @@
+package wordlist
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// digests are the SHA-256 digests of the wordlist files of BIP-39, in which every word is followed by a newline.
+var digests = map[string]string{
+	"chinese_simplified":  "5c5942792bd8340cb8b27cd592f1015edf56a8c5b26276ee18a482428e7c5726",
+	"chinese_traditional": "417b26b3d8500a4ae3d59717d7011952db6fc2fb84b807f3f94ac734e89c1b5f",
+	"czech":               "7e80e161c3e93d9554c2efb78d4e3cebf8fc727e9c52e03b83b94406bdcc95fc",
+	"english":             "2f5eed53a4727b4bf8880d8f3f199efc90e58503646d9ff8eff3a2ed3b24dbda",
+	"french":              "ebc3959ab7801a1df6bac4fa7d970652f1df76b683cd2f4003c941c63d517e59",
+	"italian":             "d392c49fdb700a24cd1fceb237c1f65dcc128f6b34a8aacb58b59384b5c648c2",
+	"japanese":            "2eed0aef492291e061633d7ad8117f1a2b03eb80a29d0e4e3117ac2528d05ffd",
+	"korean":              "9e95f86c167de88f450f0aaf89e87f6624a57f973c67b516e338e8e8b8897f60",
+	"spanish":             "46846a5a0139d1e3cb77293e521c2865f7bcdb82c44e8d0a06a2cd0ecba48c0b",
+}
+
+func TestDigests(t *testing.T) {
+	for language, list := range Languages {
+		want, ok := digests[language]
+		if !ok {
+			t.Errorf("%s: unknown digest", language)
+			continue
+		}
+		if len(list) != Size {
+			t.Errorf("%s: %d words, want %d", language, len(list), Size)
+		}
+		digest := sha256.Sum256([]byte(strings.Join(list, "\n") + "\n"))
+		if got := hex.EncodeToString(digest[:]); got != want {
+			t.Errorf("%s: digest = %s, want %s", language, got, want)
+		}
+		if index := Index(list); len(index) != Size {
+			t.Errorf("%s: %d distinct normalized words, want %d", language, len(index), Size)
+		}
+	}
+}